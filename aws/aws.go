@@ -42,7 +42,7 @@ var USEast = Region{
 	"https://sns.us-east-1.amazonaws.com",
 	"https://sqs.us-east-1.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.us-east-1.amazonaws.com",
 }
 
 var USWest = Region{
@@ -56,7 +56,7 @@ var USWest = Region{
 	"https://sns.us-west-1.amazonaws.com",
 	"https://sqs.us-west-1.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.us-west-1.amazonaws.com",
 }
 
 var USWest2 = Region{
@@ -70,7 +70,7 @@ var USWest2 = Region{
 	"https://sns.us-west-2.amazonaws.com",
 	"https://sqs.us-west-2.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.us-west-2.amazonaws.com",
 }
 
 var EUWest = Region{
@@ -84,7 +84,21 @@ var EUWest = Region{
 	"https://sns.eu-west-1.amazonaws.com",
 	"https://sqs.eu-west-1.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.eu-west-1.amazonaws.com",
+}
+
+var EUCentral = Region{
+	"eu-central-1",
+	"https://ec2.eu-central-1.amazonaws.com",
+	"https://s3-eu-central-1.amazonaws.com",
+	"",
+	true,
+	true,
+	"",
+	"https://sns.eu-central-1.amazonaws.com",
+	"https://sqs.eu-central-1.amazonaws.com",
+	"https://iam.amazonaws.com",
+	"https://elasticloadbalancing.eu-central-1.amazonaws.com",
 }
 
 var APSoutheast = Region{
@@ -98,7 +112,7 @@ var APSoutheast = Region{
 	"https://sns.ap-southeast-1.amazonaws.com",
 	"https://sqs.ap-southeast-1.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.ap-southeast-1.amazonaws.com",
 }
 
 var APSoutheast2 = Region{
@@ -112,7 +126,7 @@ var APSoutheast2 = Region{
 	"https://sns.ap-southeast-2.amazonaws.com",
 	"https://sqs.ap-southeast-2.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.ap-southeast-2.amazonaws.com",
 }
 
 var APNortheast = Region{
@@ -126,7 +140,7 @@ var APNortheast = Region{
 	"https://sns.ap-northeast-1.amazonaws.com",
 	"https://sqs.ap-northeast-1.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.ap-northeast-1.amazonaws.com",
 }
 
 var SAEast = Region{
@@ -140,13 +154,29 @@ var SAEast = Region{
 	"https://sns.sa-east-1.amazonaws.com",
 	"https://sqs.sa-east-1.amazonaws.com",
 	"https://iam.amazonaws.com",
-	"https://elasticloadbalancing.amazonaws.com",
+	"https://elasticloadbalancing.sa-east-1.amazonaws.com",
+}
+
+var CNNorth = Region{
+	"cn-north-1",
+	"https://ec2.cn-north-1.amazonaws.com.cn",
+	"https://s3.cn-north-1.amazonaws.com.cn",
+	"",
+	true,
+	true,
+	"",
+	"https://sns.cn-north-1.amazonaws.com.cn",
+	"https://sqs.cn-north-1.amazonaws.com.cn",
+	"https://iam.cn-north-1.amazonaws.com.cn",
+	"https://elasticloadbalancing.cn-north-1.amazonaws.com.cn",
 }
 
 var Regions = map[string]Region{
 	APNortheast.Name:  APNortheast,
 	APSoutheast.Name:  APSoutheast,
 	APSoutheast2.Name: APSoutheast2,
+	CNNorth.Name:      CNNorth,
+	EUCentral.Name:    EUCentral,
 	EUWest.Name:       EUWest,
 	USEast.Name:       USEast,
 	USWest.Name:       USWest,
@@ -154,8 +184,70 @@ var Regions = map[string]Region{
 	SAEast.Name:       SAEast,
 }
 
+func init() {
+	for _, r := range []Region{
+		newRegion("us-east-2", "aws"),
+		newRegion("ca-central-1", "aws"),
+		newRegion("ca-west-1", "aws"),
+		newRegion("eu-west-2", "aws"),
+		newRegion("eu-west-3", "aws"),
+		newRegion("eu-central-2", "aws"),
+		newRegion("eu-north-1", "aws"),
+		newRegion("eu-south-1", "aws"),
+		newRegion("eu-south-2", "aws"),
+		newRegion("ap-northeast-2", "aws"),
+		newRegion("ap-northeast-3", "aws"),
+		newRegion("ap-southeast-3", "aws"),
+		newRegion("ap-southeast-4", "aws"),
+		newRegion("ap-southeast-5", "aws"),
+		newRegion("ap-southeast-7", "aws"),
+		newRegion("ap-south-1", "aws"),
+		newRegion("ap-south-2", "aws"),
+		newRegion("ap-east-1", "aws"),
+		newRegion("me-south-1", "aws"),
+		newRegion("me-central-1", "aws"),
+		newRegion("il-central-1", "aws"),
+		newRegion("af-south-1", "aws"),
+		newRegion("mx-central-1", "aws"),
+		newRegion("us-gov-west-1", "aws-us-gov"),
+		newRegion("us-gov-east-1", "aws-us-gov"),
+		newRegion("cn-northwest-1", "aws-cn"),
+	} {
+		Regions[r.Name] = r
+	}
+}
+
+// newRegion returns the region with the usual endpoints of its partition.
+// SimpleDB isn't available in the regions it's used for.
+func newRegion(name, partition string) Region {
+	domain := "amazonaws.com"
+	iam := "https://iam.amazonaws.com"
+	switch partition {
+	case "aws-cn":
+		domain = "amazonaws.com.cn"
+		iam = "https://iam." + name + "." + domain
+	case "aws-us-gov":
+		iam = "https://iam.us-gov.amazonaws.com"
+	}
+	return Region{
+		Name:                 name,
+		EC2Endpoint:          "https://ec2." + name + "." + domain,
+		S3Endpoint:           "https://s3." + name + "." + domain,
+		S3LocationConstraint: true,
+		S3LowercaseBucket:    true,
+		SNSEndpoint:          "https://sns." + name + "." + domain,
+		SQSEndpoint:          "https://sqs." + name + "." + domain,
+		IAMEndpoint:          iam,
+		ELBEndpoint:          "https://elasticloadbalancing." + name + "." + domain,
+	}
+}
+
+// Auth holds the credentials used to sign requests. Token is only set for
+// temporary credentials, such as the ones issued by STS or attached to an
+// EC2 instance profile.
 type Auth struct {
 	AccessKey, SecretKey string
+	Token                string
 }
 
 var unreserved = make([]bool, 128)
@@ -171,10 +263,11 @@ func init() {
 
 // EnvAuth creates an Auth based on environment information.
 // The AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment
-// variables are used.
+// variables are used, as well as AWS_SESSION_TOKEN when present.
 func EnvAuth() (auth Auth, err error) {
 	auth.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
 	auth.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	auth.Token = os.Getenv("AWS_SESSION_TOKEN")
 	if auth.AccessKey == "" {
 		err = errors.New("AWS_ACCESS_KEY_ID not found in environment")
 	}
@@ -0,0 +1,325 @@
+package aws
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider is implemented by types able to supply the
+// credentials used to sign requests. Implementations must be safe for
+// concurrent use, since a single client may be shared by many goroutines.
+type CredentialsProvider interface {
+	Credentials() (Auth, error)
+}
+
+// StaticProvider is a CredentialsProvider that always returns the same
+// credentials.
+type StaticProvider Auth
+
+func (p StaticProvider) Credentials() (Auth, error) {
+	return Auth(p), nil
+}
+
+// DefaultMetadataEndpoint is the address of the EC2 instance metadata
+// service.
+const DefaultMetadataEndpoint = "http://169.254.169.254"
+
+// metadataTokenTTL is the lifetime requested for the session tokens of
+// the metadata service. A token is only used for one refresh of the
+// credentials.
+const metadataTokenTTL = 5 * time.Minute
+
+// refreshWindow defines how long before expiration temporary credentials
+// are considered stale and get fetched again.
+const refreshWindow = 5 * time.Minute
+
+// InstanceMetadataProvider retrieves the credentials of the IAM role
+// associated with the EC2 instance the program is running on.
+//
+// Credentials are cached and transparently refreshed shortly before they
+// expire. Requests are authenticated with a session token, as required by
+// the Instance Metadata Service Version 2, unless the metadata service
+// rejects the request of a token, in which case Version 1 is used.
+//
+// See http://docs.aws.amazon.com/AWSEC2/latest/UserGuide/iam-roles-for-amazon-ec2.html
+// for more details.
+type InstanceMetadataProvider struct {
+	// Endpoint is the base URL of the metadata service. It defaults to
+	// DefaultMetadataEndpoint.
+	Endpoint string
+	// Client is the HTTP client used to talk to the metadata service.
+	Client *http.Client
+
+	mutex      sync.Mutex
+	auth       Auth
+	expiration time.Time
+}
+
+// NewInstanceMetadataProvider returns an InstanceMetadataProvider talking
+// to the default metadata endpoint.
+func NewInstanceMetadataProvider() *InstanceMetadataProvider {
+	return &InstanceMetadataProvider{
+		Endpoint: DefaultMetadataEndpoint,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type metadataCredentials struct {
+	Code            string
+	AccessKeyId     string
+	SecretAccessKey string
+	Token           string
+	Expiration      time.Time
+}
+
+// Credentials returns the instance role credentials, fetching new ones
+// from the metadata service when the cached ones are about to expire.
+func (p *InstanceMetadataProvider) Credentials() (Auth, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.auth.AccessKey != "" && time.Now().Add(refreshWindow).Before(p.expiration) {
+		return p.auth, nil
+	}
+	token, err := p.token()
+	if err != nil {
+		return Auth{}, err
+	}
+	role, err := p.role(token)
+	if err != nil {
+		return Auth{}, err
+	}
+	var creds metadataCredentials
+	r, err := p.get("/latest/meta-data/iam/security-credentials/"+role, token)
+	if err != nil {
+		return Auth{}, err
+	}
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		return Auth{}, fmt.Errorf("cannot decode instance credentials: %v", err)
+	}
+	if creds.Code != "Success" {
+		return Auth{}, fmt.Errorf("cannot retrieve instance credentials: %s", creds.Code)
+	}
+	p.auth = Auth{
+		AccessKey: creds.AccessKeyId,
+		SecretKey: creds.SecretAccessKey,
+		Token:     creds.Token,
+	}
+	p.expiration = creds.Expiration
+	return p.auth, nil
+}
+
+// token returns a session token of the metadata service, or an empty
+// string if the metadata service rejects the request, as the ones only
+// supporting Version 1 do.
+func (p *InstanceMetadataProvider) token() (string, error) {
+	req, err := http.NewRequest("PUT", p.url("/latest/api/token"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", strconv.Itoa(int(metadataTokenTTL/time.Second)))
+	r, err := p.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != 200 {
+		return "", nil
+	}
+	token, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// role returns the name of the first IAM role attached to the instance.
+func (p *InstanceMetadataProvider) role(token string) (string, error) {
+	r, err := p.get("/latest/meta-data/iam/security-credentials/", token)
+	if err != nil {
+		return "", err
+	}
+	defer r.Body.Close()
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		if role := strings.TrimSpace(scanner.Text()); role != "" {
+			return role, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("no IAM role attached to the instance")
+}
+
+// get sends a GET request for path to the metadata service, authenticated
+// with token unless it's empty.
+func (p *InstanceMetadataProvider) get(path, token string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", p.url(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+	}
+	r, err := p.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != 200 {
+		r.Body.Close()
+		return nil, fmt.Errorf("metadata service returned %s for %s", r.Status, path)
+	}
+	return r, nil
+}
+
+func (p *InstanceMetadataProvider) url(path string) string {
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultMetadataEndpoint
+	}
+	return strings.TrimRight(endpoint, "/") + path
+}
+
+func (p *InstanceMetadataProvider) client() *http.Client {
+	if p.Client == nil {
+		return http.DefaultClient
+	}
+	return p.Client
+}
+
+// EnvProvider reads credentials from the environment, as described in
+// EnvAuth.
+type EnvProvider struct{}
+
+func (EnvProvider) Credentials() (Auth, error) {
+	return EnvAuth()
+}
+
+// SharedCredentialsProvider reads credentials from the shared credentials
+// file used by the AWS command line tools.
+type SharedCredentialsProvider struct {
+	// Filename is the path of the credentials file. When empty, the
+	// AWS_SHARED_CREDENTIALS_FILE environment variable is used, falling
+	// back to ~/.aws/credentials.
+	Filename string
+	// Profile is the section of the file holding the credentials. When
+	// empty, the AWS_PROFILE environment variable is used, falling back to
+	// "default".
+	Profile string
+}
+
+func (p SharedCredentialsProvider) Credentials() (auth Auth, err error) {
+	filename := p.Filename
+	if filename == "" {
+		filename = os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	}
+	if filename == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			return auth, errors.New("cannot locate the shared credentials file: HOME not set")
+		}
+		filename = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := p.Profile
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return auth, err
+	}
+	defer f.Close()
+	var section string
+	found := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || section == profile
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "aws_access_key_id":
+			auth.AccessKey = value
+		case "aws_secret_access_key":
+			auth.SecretKey = value
+		case "aws_session_token":
+			auth.Token = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Auth{}, err
+	}
+	if !found {
+		return Auth{}, fmt.Errorf("profile %q not found in %s", profile, filename)
+	}
+	if auth.AccessKey == "" || auth.SecretKey == "" {
+		return Auth{}, fmt.Errorf("profile %q in %s has incomplete credentials", profile, filename)
+	}
+	return auth, nil
+}
+
+// ChainProvider asks each of its providers for credentials, in order, and
+// sticks to the first one that succeeds.
+type ChainProvider struct {
+	Providers []CredentialsProvider
+
+	mutex   sync.Mutex
+	current CredentialsProvider
+}
+
+// NewChainProvider returns the default credentials chain: the environment,
+// then the shared credentials file, then the EC2 instance metadata service.
+func NewChainProvider() *ChainProvider {
+	return &ChainProvider{
+		Providers: []CredentialsProvider{
+			EnvProvider{},
+			SharedCredentialsProvider{},
+			NewInstanceMetadataProvider(),
+		},
+	}
+}
+
+func (p *ChainProvider) Credentials() (Auth, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.current != nil {
+		return p.current.Credentials()
+	}
+	var errs []string
+	for _, provider := range p.Providers {
+		auth, err := provider.Credentials()
+		if err == nil {
+			p.current = provider
+			return auth, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return Auth{}, errors.New("no valid credentials found: " + strings.Join(errs, "; "))
+}
@@ -0,0 +1,208 @@
+package aws_test
+
+import (
+	"fmt"
+	"github.com/flaviamissi/go-elb/aws"
+	"io/ioutil"
+	. "launchpad.net/gocheck"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// metadataServer fakes the metadata service. It only supports Version 1
+// unless token is set, in which case it requires Version 2 and its
+// session token.
+type metadataServer struct {
+	*httptest.Server
+	hits       int
+	expiration time.Time
+	token      string
+}
+
+func newMetadataServer(expiration time.Time) *metadataServer {
+	s := &metadataServer{expiration: expiration}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" && r.URL.Path == "/latest/api/token" && s.token != "" {
+			if r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+				http.Error(w, "missing TTL", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, s.token)
+			return
+		}
+		if r.Method != "GET" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s.token != "" && r.Header.Get("X-aws-ec2-metadata-token") != s.token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprintln(w, "webserver")
+		case "/latest/meta-data/iam/security-credentials/webserver":
+			s.hits++
+			fmt.Fprintf(w, `{
+  "Code" : "Success",
+  "LastUpdated" : "2012-04-26T16:39:16Z",
+  "Type" : "AWS-HMAC",
+  "AccessKeyId" : "access-%d",
+  "SecretAccessKey" : "secret",
+  "Token" : "token",
+  "Expiration" : %q
+}`, s.hits, s.expiration.UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+func (s *S) TestInstanceMetadataProvider(c *C) {
+	srv := newMetadataServer(time.Now().Add(time.Hour))
+	defer srv.Close()
+	p := aws.NewInstanceMetadataProvider()
+	p.Endpoint = srv.URL
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "access-1", SecretKey: "secret", Token: "token"})
+	auth, err = p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-1")
+	c.Assert(srv.hits, Equals, 1)
+}
+
+func (s *S) TestInstanceMetadataProviderVersion2(c *C) {
+	srv := newMetadataServer(time.Now().Add(time.Hour))
+	srv.token = "session-token"
+	defer srv.Close()
+	p := aws.NewInstanceMetadataProvider()
+	p.Endpoint = srv.URL
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "access-1", SecretKey: "secret", Token: "token"})
+}
+
+func (s *S) TestInstanceMetadataProviderFallsBackToVersion1(c *C) {
+	// The server rejects the PUT request of a token, as the metadata
+	// services only supporting Version 1 do.
+	srv := newMetadataServer(time.Now().Add(time.Hour))
+	defer srv.Close()
+	p := aws.NewInstanceMetadataProvider()
+	p.Endpoint = srv.URL
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-1")
+}
+
+func (s *S) TestInstanceMetadataProviderRefreshesBeforeExpiration(c *C) {
+	srv := newMetadataServer(time.Now().Add(time.Minute))
+	defer srv.Close()
+	p := aws.NewInstanceMetadataProvider()
+	p.Endpoint = srv.URL
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-1")
+	auth, err = p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-2")
+}
+
+func (s *S) TestInstanceMetadataProviderWithoutRole(c *C) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	p := aws.NewInstanceMetadataProvider()
+	p.Endpoint = srv.URL
+	_, err := p.Credentials()
+	c.Assert(err, ErrorMatches, "metadata service returned 404 Not Found for .*")
+}
+
+var sharedCredentials = `
+# comment
+[default]
+aws_access_key_id = default-access
+aws_secret_access_key = default-secret
+
+[work]
+aws_access_key_id=work-access
+aws_secret_access_key=work-secret
+aws_session_token=work-token
+`
+
+func (s *S) writeCredentials(c *C) string {
+	filename := filepath.Join(c.MkDir(), "credentials")
+	err := ioutil.WriteFile(filename, []byte(sharedCredentials), 0600)
+	c.Assert(err, IsNil)
+	return filename
+}
+
+func (s *S) TestSharedCredentialsProvider(c *C) {
+	p := aws.SharedCredentialsProvider{Filename: s.writeCredentials(c)}
+	auth, err := p.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "default-access", SecretKey: "default-secret"})
+}
+
+func (s *S) TestSharedCredentialsProviderProfile(c *C) {
+	os.Clearenv()
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", s.writeCredentials(c))
+	os.Setenv("AWS_PROFILE", "work")
+	auth, err := aws.SharedCredentialsProvider{}.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "work-access", SecretKey: "work-secret", Token: "work-token"})
+}
+
+func (s *S) TestSharedCredentialsProviderMissingProfile(c *C) {
+	p := aws.SharedCredentialsProvider{Filename: s.writeCredentials(c), Profile: "absent"}
+	_, err := p.Credentials()
+	c.Assert(err, ErrorMatches, `profile "absent" not found in .*`)
+}
+
+func (s *S) TestChainProviderPrefersEnvironment(c *C) {
+	os.Clearenv()
+	os.Setenv("AWS_ACCESS_KEY_ID", "env-access")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", s.writeCredentials(c))
+	auth, err := aws.NewChainProvider().Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "env-access", SecretKey: "env-secret"})
+}
+
+func (s *S) TestChainProviderFallsBackToSharedFile(c *C) {
+	os.Clearenv()
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", s.writeCredentials(c))
+	auth, err := aws.NewChainProvider().Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth, Equals, aws.Auth{AccessKey: "default-access", SecretKey: "default-secret"})
+}
+
+func (s *S) TestChainProviderFallsBackToInstanceMetadata(c *C) {
+	os.Clearenv()
+	srv := newMetadataServer(time.Now().Add(time.Hour))
+	defer srv.Close()
+	metadata := aws.NewInstanceMetadataProvider()
+	metadata.Endpoint = srv.URL
+	chain := &aws.ChainProvider{
+		Providers: []aws.CredentialsProvider{
+			aws.EnvProvider{},
+			aws.SharedCredentialsProvider{Filename: filepath.Join(c.MkDir(), "absent")},
+			metadata,
+		},
+	}
+	auth, err := chain.Credentials()
+	c.Assert(err, IsNil)
+	c.Assert(auth.AccessKey, Equals, "access-1")
+}
+
+func (s *S) TestChainProviderWithoutCredentials(c *C) {
+	os.Clearenv()
+	chain := &aws.ChainProvider{
+		Providers: []aws.CredentialsProvider{aws.EnvProvider{}},
+	}
+	_, err := chain.Credentials()
+	c.Assert(err, ErrorMatches, "no valid credentials found: .*")
+}
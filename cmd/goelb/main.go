@@ -0,0 +1,272 @@
+// Command goelb manages classic Elastic Load Balancers from the command
+// line, using the elb package.
+//
+// Usage:
+//
+//	goelb [-region name] [-endpoint url] [-json] command [arguments]
+//
+// The commands are:
+//
+//	create -listener spec [-listener spec...] [-zone name...] [-subnet id...] [-sg id...] [-internal] lb
+//	delete lb
+//	describe [lb...]
+//	register lb instance...
+//	deregister lb instance...
+//	health [-watch] [-interval duration] [-threshold fraction] lb [instance...]
+//
+// Listeners are given as protocol:port:instance-protocol:instance-port,
+// optionally followed by :certificate-id for HTTPS and SSL listeners, e.g.
+// http:80:http:8080.
+//
+// With -watch, health describes the instances again every interval (5s by
+// default) and redraws the table, until interrupted. With -threshold, it
+// exits with status 1 as soon as less than that fraction of the instances,
+// from 0 to 1, is InService, e.g. to abort a deploy.
+//
+// Regions missing from aws.Regions, like recently launched ones, are
+// reached through the ELB endpoint of their partition and signed with
+// Signature Version 4, as elb.LookupRegion tells.
+//
+// Credentials are read from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN environment variables, the shared credentials file
+// or the EC2 instance metadata service, in this order. Results are printed
+// as tables, or as JSON with -json.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usage = `usage: goelb [-region name] [-endpoint url] [-json] command [arguments]
+
+commands:
+  create -listener spec [-zone name] [-subnet id] [-sg id] [-internal] lb
+  delete lb
+  describe [lb...]
+  register lb instance...
+  deregister lb instance...
+  health [-watch] [-interval duration] [-threshold fraction] lb [instance...]
+`
+
+// errUsage is returned by commands called with invalid arguments.
+var errUsage = errors.New("invalid arguments")
+
+// newClient creates the ELB client used by the commands. Tests replace it
+// to authenticate against a fake server.
+var newClient = func(region aws.Region, options ...elb.Option) (*elb.ELB, error) {
+	return elb.NewFromChain(region, options...)
+}
+
+type command func(ctx context.Context, e *elb.ELB, args []string, out *output) error
+
+var commands = map[string]command{
+	"create":     create,
+	"delete":     deleteLB,
+	"describe":   describe,
+	"register":   register,
+	"deregister": deregister,
+	"health":     health,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	status := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(status)
+}
+
+// run runs goelb with the given arguments, and returns its exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("goelb", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	regionName := fs.String("region", os.Getenv("AWS_REGION"), "AWS region of the Load Balancers")
+	endpoint := fs.String("endpoint", "", "URL of the ELB API, overriding the one of the region")
+	asJSON := fs.Bool("json", false, "print results as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "goelb: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return 2
+	}
+	if *regionName == "" {
+		*regionName = "us-east-1"
+	}
+	region := elb.LookupRegion(*regionName)
+	if *endpoint != "" {
+		region = region.WithEndpoint(*endpoint)
+	}
+	var options []elb.Option
+	if region.SignatureV4Only {
+		options = append(options, elb.WithSignatureVersion(elb.SignatureV4))
+	}
+	e, err := newClient(region.AWSRegion(), options...)
+	if err != nil {
+		fmt.Fprintf(stderr, "goelb: %v\n", err)
+		return 1
+	}
+	out := &output{w: stdout, json: *asJSON}
+	if err := cmd(ctx, e, fs.Args()[1:], out); err != nil {
+		if err == errUsage {
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(stderr, "goelb %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	return 0
+}
+
+// stringsFlag is a flag that may be repeated, or given a comma separated
+// list of values.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, strings.Split(value, ",")...)
+	return nil
+}
+
+// parseListener parses a listener spec, such as http:80:http:8080 or
+// https:443:http:80:arn:aws:iam::123456789012:server-certificate/cert.
+func parseListener(spec string) (elb.Listener, error) {
+	parts := strings.SplitN(spec, ":", 5)
+	if len(parts) < 4 {
+		return elb.Listener{}, fmt.Errorf("invalid listener %q: want protocol:port:instance-protocol:instance-port[:certificate-id]", spec)
+	}
+	lbPort, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return elb.Listener{}, fmt.Errorf("invalid listener %q: bad port %q", spec, parts[1])
+	}
+	instancePort, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return elb.Listener{}, fmt.Errorf("invalid listener %q: bad instance port %q", spec, parts[3])
+	}
+	l := elb.Listener{
+		Protocol:         strings.ToUpper(parts[0]),
+		LoadBalancerPort: lbPort,
+		InstanceProtocol: strings.ToUpper(parts[2]),
+		InstancePort:     instancePort,
+	}
+	if len(parts) == 5 {
+		l.SSLCertificateId = parts[4]
+	}
+	return l, nil
+}
+
+func create(ctx context.Context, e *elb.ELB, args []string, out *output) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var listeners, zones, subnets, groups stringsFlag
+	fs.Var(&listeners, "listener", "")
+	fs.Var(&zones, "zone", "")
+	fs.Var(&subnets, "subnet", "")
+	fs.Var(&groups, "sg", "")
+	internal := fs.Bool("internal", false, "")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || len(listeners) == 0 {
+		return errUsage
+	}
+	options := elb.CreateLoadBalancer{
+		Name:           fs.Arg(0),
+		AvailZones:     zones,
+		Subnets:        subnets,
+		SecurityGroups: groups,
+	}
+	if *internal {
+		options.Scheme = elb.SchemeInternal
+	}
+	for _, spec := range listeners {
+		l, err := parseListener(spec)
+		if err != nil {
+			return err
+		}
+		options.Listeners = append(options.Listeners, l)
+	}
+	resp, err := e.CreateLoadBalancerWithContext(ctx, &options)
+	if err != nil {
+		return err
+	}
+	return out.created(options.Name, resp.DNSName)
+}
+
+func deleteLB(ctx context.Context, e *elb.ELB, args []string, out *output) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	_, err := e.DeleteLoadBalancerWithContext(ctx, args[0])
+	return err
+}
+
+func describe(ctx context.Context, e *elb.ELB, args []string, out *output) error {
+	descs, err := e.DescribeLoadBalancersAllWithContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+	return out.loadBalancers(descs)
+}
+
+func register(ctx context.Context, e *elb.ELB, args []string, out *output) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	resp, err := e.RegisterInstancesWithLoadBalancerWithContext(ctx, args[1:], args[0])
+	if err != nil {
+		return err
+	}
+	return out.instances(resp.InstanceIds)
+}
+
+func deregister(ctx context.Context, e *elb.ELB, args []string, out *output) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	resp, err := e.DeregisterInstancesFromLoadBalancerWithContext(ctx, args[1:], args[0])
+	if err != nil {
+		return err
+	}
+	return out.instances(resp.InstanceIds)
+}
+
+func health(ctx context.Context, e *elb.ELB, args []string, out *output) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	watch := fs.Bool("watch", false, "")
+	interval := fs.Duration("interval", 5*time.Second, "")
+	threshold := fs.Float64("threshold", 0, "")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 1 || *interval <= 0 || *threshold < 0 || *threshold > 1 {
+		return errUsage
+	}
+	lbName, instanceIds := fs.Arg(0), fs.Args()[1:]
+	if *watch {
+		return watchHealth(ctx, e, lbName, instanceIds, *interval, *threshold, out)
+	}
+	resp, err := e.DescribeInstanceHealthWithContext(ctx, lbName, instanceIds...)
+	if err != nil {
+		return err
+	}
+	if err := out.health(resp.InstanceStates); err != nil {
+		return err
+	}
+	return checkThreshold(lbName, resp.InstanceStates, *threshold)
+}
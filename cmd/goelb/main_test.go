@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elb/elbtest"
+	. "launchpad.net/gocheck"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type S struct {
+	srv *elbtest.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpSuite(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	s.srv = srv
+	newClient = func(region aws.Region, options ...elb.Option) (*elb.ELB, error) {
+		return elb.New(aws.Auth{AccessKey: "access", SecretKey: "secret"}, region, options...), nil
+	}
+}
+
+func (s *S) TearDownSuite(c *C) {
+	s.srv.Quit()
+}
+
+func (s *S) TearDownTest(c *C) {
+	s.srv.Reset()
+}
+
+func (s *S) goelb(c *C, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-endpoint", s.srv.URL()}, args...)
+	status := run(context.Background(), args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), status
+}
+
+func (s *S) TestParseListener(c *C) {
+	l, err := parseListener("http:80:http:8080")
+	c.Assert(err, IsNil)
+	c.Assert(l, DeepEquals, elb.Listener{Protocol: "HTTP", LoadBalancerPort: 80, InstanceProtocol: "HTTP", InstancePort: 8080})
+	l, err = parseListener("https:443:http:80:arn:aws:iam::123456789012:server-certificate/cert")
+	c.Assert(err, IsNil)
+	c.Assert(l.SSLCertificateId, Equals, "arn:aws:iam::123456789012:server-certificate/cert")
+	_, err = parseListener("http:80")
+	c.Assert(err, ErrorMatches, `invalid listener "http:80": .*`)
+	_, err = parseListener("http:web:http:80")
+	c.Assert(err, ErrorMatches, `invalid listener "http:web:http:80": bad port "web"`)
+}
+
+func (s *S) TestCreateDescribeAndDelete(c *C) {
+	stdout, stderr, status := s.goelb(c, "create", "-listener", "http:80:http:8080", "-zone", "us-east-1a", "testlb")
+	c.Assert(status, Equals, 0, Commentf("stderr: %s", stderr))
+	c.Assert(stdout, Matches, `(?s)NAME\s+DNS NAME\ntestlb\s+\S+\n`)
+	stdout, _, status = s.goelb(c, "describe")
+	c.Assert(status, Equals, 0)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	c.Assert(lines, HasLen, 2)
+	c.Assert(lines[1], Matches, `testlb\s+.*HTTP:80→8080\s+0`)
+	stdout, _, status = s.goelb(c, "-json", "describe", "testlb")
+	c.Assert(status, Equals, 0)
+	var descs []elb.LoadBalancerDescription
+	c.Assert(json.Unmarshal([]byte(stdout), &descs), IsNil)
+	c.Assert(descs, HasLen, 1)
+	c.Assert(descs[0].LoadBalancerName, Equals, "testlb")
+	_, _, status = s.goelb(c, "delete", "testlb")
+	c.Assert(status, Equals, 0)
+	stdout, _, status = s.goelb(c, "-json", "describe")
+	c.Assert(status, Equals, 0)
+	c.Assert(stdout, Equals, "[]\n")
+}
+
+func (s *S) TestRegisterAndHealth(c *C) {
+	_, _, status := s.goelb(c, "create", "-listener", "http:80:http:80", "-zone", "us-east-1a", "testlb")
+	c.Assert(status, Equals, 0)
+	instId := s.srv.NewInstance()
+	stdout, stderr, status := s.goelb(c, "register", "testlb", instId)
+	c.Assert(status, Equals, 0, Commentf("stderr: %s", stderr))
+	c.Assert(stdout, Equals, "INSTANCE\n"+instId+"\n")
+	stdout, _, status = s.goelb(c, "-json", "health", "testlb")
+	c.Assert(status, Equals, 0)
+	var states []elb.InstanceState
+	c.Assert(json.Unmarshal([]byte(stdout), &states), IsNil)
+	c.Assert(states, HasLen, 1)
+	c.Assert(states[0].InstanceId, Equals, instId)
+	stdout, _, status = s.goelb(c, "-json", "deregister", "testlb", instId)
+	c.Assert(status, Equals, 0)
+	c.Assert(stdout, Equals, "{\n  \"Instances\": []\n}\n")
+}
+
+func (s *S) TestErrors(c *C) {
+	_, stderr, status := s.goelb(c)
+	c.Assert(status, Equals, 2)
+	c.Assert(stderr, Matches, "usage: goelb .*(?s).*")
+	_, stderr, status = s.goelb(c, "frobnicate")
+	c.Assert(status, Equals, 2)
+	c.Assert(stderr, Matches, `goelb: unknown command "frobnicate"\n(?s).*`)
+	_, _, status = s.goelb(c, "register", "testlb")
+	c.Assert(status, Equals, 2)
+	_, stderr, status = s.goelb(c, "health", "unknown")
+	c.Assert(status, Equals, 1)
+	c.Assert(stderr, Matches, `goelb health: .*\(LoadBalancerNotFound\)\n`)
+}
+
+func (s *S) TestRegionMissingFromTable(c *C) {
+	orig := newClient
+	defer func() { newClient = orig }()
+	var e *elb.ELB
+	newClient = func(r aws.Region, options ...elb.Option) (*elb.ELB, error) {
+		var err error
+		e, err = orig(r, options...)
+		return e, err
+	}
+	_, stderr, status := s.goelb(c, "-region", "ap-southeast-9", "describe")
+	c.Assert(status, Equals, 0, Commentf("%s", stderr))
+	c.Assert(e.Region.Name, Equals, "ap-southeast-9")
+	c.Assert(e.Region.ELBEndpoint, Equals, s.srv.URL())
+	c.Assert(e.SignatureVersion(), Equals, elb.SignatureV4)
+	_, stderr, status = s.goelb(c, "-region", "us-east-1", "describe")
+	c.Assert(status, Equals, 0, Commentf("%s", stderr))
+	c.Assert(e.SignatureVersion(), Equals, elb.SignatureV2)
+}
+
+func (s *S) TestHealthThreshold(c *C) {
+	s.srv.NewLoadBalancer("testlb")
+	healthy, sick := s.srv.NewInstance(), s.srv.NewInstance()
+	s.srv.RegisterInstance(healthy, "testlb")
+	s.srv.RegisterInstance(sick, "testlb")
+	s.srv.SetInstanceState("testlb", healthy, elb.StateInService, "", "")
+	s.srv.SetInstanceState("testlb", sick, elb.StateOutOfService, "Instance", "Instance has failed at least the UnhealthyThreshold number of health checks consecutively.")
+	_, _, status := s.goelb(c, "health", "-threshold", "0.5", "testlb")
+	c.Assert(status, Equals, 0)
+	_, stderr, status := s.goelb(c, "health", "-threshold", "0.75", "testlb")
+	c.Assert(status, Equals, 1)
+	c.Assert(stderr, Equals, "goelb health: testlb: 1 of 2 instances healthy, below the threshold of 0.75\n")
+	_, _, status = s.goelb(c, "health", "-threshold", "2", "testlb")
+	c.Assert(status, Equals, 2)
+}
+
+func (s *S) TestHealthWatch(c *C) {
+	s.srv.NewLoadBalancer("testlb")
+	instId := s.srv.NewInstance()
+	s.srv.RegisterInstance(instId, "testlb")
+	s.srv.SetInstanceState("testlb", instId, elb.StateInService, "", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	done := make(chan int)
+	go func() {
+		args := []string{"-endpoint", s.srv.URL(), "health", "-watch", "-interval", "5ms", "-threshold", "1", "testlb"}
+		done <- run(ctx, args, &stdout, &stderr)
+	}()
+	for i := 0; len(s.srv.RequestsFor("DescribeInstanceHealth")) < 3; i++ {
+		c.Assert(i < 200, Equals, true, Commentf("stderr: %s", stderr.String()))
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.srv.SetInstanceState("testlb", instId, elb.StateOutOfService, "Instance", "")
+	select {
+	case status := <-done:
+		c.Assert(status, Equals, 1)
+	case <-time.After(time.Second):
+		c.Fatalf("watch didn't stop below the threshold")
+	}
+	c.Assert(stdout.String(), Matches, `(?s)testlb  \S+\n\nINSTANCE.*InService.*OutOfService.*`)
+	c.Assert(stderr.String(), Equals, "goelb health: testlb: 0 of 1 instances healthy, below the threshold of 1\n")
+}
+
+func (s *S) TestHealthWatchInterrupted(c *C) {
+	s.srv.NewLoadBalancer("testlb")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	args := []string{"-endpoint", s.srv.URL(), "-json", "health", "-watch", "-interval", "5ms", "testlb"}
+	c.Assert(run(ctx, args, &stdout, &stderr), Equals, 0)
+	c.Assert(strings.Count(stdout.String(), "[]\n") > 1, Equals, true)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"io"
+	"strings"
+)
+
+// output prints the results of the commands, either as tables or as JSON.
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+func (o *output) encode(v interface{}) error {
+	enc := json.NewEncoder(o.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// table prints rows aligned in columns, below a header.
+func (o *output) table(header []string, rows [][]string) error {
+	_, err := io.WriteString(o.w, elb.FormatTable(header, rows))
+	return err
+}
+
+func (o *output) created(name, dnsName string) error {
+	if o.json {
+		return o.encode(struct {
+			LoadBalancerName string
+			DNSName          string
+		}{name, dnsName})
+	}
+	return o.table([]string{"NAME", "DNS NAME"}, [][]string{{name, dnsName}})
+}
+
+func (o *output) loadBalancers(descs []elb.LoadBalancerDescription) error {
+	if o.json {
+		if descs == nil {
+			descs = []elb.LoadBalancerDescription{}
+		}
+		return o.encode(descs)
+	}
+	rows := make([][]string, len(descs))
+	for i, desc := range descs {
+		listeners := make([]string, len(desc.ListenerDescriptions))
+		for j, ld := range desc.ListenerDescriptions {
+			listeners[j] = ld.Listener.String()
+		}
+		rows[i] = []string{
+			desc.LoadBalancerName,
+			desc.DNSName,
+			desc.Scheme,
+			strings.Join(listeners, ","),
+			fmt.Sprint(len(desc.Instances)),
+		}
+	}
+	return o.table([]string{"NAME", "DNS NAME", "SCHEME", "LISTENERS", "INSTANCES"}, rows)
+}
+
+func (o *output) instances(ids []string) error {
+	if o.json {
+		if ids == nil {
+			ids = []string{}
+		}
+		return o.encode(struct{ Instances []string }{ids})
+	}
+	rows := make([][]string, len(ids))
+	for i, id := range ids {
+		rows[i] = []string{id}
+	}
+	return o.table([]string{"INSTANCE"}, rows)
+}
+
+func (o *output) health(states []elb.InstanceState) error {
+	if o.json {
+		if states == nil {
+			states = []elb.InstanceState{}
+		}
+		return o.encode(states)
+	}
+	rows := make([][]string, len(states))
+	for i, s := range states {
+		rows[i] = []string{s.InstanceId, string(s.State), s.ReasonCode, s.Description}
+	}
+	return o.table([]string{"INSTANCE", "STATE", "REASON", "DESCRIPTION"}, rows)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"io"
+	"os"
+	"time"
+)
+
+// belowThresholdError is returned by health when the fraction of healthy
+// instances of the Load Balancer drops below the threshold.
+type belowThresholdError struct {
+	lbName             string
+	healthy, instances int
+	threshold          float64
+}
+
+func (e *belowThresholdError) Error() string {
+	return fmt.Sprintf("%s: %d of %d instances healthy, below the threshold of %g", e.lbName, e.healthy, e.instances, e.threshold)
+}
+
+// checkThreshold returns a *belowThresholdError if less than threshold of
+// the instances are healthy. A zero threshold disables the check.
+func checkThreshold(lbName string, states []elb.InstanceState, threshold float64) error {
+	if threshold <= 0 {
+		return nil
+	}
+	healthy := 0
+	for _, s := range states {
+		if s.IsHealthy() {
+			healthy++
+		}
+	}
+	if len(states) > 0 && float64(healthy)/float64(len(states)) >= threshold {
+		return nil
+	}
+	return &belowThresholdError{lbName, healthy, len(states), threshold}
+}
+
+// watchHealth describes the health of the instances every interval,
+// redrawing the table each time, until ctx is done or the Load Balancer
+// drops below the threshold.
+func watchHealth(ctx context.Context, e *elb.ELB, lbName string, instanceIds []string, interval time.Duration, threshold float64, out *output) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp, err := e.DescribeInstanceHealthWithContext(ctx, lbName, instanceIds...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if !out.json {
+			if isTerminal(out.w) {
+				fmt.Fprint(out.w, "\033[H\033[2J")
+			}
+			fmt.Fprintf(out.w, "%s  %s\n\n", lbName, time.Now().Format(time.RFC3339))
+		}
+		if err := out.health(resp.InstanceStates); err != nil {
+			return err
+		}
+		if err := checkThreshold(lbName, resp.InstanceStates, threshold); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// isTerminal reports whether w is a terminal, where the table can be
+// redrawn in place.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
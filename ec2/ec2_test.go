@@ -15,7 +15,7 @@ type S struct {
 
 func (s *S) SetUpSuite(c *C) {
 	s.HTTPSuite.SetUpSuite(c)
-	auth := aws.Auth{"abc", "123"}
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
 	s.ec2 = ec2.New(auth, aws.Region{EC2Endpoint: testServer.URL})
 }
 
@@ -0,0 +1,277 @@
+package elb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EnableAccessLogs makes the Load Balancer store its access logs in the
+// given S3 bucket, under prefix, every interval. AWS accepts intervals of
+// 5 and 60 minutes.
+//
+// The bucket policy must allow Elastic Load Balancing to write to the
+// bucket, otherwise AWS answers with an InvalidConfigurationRequest error.
+// Use CheckAccessLogBucketPolicy beforehand for a clearer error.
+func (elb *ELB) EnableAccessLogs(lbName, bucket, prefix string, interval time.Duration) error {
+	return elb.EnableAccessLogsWithContext(context.Background(), lbName, bucket, prefix, interval)
+}
+
+// EnableAccessLogsWithContext is like EnableAccessLogs, but the requests
+// are bound to ctx.
+func (elb *ELB) EnableAccessLogsWithContext(ctx context.Context, lbName, bucket, prefix string, interval time.Duration) error {
+	attrs := &LoadBalancerAttributes{
+		AccessLog: &AccessLog{
+			Enabled:        true,
+			S3BucketName:   bucket,
+			S3BucketPrefix: prefix,
+			EmitInterval:   int(interval / time.Minute),
+		},
+	}
+	_, err := elb.ModifyLoadBalancerAttributesWithContext(ctx, lbName, attrs)
+	return err
+}
+
+// S3 is the subset of the S3 API needed to check bucket policies.
+// Implement it on top of any S3 client to use CheckAccessLogBucketPolicy.
+type S3 interface {
+	// GetBucketPolicy returns the policy document of the bucket.
+	GetBucketPolicy(ctx context.Context, bucket string) (string, error)
+}
+
+// elbAccounts maps the regions to the AWS accounts Elastic Load Balancing
+// uses to write access logs. Regions launched after August 2022 have no
+// such account, the logdelivery service principal is used instead.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/classic/enable-access-logs.html
+// for more details.
+var elbAccounts = map[string]string{
+	"us-east-1":      "127311923021",
+	"us-east-2":      "033677994240",
+	"us-west-1":      "027434742980",
+	"us-west-2":      "797873946194",
+	"af-south-1":     "098369216593",
+	"ca-central-1":   "985666609251",
+	"eu-central-1":   "054676820928",
+	"eu-west-1":      "156460612806",
+	"eu-west-2":      "652711504416",
+	"eu-south-1":     "635631232127",
+	"eu-west-3":      "009996457667",
+	"eu-north-1":     "897822967062",
+	"ap-east-1":      "754344448648",
+	"ap-northeast-1": "582318560864",
+	"ap-northeast-2": "600734575887",
+	"ap-northeast-3": "383597477331",
+	"ap-southeast-1": "114774131450",
+	"ap-southeast-2": "783225319266",
+	"ap-south-1":     "718504428378",
+	"me-south-1":     "076674570225",
+	"sa-east-1":      "507241528517",
+	"us-gov-west-1":  "048591011584",
+	"us-gov-east-1":  "190560391635",
+	"cn-north-1":     "638102146993",
+	"cn-northwest-1": "037604701340",
+}
+
+const logDeliveryPrincipal = "logdelivery.elasticloadbalancing.amazonaws.com"
+
+// AccessLogPolicyError is returned by CheckAccessLogBucketPolicy when the
+// policy of the bucket doesn't allow Elastic Load Balancing to write the
+// access logs.
+type AccessLogPolicyError struct {
+	Bucket string
+	// Principals lists the principals, any of which must be allowed to
+	// write to Resource.
+	Principals []string
+	Resource   string
+}
+
+func (e *AccessLogPolicyError) Error() string {
+	return fmt.Sprintf("elb: policy of bucket %s doesn't allow %s to s3:PutObject on %s",
+		e.Bucket, strings.Join(e.Principals, " or "), e.Resource)
+}
+
+// CheckAccessLogBucketPolicy checks that the policy of the bucket allows
+// Elastic Load Balancing to write the access logs of the Load Balancers of
+// the given account and region under prefix, returning an
+// *AccessLogPolicyError if it doesn't.
+//
+// Only Allow statements are considered, Deny statements and conditions are
+// ignored.
+func CheckAccessLogBucketPolicy(ctx context.Context, s3 S3, region, bucket, prefix, accountId string) error {
+	doc, err := s3.GetBucketPolicy(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	var policy bucketPolicy
+	if err := json.Unmarshal([]byte(doc), &policy); err != nil {
+		return fmt.Errorf("elb: invalid policy of bucket %s: %v", bucket, err)
+	}
+	key := "AWSLogs/" + accountId + "/elasticloadbalancing/" + region + "/"
+	if prefix != "" {
+		key = strings.Trim(prefix, "/") + "/" + key
+	}
+	resource := ARN{Partition: PartitionOf(region), Service: "s3", Resource: bucket + "/" + key}.String()
+	principals := []string{logDeliveryPrincipal}
+	if account, ok := elbAccounts[region]; ok {
+		principals = append([]string{account}, principals...)
+	}
+	for _, s := range policy.Statement {
+		if s.Effect == "Allow" && s.allowsPrincipal(principals) && s.allowsPutObject() && s.allowsResource(resource) {
+			return nil
+		}
+	}
+	return &AccessLogPolicyError{Bucket: bucket, Principals: principals, Resource: resource + "*"}
+}
+
+type bucketPolicy struct {
+	Statement statements
+}
+
+type policyStatement struct {
+	Effect    string
+	Principal principal
+	Action    stringList
+	Resource  stringList
+}
+
+// allowsPrincipal reports whether s applies to any of the principals,
+// either service principals or account ids.
+func (s *policyStatement) allowsPrincipal(principals []string) bool {
+	if s.Principal.any {
+		return true
+	}
+	for _, p := range principals {
+		if strings.Contains(p, ".") {
+			if s.Principal.Service.contains(p) {
+				return true
+			}
+			continue
+		}
+		for _, account := range s.Principal.AWS {
+			if account == p || account == "*" || strings.HasPrefix(account, "arn:") && strings.Contains(account, "::"+p+":") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *policyStatement) allowsPutObject() bool {
+	for _, action := range s.Action {
+		if globMatch(strings.ToLower(action), "s3:putobject") {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsResource reports whether s applies to the keys of the access logs
+// under prefix, which are partitioned by date.
+func (s *policyStatement) allowsResource(prefix string) bool {
+	key := prefix + "2006/01/02/access.log"
+	for _, r := range s.Resource {
+		if globMatch(r, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch reports whether s matches pattern, where * matches any
+// sequence of characters and ? any single character.
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return len(s) == 0
+}
+
+// statements decodes the Statement of a policy, which is either a single
+// statement or a list of them.
+type statements []policyStatement
+
+func (s *statements) UnmarshalJSON(data []byte) error {
+	var list []policyStatement
+	if err := json.Unmarshal(data, &list); err == nil {
+		*s = list
+		return nil
+	}
+	var single policyStatement
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*s = statements{single}
+	return nil
+}
+
+// principal decodes the Principal of a statement, which is either "*" or
+// an object listing AWS accounts and services.
+type principal struct {
+	any     bool
+	AWS     stringList
+	Service stringList
+}
+
+func (p *principal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		p.any = s == "*"
+		return nil
+	}
+	var v struct {
+		AWS     stringList
+		Service stringList
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.AWS, p.Service = v.AWS, v.Service
+	return nil
+}
+
+// stringList decodes policy elements that are either a string or a list
+// of strings.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*l = list
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*l = stringList{s}
+	return nil
+}
+
+func (l stringList) contains(s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
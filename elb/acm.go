@@ -0,0 +1,98 @@
+package elb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrACMCertificateNotFound is returned, wrapped along with the domain
+// name, when no issued ACM certificate covers the requested domain.
+var ErrACMCertificateNotFound = errors.New("elb: ACM certificate not found")
+
+// ACMCertificate describes a certificate managed by AWS Certificate
+// Manager.
+type ACMCertificate struct {
+	Arn                     string
+	DomainName              string
+	SubjectAlternativeNames []string
+
+	// Status is the status of the certificate, like ISSUED or
+	// PENDING_VALIDATION. Only issued certificates can be used by
+	// listeners.
+	Status   string
+	NotAfter time.Time
+}
+
+// ACM is the subset of the ACM API needed to find certificates. Implement
+// it on top of any ACM client, for the region of the Load Balancers, to
+// use FindACMCertificate.
+type ACM interface {
+	// ListCertificates returns all the certificates of the region,
+	// following the pagination of the API.
+	//
+	// See http://docs.aws.amazon.com/acm/latest/APIReference/API_ListCertificates.html
+	// for more details.
+	ListCertificates(ctx context.Context) ([]ACMCertificate, error)
+}
+
+// FindACMCertificate returns the issued ACM certificate covering domain,
+// through its domain name or one of its subject alternative names, so
+// that its ARN can be used as the SSLCertificateId of a listener.
+// Certificates naming domain exactly are preferred over wildcard ones, and
+// among them the one expiring last is returned. An error wrapping
+// ErrACMCertificateNotFound is returned if none covers domain.
+func FindACMCertificate(ctx context.Context, acm ACM, domain string) (*ACMCertificate, error) {
+	certs, err := acm.ListCertificates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var best *ACMCertificate
+	bestExact := false
+	for i := range certs {
+		cert := &certs[i]
+		if cert.Status != "ISSUED" {
+			continue
+		}
+		covered, exact := cert.covers(domain)
+		if !covered {
+			continue
+		}
+		if best == nil || exact && !bestExact || exact == bestExact && cert.NotAfter.After(best.NotAfter) {
+			best, bestExact = cert, exact
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: %s", ErrACMCertificateNotFound, domain)
+	}
+	return best, nil
+}
+
+// covers reports whether the certificate is valid for domain, and whether
+// it names it exactly rather than through a wildcard.
+func (cert *ACMCertificate) covers(domain string) (covered, exact bool) {
+	names := append([]string{cert.DomainName}, cert.SubjectAlternativeNames...)
+	for _, name := range names {
+		if strings.EqualFold(name, domain) {
+			return true, true
+		}
+	}
+	for _, name := range names {
+		if matchWildcard(name, domain) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// matchWildcard reports whether the wildcard name, like *.example.com,
+// matches domain. Like in TLS, the wildcard only covers a single label.
+func matchWildcard(name, domain string) bool {
+	if !strings.HasPrefix(name, "*.") {
+		return false
+	}
+	i := strings.Index(domain, ".")
+	return i > 0 && strings.EqualFold(name[1:], domain[i:])
+}
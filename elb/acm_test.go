@@ -0,0 +1,46 @@
+package elb_test
+
+import (
+	"context"
+	"errors"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+	"time"
+)
+
+type fakeACM []elb.ACMCertificate
+
+func (acm fakeACM) ListCertificates(ctx context.Context) ([]elb.ACMCertificate, error) {
+	return acm, nil
+}
+
+func (s *S) TestFindACMCertificate(c *C) {
+	now := time.Now()
+	acm := fakeACM{
+		{Arn: "arn:aws:acm:us-east-1:123456789012:certificate/wildcard", DomainName: "*.example.com", Status: "ISSUED", NotAfter: now.Add(90 * 24 * time.Hour)},
+		{Arn: "arn:aws:acm:us-east-1:123456789012:certificate/old", DomainName: "example.com", SubjectAlternativeNames: []string{"www.example.com"}, Status: "ISSUED", NotAfter: now.Add(24 * time.Hour)},
+		{Arn: "arn:aws:acm:us-east-1:123456789012:certificate/new", DomainName: "example.com", SubjectAlternativeNames: []string{"www.example.com"}, Status: "ISSUED", NotAfter: now.Add(30 * 24 * time.Hour)},
+		{Arn: "arn:aws:acm:us-east-1:123456789012:certificate/pending", DomainName: "api.example.com", Status: "PENDING_VALIDATION", NotAfter: now.Add(365 * 24 * time.Hour)},
+	}
+	tests := []struct {
+		domain, arn string
+	}{
+		// Exact names win over wildcards, the latest expiring first.
+		{"www.example.com", "arn:aws:acm:us-east-1:123456789012:certificate/new"},
+		{"EXAMPLE.com", "arn:aws:acm:us-east-1:123456789012:certificate/new"},
+		// Pending certificates are ignored.
+		{"api.example.com", "arn:aws:acm:us-east-1:123456789012:certificate/wildcard"},
+	}
+	for _, t := range tests {
+		cert, err := elb.FindACMCertificate(context.Background(), acm, t.domain)
+		c.Assert(err, IsNil)
+		c.Check(cert.Arn, Equals, t.arn, Commentf("%s", t.domain))
+	}
+	// Wildcards only cover a single label.
+	for _, domain := range []string{"a.b.example.com", "other.org"} {
+		_, err := elb.FindACMCertificate(context.Background(), acm, domain)
+		c.Check(errors.Is(err, elb.ErrACMCertificateNotFound), Equals, true)
+	}
+	_, err := elb.FindACMCertificate(context.Background(), acm, "other.org")
+	c.Assert(err, ErrorMatches, "elb: ACM certificate not found: other.org")
+}
@@ -0,0 +1,138 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AliasTarget is the target of a Route53 alias record pointing to a Load
+// Balancer.
+type AliasTarget struct {
+	HostedZoneId         string
+	DNSName              string
+	EvaluateTargetHealth bool
+}
+
+// AliasTarget returns the target of a Route53 alias record pointing to the
+// Load Balancer. An error is returned if the description doesn't have the
+// canonical hosted zone of the Load Balancer.
+func (d *LoadBalancerDescription) AliasTarget() (AliasTarget, error) {
+	if d.CanonicalHostedZoneNameId == "" || d.DNSName == "" {
+		return AliasTarget{}, fmt.Errorf("elb: no canonical hosted zone for Load Balancer %s", d.LoadBalancerName)
+	}
+	return AliasTarget{
+		HostedZoneId: d.CanonicalHostedZoneNameId,
+		DNSName:      strings.ToLower(d.DNSName) + ".",
+	}, nil
+}
+
+// DualStackPrefix is prepended to the DNS name of a Load Balancer to get a
+// name resolving to both its IPv4 (A) and IPv6 (AAAA) addresses.
+const DualStackPrefix = "dualstack."
+
+// DualStackDNSName returns the DNS name of the Load Balancer resolving to
+// both its IPv4 and IPv6 addresses, or an empty string if the description
+// has no DNS name.
+func (d *LoadBalancerDescription) DualStackDNSName() string {
+	if d.DNSName == "" {
+		return ""
+	}
+	return DualStackPrefix + d.DNSName
+}
+
+// DualStackAliasTarget is like AliasTarget, but the target is the dualstack
+// DNS name of the Load Balancer, which both A and AAAA alias records may
+// point to.
+func (d *LoadBalancerDescription) DualStackAliasTarget() (AliasTarget, error) {
+	target, err := d.AliasTarget()
+	if err != nil {
+		return target, err
+	}
+	target.DNSName = DualStackPrefix + target.DNSName
+	return target, nil
+}
+
+// Actions of a Route53 record change.
+const (
+	RecordCreate = "CREATE"
+	RecordUpsert = "UPSERT"
+	RecordDelete = "DELETE"
+)
+
+// RecordChange is a change of an alias record in a Route53 hosted zone.
+type RecordChange struct {
+	Action      string
+	Name        string
+	Type        string
+	AliasTarget AliasTarget
+}
+
+// Route53 is the subset of the Route53 API needed to manage alias records.
+// Implement it on top of any Route53 client to use UpsertAliasRecord and
+// DeleteAliasRecord.
+//
+// See http://docs.aws.amazon.com/Route53/latest/APIReference/API_ChangeResourceRecordSets.html
+// for more details.
+type Route53 interface {
+	ChangeResourceRecordSets(ctx context.Context, hostedZoneId string, changes []RecordChange) error
+}
+
+// UpsertAliasRecord creates or updates the A record name in the given
+// hosted zone, making it an alias of the Load Balancer described by desc.
+// When evaluateTargetHealth is true, Route53 considers the health of the
+// Load Balancer when answering queries.
+func UpsertAliasRecord(ctx context.Context, r Route53, hostedZoneId, name string, desc *LoadBalancerDescription, evaluateTargetHealth bool) error {
+	target, err := desc.AliasTarget()
+	if err != nil {
+		return err
+	}
+	return changeAliasRecords(ctx, r, RecordUpsert, hostedZoneId, name, target, evaluateTargetHealth, "A")
+}
+
+// DeleteAliasRecord deletes the A record name, an alias of the Load
+// Balancer described by desc, from the given hosted zone. Route53 only
+// deletes records matching exactly, so evaluateTargetHealth must be the
+// value the record was created with.
+func DeleteAliasRecord(ctx context.Context, r Route53, hostedZoneId, name string, desc *LoadBalancerDescription, evaluateTargetHealth bool) error {
+	target, err := desc.AliasTarget()
+	if err != nil {
+		return err
+	}
+	return changeAliasRecords(ctx, r, RecordDelete, hostedZoneId, name, target, evaluateTargetHealth, "A")
+}
+
+// UpsertDualStackAliasRecords is like UpsertAliasRecord, but it creates or
+// updates both the A and AAAA records name, aliases of the dualstack DNS
+// name of the Load Balancer, in a single change.
+func UpsertDualStackAliasRecords(ctx context.Context, r Route53, hostedZoneId, name string, desc *LoadBalancerDescription, evaluateTargetHealth bool) error {
+	target, err := desc.DualStackAliasTarget()
+	if err != nil {
+		return err
+	}
+	return changeAliasRecords(ctx, r, RecordUpsert, hostedZoneId, name, target, evaluateTargetHealth, "A", "AAAA")
+}
+
+// DeleteDualStackAliasRecords deletes the A and AAAA records created by
+// UpsertDualStackAliasRecords.
+func DeleteDualStackAliasRecords(ctx context.Context, r Route53, hostedZoneId, name string, desc *LoadBalancerDescription, evaluateTargetHealth bool) error {
+	target, err := desc.DualStackAliasTarget()
+	if err != nil {
+		return err
+	}
+	return changeAliasRecords(ctx, r, RecordDelete, hostedZoneId, name, target, evaluateTargetHealth, "A", "AAAA")
+}
+
+func changeAliasRecords(ctx context.Context, r Route53, action, hostedZoneId, name string, target AliasTarget, evaluateTargetHealth bool, types ...string) error {
+	target.EvaluateTargetHealth = evaluateTargetHealth
+	changes := make([]RecordChange, len(types))
+	for i, t := range types {
+		changes[i] = RecordChange{
+			Action:      action,
+			Name:        name,
+			Type:        t,
+			AliasTarget: target,
+		}
+	}
+	return r.ChangeResourceRecordSets(ctx, hostedZoneId, changes)
+}
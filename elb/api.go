@@ -0,0 +1,119 @@
+package elb
+
+import (
+	"context"
+	"time"
+)
+
+// API is the set of operations of the Elastic Load Balancing client. *ELB
+// implements it, and so does the mock of the elbmock package, which lets
+// code depending on API be unit tested without a fake server.
+type API interface {
+	CreateLoadBalancer(options *CreateLoadBalancer) (resp *CreateLoadBalancerResp, err error)
+	CreateLoadBalancerWithContext(ctx context.Context, options *CreateLoadBalancer) (resp *CreateLoadBalancerResp, err error)
+	DeleteLoadBalancer(name string) (resp *SimpleResp, err error)
+	DeleteLoadBalancerWithContext(ctx context.Context, name string) (resp *SimpleResp, err error)
+	RegisterInstancesWithLoadBalancer(instanceIds []string, lbName string) (resp *RegisterInstancesResp, err error)
+	RegisterInstancesWithLoadBalancerWithContext(ctx context.Context, instanceIds []string, lbName string) (resp *RegisterInstancesResp, err error)
+	DeregisterInstancesFromLoadBalancer(instanceIds []string, lbName string) (resp *DeregisterInstancesResp, err error)
+	DeregisterInstancesFromLoadBalancerWithContext(ctx context.Context, instanceIds []string, lbName string) (resp *DeregisterInstancesResp, err error)
+	DescribeLoadBalancers(names ...string) (*DescribeLoadBalancerResp, error)
+	DescribeLoadBalancersWithContext(ctx context.Context, names ...string) (*DescribeLoadBalancerResp, error)
+	DescribeLoadBalancersPage(marker string, pageSize int, names ...string) (*DescribeLoadBalancerResp, error)
+	DescribeLoadBalancersPageWithContext(ctx context.Context, marker string, pageSize int, names ...string) (*DescribeLoadBalancerResp, error)
+	DescribeLoadBalancersAll(names ...string) ([]LoadBalancerDescription, error)
+	DescribeLoadBalancersAllWithContext(ctx context.Context, names ...string) ([]LoadBalancerDescription, error)
+	DescribeInstanceHealth(lbName string, instanceIds ...string) (*DescribeInstanceHealthResp, error)
+	DescribeInstanceHealthWithContext(ctx context.Context, lbName string, instanceIds ...string) (*DescribeInstanceHealthResp, error)
+	DescribeInstanceHealthFiltered(lbName string, filter *InstanceHealthFilter) (*DescribeInstanceHealthResp, error)
+	DescribeInstanceHealthFilteredWithContext(ctx context.Context, lbName string, filter *InstanceHealthFilter) (*DescribeInstanceHealthResp, error)
+	ConfigureHealthCheck(lbName string, healthCheck *HealthCheck) (*HealthCheckResp, error)
+	ConfigureHealthCheckWithContext(ctx context.Context, lbName string, healthCheck *HealthCheck) (*HealthCheckResp, error)
+	DescribeLoadBalancerAttributes(lbName string) (*DescribeLoadBalancerAttributesResp, error)
+	DescribeLoadBalancerAttributesWithContext(ctx context.Context, lbName string) (*DescribeLoadBalancerAttributesResp, error)
+	ModifyLoadBalancerAttributes(lbName string, attrs *LoadBalancerAttributes) (*ModifyLoadBalancerAttributesResp, error)
+	ModifyLoadBalancerAttributesWithContext(ctx context.Context, lbName string, attrs *LoadBalancerAttributes) (*ModifyLoadBalancerAttributesResp, error)
+	SetCrossZoneLoadBalancing(lbName string, enabled bool) error
+	SetCrossZoneLoadBalancingWithContext(ctx context.Context, lbName string, enabled bool) error
+	CreateLoadBalancerListeners(lbName string, listeners []Listener) (*SimpleResp, error)
+	CreateLoadBalancerListenersWithContext(ctx context.Context, lbName string, listeners []Listener) (*SimpleResp, error)
+	DeleteLoadBalancerListeners(lbName string, ports ...int) (*SimpleResp, error)
+	DeleteLoadBalancerListenersWithContext(ctx context.Context, lbName string, ports ...int) (*SimpleResp, error)
+	SetLoadBalancerListenerSSLCertificate(lbName string, port int, certId string) (*SimpleResp, error)
+	SetLoadBalancerListenerSSLCertificateWithContext(ctx context.Context, lbName string, port int, certId string) (*SimpleResp, error)
+	ApplySecurityGroups(lbName string, groups []string) (*ApplySecurityGroupsResp, error)
+	ApplySecurityGroupsWithContext(ctx context.Context, lbName string, groups []string) (*ApplySecurityGroupsResp, error)
+	AttachLoadBalancerToSubnets(lbName string, subnets []string) (*AttachLoadBalancerToSubnetsResp, error)
+	AttachLoadBalancerToSubnetsWithContext(ctx context.Context, lbName string, subnets []string) (*AttachLoadBalancerToSubnetsResp, error)
+	DetachLoadBalancerFromSubnets(lbName string, subnets []string) (*DetachLoadBalancerFromSubnetsResp, error)
+	DetachLoadBalancerFromSubnetsWithContext(ctx context.Context, lbName string, subnets []string) (*DetachLoadBalancerFromSubnetsResp, error)
+	EnableAvailabilityZonesForLoadBalancer(lbName string, zones []string) (*EnableAvailabilityZonesResp, error)
+	EnableAvailabilityZonesForLoadBalancerWithContext(ctx context.Context, lbName string, zones []string) (*EnableAvailabilityZonesResp, error)
+	DisableAvailabilityZonesForLoadBalancer(lbName string, zones []string) (*DisableAvailabilityZonesResp, error)
+	DisableAvailabilityZonesForLoadBalancerWithContext(ctx context.Context, lbName string, zones []string) (*DisableAvailabilityZonesResp, error)
+	CreateLBCookieStickinessPolicy(lbName, policyName string, expiration int64) (*SimpleResp, error)
+	CreateLBCookieStickinessPolicyWithContext(ctx context.Context, lbName, policyName string, expiration int64) (*SimpleResp, error)
+	CreateAppCookieStickinessPolicy(lbName, policyName, cookieName string) (*SimpleResp, error)
+	CreateAppCookieStickinessPolicyWithContext(ctx context.Context, lbName, policyName, cookieName string) (*SimpleResp, error)
+	DeleteLoadBalancerPolicy(lbName, policyName string) (*SimpleResp, error)
+	DeleteLoadBalancerPolicyWithContext(ctx context.Context, lbName, policyName string) (*SimpleResp, error)
+	SetLoadBalancerPoliciesOfListener(lbName string, port int, policyNames []string) (*SimpleResp, error)
+	SetLoadBalancerPoliciesOfListenerWithContext(ctx context.Context, lbName string, port int, policyNames []string) (*SimpleResp, error)
+	CreateLoadBalancerPolicy(lbName, policyName, policyTypeName string, attrs []PolicyAttribute) (*SimpleResp, error)
+	CreateLoadBalancerPolicyWithContext(ctx context.Context, lbName, policyName, policyTypeName string, attrs []PolicyAttribute) (*SimpleResp, error)
+	DescribeLoadBalancerPolicies(lbName string, policyNames ...string) (*DescribeLoadBalancerPoliciesResp, error)
+	DescribeLoadBalancerPoliciesWithContext(ctx context.Context, lbName string, policyNames ...string) (*DescribeLoadBalancerPoliciesResp, error)
+	DescribeLoadBalancerPolicyTypes(typeNames ...string) (*DescribeLoadBalancerPolicyTypesResp, error)
+	DescribeLoadBalancerPolicyTypesWithContext(ctx context.Context, typeNames ...string) (*DescribeLoadBalancerPolicyTypesResp, error)
+	SetLoadBalancerPoliciesForBackendServer(lbName string, instancePort int, policyNames []string) (*SimpleResp, error)
+	SetLoadBalancerPoliciesForBackendServerWithContext(ctx context.Context, lbName string, instancePort int, policyNames []string) (*SimpleResp, error)
+	AddTags(lbNames []string, tags []Tag) (*SimpleResp, error)
+	AddTagsWithContext(ctx context.Context, lbNames []string, tags []Tag) (*SimpleResp, error)
+	RemoveTags(lbNames []string, keys []string) (*SimpleResp, error)
+	RemoveTagsWithContext(ctx context.Context, lbNames []string, keys []string) (*SimpleResp, error)
+	DescribeTags(lbNames ...string) (*DescribeTagsResp, error)
+	DescribeTagsWithContext(ctx context.Context, lbNames ...string) (*DescribeTagsResp, error)
+	Query(ctx context.Context, version string, params map[string]string, resp interface{}) error
+
+	// Operations built on the ones above.
+	EnableAccessLogs(lbName, bucket, prefix string, interval time.Duration) error
+	EnableAccessLogsWithContext(ctx context.Context, lbName, bucket, prefix string, interval time.Duration) error
+	RegisterInstancesInBatches(lbName string, instanceIds []string, opts *BatchOptions) *BatchReport
+	RegisterInstancesInBatchesWithContext(ctx context.Context, lbName string, instanceIds []string, opts *BatchOptions) *BatchReport
+	SwapInstances(lbName string, blue, green []string, opts *SwapOptions) error
+	SwapInstancesWithContext(ctx context.Context, lbName string, blue, green []string, opts *SwapOptions) error
+	RegisterInstancesByTag(e EC2, lbName string, tags map[string]string) ([]string, error)
+	RegisterInstancesByTagWithContext(ctx context.Context, e EC2, lbName string, tags map[string]string) ([]string, error)
+	FindLoadBalancers(filter *LoadBalancerFilter) ([]LoadBalancerDescription, error)
+	FindLoadBalancersWithContext(ctx context.Context, filter *LoadBalancerFilter) ([]LoadBalancerDescription, error)
+	EnableConnectionDraining(lbName string, timeout time.Duration) error
+	EnableConnectionDrainingWithContext(ctx context.Context, lbName string, timeout time.Duration) error
+	DeregisterAndDrain(lbName string, instanceIds []string, cfg *WaiterConfig) error
+	DeregisterAndDrainWithContext(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig) error
+	EnsureLoadBalancer(spec *LoadBalancerSpec) (*LoadBalancerDescription, error)
+	EnsureLoadBalancerWithContext(ctx context.Context, spec *LoadBalancerSpec) (*LoadBalancerDescription, error)
+	CreateLoadBalancerIfNotExists(options *CreateLoadBalancer) (dnsName string, err error)
+	CreateLoadBalancerIfNotExistsWithContext(ctx context.Context, options *CreateLoadBalancer) (dnsName string, err error)
+	SummarizeHealth(lbName string, requireInstances bool) (*HealthSummary, error)
+	SummarizeHealthWithContext(ctx context.Context, lbName string, requireInstances bool) (*HealthSummary, error)
+	DeleteLoadBalancerSafe(lbName string, opts *DeleteOptions) error
+	DeleteLoadBalancerSafeWithContext(ctx context.Context, lbName string, opts *DeleteOptions) error
+	EnableProxyProtocol(lbName string, backendPorts []int) error
+	EnableProxyProtocolWithContext(ctx context.Context, lbName string, backendPorts []int) error
+	DisableProxyProtocol(lbName string, backendPorts []int) error
+	DisableProxyProtocolWithContext(ctx context.Context, lbName string, backendPorts []int) error
+	RotateInstances(lbName string, oldIds, newIds []string, opts *RotateOptions) error
+	RotateInstancesWithContext(ctx context.Context, lbName string, oldIds, newIds []string, opts *RotateOptions) error
+	PredefinedSSLPolicies() ([]string, error)
+	PredefinedSSLPoliciesWithContext(ctx context.Context) ([]string, error)
+	CreateSSLPolicy(lbName, policyName, referencePolicy string) error
+	CreateSSLPolicyWithContext(ctx context.Context, lbName, policyName, referencePolicy string) error
+	SetListenerSSLPolicy(lbName string, port int, referencePolicy string) error
+	SetListenerSSLPolicyWithContext(ctx context.Context, lbName string, port int, referencePolicy string) error
+	WaitUntilInstanceInService(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig) error
+	WaitUntilInstanceOutOfService(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig) error
+	WaitUntilLoadBalancerExists(ctx context.Context, lbName string, cfg *WaiterConfig) error
+	WaitUntilLoadBalancerDeleted(ctx context.Context, lbName string, cfg *WaiterConfig) error
+}
+
+var _ API = (*ELB)(nil)
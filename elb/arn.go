@@ -0,0 +1,60 @@
+package elb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ARN is an Amazon Resource Name, like the ARN of a server certificate
+// given as SSLCertificateId. Its String method formats it.
+type ARN struct {
+	Partition string
+	Service   string
+	Region    string
+	AccountId string
+	Resource  string
+}
+
+// ParseARN parses an ARN like
+// arn:aws-cn:iam::123456789012:server-certificate/my-cert. Global
+// resources, like IAM ones, have no Region, and S3 ones have no AccountId
+// either.
+func ParseARN(s string) (ARN, error) {
+	parts := strings.SplitN(s, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return ARN{}, fmt.Errorf("elb: invalid ARN %q", s)
+	}
+	a := ARN{
+		Partition: parts[1],
+		Service:   parts[2],
+		Region:    parts[3],
+		AccountId: parts[4],
+		Resource:  parts[5],
+	}
+	if a.Partition == "" || a.Service == "" || a.Resource == "" {
+		return ARN{}, fmt.Errorf("elb: invalid ARN %q", s)
+	}
+	return a, nil
+}
+
+func (a ARN) String() string {
+	return "arn:" + a.Partition + ":" + a.Service + ":" + a.Region + ":" + a.AccountId + ":" + a.Resource
+}
+
+// ServerCertificateARN returns the ARN of the IAM server certificate
+// uploaded by accountId under name, in the partition of region.
+func ServerCertificateARN(region, accountId, name string) string {
+	return ARN{Partition: PartitionOf(region), Service: "iam", AccountId: accountId, Resource: "server-certificate/" + name}.String()
+}
+
+// ACMCertificateARN returns the ARN of the ACM certificate with the given
+// id, imported or issued in region by accountId.
+func ACMCertificateARN(region, accountId, certificateId string) string {
+	return ARN{Partition: PartitionOf(region), Service: "acm", Region: region, AccountId: accountId, Resource: "certificate/" + certificateId}.String()
+}
+
+// LoadBalancerARN returns the ARN of the named Load Balancer of accountId
+// in region, as used by the resource groups tagging API.
+func LoadBalancerARN(region, accountId, lbName string) string {
+	return ARN{Partition: PartitionOf(region), Service: "elasticloadbalancing", Region: region, AccountId: accountId, Resource: "loadbalancer/" + lbName}.String()
+}
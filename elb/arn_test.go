@@ -0,0 +1,87 @@
+package elb_test
+
+import (
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+)
+
+func (s *S) TestParseARN(c *C) {
+	arn, err := elb.ParseARN("arn:aws-us-gov:iam::123456789012:server-certificate/my-cert")
+	c.Assert(err, IsNil)
+	c.Assert(arn, Equals, elb.ARN{Partition: elb.PartitionGovCloud, Service: "iam", AccountId: "123456789012", Resource: "server-certificate/my-cert"})
+	c.Assert(arn.String(), Equals, "arn:aws-us-gov:iam::123456789012:server-certificate/my-cert")
+	arn, err = elb.ParseARN("arn:aws-cn:acm:cn-north-1:123456789012:certificate/a:b")
+	c.Assert(err, IsNil)
+	c.Assert(arn.Region, Equals, "cn-north-1")
+	c.Assert(arn.Resource, Equals, "certificate/a:b")
+	for _, s := range []string{"", "my-cert", "arn:aws:iam::123", "urn:aws:iam::1:x", "arn::iam::1:x", "arn:aws:iam::1:"} {
+		_, err := elb.ParseARN(s)
+		c.Check(err, ErrorMatches, `elb: invalid ARN ".*"`, Commentf("%s", s))
+	}
+}
+
+func (s *S) TestARNBuilders(c *C) {
+	c.Assert(elb.ServerCertificateARN("us-gov-west-1", "123456789012", "my-cert"), Equals, "arn:aws-us-gov:iam::123456789012:server-certificate/my-cert")
+	c.Assert(elb.ServerCertificateARN("eu-west-1", "123456789012", "my-cert"), Equals, "arn:aws:iam::123456789012:server-certificate/my-cert")
+	c.Assert(elb.ACMCertificateARN("cn-northwest-1", "123456789012", "abcd"), Equals, "arn:aws-cn:acm:cn-northwest-1:123456789012:certificate/abcd")
+	c.Assert(elb.LoadBalancerARN("us-east-1", "123456789012", "testlb"), Equals, "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/testlb")
+}
+
+func (s *S) TestLookupRegion(c *C) {
+	c.Assert(elb.LookupRegion("us-east-1"), Equals, elb.Regions["us-east-1"])
+	c.Assert(elb.LookupRegion("us-east-1").AWSRegion(), Equals, aws.USEast)
+	r := elb.LookupRegion("cn-south-9")
+	c.Assert(r.Endpoint, Equals, "https://elasticloadbalancing.cn-south-9.amazonaws.com.cn")
+	c.Assert(r.Partition, Equals, elb.PartitionChina)
+	c.Assert(r.SignatureV4Only, Equals, true)
+	r = elb.LookupRegion("us-gov-central-1")
+	c.Assert(r.Endpoint, Equals, "https://elasticloadbalancing.us-gov-central-1.amazonaws.com")
+	c.Assert(r.Partition, Equals, elb.PartitionGovCloud)
+	c.Assert(elb.PartitionOf("eu-west-1"), Equals, elb.PartitionAWS)
+	c.Assert(elb.ServiceEndpoint("cn-north-1", "autoscaling"), Equals, "https://autoscaling.cn-north-1.amazonaws.com.cn")
+	c.Assert(elb.ServiceEndpoint("", "monitoring"), Equals, "https://monitoring.us-east-1.amazonaws.com")
+	// A region without an endpoint gets the one of its name.
+	e := elb.New(aws.Auth{}, aws.Region{Name: "cn-south-9"})
+	c.Assert(e.SignatureVersion(), Equals, elb.SignatureV4)
+}
+
+func (s *S) TestCertificatePartition(c *C) {
+	e := elb.New(s.elb.Auth, aws.Region{Name: "cn-north-1", ELBEndpoint: testServer.URL})
+	listener := elb.Listener{Protocol: "HTTPS", LoadBalancerPort: 443, InstancePort: 80, SSLCertificateId: elb.ServerCertificateARN("us-east-1", "123456789012", "my-cert")}
+	_, err := e.CreateLoadBalancerListeners("testlb", []elb.Listener{listener})
+	c.Assert(err, ErrorMatches, `elb: invalid listener 1 .*: certificate arn:aws:iam::123456789012:server-certificate/my-cert is in partition aws, but region cn-north-1 is in partition aws-cn`)
+	testServer.PrepareResponse(200, nil, CreateLoadBalancerListeners)
+	listener.SSLCertificateId = elb.ServerCertificateARN("cn-north-1", "123456789012", "my-cert")
+	_, err = e.CreateLoadBalancerListeners("testlb", []elb.Listener{listener})
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestACMCertificateRegion(c *C) {
+	e := elb.New(s.elb.Auth, aws.Region{Name: "eu-west-1", ELBEndpoint: testServer.URL})
+	listener := elb.Listener{Protocol: "HTTPS", LoadBalancerPort: 443, InstancePort: 80, SSLCertificateId: elb.ACMCertificateARN("us-east-1", "123456789012", "abcd")}
+	_, err := e.CreateLoadBalancerListeners("testlb", []elb.Listener{listener})
+	c.Assert(err, ErrorMatches, `elb: invalid listener 1 .*: ACM certificate arn:aws:acm:us-east-1:123456789012:certificate/abcd is in region us-east-1, not eu-west-1`)
+	testServer.PrepareResponse(200, nil, CreateLoadBalancerListeners)
+	listener.SSLCertificateId = elb.ACMCertificateARN("eu-west-1", "123456789012", "abcd")
+	_, err = e.CreateLoadBalancerListeners("testlb", []elb.Listener{listener})
+	c.Assert(err, IsNil)
+	c.Assert(testServer.WaitRequest().URL.Query().Get("Listeners.member.1.SSLCertificateId"), Equals, listener.SSLCertificateId)
+}
+
+func (s *S) TestSetListenerCertificateValidation(c *C) {
+	e := elb.New(s.elb.Auth, aws.Region{Name: "eu-west-1", ELBEndpoint: testServer.URL})
+	_, err := e.SetLoadBalancerListenerSSLCertificate("testlb", 443, "arn:aws:acm:eu-west-1:123456789012:cert/abcd")
+	c.Assert(err, ErrorMatches, `elb: invalid listener on port 443: invalid ACM certificate ARN arn:aws:acm:eu-west-1:123456789012:cert/abcd`)
+	lerr, ok := err.(*elb.ListenerError)
+	c.Assert(ok, Equals, true)
+	c.Assert(lerr.Listener.SSLCertificateId, Equals, "arn:aws:acm:eu-west-1:123456789012:cert/abcd")
+	_, err = e.SetLoadBalancerListenerSSLCertificate("testlb", 443, elb.ACMCertificateARN("us-east-1", "123456789012", "abcd"))
+	c.Assert(err, ErrorMatches, `elb: invalid listener on port 443: ACM certificate arn:aws:acm:us-east-1:123456789012:certificate/abcd is in region us-east-1, not eu-west-1`)
+	testServer.PrepareResponse(200, nil, SetLoadBalancerListenerSSLCertificate)
+	certId := elb.ACMCertificateARN("eu-west-1", "123456789012", "abcd")
+	_, err = e.SetLoadBalancerListenerSSLCertificate("testlb", 443, certId)
+	c.Assert(err, IsNil)
+	c.Assert(testServer.WaitRequest().URL.Query().Get("SSLCertificateId"), Equals, certId)
+}
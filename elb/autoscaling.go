@@ -0,0 +1,53 @@
+package elb
+
+import "context"
+
+// maxAutoScalingLoadBalancers is the maximum number of Load Balancers
+// attached to or detached from an Auto Scaling group by a single request.
+const maxAutoScalingLoadBalancers = 10
+
+// AutoScaling is the subset of the Auto Scaling API needed to attach Load
+// Balancers to Auto Scaling groups, which then register their instances
+// with the Load Balancers as they launch and terminate them. Implement it
+// on top of any Auto Scaling client, or use the elbasg package, to use
+// AttachToAutoScalingGroup and DetachFromAutoScalingGroup.
+//
+// See http://docs.aws.amazon.com/autoscaling/ec2/APIReference/API_AttachLoadBalancers.html
+// for more details.
+type AutoScaling interface {
+	AttachLoadBalancers(ctx context.Context, groupName string, lbNames []string) error
+	DetachLoadBalancers(ctx context.Context, groupName string, lbNames []string) error
+}
+
+// AttachToAutoScalingGroup attaches the Load Balancers to the Auto Scaling
+// group, sending as many requests as the limit of Load Balancers per
+// request of Auto Scaling requires.
+func AttachToAutoScalingGroup(ctx context.Context, as AutoScaling, groupName string, lbNames ...string) error {
+	return inChunks(lbNames, maxAutoScalingLoadBalancers, func(names []string) error {
+		return as.AttachLoadBalancers(ctx, groupName, names)
+	})
+}
+
+// DetachFromAutoScalingGroup detaches the Load Balancers from the Auto
+// Scaling group. The instances of the group are deregistered from the Load
+// Balancers, with connection draining when it's enabled.
+func DetachFromAutoScalingGroup(ctx context.Context, as AutoScaling, groupName string, lbNames ...string) error {
+	return inChunks(lbNames, maxAutoScalingLoadBalancers, func(names []string) error {
+		return as.DetachLoadBalancers(ctx, groupName, names)
+	})
+}
+
+// inChunks calls f with consecutive chunks of at most size names, stopping
+// at the first error.
+func inChunks(names []string, size int, f func([]string) error) error {
+	for start := 0; start < len(names); start += size {
+		end := start + size
+		if end > len(names) {
+			end = len(names)
+		}
+		if err := f(names[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
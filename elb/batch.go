@@ -0,0 +1,113 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DefaultBatchSize is the number of instances registered by each request of
+// RegisterInstancesInBatches when BatchOptions doesn't set one.
+const DefaultBatchSize = 100
+
+// BatchOptions configures RegisterInstancesInBatches.
+type BatchOptions struct {
+	// BatchSize is the maximum number of instances sent in a single
+	// request. It defaults to DefaultBatchSize.
+	BatchSize int
+	// Concurrency is the maximum number of requests in flight. It
+	// defaults to 4.
+	Concurrency int
+}
+
+// InstanceResult is the outcome of the registration of a single instance.
+type InstanceResult struct {
+	InstanceId string
+	// Err is the error returned for the batch the instance was sent in,
+	// or nil if the instance was registered.
+	Err error
+}
+
+// BatchReport holds the outcome of RegisterInstancesInBatches, with one
+// result per instance, in the order the instances were given.
+type BatchReport struct {
+	Results []InstanceResult
+}
+
+// Failed returns the results of the instances that couldn't be registered.
+func (r *BatchReport) Failed() []InstanceResult {
+	var failed []InstanceResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err returns an error summarizing the failures, or nil if all the
+// instances were registered.
+func (r *BatchReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("elb: %d of %d instances not registered, first error: %v", len(failed), len(r.Results), failed[0].Err)
+}
+
+// RegisterInstancesInBatches registers many instances with a Load Balancer,
+// splitting them in batches that are sent in parallel, to stay within the
+// request size limits of ELB.
+//
+// A failed batch doesn't stop the others: the report tells which instances
+// were registered and which weren't, along with the error of their batch.
+func (elb *ELB) RegisterInstancesInBatches(lbName string, instanceIds []string, opts *BatchOptions) *BatchReport {
+	return elb.RegisterInstancesInBatchesWithContext(context.Background(), lbName, instanceIds, opts)
+}
+
+// RegisterInstancesInBatchesWithContext is like
+// RegisterInstancesInBatches, but the requests are bound to ctx.
+func (elb *ELB) RegisterInstancesInBatchesWithContext(ctx context.Context, lbName string, instanceIds []string, opts *BatchOptions) *BatchReport {
+	ctx = withOperation(ctx)
+	size, concurrency := DefaultBatchSize, 4
+	if opts != nil {
+		if opts.BatchSize > 0 {
+			size = opts.BatchSize
+		}
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+	}
+	report := &BatchReport{Results: make([]InstanceResult, len(instanceIds))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for start := 0; start < len(instanceIds); start += size {
+		end := start + size
+		if end > len(instanceIds) {
+			end = len(instanceIds)
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			batch := instanceIds[start:end]
+			resp, err := elb.RegisterInstancesWithLoadBalancerWithContext(ctx, batch, lbName)
+			registered := make(map[string]bool)
+			if err == nil {
+				for _, id := range resp.InstanceIds {
+					registered[id] = true
+				}
+			}
+			for i, id := range batch {
+				result := InstanceResult{InstanceId: id, Err: err}
+				if err == nil && !registered[id] {
+					result.Err = fmt.Errorf("elb: instance %s missing from the registered instances of %s", id, lbName)
+				}
+				report.Results[start+i] = result
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return report
+}
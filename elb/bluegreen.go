@@ -0,0 +1,74 @@
+package elb
+
+import (
+	"context"
+	"errors"
+)
+
+// SwapOptions configures SwapInstances. A nil *SwapOptions uses the
+// defaults of each step.
+type SwapOptions struct {
+	// Batch configures the registration of the green instances.
+	Batch *BatchOptions
+	// Waiter configures the wait for the green instances to be InService
+	// and for the blue instances to drain.
+	Waiter *WaiterConfig
+}
+
+// SwapInstances moves the traffic of the Load Balancer from the blue
+// instances to the green ones: the green instances are registered in
+// batches, and once all of them are InService the blue instances are
+// deregistered and drained, like DeregisterAndDrain does.
+//
+// If the green instances can't be registered or don't become healthy,
+// the ones registered are deregistered again and the blue instances are
+// left untouched, still serving. The error of the failed step is
+// returned, joined with the error of the rollback if it failed too.
+func (elb *ELB) SwapInstances(lbName string, blue, green []string, opts *SwapOptions) error {
+	return elb.SwapInstancesWithContext(context.Background(), lbName, blue, green, opts)
+}
+
+// SwapInstancesWithContext is like SwapInstances, but the requests are
+// bound to ctx. The rollback runs even if a step failed because ctx was
+// canceled or its deadline passed, bounded by the MaxWait of the waiter
+// instead.
+func (elb *ELB) SwapInstancesWithContext(ctx context.Context, lbName string, blue, green []string, opts *SwapOptions) error {
+	ctx = withOperation(ctx)
+	var o SwapOptions
+	if opts != nil {
+		o = *opts
+	}
+	report := elb.RegisterInstancesInBatchesWithContext(ctx, lbName, green, o.Batch)
+	if err := report.Err(); err != nil {
+		var registered []string
+		for _, result := range report.Results {
+			if result.Err == nil {
+				registered = append(registered, result.InstanceId)
+			}
+		}
+		return elb.rollback(ctx, lbName, registered, o.Waiter, err)
+	}
+	if err := elb.WaitUntilInstanceInService(ctx, lbName, green, o.Waiter); err != nil {
+		return elb.rollback(ctx, lbName, green, o.Waiter, err)
+	}
+	if len(blue) == 0 {
+		return nil
+	}
+	return elb.DeregisterAndDrainWithContext(ctx, lbName, blue, o.Waiter)
+}
+
+// rollback deregisters the instances registered by an operation that
+// failed with err, on a context detached from the cancellation of ctx
+// since the operation may have failed because ctx is done. It returns err,
+// joined with the error of the deregistration if any.
+func (elb *ELB) rollback(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig, err error) error {
+	if len(instanceIds) == 0 {
+		return err
+	}
+	ctx, cancel := rollbackContext(ctx, cfg)
+	defer cancel()
+	if _, rbErr := elb.DeregisterInstancesFromLoadBalancerWithContext(ctx, instanceIds, lbName); rbErr != nil {
+		return errors.Join(err, rbErr)
+	}
+	return err
+}
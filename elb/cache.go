@@ -0,0 +1,128 @@
+package elb
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCache makes the client remember the results of DescribeLoadBalancers
+// and DescribeInstanceHealth for ttl, answering identical requests from
+// memory, which keeps polling loops like the ones of dashboards and
+// controllers under the rate limits of the API.
+//
+// Requests are identical when all their parameters are, so each page, set
+// of names or set of instances is cached on its own. Any other call
+// through the same client, apart from other descriptions, may change the
+// Load Balancers and empties the cache. Changes made by other clients are
+// only seen once the results expire. A ttl lower than or equal to zero
+// disables the cache.
+func WithCache(ttl time.Duration) Option {
+	return func(elb *ELB) {
+		if ttl <= 0 {
+			elb.cache = nil
+			return
+		}
+		elb.cache = &responseCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+	}
+}
+
+// cachedActions holds the actions whose results are cached.
+var cachedActions = map[string]bool{
+	"DescribeLoadBalancers":  true,
+	"DescribeInstanceHealth": true,
+}
+
+// responseCache holds the results of requests, encoded as JSON so that
+// callers never share them.
+type responseCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	// generation is incremented by each invalidation, so that the results
+	// of requests sent before it aren't stored.
+	generation uint64
+}
+
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// cacheKey returns the key of the request with the given parameters.
+func cacheKey(params map[string]string) string {
+	values := make(url.Values, len(params))
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// get decodes the result stored for key into resp, reporting whether
+// there was one.
+func (c *responseCache) get(key string, resp interface{}) bool {
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if ok && !time.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mutex.Unlock()
+	return ok && json.Unmarshal(entry.data, resp) == nil
+}
+
+// put stores resp for key, unless the cache was invalidated since
+// generation.
+func (c *responseCache) put(key string, generation uint64, resp interface{}) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generation == generation {
+		c.entries[key] = cacheEntry{data: data, expires: time.Now().Add(c.ttl)}
+	}
+}
+
+func (c *responseCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+func (c *responseCache) invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.entries = make(map[string]cacheEntry)
+}
+
+// cachedQuery sends the request through the cache of the client, if any.
+func (elb *ELB) cachedQuery(params map[string]string, resp interface{}, query func() error) error {
+	c := elb.cache
+	if c == nil {
+		return query()
+	}
+	action := params["Action"]
+	if !cachedActions[action] {
+		err := query()
+		if !strings.HasPrefix(action, "Describe") {
+			// Invalidate even on errors, which may follow partial changes.
+			c.invalidate()
+		}
+		return err
+	}
+	key := cacheKey(params)
+	if c.get(key, resp) {
+		return nil
+	}
+	generation := c.currentGeneration()
+	if err := query(); err != nil {
+		return err
+	}
+	c.put(key, generation, resp)
+	return nil
+}
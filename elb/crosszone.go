@@ -0,0 +1,45 @@
+package elb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrAttributesNotApplied is returned, wrapped along with the attribute,
+// when the attributes read back from a Load Balancer after modifying them
+// don't hold the values set.
+var ErrAttributesNotApplied = errors.New("elb: attributes not applied")
+
+// SetCrossZoneLoadBalancing enables or disables cross-zone load balancing
+// on the Load Balancer, which then routes requests evenly across the
+// instances of all its Availability Zones rather than evenly across the
+// zones. The attribute is read back after the modification, and an error
+// wrapping ErrAttributesNotApplied is returned if it doesn't hold enabled.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/classic/enable-disable-crosszone-lb.html
+// for more details.
+func (elb *ELB) SetCrossZoneLoadBalancing(lbName string, enabled bool) error {
+	return elb.SetCrossZoneLoadBalancingWithContext(context.Background(), lbName, enabled)
+}
+
+// SetCrossZoneLoadBalancingWithContext is like SetCrossZoneLoadBalancing,
+// but the requests are bound to ctx.
+func (elb *ELB) SetCrossZoneLoadBalancingWithContext(ctx context.Context, lbName string, enabled bool) error {
+	ctx = withOperation(ctx)
+	attrs := &LoadBalancerAttributes{
+		CrossZoneLoadBalancing: &CrossZoneLoadBalancing{Enabled: enabled},
+	}
+	if _, err := elb.ModifyLoadBalancerAttributesWithContext(ctx, lbName, attrs); err != nil {
+		return err
+	}
+	resp, err := elb.DescribeLoadBalancerAttributesWithContext(ctx, lbName)
+	if err != nil {
+		return err
+	}
+	cz := resp.LoadBalancerAttributes.CrossZoneLoadBalancing
+	if cz == nil || cz.Enabled != enabled {
+		return fmt.Errorf("%w: CrossZoneLoadBalancing.Enabled of %s isn't %t", ErrAttributesNotApplied, lbName, enabled)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package elb
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxResponseSize is the maximum size of the response bodies
+// accepted by clients created without WithMaxResponseSize. It's far above
+// the size of the largest pages of results, and only guards against
+// misbehaving endpoints.
+const DefaultMaxResponseSize = 64 << 20
+
+// ErrResponseTooLarge is returned when the body of a response exceeds the
+// maximum size accepted by the client.
+var ErrResponseTooLarge = errors.New("elb: response body too large")
+
+// WithMaxResponseSize makes the client reject response bodies larger than
+// n bytes with ErrResponseTooLarge. A value of n lower than 1 removes the
+// limit.
+func WithMaxResponseSize(n int64) Option {
+	return func(elb *ELB) {
+		elb.maxResponseSize = &n
+	}
+}
+
+// limitBody returns the body of r limited to the maximum response size of
+// the client, or ErrResponseTooLarge if its announced length is already
+// above it.
+func (elb *ELB) limitBody(r *http.Response) (io.Reader, error) {
+	n := int64(DefaultMaxResponseSize)
+	if elb.maxResponseSize != nil {
+		n = *elb.maxResponseSize
+	}
+	if n < 1 {
+		return r.Body, nil
+	}
+	if r.ContentLength > n {
+		return nil, ErrResponseTooLarge
+	}
+	return &limitedReader{r: r.Body, n: n}, nil
+}
+
+// limitedReader reads from r until n bytes have been read, and fails with
+// ErrResponseTooLarge if there's more to read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// decodeResponse decodes the XML response read from body into resp as
+// it's received, without buffering the whole body. Only its beginning is
+// kept, for the *DecodeError returned if it's malformed.
+func decodeResponse(body io.Reader, resp interface{}) error {
+	head := headBuffer{max: maxBodySnippet + 1}
+	r := io.TeeReader(body, &head)
+	if err := xml.NewDecoder(r).Decode(resp); err != nil {
+		if err == ErrResponseTooLarge {
+			return err
+		}
+		// The decoder may stop before the end of the snippet.
+		io.CopyN(io.Discard, r, int64(head.max))
+		return &DecodeError{Body: bodySnippet(head.buf), Err: err}
+	}
+	// Reading what follows the document lets the connection be reused, and
+	// checks that it isn't above the size limit either.
+	_, err := io.Copy(io.Discard, body)
+	return err
+}
+
+// headBuffer keeps the first max bytes written to it, and discards the
+// others.
+type headBuffer struct {
+	buf []byte
+	max int
+}
+
+func (h *headBuffer) Write(p []byte) (int, error) {
+	if n := h.max - len(h.buf); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		h.buf = append(h.buf, p[:n]...)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,129 @@
+package elb_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"io"
+	. "launchpad.net/gocheck"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (s *S) TestMaxResponseSize(c *C) {
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	region := aws.Region{ELBEndpoint: testServer.URL}
+	e := elb.New(s.elb.Auth, region, noRetry, elb.WithMaxResponseSize(int64(len(DescribeLoadBalancers)-1)))
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	_, err := e.DescribeLoadBalancers()
+	c.Assert(err, Equals, elb.ErrResponseTooLarge)
+	testServer.WaitRequest()
+
+	// Without a Content-Length, the limit is only hit while decoding.
+	e = elb.New(s.elb.Auth, region, noRetry, elb.WithMaxResponseSize(100))
+	testServer.PrepareResponse(200, map[string]string{"Transfer-Encoding": "chunked"}, DescribeLoadBalancers)
+	_, err = e.DescribeLoadBalancers()
+	c.Assert(err, Equals, elb.ErrResponseTooLarge)
+	testServer.WaitRequest()
+
+	e = elb.New(s.elb.Auth, region, noRetry, elb.WithMaxResponseSize(int64(len(DescribeLoadBalancers))))
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	resp, err := e.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestMaxResponseSizeWithLogger(c *C) {
+	var buf bytes.Buffer
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	e := elb.New(s.elb.Auth, aws.Region{ELBEndpoint: testServer.URL}, noRetry,
+		elb.WithMaxResponseSize(100), elb.WithLogger(log.New(&buf, "", 0)))
+	testServer.PrepareResponse(200, map[string]string{"Transfer-Encoding": "chunked"}, DescribeLoadBalancers)
+	_, err := e.DescribeLoadBalancers()
+	c.Assert(err, Equals, elb.ErrResponseTooLarge)
+	testServer.WaitRequest()
+	c.Assert(buf.String(), Matches, "(?s)elb: DescribeLoadBalancers .*: 200, reading body: elb: response body too large .*")
+
+	// Only the beginning of large bodies is logged.
+	buf.Reset()
+	e = elb.New(s.elb.Auth, aws.Region{ELBEndpoint: testServer.URL}, noRetry, elb.WithLogger(log.New(&buf, "", 0)))
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	_, err = e.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	c.Assert(len(DescribeLoadBalancers) > 512, Equals, true)
+	c.Assert(buf.String(), Matches, "(?s).*: 200 \\(.*\\)\n\\s*<DescribeLoadBalancersResponse.*\\.\\.\\.\n")
+	c.Assert(strings.Contains(buf.String(), DescribeLoadBalancers[:512]), Equals, true)
+	c.Assert(strings.Contains(buf.String(), DescribeLoadBalancers[:513]), Equals, false)
+}
+
+func (s *S) TestMaxResponseSizeDisabled(c *C) {
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	e := elb.New(s.elb.Auth, aws.Region{ELBEndpoint: testServer.URL}, noRetry, elb.WithMaxResponseSize(0))
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	_, err := e.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+}
+
+// DecodeSuite serves a page of 1,000 Load Balancers, to measure the cost
+// of decoding large responses.
+type DecodeSuite struct {
+	srv     *httptest.Server
+	fixture string
+	elb     *elb.ELB
+}
+
+var _ = Suite(&DecodeSuite{})
+
+func (s *DecodeSuite) SetUpSuite(c *C) {
+	s.fixture = describeLoadBalancersFixture(1000)
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		io.WriteString(w, s.fixture)
+	}))
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	s.elb = elb.New(auth, aws.Region{ELBEndpoint: s.srv.URL})
+}
+
+func (s *DecodeSuite) TearDownSuite(c *C) {
+	s.srv.Close()
+}
+
+// describeLoadBalancersFixture returns a DescribeLoadBalancers response
+// holding n copies of the Load Balancer of DescribeLoadBalancers.
+func describeLoadBalancersFixture(n int) string {
+	start := strings.Index(DescribeLoadBalancers, "<member>")
+	end := strings.LastIndex(DescribeLoadBalancers, "</LoadBalancerDescriptions>")
+	member := DescribeLoadBalancers[start:end]
+	var b strings.Builder
+	b.WriteString(DescribeLoadBalancers[:start])
+	for i := 0; i < n; i++ {
+		b.WriteString(strings.Replace(member, "testlb", fmt.Sprintf("testlb%d", i), -1))
+	}
+	b.WriteString(DescribeLoadBalancers[end:])
+	return b.String()
+}
+
+func (s *DecodeSuite) TestDecodeLargeResponse(c *C) {
+	resp, err := s.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1000)
+	c.Assert(resp.LoadBalancerDescriptions[999].LoadBalancerName, Equals, "testlb999")
+	c.Assert(resp.LoadBalancerDescriptions[999].ListenerDescriptions, HasLen, 1)
+}
+
+// BenchmarkDescribe1000 decodes the page of 1,000 Load Balancers. Run it
+// with go test -check.b -check.bmem to see the memory used per call.
+func (s *DecodeSuite) BenchmarkDescribe1000(c *C) {
+	c.SetBytes(int64(len(s.fixture)))
+	for i := 0; i < c.N; i++ {
+		if _, err := s.elb.DescribeLoadBalancers(); err != nil {
+			c.Fatal(err)
+		}
+	}
+}
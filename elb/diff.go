@@ -0,0 +1,198 @@
+package elb
+
+import (
+	"sort"
+	"strings"
+)
+
+// ChangeSet holds the changes needed to turn a Load Balancer into the
+// desired one, as computed by Diff. The zero value means no drift.
+type ChangeSet struct {
+	// ListenersToRemove holds the listeners to delete, and ListenersToAdd
+	// the ones to create. A listener whose protocols or instance port
+	// changed appears in both, as listeners can't be modified in place.
+	ListenersToAdd    []Listener
+	ListenersToRemove []Listener
+	// CertificatesToSet holds the desired listeners that only differ from
+	// the current ones by their SSLCertificateId, which can be updated with
+	// SetLoadBalancerListenerSSLCertificate.
+	CertificatesToSet []Listener
+
+	// HealthCheck is the desired health check when it differs from the
+	// current one, or nil.
+	HealthCheck *HealthCheck
+
+	AvailZonesToAdd        []string
+	AvailZonesToRemove     []string
+	SubnetsToAdd           []string
+	SubnetsToRemove        []string
+	SecurityGroupsToAdd    []string
+	SecurityGroupsToRemove []string
+
+	// SchemeChanged is set when the schemes differ, which can only be
+	// fixed by creating the Load Balancer again.
+	SchemeChanged bool
+
+	// TagsToSet holds the tags to add or update, and TagsToRemove the keys
+	// of the tags to remove. Diff leaves them empty, as descriptions don't
+	// hold tags: fill them with DiffTags.
+	TagsToSet    []Tag
+	TagsToRemove []string
+}
+
+// Empty reports whether there is no change to make.
+func (cs *ChangeSet) Empty() bool {
+	return len(cs.drifted()) == 0
+}
+
+// drifted returns the names of the fields with changes.
+func (cs *ChangeSet) drifted() []string {
+	var fields []string
+	if len(cs.ListenersToAdd)+len(cs.ListenersToRemove)+len(cs.CertificatesToSet) > 0 {
+		fields = append(fields, "listeners")
+	}
+	if cs.HealthCheck != nil {
+		fields = append(fields, "health check")
+	}
+	if len(cs.AvailZonesToAdd)+len(cs.AvailZonesToRemove) > 0 {
+		fields = append(fields, "availability zones")
+	}
+	if len(cs.SubnetsToAdd)+len(cs.SubnetsToRemove) > 0 {
+		fields = append(fields, "subnets")
+	}
+	if len(cs.SecurityGroupsToAdd)+len(cs.SecurityGroupsToRemove) > 0 {
+		fields = append(fields, "security groups")
+	}
+	if cs.SchemeChanged {
+		fields = append(fields, "scheme")
+	}
+	if len(cs.TagsToSet)+len(cs.TagsToRemove) > 0 {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+// Diff compares the desired state of a Load Balancer with its current
+// description, as returned by DescribeLoadBalancers, and returns the
+// changes needed to converge it.
+//
+// Comparisons follow the semantics of AWS rather than plain equality: the
+// order of zones, subnets, security groups and listeners doesn't matter,
+// protocols, including the one of health check targets, are case
+// insensitive, an empty instance protocol defaults like it does in AWS,
+// and an empty scheme means internet-facing. Like in LoadBalancerSpec, nil
+// slices and a zero HealthCheck in desired mean that the field isn't
+// managed, and are never reported as drift.
+func Diff(desired, actual LoadBalancerDescription) *ChangeSet {
+	var cs ChangeSet
+	if desired.ListenerDescriptions != nil {
+		listeners := make([]Listener, len(desired.ListenerDescriptions))
+		for i, d := range desired.ListenerDescriptions {
+			listeners[i] = d.Listener
+		}
+		cs.ListenersToAdd, cs.ListenersToRemove, cs.CertificatesToSet = diffListeners(listeners, actual.ListenerDescriptions)
+	}
+	if desired.HealthCheck != (HealthCheck{}) && !sameHealthCheck(desired.HealthCheck, actual.HealthCheck) {
+		hc := desired.HealthCheck
+		cs.HealthCheck = &hc
+	}
+	if desired.AvailZones != nil {
+		cs.AvailZonesToAdd, cs.AvailZonesToRemove = diffStrings(actual.AvailZones, desired.AvailZones)
+	}
+	if desired.Subnets != nil {
+		cs.SubnetsToAdd, cs.SubnetsToRemove = diffStrings(actual.Subnets, desired.Subnets)
+	}
+	if desired.SecurityGroups != nil {
+		cs.SecurityGroupsToAdd, cs.SecurityGroupsToRemove = diffStrings(actual.SecurityGroups, desired.SecurityGroups)
+	}
+	cs.SchemeChanged = !strings.EqualFold(scheme(desired.Scheme), scheme(actual.Scheme))
+	return &cs
+}
+
+// DiffTags compares the desired tags of a Load Balancer with its current
+// ones, as returned by DescribeTags, and returns the tags to add or
+// update, and the keys of the tags to remove, sorted by key.
+func DiffTags(desired, actual []Tag) (set []Tag, remove []string) {
+	current := make(map[string]string, len(actual))
+	for _, t := range actual {
+		current[t.Key] = t.Value
+	}
+	return diffTags(desired, current)
+}
+
+func diffTags(desired []Tag, current map[string]string) (set []Tag, remove []string) {
+	wanted := make(map[string]bool, len(desired))
+	for _, t := range desired {
+		wanted[t.Key] = true
+		if v, ok := current[t.Key]; !ok || v != t.Value {
+			set = append(set, t)
+		}
+	}
+	for k := range current {
+		if !wanted[k] {
+			remove = append(remove, k)
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Key < set[j].Key })
+	sort.Strings(remove)
+	return set, remove
+}
+
+// diffListeners returns the listeners to create and delete, matched by
+// Load Balancer port, and the ones whose certificate only needs updating.
+func diffListeners(desired []Listener, current []ListenerDescription) (add, remove, certificates []Listener) {
+	wanted := make(map[int]Listener, len(desired))
+	for _, l := range desired {
+		wanted[l.LoadBalancerPort] = l
+	}
+	existing := make(map[int]bool, len(current))
+	for _, d := range current {
+		cur := d.Listener
+		existing[cur.LoadBalancerPort] = true
+		l, ok := wanted[cur.LoadBalancerPort]
+		switch {
+		case !ok:
+			remove = append(remove, cur)
+		case !sameListener(cur, l):
+			remove = append(remove, cur)
+			add = append(add, l)
+		case cur.SSLCertificateId != l.SSLCertificateId:
+			certificates = append(certificates, l)
+		}
+	}
+	for _, l := range desired {
+		if !existing[l.LoadBalancerPort] {
+			add = append(add, l)
+		}
+	}
+	return add, remove, certificates
+}
+
+// sameHealthCheck reports whether two health checks are equivalent. The
+// protocol of the targets is case insensitive, but not their path.
+func sameHealthCheck(a, b HealthCheck) bool {
+	ta, tb := a.Target, b.Target
+	a.Target, b.Target = "", ""
+	if a != b {
+		return false
+	}
+	pa, ra := splitTarget(ta)
+	pb, rb := splitTarget(tb)
+	return strings.EqualFold(pa, pb) && ra == rb
+}
+
+// splitTarget splits a health check target like HTTP:80/ping into its
+// protocol and the rest.
+func splitTarget(target string) (protocol, rest string) {
+	if i := strings.Index(target, ":"); i >= 0 {
+		return target[:i], target[i:]
+	}
+	return target, ""
+}
+
+func scheme(s string) string {
+	if s == "" {
+		return SchemeInternetFacing
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package elb_test
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+)
+
+func listeners(ls ...elb.Listener) []elb.ListenerDescription {
+	descs := make([]elb.ListenerDescription, len(ls))
+	for i, l := range ls {
+		descs[i].Listener = l
+	}
+	return descs
+}
+
+func (s *S) TestDiffNoDrift(c *C) {
+	actual := elb.LoadBalancerDescription{
+		LoadBalancerName: "testlb",
+		Scheme:           elb.SchemeInternetFacing,
+		AvailZones:       []string{"us-east-1a", "us-east-1b"},
+		ListenerDescriptions: listeners(
+			elb.Listener{Protocol: "HTTP", LoadBalancerPort: 80, InstanceProtocol: "HTTP", InstancePort: 8080},
+			elb.Listener{Protocol: "TCP", LoadBalancerPort: 22, InstanceProtocol: "TCP", InstancePort: 22},
+		),
+		HealthCheck: elb.HealthCheck{Target: "HTTP:8080/ping", Interval: 30, Timeout: 5, HealthyThreshold: 2, UnhealthyThreshold: 2},
+	}
+	desired := elb.LoadBalancerDescription{
+		LoadBalancerName: "testlb",
+		AvailZones:       []string{"us-east-1b", "us-east-1a"},
+		ListenerDescriptions: listeners(
+			elb.Listener{Protocol: "tcp", LoadBalancerPort: 22, InstancePort: 22},
+			elb.Listener{Protocol: "http", LoadBalancerPort: 80, InstancePort: 8080},
+		),
+		HealthCheck: elb.HealthCheck{Target: "http:8080/ping", Interval: 30, Timeout: 5, HealthyThreshold: 2, UnhealthyThreshold: 2},
+	}
+	cs := elb.Diff(desired, actual)
+	c.Assert(cs.Empty(), Equals, true, Commentf("%#v", cs))
+	// Unmanaged fields are never reported.
+	c.Assert(elb.Diff(elb.LoadBalancerDescription{}, actual).Empty(), Equals, true)
+}
+
+func (s *S) TestDiff(c *C) {
+	actual := elb.LoadBalancerDescription{
+		Scheme:         elb.SchemeInternetFacing,
+		Subnets:        []string{"subnet-1", "subnet-2"},
+		SecurityGroups: []string{"sg-1"},
+		ListenerDescriptions: listeners(
+			elb.Listener{Protocol: "HTTP", LoadBalancerPort: 80, InstanceProtocol: "HTTP", InstancePort: 80},
+			elb.Listener{Protocol: "HTTPS", LoadBalancerPort: 443, InstanceProtocol: "HTTP", InstancePort: 80, SSLCertificateId: "old"},
+			elb.Listener{Protocol: "TCP", LoadBalancerPort: 22, InstanceProtocol: "TCP", InstancePort: 22},
+		),
+		HealthCheck: elb.HealthCheck{Target: "HTTP:80/ping", Interval: 30, Timeout: 5, HealthyThreshold: 2, UnhealthyThreshold: 2},
+	}
+	desired := elb.LoadBalancerDescription{
+		Scheme:         elb.SchemeInternal,
+		Subnets:        []string{"subnet-2", "subnet-3"},
+		SecurityGroups: []string{},
+		ListenerDescriptions: listeners(
+			elb.Listener{Protocol: "HTTP", LoadBalancerPort: 80, InstancePort: 8080},
+			elb.Listener{Protocol: "HTTPS", LoadBalancerPort: 443, InstanceProtocol: "HTTP", InstancePort: 80, SSLCertificateId: "new"},
+			elb.Listener{Protocol: "SSL", LoadBalancerPort: 8443, InstancePort: 8443, SSLCertificateId: "new"},
+		),
+		HealthCheck: elb.HealthCheck{Target: "HTTP:8080/Ping", Interval: 30, Timeout: 5, HealthyThreshold: 2, UnhealthyThreshold: 2},
+	}
+	cs := elb.Diff(desired, actual)
+	c.Assert(cs.ListenersToRemove, DeepEquals, []elb.Listener{
+		{Protocol: "HTTP", LoadBalancerPort: 80, InstanceProtocol: "HTTP", InstancePort: 80},
+		{Protocol: "TCP", LoadBalancerPort: 22, InstanceProtocol: "TCP", InstancePort: 22},
+	})
+	c.Assert(cs.ListenersToAdd, DeepEquals, []elb.Listener{
+		{Protocol: "HTTP", LoadBalancerPort: 80, InstancePort: 8080},
+		{Protocol: "SSL", LoadBalancerPort: 8443, InstancePort: 8443, SSLCertificateId: "new"},
+	})
+	c.Assert(cs.CertificatesToSet, DeepEquals, []elb.Listener{
+		{Protocol: "HTTPS", LoadBalancerPort: 443, InstanceProtocol: "HTTP", InstancePort: 80, SSLCertificateId: "new"},
+	})
+	c.Assert(cs.HealthCheck, DeepEquals, &desired.HealthCheck)
+	c.Assert(cs.SubnetsToAdd, DeepEquals, []string{"subnet-3"})
+	c.Assert(cs.SubnetsToRemove, DeepEquals, []string{"subnet-1"})
+	c.Assert(cs.SecurityGroupsToAdd, IsNil)
+	c.Assert(cs.SecurityGroupsToRemove, DeepEquals, []string{"sg-1"})
+	c.Assert(cs.AvailZonesToAdd, IsNil)
+	c.Assert(cs.SchemeChanged, Equals, true)
+	c.Assert(cs.Empty(), Equals, false)
+}
+
+func (s *S) TestDiffTags(c *C) {
+	actual := []elb.Tag{{Key: "env", Value: "staging"}, {Key: "team", Value: "web"}, {Key: "old", Value: "x"}}
+	desired := []elb.Tag{{Key: "team", Value: "web"}, {Key: "env", Value: "prod"}, {Key: "app", Value: "shop"}}
+	set, remove := elb.DiffTags(desired, actual)
+	c.Assert(set, DeepEquals, []elb.Tag{{Key: "app", Value: "shop"}, {Key: "env", Value: "prod"}})
+	c.Assert(remove, DeepEquals, []string{"old"})
+	set, remove = elb.DiffTags(actual, actual)
+	c.Assert(set, IsNil)
+	c.Assert(remove, IsNil)
+}
@@ -0,0 +1,57 @@
+package elb
+
+import (
+	"context"
+	"sort"
+)
+
+// EC2 is the subset of the EC2 API needed to discover instances. Implement
+// it on top of any EC2 client, e.g. the ec2 package of this repository,
+// or wrap a function with EC2Func, to use RegisterInstancesByTag.
+type EC2 interface {
+	// InstanceIds returns the ids of the instances matching all the
+	// filters of DescribeInstances, given as filter names mapped to
+	// their accepted values.
+	//
+	// See http://docs.aws.amazon.com/AWSEC2/latest/APIReference/API_DescribeInstances.html
+	// for more details.
+	InstanceIds(ctx context.Context, filters map[string][]string) ([]string, error)
+}
+
+// EC2Func adapts a function to the EC2 interface.
+type EC2Func func(ctx context.Context, filters map[string][]string) ([]string, error)
+
+// InstanceIds calls f(ctx, filters).
+func (f EC2Func) InstanceIds(ctx context.Context, filters map[string][]string) ([]string, error) {
+	return f(ctx, filters)
+}
+
+// RegisterInstancesByTag registers with the Load Balancer the running
+// instances having all the given tags, e.g. {"role": "web"}, and returns
+// their ids, sorted. Instances are registered in batches, like
+// RegisterInstancesInBatches does, and the ids of the instances found are
+// returned even if some of them couldn't be registered.
+//
+// Finding no instance isn't an error: nothing is registered.
+func (elb *ELB) RegisterInstancesByTag(e EC2, lbName string, tags map[string]string) ([]string, error) {
+	return elb.RegisterInstancesByTagWithContext(context.Background(), e, lbName, tags)
+}
+
+// RegisterInstancesByTagWithContext is like RegisterInstancesByTag, but
+// ctx is passed to e and the requests are bound to it.
+func (elb *ELB) RegisterInstancesByTagWithContext(ctx context.Context, e EC2, lbName string, tags map[string]string) ([]string, error) {
+	ctx = withOperation(ctx)
+	filters := map[string][]string{"instance-state-name": {"running"}}
+	for k, v := range tags {
+		filters["tag:"+k] = []string{v}
+	}
+	ids, err := e.InstanceIds(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sort.Strings(ids)
+	return ids, elb.RegisterInstancesInBatchesWithContext(ctx, lbName, ids, nil).Err()
+}
@@ -0,0 +1,68 @@
+package elb
+
+import (
+	"context"
+	"time"
+)
+
+// EnableConnectionDraining enables connection draining on the Load
+// Balancer: deregistered instances keep serving in-flight requests for up
+// to timeout before being removed. The timeout is rounded down to seconds,
+// and AWS accepts values between 1 second and 1 hour.
+func (elb *ELB) EnableConnectionDraining(lbName string, timeout time.Duration) error {
+	return elb.EnableConnectionDrainingWithContext(context.Background(), lbName, timeout)
+}
+
+// EnableConnectionDrainingWithContext is like EnableConnectionDraining,
+// but the requests are bound to ctx.
+func (elb *ELB) EnableConnectionDrainingWithContext(ctx context.Context, lbName string, timeout time.Duration) error {
+	attrs := &LoadBalancerAttributes{
+		ConnectionDraining: &ConnectionDraining{
+			Enabled: true,
+			Timeout: int(timeout / time.Second),
+		},
+	}
+	_, err := elb.ModifyLoadBalancerAttributesWithContext(ctx, lbName, attrs)
+	return err
+}
+
+// DeregisterAndDrain deregisters the instances from the Load Balancer and
+// waits until none of them is InService anymore, so that they can be
+// stopped without dropping requests.
+//
+// When connection draining is enabled, the wait ends successfully once the
+// draining timeout elapses, since AWS stops routing to the instances by
+// then. Otherwise cfg.MaxWait applies and ErrWaitTimeout is returned if
+// it's exceeded.
+func (elb *ELB) DeregisterAndDrain(lbName string, instanceIds []string, cfg *WaiterConfig) error {
+	return elb.DeregisterAndDrainWithContext(context.Background(), lbName, instanceIds, cfg)
+}
+
+// DeregisterAndDrainWithContext is like DeregisterAndDrain, but the
+// requests and the wait are bound to ctx.
+func (elb *ELB) DeregisterAndDrainWithContext(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig) error {
+	ctx = withOperation(ctx)
+	attrs, err := elb.DescribeLoadBalancerAttributesWithContext(ctx, lbName)
+	if err != nil {
+		return err
+	}
+	if _, err := elb.DeregisterInstancesFromLoadBalancerWithContext(ctx, instanceIds, lbName); err != nil {
+		return err
+	}
+	draining := attrs.LoadBalancerAttributes.ConnectionDraining
+	if draining == nil || !draining.Enabled || draining.Timeout <= 0 {
+		return elb.WaitUntilInstanceOutOfService(ctx, lbName, instanceIds, cfg)
+	}
+	c := WaiterConfig{Delay: DefaultWaiterConfig.Delay}
+	if cfg != nil && cfg.Delay > 0 {
+		c.Delay = cfg.Delay
+	}
+	// The waiter gives up when the next poll would be past MaxWait, so one
+	// delay is added for the wait to last the whole draining timeout.
+	c.MaxWait = time.Duration(draining.Timeout)*time.Second + c.Delay
+	err = elb.WaitUntilInstanceOutOfService(ctx, lbName, instanceIds, &c)
+	if err == ErrWaitTimeout {
+		return nil
+	}
+	return err
+}
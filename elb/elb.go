@@ -2,22 +2,171 @@
 package elb
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb/sign"
+	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"strings"
 	"time"
 )
 
 type ELB struct {
 	aws.Auth
 	aws.Region
+	signatureVersion SignatureVersion
+	credentials      aws.CredentialsProvider
+	retryPolicy      *RetryPolicy
+	client           *http.Client
+	insecure         bool
+	disableSSL       bool
+	logger           Logger
+	metrics          Metrics
+	signingName      string
+	limiter          *rateLimiter
+	protectedNames   []string
+	throttleObserver ThrottleObserver
+	transportConfig  *TransportConfig
+	timeouts         *TimeoutConfig
+	maxResponseSize  *int64
+	cache            *responseCache
+
+	skipListenerValidation bool
+}
+
+// SignatureVersion identifies the algorithm used to sign requests.
+type SignatureVersion int
+
+const (
+	SignatureV2 SignatureVersion = 2
+	SignatureV4 SignatureVersion = 4
+)
+
+// Option configures optional behaviour of an ELB client. Options are
+// applied in order by New.
+type Option func(*ELB)
+
+// WithSignatureVersion forces the client to sign requests with the given
+// signature version, regardless of the region defaults.
+func WithSignatureVersion(v SignatureVersion) Option {
+	return func(elb *ELB) {
+		elb.signatureVersion = v
+	}
+}
+
+// WithCredentials makes the client ask p for credentials before signing
+// each request, instead of using the static aws.Auth given to New.
+func WithCredentials(p aws.CredentialsProvider) Option {
+	return func(elb *ELB) {
+		elb.credentials = p
+	}
+}
+
+// WithHTTPClient makes the client send requests through c, allowing
+// callers to configure timeouts, proxies, connection pooling or TLS.
+//
+// By default each client gets its own transport, configured by
+// WithTransport, and no timeout, so requests are only bounded by the
+// deadline of the context given to the WithContext variants of the
+// operations.
+func WithHTTPClient(c *http.Client) Option {
+	return func(elb *ELB) {
+		elb.client = c
+	}
+}
+
+// WithEndpoint makes the client send requests to endpoint instead of the
+// ELB endpoint of its region, e.g. to use LocalStack, moto or the elbtest
+// server. The region name is still used to sign requests.
+func WithEndpoint(endpoint string) Option {
+	return func(elb *ELB) {
+		elb.Region.ELBEndpoint = endpoint
+	}
+}
+
+// WithSigningName makes the client sign requests with Signature Version 4
+// for the given service instead of "elasticloadbalancing". It allows
+// packages built on Query to reach other AWS query APIs, like CloudWatch.
+func WithSigningName(service string) Option {
+	return func(elb *ELB) {
+		elb.signingName = service
+	}
+}
+
+// WithInsecureSkipVerify makes the client accept any TLS certificate
+// presented by the endpoint. It's meant for emulators using self-signed
+// certificates and must not be used against AWS.
+//
+// It has no effect on HTTP clients given to WithHTTPClient whose transport
+// isn't an *http.Transport.
+func WithInsecureSkipVerify() Option {
+	return func(elb *ELB) {
+		elb.insecure = true
+	}
+}
+
+// WithDisableSSL makes the client use plain HTTP, even if the endpoint is
+// an https URL.
+func WithDisableSSL() Option {
+	return func(elb *ELB) {
+		elb.disableSSL = true
+	}
+}
+
+// New creates a new ELB client for the given region.
+//
+// Requests are signed with Signature Version 4 when the region returned by
+// LookupRegion for its name is SignatureV4Only, which includes the regions
+// missing from Regions, and with Signature Version 2 otherwise or when the
+// region has no name. WithSignatureVersion overrides the default. A region
+// without an ELBEndpoint uses the endpoint returned by LookupRegion for its
+// name, so that regions of any partition work out of the box.
+func New(auth aws.Auth, region aws.Region, options ...Option) *ELB {
+	elb := &ELB{Auth: auth, Region: region}
+	for _, option := range options {
+		option(elb)
+	}
+	if elb.client == nil {
+		t := newTransport(elb.transportConfig)
+		applyTimeouts(t, elb.timeouts)
+		elb.client = &http.Client{Transport: t}
+	}
+	if elb.insecure {
+		elb.client = insecureClient(elb.httpClient())
+	}
+	return elb
 }
 
-func New(auth aws.Auth, region aws.Region) *ELB {
-	return &ELB{auth, region}
+// NewFromChain creates a new ELB client that resolves its credentials
+// through the default chain: the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN environment variables, then the shared credentials
+// file (~/.aws/credentials), then the EC2 instance metadata service.
+//
+// An error is returned if none of them is able to provide credentials.
+func NewFromChain(region aws.Region, options ...Option) (*ELB, error) {
+	chain := aws.NewChainProvider()
+	if _, err := chain.Credentials(); err != nil {
+		return nil, err
+	}
+	options = append([]Option{WithCredentials(chain)}, options...)
+	return New(aws.Auth{}, region, options...), nil
+}
+
+// SignatureVersion returns the signature version used by the client.
+func (elb *ELB) SignatureVersion() SignatureVersion {
+	if elb.signatureVersion != 0 {
+		return elb.signatureVersion
+	}
+	if elb.Region.Name != "" && LookupRegion(elb.Region.Name).SignatureV4Only {
+		return SignatureV4
+	}
+	return SignatureV2
 }
 
 // The CreateLoadBalancer type encapsulates options for the respective request in AWS.
@@ -25,43 +174,88 @@ func New(auth aws.Auth, region aws.Region) *ELB {
 //
 // See http://goo.gl/4QFKi for more details.
 type CreateLoadBalancer struct {
-	Name           string
-	AvailZones     []string
-	Listeners      []Listener
-	Scheme         string
-	SecurityGroups []string
-	Subnets        []string
+	Name       string     `elb:"LoadBalancerName" json:"LoadBalancerName"`
+	AvailZones []string   `elb:"AvailabilityZones.member" json:"AvailabilityZones"`
+	Listeners  []Listener `elb:"Listeners.member" json:"Listeners"`
+
+	// Scheme is SchemeInternetFacing, the default, or SchemeInternal.
+	// Internal Load Balancers are only reachable from inside their VPC, so
+	// they require Subnets.
+	Scheme string `elb:",omitempty" json:"Scheme,omitempty"`
+
+	// SecurityGroups and Subnets place the Load Balancer in a VPC. Subnets
+	// can't be used along with AvailZones.
+	SecurityGroups []string `elb:"SecurityGroups.member" json:"SecurityGroups"`
+	Subnets        []string `elb:"Subnets.member" json:"Subnets"`
+}
+
+// Schemes of Load Balancers.
+const (
+	SchemeInternetFacing = "internet-facing"
+	SchemeInternal       = "internal"
+)
+
+// NormalizeScheme returns the canonical spelling of scheme, like
+// SchemeInternal for "Internal", matching it case-insensitively. Unknown
+// schemes are returned as is.
+func NormalizeScheme(scheme string) string {
+	switch {
+	case strings.EqualFold(scheme, SchemeInternetFacing):
+		return SchemeInternetFacing
+	case strings.EqualFold(scheme, SchemeInternal):
+		return SchemeInternal
+	}
+	return scheme
 }
 
 // Listener to configure in Load Balancer.
 //
 // See http://goo.gl/NJQCj for more details.
 type Listener struct {
-	InstancePort     int
-	InstanceProtocol string
-	LoadBalancerPort int
-	Protocol         string
-	SSLCertificateId string
+	InstancePort     int    `json:"InstancePort"`
+	InstanceProtocol string `json:"InstanceProtocol"`
+	LoadBalancerPort int    `json:"LoadBalancerPort"`
+	Protocol         string `json:"Protocol"`
+	SSLCertificateId string `elb:",omitempty" json:"SSLCertificateId,omitempty"`
 }
 
 // Response to a CreateLoadBalance request.
 //
+// AWS only returns the DNS name of the new Load Balancer. Its canonical
+// hosted zone, needed to create alias records, is part of the description
+// returned by DescribeLoadBalancers.
+//
 // See http://goo.gl/4QFKi for more details.
 type CreateLoadBalancerResp struct {
-	DNSName string `xml:"CreateLoadBalancerResult>DNSName"`
+	DNSName   string `xml:"CreateLoadBalancerResult>DNSName" json:"DNSName"`
+	RequestId string `xml:"ResponseMetadata>RequestId" json:"RequestId"`
 }
 
 type SimpleResp struct {
-	RequestId string `xml:"ResponseMetadata>RequestId"`
+	RequestId string `xml:"ResponseMetadata>RequestId" json:"RequestId"`
 }
 
 // Creates a Load Balancer in Amazon.
 //
+// Invalid listeners are reported with a *ListenerError before sending the
+// request, see Listener.Validate.
+//
 // See http://goo.gl/4QFKi for more details.
 func (elb *ELB) CreateLoadBalancer(options *CreateLoadBalancer) (resp *CreateLoadBalancerResp, err error) {
-	params := makeCreateParams(options)
+	return elb.CreateLoadBalancerWithContext(context.Background(), options)
+}
+
+// CreateLoadBalancerWithContext is like CreateLoadBalancer, but the request is
+// bound to ctx.
+func (elb *ELB) CreateLoadBalancerWithContext(ctx context.Context, options *CreateLoadBalancer) (resp *CreateLoadBalancerResp, err error) {
+	normalized := *options
+	normalized.Listeners = normalizeListeners(options.Listeners)
+	normalized.Scheme = NormalizeScheme(options.Scheme)
+	if err := elb.validateListeners(normalized.Listeners); err != nil {
+		return nil, err
+	}
 	resp = new(CreateLoadBalancerResp)
-	if err := elb.query(params, resp); err != nil {
+	if err := elb.call(ctx, "CreateLoadBalancer", &normalized, resp); err != nil {
 		return nil, err
 	}
 	return
@@ -69,84 +263,101 @@ func (elb *ELB) CreateLoadBalancer(options *CreateLoadBalancer) (resp *CreateLoa
 
 // Deletes a Load Balancer.
 //
+// Load Balancers protected by WithProtectedNames aren't deleted:
+// ErrProtectedLoadBalancer is returned before sending the request.
+//
 // See http://goo.gl/sDmPp for more details.
 func (elb *ELB) DeleteLoadBalancer(name string) (resp *SimpleResp, err error) {
-	params := map[string]string{
-		"Action":           "DeleteLoadBalancer",
-		"LoadBalancerName": name,
+	return elb.DeleteLoadBalancerWithContext(context.Background(), name)
+}
+
+// DeleteLoadBalancerWithContext is like DeleteLoadBalancer, but the request is
+// bound to ctx.
+func (elb *ELB) DeleteLoadBalancerWithContext(ctx context.Context, name string) (resp *SimpleResp, err error) {
+	if elb.isProtected(name) {
+		return nil, ErrProtectedLoadBalancer
 	}
+	req := struct{ LoadBalancerName string }{name}
 	resp = new(SimpleResp)
-	if err := elb.query(params, resp); err != nil {
+	if err := elb.call(ctx, "DeleteLoadBalancer", &req, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
 type RegisterInstancesResp struct {
-	InstanceIds []string `xml:"RegisterInstancesWithLoadBalancerResult>Instances>member>InstanceId"`
+	InstanceIds []string `xml:"RegisterInstancesWithLoadBalancerResult>Instances>member>InstanceId" json:"InstanceIds"`
+	RequestId   string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
 }
 
 // Register N instances with a given Load Balancer.
 //
 // See http://goo.gl/x9hru for more details.
 func (elb *ELB) RegisterInstancesWithLoadBalancer(instanceIds []string, lbName string) (resp *RegisterInstancesResp, err error) {
+	return elb.RegisterInstancesWithLoadBalancerWithContext(context.Background(), instanceIds, lbName)
+}
+
+// RegisterInstancesWithLoadBalancerWithContext is like
+// RegisterInstancesWithLoadBalancer, but the request is bound to ctx.
+func (elb *ELB) RegisterInstancesWithLoadBalancerWithContext(ctx context.Context, instanceIds []string, lbName string) (resp *RegisterInstancesResp, err error) {
 	// TODO: change params order and use ..., e.g (lbName string, instanceIds ...string)
-	params := map[string]string{
-		"Action":           "RegisterInstancesWithLoadBalancer",
-		"LoadBalancerName": lbName,
-	}
-	for i, instanceId := range instanceIds {
-		key := fmt.Sprintf("Instances.member.%d.InstanceId", i+1)
-		params[key] = instanceId
-	}
+	req := instancesRequest{LoadBalancerName: lbName, Instances: instances(instanceIds)}
 	resp = new(RegisterInstancesResp)
-	if err := elb.query(params, resp); err != nil {
+	if err := elb.call(ctx, "RegisterInstancesWithLoadBalancer", &req, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// Response to a DeregisterInstancesFromLoadBalancer request. InstanceIds
+// lists the instances still registered with the Load Balancer.
+type DeregisterInstancesResp struct {
+	InstanceIds []string `xml:"DeregisterInstancesFromLoadBalancerResult>Instances>member>InstanceId" json:"InstanceIds"`
+	RequestId   string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
 // Deregister N instances from a given Load Balancer.
 //
 // See http://goo.gl/Hgo4U for more details.
-func (elb *ELB) DeregisterInstancesFromLoadBalancer(instanceIds []string, lbName string) (resp *SimpleResp, err error) {
+func (elb *ELB) DeregisterInstancesFromLoadBalancer(instanceIds []string, lbName string) (resp *DeregisterInstancesResp, err error) {
+	return elb.DeregisterInstancesFromLoadBalancerWithContext(context.Background(), instanceIds, lbName)
+}
+
+// DeregisterInstancesFromLoadBalancerWithContext is like
+// DeregisterInstancesFromLoadBalancer, but the request is bound to ctx.
+func (elb *ELB) DeregisterInstancesFromLoadBalancerWithContext(ctx context.Context, instanceIds []string, lbName string) (resp *DeregisterInstancesResp, err error) {
 	// TODO: change params order and use ..., e.g (lbName string, instanceIds ...string)
-	params := map[string]string{
-		"Action":           "DeregisterInstancesFromLoadBalancer",
-		"LoadBalancerName": lbName,
-	}
-	for i, instanceId := range instanceIds {
-		key := fmt.Sprintf("Instances.member.%d.InstanceId", i+1)
-		params[key] = instanceId
-	}
-	resp = new(SimpleResp)
-	if err := elb.query(params, resp); err != nil {
+	req := instancesRequest{LoadBalancerName: lbName, Instances: instances(instanceIds)}
+	resp = new(DeregisterInstancesResp)
+	if err := elb.call(ctx, "DeregisterInstancesFromLoadBalancer", &req, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
 type DescribeLoadBalancerResp struct {
-	LoadBalancerDescriptions []LoadBalancerDescription `xml:"DescribeLoadBalancersResult>LoadBalancerDescriptions>member"`
+	LoadBalancerDescriptions []LoadBalancerDescription `xml:"DescribeLoadBalancersResult>LoadBalancerDescriptions>member" json:"LoadBalancerDescriptions"`
+	NextMarker               string                    `xml:"DescribeLoadBalancersResult>NextMarker" json:"NextMarker"`
+	RequestId                string                    `xml:"ResponseMetadata>RequestId" json:"RequestId"`
 }
 
 type LoadBalancerDescription struct {
-	AvailZones                []string                    `xml:"AvailabilityZones>member"`
-	BackendServerDescriptions []BackendServerDescriptions `xml:"BackendServerDescriptions>member"`
-	CanonicalHostedZoneName   string                      `xml:"CanonicalHostedZoneName"`
-	CanonicalHostedZoneNameId string                      `xml:"CanonicalHostedZoneNameID"`
-	CreatedTime               time.Time                   `xml:"CreatedTime"`
-	DNSName                   string                      `xml:"DNSName"`
-	HealthCheck               HealthCheck                 `xml:"HealthCheck"`
-	Instances                 []Instance                  `xml:"Instances>member"`
-	ListenerDescriptions      []ListenerDescription       `xml:"ListenerDescriptions>member"`
-	LoadBalancerName          string                      `xml:"LoadBalancerName"`
-	Policies                  Policies                    `xml:"Policies"`
-	Scheme                    string                      `xml:"Scheme"`
-	SecurityGroups            []string                    `xml:"SecurityGroups>member"` //vpc only
-	SourceSecurityGroup       SourceSecurityGroup         `xml:"SourceSecurityGroup"`
-	Subnets                   []string                    `xml:"Subnets>member"`
-	VPCId                     string                      `xml:"VPCId"`
+	AvailZones                []string                    `xml:"AvailabilityZones>member" json:"AvailabilityZones"`
+	BackendServerDescriptions []BackendServerDescriptions `xml:"BackendServerDescriptions>member" json:"BackendServerDescriptions"`
+	CanonicalHostedZoneName   string                      `xml:"CanonicalHostedZoneName" json:"CanonicalHostedZoneName"`
+	CanonicalHostedZoneNameId string                      `xml:"CanonicalHostedZoneNameID" json:"CanonicalHostedZoneNameID"`
+	CreatedTime               time.Time                   `xml:"CreatedTime" json:"CreatedTime"`
+	DNSName                   string                      `xml:"DNSName" json:"DNSName"`
+	HealthCheck               HealthCheck                 `xml:"HealthCheck" json:"HealthCheck"`
+	Instances                 []Instance                  `xml:"Instances>member" json:"Instances"`
+	ListenerDescriptions      []ListenerDescription       `xml:"ListenerDescriptions>member" json:"ListenerDescriptions"`
+	LoadBalancerName          string                      `xml:"LoadBalancerName" json:"LoadBalancerName"`
+	Policies                  Policies                    `xml:"Policies" json:"Policies"`
+	Scheme                    string                      `xml:"Scheme" json:"Scheme"`
+	SecurityGroups            []string                    `xml:"SecurityGroups>member" json:"SecurityGroups"` //vpc only
+	SourceSecurityGroup       SourceSecurityGroup         `xml:"SourceSecurityGroup" json:"SourceSecurityGroup"`
+	Subnets                   []string                    `xml:"Subnets>member" json:"Subnets"`
+	VPCId                     string                      `xml:"VPCId" json:"VPCId"`
 }
 
 // Describe Load Balancers.
@@ -154,151 +365,1163 @@ type LoadBalancerDescription struct {
 //
 // See http://goo.gl/wofJA for more details.
 func (elb *ELB) DescribeLoadBalancers(names ...string) (*DescribeLoadBalancerResp, error) {
-	params := map[string]string{"Action": "DescribeLoadBalancers"}
-	for i, name := range names {
-		index := fmt.Sprintf("LoadBalancerNames.member.%d", i+1)
-		params[index] = name
-	}
+	return elb.DescribeLoadBalancersWithContext(context.Background(), names...)
+}
+
+// DescribeLoadBalancersWithContext is like DescribeLoadBalancers, but the
+// request is bound to ctx.
+func (elb *ELB) DescribeLoadBalancersWithContext(ctx context.Context, names ...string) (*DescribeLoadBalancerResp, error) {
+	return elb.DescribeLoadBalancersPageWithContext(ctx, "", 0, names...)
+}
+
+// Describe a single page of Load Balancers, starting at marker. An empty
+// marker requests the first page, and a zero pageSize leaves the page size
+// up to the service. When there are more results, the NextMarker of the
+// response holds the marker of the next page.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DescribeLoadBalancers.html
+// for more details.
+func (elb *ELB) DescribeLoadBalancersPage(marker string, pageSize int, names ...string) (*DescribeLoadBalancerResp, error) {
+	return elb.DescribeLoadBalancersPageWithContext(context.Background(), marker, pageSize, names...)
+}
+
+// DescribeLoadBalancersPageWithContext is like DescribeLoadBalancersPage, but
+// the request is bound to ctx.
+func (elb *ELB) DescribeLoadBalancersPageWithContext(ctx context.Context, marker string, pageSize int, names ...string) (*DescribeLoadBalancerResp, error) {
+	req := struct {
+		LoadBalancerNames []string `elb:"LoadBalancerNames.member"`
+		Marker            string   `elb:",omitempty"`
+		PageSize          int      `elb:",omitempty"`
+	}{names, marker, pageSize}
 	resp := new(DescribeLoadBalancerResp)
-	if err := elb.query(params, resp); err != nil {
+	if err := elb.call(ctx, "DescribeLoadBalancers", &req, resp); err != nil {
 		return nil, err
 	}
+	for i := range resp.LoadBalancerDescriptions {
+		resp.LoadBalancerDescriptions[i].normalize()
+	}
 	return resp, nil
 }
 
+// normalize normalizes the scheme and listener protocols of d, so they can
+// be compared with the constants of this package.
+func (d *LoadBalancerDescription) normalize() {
+	d.Scheme = NormalizeScheme(d.Scheme)
+	for i := range d.ListenerDescriptions {
+		l := &d.ListenerDescriptions[i].Listener
+		l.Protocol = NormalizeProtocol(l.Protocol)
+		l.InstanceProtocol = NormalizeProtocol(l.InstanceProtocol)
+	}
+}
+
+// DescribeLoadBalancersAll describes Load Balancers like
+// DescribeLoadBalancers, following NextMarker until all the pages have
+// been retrieved.
+func (elb *ELB) DescribeLoadBalancersAll(names ...string) ([]LoadBalancerDescription, error) {
+	return elb.DescribeLoadBalancersAllWithContext(context.Background(), names...)
+}
+
+// DescribeLoadBalancersAllWithContext is like DescribeLoadBalancersAll, but the
+// request is bound to ctx.
+func (elb *ELB) DescribeLoadBalancersAllWithContext(ctx context.Context, names ...string) ([]LoadBalancerDescription, error) {
+	var descs []LoadBalancerDescription
+	marker := ""
+	for {
+		resp, err := elb.DescribeLoadBalancersPageWithContext(ctx, marker, 0, names...)
+		if err != nil {
+			return nil, err
+		}
+		descs = append(descs, resp.LoadBalancerDescriptions...)
+		if resp.NextMarker == "" {
+			return descs, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// BackendServerDescriptions holds the policies enabled for an instance
+// port of a Load Balancer, as set by SetLoadBalancerPoliciesForBackendServer.
 type BackendServerDescriptions struct {
-	InstancePort int      `xml:"InstancePort"`
-	PolicyNames  []string `xml:"PolicyNames>member"`
+	InstancePort int      `xml:"InstancePort" json:"InstancePort"`
+	PolicyNames  []string `xml:"PolicyNames>member" json:"PolicyNames"`
+}
+
+// BackendPolicyNames returns the names of the policies enabled for
+// instancePort, like the proxy protocol policy set by EnableProxyProtocol,
+// or nil if the port has none.
+func (d *LoadBalancerDescription) BackendPolicyNames(instancePort int) []string {
+	for _, b := range d.BackendServerDescriptions {
+		if b.InstancePort == instancePort {
+			return b.PolicyNames
+		}
+	}
+	return nil
 }
 
 type HealthCheck struct {
-	HealthyThreshold   int    `xml:"HealthyThreshold"`
-	Interval           int    `xml:"Interval"`
-	Target             string `xml:"Target"`
-	Timeout            int    `xml:"Timeout"`
-	UnhealthyThreshold int    `xml:"UnhealthyThreshold"`
+	HealthyThreshold int `xml:"HealthyThreshold" json:"HealthyThreshold"`
+	Interval         int `xml:"Interval" json:"Interval"`
+
+	// Target is the protocol, port and, for HTTP and HTTPS, the path
+	// checked, like HTTP:80/ping. It can be built with HTTPTarget or
+	// TCPTarget, and parsed with ParseTarget.
+	Target             string `xml:"Target" json:"Target"`
+	Timeout            int    `xml:"Timeout" json:"Timeout"`
+	UnhealthyThreshold int    `xml:"UnhealthyThreshold" json:"UnhealthyThreshold"`
 }
 
 type Instance struct {
-	InstanceId string `xml:"InstanceId"`
+	InstanceId string `xml:"InstanceId" json:"InstanceId"`
 }
 
 type ListenerDescription struct {
-	Listener    Listener `xml:"Listener"`
-	PolicyNames []string `xml:"PolicyNames>member"`
+	Listener    Listener `xml:"Listener" json:"Listener"`
+	PolicyNames []string `xml:"PolicyNames>member" json:"PolicyNames"`
 }
 
 type Policies struct {
-	AppCookieStickinessPolicies []AppCookieStickinessPolicies `xml:"AppCookieStickinessPolicies>member"`
-	LBCookieStickinessPolicies  []LBCookieStickinessPolicies  `xml:"LBCookieStickinessPolicies>member"`
-	OtherPolicies               []string                      `xml:"OtherPolicies>member"`
+	AppCookieStickinessPolicies []AppCookieStickinessPolicies `xml:"AppCookieStickinessPolicies>member" json:"AppCookieStickinessPolicies"`
+	LBCookieStickinessPolicies  []LBCookieStickinessPolicies  `xml:"LBCookieStickinessPolicies>member" json:"LBCookieStickinessPolicies"`
+	OtherPolicies               []string                      `xml:"OtherPolicies>member" json:"OtherPolicies"`
 }
 
 // see http://goo.gl/clXGV for more information.
 type AppCookieStickinessPolicies struct {
-	CookieName string `xml:"CookieName"`
-	PolicyName string `xml:"PolicyName"`
+	CookieName string `xml:"CookieName" json:"CookieName"`
+	PolicyName string `xml:"PolicyName" json:"PolicyName"`
 }
 
 type LBCookieStickinessPolicies struct {
-	CookieExpirationPeriod int    `xml:"CookieExpirationPeriod"`
-	PolicyName             string `xml:"PolicyName"`
+	CookieExpirationPeriod int    `xml:"CookieExpirationPeriod" json:"CookieExpirationPeriod"`
+	PolicyName             string `xml:"PolicyName" json:"PolicyName"`
 }
 
 type SourceSecurityGroup struct {
-	GroupName  string `xml:"GroupName"`
-	OwnerAlias string `xml:"OwnerAlias"`
+	GroupName  string `xml:"GroupName" json:"GroupName"`
+	OwnerAlias string `xml:"OwnerAlias" json:"OwnerAlias"`
 }
 
 // Represents a XML response for DescribeInstanceHealth action
 //
 // See http://goo.gl/ovIB1 for more information.
 type DescribeInstanceHealthResp struct {
-	InstanceStates []InstanceState `xml:"DescribeInstanceHealthResult>InstanceStates>member"`
+	InstanceStates []InstanceState `xml:"DescribeInstanceHealthResult>InstanceStates>member" json:"InstanceStates"`
+	RequestId      string          `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// HealthState is the health of an instance as seen by a Load Balancer.
+type HealthState string
+
+const (
+	StateInService    HealthState = "InService"
+	StateOutOfService HealthState = "OutOfService"
+	StateUnknown      HealthState = "Unknown"
+)
+
+const (
+	// Deprecated: use StateInService.
+	InService = StateInService
+	// Deprecated: use StateOutOfService.
+	OutOfService = StateOutOfService
+	// Deprecated: use StateUnknown.
+	Unknown = StateUnknown
+)
+
+// NormalizeHealthState returns the canonical spelling of state, like
+// StateInService for "inservice", matching it case-insensitively. Unknown
+// states are returned as is.
+func NormalizeHealthState(state string) HealthState {
+	for _, s := range []HealthState{StateInService, StateOutOfService, StateUnknown} {
+		if strings.EqualFold(state, string(s)) {
+			return s
+		}
+	}
+	return HealthState(state)
 }
 
+// UnmarshalText decodes a state normalized by NormalizeHealthState.
+func (s *HealthState) UnmarshalText(text []byte) error {
+	*s = NormalizeHealthState(string(text))
+	return nil
+}
+
+// Reason codes of the health of instances, telling whether an
+// OutOfService instance is failing because of the Load Balancer, like when
+// its registration is still in progress, or because of the instance
+// itself, like when it fails the health checks.
+const (
+	ReasonCodeELB      = "ELB"
+	ReasonCodeInstance = "Instance"
+	ReasonCodeNone     = "N/A"
+)
+
 // See http://goo.gl/dzWfP for more information.
 type InstanceState struct {
-	Description string `xml:"Description"`
-	InstanceId  string `xml:"InstanceId"`
-	ReasonCode  string `xml:"ReasonCode"`
-	State       string `xml:"State"`
+	Description string      `xml:"Description" json:"Description"`
+	InstanceId  string      `xml:"InstanceId" json:"InstanceId"`
+	ReasonCode  string      `xml:"ReasonCode" json:"ReasonCode"`
+	State       HealthState `xml:"State" json:"State"`
+}
+
+// IsHealthy reports whether the Load Balancer routes requests to the
+// instance.
+func (s InstanceState) IsHealthy() bool {
+	return s.State == StateInService
 }
 
 // Describe instance health.
 //
 // See http://goo.gl/ovIB1 for more information.
 func (elb *ELB) DescribeInstanceHealth(lbName string, instanceIds ...string) (*DescribeInstanceHealthResp, error) {
-	params := map[string]string{
-		"Action":           "DescribeInstanceHealth",
-		"LoadBalancerName": lbName,
-	}
-	for _, iId := range instanceIds {
-		key := fmt.Sprintf("Instances.member.1.InstanceId")
-		params[key] = iId
+	return elb.DescribeInstanceHealthWithContext(context.Background(), lbName, instanceIds...)
+}
+
+// DescribeInstanceHealthWithContext is like DescribeInstanceHealth, but the
+// request is bound to ctx.
+func (elb *ELB) DescribeInstanceHealthWithContext(ctx context.Context, lbName string, instanceIds ...string) (*DescribeInstanceHealthResp, error) {
+	return elb.DescribeInstanceHealthFilteredWithContext(ctx, lbName, &InstanceHealthFilter{InstanceIds: instanceIds})
+}
+
+// InstanceHealthFilter selects the instances described by
+// DescribeInstanceHealthFiltered.
+type InstanceHealthFilter struct {
+	// InstanceIds limits the description to the given instances. All the
+	// instances registered with the Load Balancer are described when it's
+	// empty.
+	InstanceIds []string
+	// States keeps only the instances in one of the given states. ELB
+	// doesn't support this filter, so it's applied to the response.
+	States []HealthState
+}
+
+// Describe the health of the instances of a Load Balancer selected by
+// filter, which may be nil.
+//
+// See http://goo.gl/ovIB1 for more information.
+func (elb *ELB) DescribeInstanceHealthFiltered(lbName string, filter *InstanceHealthFilter) (*DescribeInstanceHealthResp, error) {
+	return elb.DescribeInstanceHealthFilteredWithContext(context.Background(), lbName, filter)
+}
+
+// DescribeInstanceHealthFilteredWithContext is like
+// DescribeInstanceHealthFiltered, but the request is bound to ctx.
+func (elb *ELB) DescribeInstanceHealthFilteredWithContext(ctx context.Context, lbName string, filter *InstanceHealthFilter) (*DescribeInstanceHealthResp, error) {
+	if filter == nil {
+		filter = &InstanceHealthFilter{}
 	}
+	req := instancesRequest{LoadBalancerName: lbName, Instances: instances(filter.InstanceIds)}
 	resp := new(DescribeInstanceHealthResp)
-	if err := elb.query(params, resp); err != nil {
+	if err := elb.call(ctx, "DescribeInstanceHealth", &req, resp); err != nil {
 		return nil, err
 	}
+	if len(filter.States) > 0 {
+		var states []InstanceState
+		for _, s := range resp.InstanceStates {
+			for _, want := range filter.States {
+				if s.State == want {
+					states = append(states, s)
+					break
+				}
+			}
+		}
+		resp.InstanceStates = states
+	}
 	return resp, nil
 }
 
 type HealthCheckResp struct {
-	HealthCheck *HealthCheck `xml:"ConfigureHealthCheckResult>HealthCheck"`
+	HealthCheck *HealthCheck `xml:"ConfigureHealthCheckResult>HealthCheck" json:"HealthCheck,omitempty"`
+	RequestId   string       `xml:"ResponseMetadata>RequestId" json:"RequestId"`
 }
 
 // Configure health check for a LB
 //
 // See http://goo.gl/2HE6a for more information
 func (elb *ELB) ConfigureHealthCheck(lbName string, healthCheck *HealthCheck) (*HealthCheckResp, error) {
-	params := map[string]string{
-		"Action":                         "ConfigureHealthCheck",
-		"LoadBalancerName":               lbName,
-		"HealthCheck.HealthyThreshold":   strconv.Itoa(healthCheck.HealthyThreshold),
-		"HealthCheck.Interval":           strconv.Itoa(healthCheck.Interval),
-		"HealthCheck.Target":             healthCheck.Target,
-		"HealthCheck.Timeout":            strconv.Itoa(healthCheck.Timeout),
-		"HealthCheck.UnhealthyThreshold": strconv.Itoa(healthCheck.UnhealthyThreshold),
-	}
+	return elb.ConfigureHealthCheckWithContext(context.Background(), lbName, healthCheck)
+}
+
+// ConfigureHealthCheckWithContext is like ConfigureHealthCheck, but the request
+// is bound to ctx.
+func (elb *ELB) ConfigureHealthCheckWithContext(ctx context.Context, lbName string, healthCheck *HealthCheck) (*HealthCheckResp, error) {
+	req := struct {
+		LoadBalancerName string
+		HealthCheck      *HealthCheck
+	}{lbName, healthCheck}
 	resp := new(HealthCheckResp)
-	if err := elb.query(params, resp); err != nil {
+	if err := elb.call(ctx, "ConfigureHealthCheck", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// LoadBalancerAttributes holds the optional attributes of a Load Balancer.
+//
+// When modifying attributes, only the non-nil fields are sent to AWS, so
+// the remaining attributes are left untouched.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_LoadBalancerAttributes.html
+// for more details.
+type LoadBalancerAttributes struct {
+	CrossZoneLoadBalancing *CrossZoneLoadBalancing `xml:"CrossZoneLoadBalancing" json:"CrossZoneLoadBalancing,omitempty"`
+	AccessLog              *AccessLog              `xml:"AccessLog" json:"AccessLog,omitempty"`
+	ConnectionDraining     *ConnectionDraining     `xml:"ConnectionDraining" json:"ConnectionDraining,omitempty"`
+	ConnectionSettings     *ConnectionSettings     `xml:"ConnectionSettings" json:"ConnectionSettings,omitempty"`
+}
+
+type CrossZoneLoadBalancing struct {
+	Enabled bool `xml:"Enabled" json:"Enabled"`
+}
+
+type AccessLog struct {
+	Enabled        bool   `xml:"Enabled" json:"Enabled"`
+	S3BucketName   string `xml:"S3BucketName" elb:",omitempty" json:"S3BucketName,omitempty"`
+	S3BucketPrefix string `xml:"S3BucketPrefix" elb:",omitempty" json:"S3BucketPrefix,omitempty"`
+	EmitInterval   int    `xml:"EmitInterval" elb:",omitempty" json:"EmitInterval,omitempty"`
+}
+
+type ConnectionDraining struct {
+	Enabled bool `xml:"Enabled" json:"Enabled"`
+	Timeout int  `xml:"Timeout" elb:",omitempty" json:"Timeout,omitempty"`
+}
+
+type ConnectionSettings struct {
+	IdleTimeout int `xml:"IdleTimeout" json:"IdleTimeout"`
+}
+
+type DescribeLoadBalancerAttributesResp struct {
+	LoadBalancerAttributes LoadBalancerAttributes `xml:"DescribeLoadBalancerAttributesResult>LoadBalancerAttributes" json:"LoadBalancerAttributes"`
+	RequestId              string                 `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Describe the attributes of a Load Balancer.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DescribeLoadBalancerAttributes.html
+// for more details.
+func (elb *ELB) DescribeLoadBalancerAttributes(lbName string) (*DescribeLoadBalancerAttributesResp, error) {
+	return elb.DescribeLoadBalancerAttributesWithContext(context.Background(), lbName)
+}
+
+// DescribeLoadBalancerAttributesWithContext is like
+// DescribeLoadBalancerAttributes, but the request is bound to ctx.
+func (elb *ELB) DescribeLoadBalancerAttributesWithContext(ctx context.Context, lbName string) (*DescribeLoadBalancerAttributesResp, error) {
+	req := struct{ LoadBalancerName string }{lbName}
+	resp := new(DescribeLoadBalancerAttributesResp)
+	if err := elb.call(ctx, "DescribeLoadBalancerAttributes", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type ModifyLoadBalancerAttributesResp struct {
+	LoadBalancerName       string                 `xml:"ModifyLoadBalancerAttributesResult>LoadBalancerName" json:"LoadBalancerName"`
+	LoadBalancerAttributes LoadBalancerAttributes `xml:"ModifyLoadBalancerAttributesResult>LoadBalancerAttributes" json:"LoadBalancerAttributes"`
+	RequestId              string                 `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Modify the attributes of a Load Balancer.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_ModifyLoadBalancerAttributes.html
+// for more details.
+func (elb *ELB) ModifyLoadBalancerAttributes(lbName string, attrs *LoadBalancerAttributes) (*ModifyLoadBalancerAttributesResp, error) {
+	return elb.ModifyLoadBalancerAttributesWithContext(context.Background(), lbName, attrs)
+}
+
+// ModifyLoadBalancerAttributesWithContext is like ModifyLoadBalancerAttributes,
+// but the request is bound to ctx.
+func (elb *ELB) ModifyLoadBalancerAttributesWithContext(ctx context.Context, lbName string, attrs *LoadBalancerAttributes) (*ModifyLoadBalancerAttributesResp, error) {
+	req := struct {
+		LoadBalancerName       string
+		LoadBalancerAttributes *LoadBalancerAttributes
+	}{lbName, attrs}
+	resp := new(ModifyLoadBalancerAttributesResp)
+	if err := elb.call(ctx, "ModifyLoadBalancerAttributes", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create listeners on an existing Load Balancer.
+//
+// AWS answers with a DuplicateListener error when a listener already uses
+// one of the given Load Balancer ports with a different configuration.
+// Invalid listeners are reported with a *ListenerError before sending the
+// request, see Listener.Validate.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_CreateLoadBalancerListeners.html
+// for more details.
+func (elb *ELB) CreateLoadBalancerListeners(lbName string, listeners []Listener) (*SimpleResp, error) {
+	return elb.CreateLoadBalancerListenersWithContext(context.Background(), lbName, listeners)
+}
+
+// CreateLoadBalancerListenersWithContext is like CreateLoadBalancerListeners,
+// but the request is bound to ctx.
+func (elb *ELB) CreateLoadBalancerListenersWithContext(ctx context.Context, lbName string, listeners []Listener) (*SimpleResp, error) {
+	listeners = normalizeListeners(listeners)
+	if err := elb.validateListeners(listeners); err != nil {
+		return nil, err
+	}
+	req := struct {
+		LoadBalancerName string
+		Listeners        []Listener `elb:"Listeners.member"`
+	}{lbName, listeners}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "CreateLoadBalancerListeners", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Delete the listeners bound to the given ports of a Load Balancer.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DeleteLoadBalancerListeners.html
+// for more details.
+func (elb *ELB) DeleteLoadBalancerListeners(lbName string, ports ...int) (*SimpleResp, error) {
+	return elb.DeleteLoadBalancerListenersWithContext(context.Background(), lbName, ports...)
+}
+
+// DeleteLoadBalancerListenersWithContext is like DeleteLoadBalancerListeners,
+// but the request is bound to ctx.
+func (elb *ELB) DeleteLoadBalancerListenersWithContext(ctx context.Context, lbName string, ports ...int) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerName  string
+		LoadBalancerPorts []int `elb:"LoadBalancerPorts.member"`
+	}{lbName, ports}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "DeleteLoadBalancerListeners", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Replace the SSL certificate of the HTTPS or SSL listener bound to the
+// given port of a Load Balancer. A certificate ARN is validated like the
+// ones given to CreateLoadBalancerListeners, and a *ListenerError is
+// returned without sending the request if it's invalid.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_SetLoadBalancerListenerSSLCertificate.html
+// for more details.
+func (elb *ELB) SetLoadBalancerListenerSSLCertificate(lbName string, port int, certId string) (*SimpleResp, error) {
+	return elb.SetLoadBalancerListenerSSLCertificateWithContext(context.Background(), lbName, port, certId)
+}
+
+// SetLoadBalancerListenerSSLCertificateWithContext is like
+// SetLoadBalancerListenerSSLCertificate, but the request is bound to ctx.
+func (elb *ELB) SetLoadBalancerListenerSSLCertificateWithContext(ctx context.Context, lbName string, port int, certId string) (*SimpleResp, error) {
+	if err := elb.validateCertificate(port, certId); err != nil {
+		return nil, err
+	}
+	req := struct {
+		LoadBalancerName string
+		LoadBalancerPort int
+		SSLCertificateId string
+	}{lbName, port, certId}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "SetLoadBalancerListenerSSLCertificate", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type ApplySecurityGroupsResp struct {
+	SecurityGroups []string `xml:"ApplySecurityGroupsToLoadBalancerResult>SecurityGroups>member" json:"SecurityGroups"`
+	RequestId      string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Associate security groups with a Load Balancer in a VPC, replacing the
+// ones currently associated with it. The response carries the security
+// groups now associated with the Load Balancer.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_ApplySecurityGroupsToLoadBalancer.html
+// for more details.
+func (elb *ELB) ApplySecurityGroups(lbName string, groups []string) (*ApplySecurityGroupsResp, error) {
+	return elb.ApplySecurityGroupsWithContext(context.Background(), lbName, groups)
+}
+
+// ApplySecurityGroupsWithContext is like ApplySecurityGroups, but the request
+// is bound to ctx.
+func (elb *ELB) ApplySecurityGroupsWithContext(ctx context.Context, lbName string, groups []string) (*ApplySecurityGroupsResp, error) {
+	req := struct {
+		LoadBalancerName string
+		SecurityGroups   []string `elb:"SecurityGroups.member"`
+	}{lbName, groups}
+	resp := new(ApplySecurityGroupsResp)
+	if err := elb.call(ctx, "ApplySecurityGroupsToLoadBalancer", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type AttachLoadBalancerToSubnetsResp struct {
+	Subnets   []string `xml:"AttachLoadBalancerToSubnetsResult>Subnets>member" json:"Subnets"`
+	RequestId string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Attach a Load Balancer in a VPC to the given subnets. The response
+// carries all the subnets the Load Balancer is now attached to.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_AttachLoadBalancerToSubnets.html
+// for more details.
+func (elb *ELB) AttachLoadBalancerToSubnets(lbName string, subnets []string) (*AttachLoadBalancerToSubnetsResp, error) {
+	return elb.AttachLoadBalancerToSubnetsWithContext(context.Background(), lbName, subnets)
+}
+
+// AttachLoadBalancerToSubnetsWithContext is like AttachLoadBalancerToSubnets,
+// but the request is bound to ctx.
+func (elb *ELB) AttachLoadBalancerToSubnetsWithContext(ctx context.Context, lbName string, subnets []string) (*AttachLoadBalancerToSubnetsResp, error) {
+	req := subnetsRequest{LoadBalancerName: lbName, Subnets: subnets}
+	resp := new(AttachLoadBalancerToSubnetsResp)
+	if err := elb.call(ctx, "AttachLoadBalancerToSubnets", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DetachLoadBalancerFromSubnetsResp struct {
+	Subnets   []string `xml:"DetachLoadBalancerFromSubnetsResult>Subnets>member" json:"Subnets"`
+	RequestId string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Detach a Load Balancer in a VPC from the given subnets. The response
+// carries the subnets the Load Balancer remains attached to.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DetachLoadBalancerFromSubnets.html
+// for more details.
+func (elb *ELB) DetachLoadBalancerFromSubnets(lbName string, subnets []string) (*DetachLoadBalancerFromSubnetsResp, error) {
+	return elb.DetachLoadBalancerFromSubnetsWithContext(context.Background(), lbName, subnets)
+}
+
+// DetachLoadBalancerFromSubnetsWithContext is like
+// DetachLoadBalancerFromSubnets, but the request is bound to ctx.
+func (elb *ELB) DetachLoadBalancerFromSubnetsWithContext(ctx context.Context, lbName string, subnets []string) (*DetachLoadBalancerFromSubnetsResp, error) {
+	req := subnetsRequest{LoadBalancerName: lbName, Subnets: subnets}
+	resp := new(DetachLoadBalancerFromSubnetsResp)
+	if err := elb.call(ctx, "DetachLoadBalancerFromSubnets", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type EnableAvailabilityZonesResp struct {
+	AvailZones []string `xml:"EnableAvailabilityZonesForLoadBalancerResult>AvailabilityZones>member" json:"AvailabilityZones"`
+	RequestId  string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Add availability zones to a Load Balancer outside of a VPC. The response
+// carries all the zones the Load Balancer now spans.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_EnableAvailabilityZonesForLoadBalancer.html
+// for more details.
+func (elb *ELB) EnableAvailabilityZonesForLoadBalancer(lbName string, zones []string) (*EnableAvailabilityZonesResp, error) {
+	return elb.EnableAvailabilityZonesForLoadBalancerWithContext(context.Background(), lbName, zones)
+}
+
+// EnableAvailabilityZonesForLoadBalancerWithContext is like
+// EnableAvailabilityZonesForLoadBalancer, but the request is bound to ctx.
+func (elb *ELB) EnableAvailabilityZonesForLoadBalancerWithContext(ctx context.Context, lbName string, zones []string) (*EnableAvailabilityZonesResp, error) {
+	req := zonesRequest{LoadBalancerName: lbName, AvailabilityZones: zones}
+	resp := new(EnableAvailabilityZonesResp)
+	if err := elb.call(ctx, "EnableAvailabilityZonesForLoadBalancer", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DisableAvailabilityZonesResp struct {
+	AvailZones []string `xml:"DisableAvailabilityZonesForLoadBalancerResult>AvailabilityZones>member" json:"AvailabilityZones"`
+	RequestId  string   `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+// Remove availability zones from a Load Balancer outside of a VPC. The
+// response carries the zones the Load Balancer still spans.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DisableAvailabilityZonesForLoadBalancer.html
+// for more details.
+func (elb *ELB) DisableAvailabilityZonesForLoadBalancer(lbName string, zones []string) (*DisableAvailabilityZonesResp, error) {
+	return elb.DisableAvailabilityZonesForLoadBalancerWithContext(context.Background(), lbName, zones)
+}
+
+// DisableAvailabilityZonesForLoadBalancerWithContext is like
+// DisableAvailabilityZonesForLoadBalancer, but the request is bound to ctx.
+func (elb *ELB) DisableAvailabilityZonesForLoadBalancerWithContext(ctx context.Context, lbName string, zones []string) (*DisableAvailabilityZonesResp, error) {
+	req := zonesRequest{LoadBalancerName: lbName, AvailabilityZones: zones}
+	resp := new(DisableAvailabilityZonesResp)
+	if err := elb.call(ctx, "DisableAvailabilityZonesForLoadBalancer", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create a stickiness policy whose sessions follow the lifetime of the
+// browser (user-agent) or, when expiration is positive, last for the given
+// number of seconds.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_CreateLBCookieStickinessPolicy.html
+// for more details.
+func (elb *ELB) CreateLBCookieStickinessPolicy(lbName, policyName string, expiration int64) (*SimpleResp, error) {
+	return elb.CreateLBCookieStickinessPolicyWithContext(context.Background(), lbName, policyName, expiration)
+}
+
+// CreateLBCookieStickinessPolicyWithContext is like
+// CreateLBCookieStickinessPolicy, but the request is bound to ctx.
+func (elb *ELB) CreateLBCookieStickinessPolicyWithContext(ctx context.Context, lbName, policyName string, expiration int64) (*SimpleResp, error) {
+	if expiration < 0 {
+		expiration = 0
+	}
+	req := struct {
+		LoadBalancerName       string
+		PolicyName             string
+		CookieExpirationPeriod int64 `elb:",omitempty"`
+	}{lbName, policyName, expiration}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "CreateLBCookieStickinessPolicy", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Create a stickiness policy whose sessions follow the lifetime of the
+// given application-generated cookie.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_CreateAppCookieStickinessPolicy.html
+// for more details.
+func (elb *ELB) CreateAppCookieStickinessPolicy(lbName, policyName, cookieName string) (*SimpleResp, error) {
+	return elb.CreateAppCookieStickinessPolicyWithContext(context.Background(), lbName, policyName, cookieName)
+}
+
+// CreateAppCookieStickinessPolicyWithContext is like
+// CreateAppCookieStickinessPolicy, but the request is bound to ctx.
+func (elb *ELB) CreateAppCookieStickinessPolicyWithContext(ctx context.Context, lbName, policyName, cookieName string) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerName string
+		PolicyName       string
+		CookieName       string
+	}{lbName, policyName, cookieName}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "CreateAppCookieStickinessPolicy", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Delete a policy from a Load Balancer. The policy must not be enabled
+// for any listener.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DeleteLoadBalancerPolicy.html
+// for more details.
+func (elb *ELB) DeleteLoadBalancerPolicy(lbName, policyName string) (*SimpleResp, error) {
+	return elb.DeleteLoadBalancerPolicyWithContext(context.Background(), lbName, policyName)
+}
+
+// DeleteLoadBalancerPolicyWithContext is like DeleteLoadBalancerPolicy, but the
+// request is bound to ctx.
+func (elb *ELB) DeleteLoadBalancerPolicyWithContext(ctx context.Context, lbName, policyName string) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerName string
+		PolicyName       string
+	}{lbName, policyName}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "DeleteLoadBalancerPolicy", &req, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (elb *ELB) query(params map[string]string, resp interface{}) error {
-	params["Version"] = "2012-06-01"
+// Replace the policies of the listener bound to the given port of a Load
+// Balancer. An empty list of policy names disables all the policies of
+// the listener.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_SetLoadBalancerPoliciesOfListener.html
+// for more details.
+func (elb *ELB) SetLoadBalancerPoliciesOfListener(lbName string, port int, policyNames []string) (*SimpleResp, error) {
+	return elb.SetLoadBalancerPoliciesOfListenerWithContext(context.Background(), lbName, port, policyNames)
+}
+
+// SetLoadBalancerPoliciesOfListenerWithContext is like
+// SetLoadBalancerPoliciesOfListener, but the request is bound to ctx.
+func (elb *ELB) SetLoadBalancerPoliciesOfListenerWithContext(ctx context.Context, lbName string, port int, policyNames []string) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerName string
+		LoadBalancerPort int
+		PolicyNames      []string `elb:"PolicyNames.member,keepempty"`
+	}{lbName, port, policyNames}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "SetLoadBalancerPoliciesOfListener", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PolicyAttribute is a name/value pair used to configure a policy.
+type PolicyAttribute struct {
+	AttributeName  string `xml:"AttributeName" json:"AttributeName"`
+	AttributeValue string `xml:"AttributeValue" json:"AttributeValue"`
+}
+
+// Create a policy of the given type, configured by attrs. The available
+// policy types may be listed with DescribeLoadBalancerPolicyTypes.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_CreateLoadBalancerPolicy.html
+// for more details.
+func (elb *ELB) CreateLoadBalancerPolicy(lbName, policyName, policyTypeName string, attrs []PolicyAttribute) (*SimpleResp, error) {
+	return elb.CreateLoadBalancerPolicyWithContext(context.Background(), lbName, policyName, policyTypeName, attrs)
+}
+
+// CreateLoadBalancerPolicyWithContext is like CreateLoadBalancerPolicy, but the
+// request is bound to ctx.
+func (elb *ELB) CreateLoadBalancerPolicyWithContext(ctx context.Context, lbName, policyName, policyTypeName string, attrs []PolicyAttribute) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerName string
+		PolicyName       string
+		PolicyTypeName   string
+		PolicyAttributes []PolicyAttribute `elb:"PolicyAttributes.member"`
+	}{lbName, policyName, policyTypeName, attrs}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "CreateLoadBalancerPolicy", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DescribeLoadBalancerPoliciesResp struct {
+	PolicyDescriptions []PolicyDescription `xml:"DescribeLoadBalancerPoliciesResult>PolicyDescriptions>member" json:"PolicyDescriptions"`
+	RequestId          string              `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+type PolicyDescription struct {
+	PolicyName                  string                       `xml:"PolicyName" json:"PolicyName"`
+	PolicyTypeName              string                       `xml:"PolicyTypeName" json:"PolicyTypeName"`
+	PolicyAttributeDescriptions []PolicyAttributeDescription `xml:"PolicyAttributeDescriptions>member" json:"PolicyAttributeDescriptions"`
+}
+
+type PolicyAttributeDescription struct {
+	AttributeName  string `xml:"AttributeName" json:"AttributeName"`
+	AttributeValue string `xml:"AttributeValue" json:"AttributeValue"`
+}
+
+// Describe the policies of a Load Balancer. When lbName is empty, the
+// sample policies predefined by AWS are described instead.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DescribeLoadBalancerPolicies.html
+// for more details.
+func (elb *ELB) DescribeLoadBalancerPolicies(lbName string, policyNames ...string) (*DescribeLoadBalancerPoliciesResp, error) {
+	return elb.DescribeLoadBalancerPoliciesWithContext(context.Background(), lbName, policyNames...)
+}
+
+// DescribeLoadBalancerPoliciesWithContext is like DescribeLoadBalancerPolicies,
+// but the request is bound to ctx.
+func (elb *ELB) DescribeLoadBalancerPoliciesWithContext(ctx context.Context, lbName string, policyNames ...string) (*DescribeLoadBalancerPoliciesResp, error) {
+	req := struct {
+		LoadBalancerName string   `elb:",omitempty"`
+		PolicyNames      []string `elb:"PolicyNames.member"`
+	}{lbName, policyNames}
+	resp := new(DescribeLoadBalancerPoliciesResp)
+	if err := elb.call(ctx, "DescribeLoadBalancerPolicies", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DescribeLoadBalancerPolicyTypesResp struct {
+	PolicyTypeDescriptions []PolicyTypeDescription `xml:"DescribeLoadBalancerPolicyTypesResult>PolicyTypeDescriptions>member" json:"PolicyTypeDescriptions"`
+	RequestId              string                  `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+type PolicyTypeDescription struct {
+	PolicyTypeName                  string                           `xml:"PolicyTypeName" json:"PolicyTypeName"`
+	Description                     string                           `xml:"Description" json:"Description"`
+	PolicyAttributeTypeDescriptions []PolicyAttributeTypeDescription `xml:"PolicyAttributeTypeDescriptions>member" json:"PolicyAttributeTypeDescriptions"`
+}
+
+type PolicyAttributeTypeDescription struct {
+	AttributeName string `xml:"AttributeName" json:"AttributeName"`
+	AttributeType string `xml:"AttributeType" json:"AttributeType"`
+	Cardinality   string `xml:"Cardinality" json:"Cardinality"`
+	DefaultValue  string `xml:"DefaultValue" json:"DefaultValue"`
+	Description   string `xml:"Description" json:"Description"`
+}
+
+// Describe the policy types that can be used to create policies. All the
+// types are described when no names are given.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DescribeLoadBalancerPolicyTypes.html
+// for more details.
+func (elb *ELB) DescribeLoadBalancerPolicyTypes(typeNames ...string) (*DescribeLoadBalancerPolicyTypesResp, error) {
+	return elb.DescribeLoadBalancerPolicyTypesWithContext(context.Background(), typeNames...)
+}
+
+// DescribeLoadBalancerPolicyTypesWithContext is like
+// DescribeLoadBalancerPolicyTypes, but the request is bound to ctx.
+func (elb *ELB) DescribeLoadBalancerPolicyTypesWithContext(ctx context.Context, typeNames ...string) (*DescribeLoadBalancerPolicyTypesResp, error) {
+	req := struct {
+		PolicyTypeNames []string `elb:"PolicyTypeNames.member"`
+	}{typeNames}
+	resp := new(DescribeLoadBalancerPolicyTypesResp)
+	if err := elb.call(ctx, "DescribeLoadBalancerPolicyTypes", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Replace the policies applied to connections between a Load Balancer and
+// its instances on the given port. An empty list of policy names removes
+// all the policies from the port.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_SetLoadBalancerPoliciesForBackendServer.html
+// for more details.
+func (elb *ELB) SetLoadBalancerPoliciesForBackendServer(lbName string, instancePort int, policyNames []string) (*SimpleResp, error) {
+	return elb.SetLoadBalancerPoliciesForBackendServerWithContext(context.Background(), lbName, instancePort, policyNames)
+}
+
+// SetLoadBalancerPoliciesForBackendServerWithContext is like
+// SetLoadBalancerPoliciesForBackendServer, but the request is bound to ctx.
+func (elb *ELB) SetLoadBalancerPoliciesForBackendServerWithContext(ctx context.Context, lbName string, instancePort int, policyNames []string) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerName string
+		InstancePort     int
+		PolicyNames      []string `elb:"PolicyNames.member,keepempty"`
+	}{lbName, instancePort, policyNames}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "SetLoadBalancerPoliciesForBackendServer", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Tag is a key/value pair attached to a Load Balancer.
+type Tag struct {
+	Key   string `xml:"Key" json:"Key"`
+	Value string `xml:"Value" json:"Value"`
+}
+
+// Add tags to the given Load Balancers. Tags whose keys are already
+// associated with a Load Balancer have their values updated.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_AddTags.html
+// for more details.
+func (elb *ELB) AddTags(lbNames []string, tags []Tag) (*SimpleResp, error) {
+	return elb.AddTagsWithContext(context.Background(), lbNames, tags)
+}
+
+// AddTagsWithContext is like AddTags, but the request is bound to ctx.
+func (elb *ELB) AddTagsWithContext(ctx context.Context, lbNames []string, tags []Tag) (*SimpleResp, error) {
+	req := struct {
+		LoadBalancerNames []string `elb:"LoadBalancerNames.member"`
+		Tags              []Tag    `elb:"Tags.member"`
+	}{lbNames, tags}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "AddTags", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Remove the tags with the given keys from the given Load Balancers.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_RemoveTags.html
+// for more details.
+func (elb *ELB) RemoveTags(lbNames []string, keys []string) (*SimpleResp, error) {
+	return elb.RemoveTagsWithContext(context.Background(), lbNames, keys)
+}
+
+// RemoveTagsWithContext is like RemoveTags, but the request is bound to ctx.
+func (elb *ELB) RemoveTagsWithContext(ctx context.Context, lbNames []string, keys []string) (*SimpleResp, error) {
+	type tagKey struct{ Key string }
+	req := struct {
+		LoadBalancerNames []string `elb:"LoadBalancerNames.member"`
+		Tags              []tagKey `elb:"Tags.member"`
+	}{LoadBalancerNames: lbNames}
+	for _, k := range keys {
+		req.Tags = append(req.Tags, tagKey{k})
+	}
+	resp := new(SimpleResp)
+	if err := elb.call(ctx, "RemoveTags", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DescribeTagsResp struct {
+	TagDescriptions []TagDescription `xml:"DescribeTagsResult>TagDescriptions>member" json:"TagDescriptions"`
+	RequestId       string           `xml:"ResponseMetadata>RequestId" json:"RequestId"`
+}
+
+type TagDescription struct {
+	LoadBalancerName string `xml:"LoadBalancerName" json:"LoadBalancerName"`
+	Tags             []Tag  `xml:"Tags>member" json:"Tags"`
+}
+
+// Describe the tags associated with the given Load Balancers.
+//
+// See http://docs.aws.amazon.com/ElasticLoadBalancing/latest/APIReference/API_DescribeTags.html
+// for more details.
+func (elb *ELB) DescribeTags(lbNames ...string) (*DescribeTagsResp, error) {
+	return elb.DescribeTagsWithContext(context.Background(), lbNames...)
+}
+
+// DescribeTagsWithContext is like DescribeTags, but the request is bound to
+// ctx.
+func (elb *ELB) DescribeTagsWithContext(ctx context.Context, lbNames ...string) (*DescribeTagsResp, error) {
+	req := struct {
+		LoadBalancerNames []string `elb:"LoadBalancerNames.member"`
+	}{lbNames}
+	resp := new(DescribeTagsResp)
+	if err := elb.call(ctx, "DescribeTags", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// instancesRequest holds the parameters of the operations acting on some
+// instances of a Load Balancer.
+type instancesRequest struct {
+	LoadBalancerName string
+	Instances        []Instance `elb:"Instances.member"`
+}
+
+func instances(ids []string) []Instance {
+	instances := make([]Instance, len(ids))
+	for i, id := range ids {
+		instances[i] = Instance{InstanceId: id}
+	}
+	return instances
+}
+
+type subnetsRequest struct {
+	LoadBalancerName string
+	Subnets          []string `elb:"Subnets.member"`
+}
+
+type zonesRequest struct {
+	LoadBalancerName  string
+	AvailabilityZones []string `elb:"AvailabilityZones.member"`
+}
+
+// auth returns the credentials used to sign the next request.
+func (elb *ELB) auth() (aws.Auth, error) {
+	if elb.credentials == nil {
+		return elb.Auth, nil
+	}
+	return elb.credentials.Credentials()
+}
+
+func (elb *ELB) query(ctx context.Context, params map[string]string, resp interface{}) error {
+	return elb.cachedQuery(params, resp, func() error {
+		return elb.Query(ctx, "2012-06-01", params, resp)
+	})
+}
+
+// Query sends a request for the given version of the Elastic Load
+// Balancing API and decodes the response into resp. The request is signed
+// and retried, and its rate limited, according to the client
+// configuration. Retries are reported to the ThrottleObserver of the
+// client, if any.
+//
+// It is the building block of the operations in this package, and allows
+// other versions of the API, like the one implemented by the elbv2
+// package, to share the same transport.
+//
+// The call is bound by the operation timeout of the client, see
+// WithTimeouts. Each call gets a new client request token, sent in the
+// ClientRequestTokenHeader header and recorded, along with the operation
+// id set by WithOperationID, in the debug logs and the *Error returned.
+func (elb *ELB) Query(ctx context.Context, version string, params map[string]string, resp interface{}) (err error) {
+	ctx, cancel := elb.operationContext(ctx, params["Action"])
+	defer cancel()
+	ctx = context.WithValue(ctx, requestTokenKey{}, newToken())
+	defer func() {
+		annotateError(ctx, err)
+	}()
+	policy := elb.retryPolicyOrDefault()
+	for retry := 0; ; retry++ {
+		attempt := make(map[string]string, len(params))
+		for k, v := range params {
+			attempt[k] = v
+		}
+		if err := elb.limiter.wait(ctx); err != nil {
+			return err
+		}
+		err := elb.send(ctx, version, attempt, resp)
+		if err == nil || !policy.retryable(err) {
+			return err
+		}
+		if retry+1 >= policy.MaxAttempts {
+			if elb.throttleObserver != nil {
+				elb.throttleObserver.OnGiveUp(params["Action"], retry+1, err)
+			}
+			return err
+		}
+		delay := policy.delay(retry)
+		if elb.throttleObserver != nil {
+			elb.throttleObserver.OnRetry(params["Action"], retry+1, delay, err)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// do sends a single signed request.
+func (elb *ELB) do(ctx context.Context, version string, params map[string]string, resp interface{}) error {
+	auth, err := elb.auth()
+	if err != nil {
+		return err
+	}
+	params["Version"] = version
 	params["Timestamp"] = time.Now().In(time.UTC).Format(time.RFC3339)
-	endpoint, err := url.Parse(elb.Region.ELBEndpoint)
+	endpoint, err := url.Parse(elb.endpoint())
 	if err != nil {
 		return err
 	}
 	if endpoint.Path == "" {
 		endpoint.Path = "/"
 	}
-	sign(elb.Auth, "GET", endpoint.Path, params, endpoint.Host)
+	if elb.disableSSL {
+		endpoint.Scheme = "http"
+	}
 	endpoint.RawQuery = multimap(params).Encode()
-	r, err := http.Get(endpoint.String())
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	sigVersion := elb.SignatureVersion()
+	sign.Sign(req, auth, sign.Region{Name: elb.signingRegion(), Service: elb.signingService(), Version: sign.Version(sigVersion)}, time.Now())
+	if sigVersion == SignatureV2 {
+		// Keep the signature parameters in params, so they are logged.
+		for k, v := range req.URL.Query() {
+			params[k] = v[0]
+		}
+	}
+	// Set after signing, the token is left out of the signed headers.
+	if token := requestToken(ctx); token != "" {
+		req.Header.Set(ClientRequestTokenHeader, token)
+	}
+	start := time.Now()
+	r, err := elb.httpClient().Do(req)
+	latency := time.Since(start)
 	if err != nil {
+		elb.logResponse(ctx, params, nil, err, latency, nil)
 		return err
 	}
 	defer r.Body.Close()
+	body, err := elb.limitBody(r)
+	if err != nil {
+		elb.logResponse(ctx, params, r, err, latency, nil)
+		return err
+	}
+	// The logger only gets the beginning of the body, kept while it's
+	// decoded.
+	var head *headBuffer
+	if elb.logger != nil {
+		head = &headBuffer{max: maxBodySnippet + 1}
+		body = io.TeeReader(body, head)
+	}
 	if r.StatusCode != 200 {
-		return buildError(r)
+		err = buildError(r, body)
+	} else {
+		err = decodeResponse(body, resp)
+	}
+	if head != nil {
+		var readErr error
+		if errors.Is(err, ErrResponseTooLarge) {
+			readErr = err
+		}
+		elb.logResponse(ctx, params, r, readErr, latency, head.buf)
+	}
+	return err
+}
+
+// endpoint returns the endpoint of the client region, resolved with
+// LookupRegion when the region has none.
+func (elb *ELB) endpoint() string {
+	if elb.Region.ELBEndpoint == "" && elb.Region.Name != "" {
+		return LookupRegion(elb.Region.Name).Endpoint
+	}
+	return elb.Region.ELBEndpoint
+}
+
+// httpClient returns the HTTP client used to send requests.
+func (elb *ELB) httpClient() *http.Client {
+	if elb.client != nil {
+		return elb.client
+	}
+	return http.DefaultClient
+}
+
+// insecureClient returns a copy of c that skips the verification of TLS
+// certificates.
+func insecureClient(c *http.Client) *http.Client {
+	var t *http.Transport
+	switch rt := c.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return c
+	}
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
 	}
-	return xml.NewDecoder(r.Body).Decode(resp)
+	t.TLSClientConfig.InsecureSkipVerify = true
+	insecure := *c
+	insecure.Transport = t
+	return &insecure
 }
 
+// signingService returns the service name used in the Signature Version 4
+// credential scope.
+func (elb *ELB) signingService() string {
+	if elb.signingName != "" {
+		return elb.signingName
+	}
+	return "elasticloadbalancing"
+}
+
+// signingRegion returns the region name used in the Signature Version 4
+// credential scope. Custom regions without a name default to us-east-1.
+func (elb *ELB) signingRegion() string {
+	if elb.Region.Name != "" {
+		return elb.Region.Name
+	}
+	return "us-east-1"
+}
+
+// Codes of errors returned by ELB.
+const (
+	ErrCertificateNotFound         = "CertificateNotFound"
+	ErrDuplicateListener           = "DuplicateListener"
+	ErrDuplicateLoadBalancerName   = "DuplicateLoadBalancerName"
+	ErrDuplicatePolicyName         = "DuplicatePolicyName"
+	ErrDuplicateTagKeys            = "DuplicateTagKeys"
+	ErrInvalidConfigurationRequest = "InvalidConfigurationRequest"
+	ErrInvalidInstance             = "InvalidInstance"
+	ErrInvalidSecurityGroup        = "InvalidSecurityGroup"
+	ErrInvalidSubnet               = "InvalidSubnet"
+	ErrListenerNotFound            = "ListenerNotFound"
+	ErrLoadBalancerNotFound        = "LoadBalancerNotFound"
+	ErrPolicyNotFound              = "PolicyNotFound"
+	ErrPolicyTypeNotFound          = "PolicyTypeNotFound"
+	ErrSubnetNotFound              = "SubnetNotFound"
+	ErrThrottling                  = "Throttling"
+	ErrTooManyTags                 = "TooManyTags"
+	ErrTooManyLoadBalancers        = "TooManyLoadBalancers"
+	ErrValidation                  = "ValidationError"
+)
+
 // Error encapsulates an error returned by ELB.
 type Error struct {
 	// HTTP status code
-	StatusCode int
+	StatusCode int `json:"StatusCode"`
 	// AWS error code
-	Code string
+	Code string `json:"Code"`
 	// The human-oriented error message
-	Message string
+	Message string `json:"Message"`
+	// The ID of the failed request, useful when contacting AWS support
+	RequestId string `xml:"-" json:"RequestId"`
+	// The client request token and operation id of the failed request,
+	// see ClientRequestTokenHeader and WithOperationID.
+	ClientRequestToken string `xml:"-" json:"ClientRequestToken,omitempty"`
+	OperationId        string `xml:"-" json:"OperationId,omitempty"`
 }
 
 func (err *Error) Error() string {
@@ -309,20 +1532,122 @@ func (err *Error) Error() string {
 	return fmt.Sprintf("%s (%s)", err.Message, err.Code)
 }
 
+// ErrorCode returns the AWS error code of err, or an empty string if err
+// wasn't returned by ELB.
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// IsLoadBalancerNotFound reports whether err was caused by a reference to a
+// Load Balancer that doesn't exist.
+func IsLoadBalancerNotFound(err error) bool {
+	return ErrorCode(err) == ErrLoadBalancerNotFound
+}
+
+// IsDuplicateLoadBalancerName reports whether err was caused by the
+// creation of a Load Balancer with a name already in use.
+func IsDuplicateLoadBalancerName(err error) bool {
+	return ErrorCode(err) == ErrDuplicateLoadBalancerName
+}
+
+// IsThrottling reports whether err was caused by the request rate
+// exceeding the account limits.
+func IsThrottling(err error) bool {
+	switch ErrorCode(err) {
+	case ErrThrottling, "ThrottlingException", "RequestLimitExceeded", "RequestThrottled":
+		return true
+	}
+	return false
+}
+
+// maxBodySnippet is the maximum length of the body kept by DecodeError.
+const maxBodySnippet = 512
+
+// DecodeError is returned when the body of a successful response can't be
+// decoded, e.g. when a proxy answers in place of AWS.
+type DecodeError struct {
+	// Body holds the beginning of the response body.
+	Body string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("elb: can't decode response: %v; body: %q", e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TransportError is returned when a failed response has a body that isn't
+// an ELB error document, e.g. an HTML page served by a proxy or an edge in
+// front of AWS, or a truncated document. Those with a 5xx status are
+// retryable.
+type TransportError struct {
+	StatusCode  int
+	Status      string
+	ContentType string
+	// Body holds the beginning of the response body.
+	Body string
+	// Err is the error met decoding the body.
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("elb: unexpected response %s (%s): %q", e.Status, e.ContentType, e.Body)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+func bodySnippet(body []byte) string {
+	if len(body) > maxBodySnippet {
+		return string(body[:maxBodySnippet]) + "..."
+	}
+	return string(body)
+}
+
 type xmlErrors struct {
-	Errors []Error `xml:"Error"`
+	Errors    []Error `xml:"Error"`
+	RequestId string  `xml:"RequestId"`
 }
 
-func buildError(r *http.Response) error {
+// buildError returns the *Error described by the body of the failed
+// response r, or a *TransportError if the body isn't an error document.
+func buildError(r *http.Response, body io.Reader) error {
 	var (
 		err    Error
 		errors xmlErrors
 	)
-	xml.NewDecoder(r.Body).Decode(&errors)
+	head := headBuffer{max: maxBodySnippet + 1}
+	tee := io.TeeReader(body, &head)
+	decodeErr := xml.NewDecoder(tee).Decode(&errors)
+	if decodeErr == ErrResponseTooLarge {
+		return decodeErr
+	}
+	if decodeErr == io.EOF {
+		decodeErr = nil
+	}
+	io.CopyN(io.Discard, tee, int64(head.max))
+	if len(errors.Errors) == 0 && len(bytes.TrimSpace(head.buf)) > 0 {
+		return &TransportError{
+			StatusCode:  r.StatusCode,
+			Status:      r.Status,
+			ContentType: r.Header.Get("Content-Type"),
+			Body:        bodySnippet(head.buf),
+			Err:         decodeErr,
+		}
+	}
 	if len(errors.Errors) > 0 {
 		err = errors.Errors[0]
 	}
 	err.StatusCode = r.StatusCode
+	err.RequestId = errors.RequestId
 	if err.Message == "" {
 		err.Message = r.Status
 	}
@@ -336,33 +1661,3 @@ func multimap(p map[string]string) url.Values {
 	}
 	return q
 }
-
-func makeCreateParams(createLB *CreateLoadBalancer) map[string]string {
-	params := make(map[string]string)
-	params["LoadBalancerName"] = createLB.Name
-	params["Action"] = "CreateLoadBalancer"
-	if createLB.Scheme != "" {
-		params["Scheme"] = createLB.Scheme
-	}
-	for i, s := range createLB.SecurityGroups {
-		key := fmt.Sprintf("SecurityGroups.member.%d", i+1)
-		params[key] = s
-	}
-	for i, s := range createLB.Subnets {
-		key := fmt.Sprintf("Subnets.member.%d", i+1)
-		params[key] = s
-	}
-	for i, l := range createLB.Listeners {
-		key := "Listeners.member.%d.%s"
-		index := i + 1
-		params[fmt.Sprintf(key, index, "InstancePort")] = strconv.Itoa(l.InstancePort)
-		params[fmt.Sprintf(key, index, "InstanceProtocol")] = l.InstanceProtocol
-		params[fmt.Sprintf(key, index, "Protocol")] = l.Protocol
-		params[fmt.Sprintf(key, index, "LoadBalancerPort")] = strconv.Itoa(l.LoadBalancerPort)
-	}
-	for i, az := range createLB.AvailZones {
-		key := fmt.Sprintf("AvailabilityZones.member.%d", i+1)
-		params[key] = az
-	}
-	return params
-}
@@ -1,9 +1,19 @@
 package elb_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/flaviamissi/go-elb/aws"
 	"github.com/flaviamissi/go-elb/elb"
+	"io"
 	. "launchpad.net/gocheck"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -16,8 +26,10 @@ var _ = Suite(&S{})
 
 func (s *S) SetUpSuite(c *C) {
 	s.HTTPSuite.SetUpSuite(c)
-	auth := aws.Auth{"abc", "123"}
-	s.elb = elb.New(auth, aws.Region{ELBEndpoint: testServer.URL})
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	// Retries would consume responses prepared for other requests.
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	s.elb = elb.New(auth, aws.Region{ELBEndpoint: testServer.URL}, noRetry)
 }
 
 func (s *S) TestCreateLoadBalancer(c *C) {
@@ -45,11 +57,12 @@ func (s *S) TestCreateLoadBalancer(c *C) {
 	c.Assert(values.Get("AvailabilityZones.member.1"), Equals, "us-east-1a")
 	c.Assert(values.Get("AvailabilityZones.member.2"), Equals, "us-east-1b")
 	c.Assert(values.Get("Listeners.member.1.InstancePort"), Equals, "80")
-	c.Assert(values.Get("Listeners.member.1.InstanceProtocol"), Equals, "http")
-	c.Assert(values.Get("Listeners.member.1.Protocol"), Equals, "http")
+	c.Assert(values.Get("Listeners.member.1.InstanceProtocol"), Equals, "HTTP")
+	c.Assert(values.Get("Listeners.member.1.Protocol"), Equals, "HTTP")
 	c.Assert(values.Get("Listeners.member.1.LoadBalancerPort"), Equals, "80")
 	c.Assert(values.Get("Signature"), Not(Equals), "")
 	c.Assert(resp.DNSName, Equals, "testlb-339187009.us-east-1.elb.amazonaws.com")
+	c.Assert(resp.RequestId, Equals, "0c3a8e29-490e-11e2-8647-e14ad5151f1f")
 }
 
 func (s *S) TestCreateLoadBalancerWithSubnetsAndMoreListeners(c *C) {
@@ -162,6 +175,7 @@ func (s *S) TestDeregisterInstancesFromLoadBalancer(c *C) {
 	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
 	c.Assert(values.Get("Instances.member.1.InstanceId"), Equals, "i-b44db8ca")
 	c.Assert(values.Get("Instances.member.2.InstanceId"), Equals, "i-461ecf38")
+	c.Assert(resp.InstanceIds, DeepEquals, []string{"i-315b7e51"})
 	c.Assert(resp.RequestId, Equals, "d6490837-49fd-11e2-bba9-35ba56032fe1")
 }
 
@@ -187,7 +201,7 @@ func (s *S) TestDescribeLoadBalancers(c *C) {
 	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
 	t, _ := time.Parse(time.RFC3339, "2012-12-27T11:51:52.970Z")
 	expected := &elb.DescribeLoadBalancerResp{
-		[]elb.LoadBalancerDescription{
+		LoadBalancerDescriptions: []elb.LoadBalancerDescription{
 			{
 				AvailZones:                []string{"us-east-1a"},
 				BackendServerDescriptions: []elb.BackendServerDescriptions(nil),
@@ -224,10 +238,34 @@ func (s *S) TestDescribeLoadBalancers(c *C) {
 				Subnets: []string(nil),
 			},
 		},
+		RequestId: "e2e81963-5055-11e2-99c7-434205631d9b",
 	}
 	c.Assert(resp, DeepEquals, expected)
 }
 
+func (s *S) TestDescribeLoadBalancersVPC(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancersVPC)
+	resp, err := s.elb.DescribeLoadBalancers("vpclb")
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	desc := resp.LoadBalancerDescriptions[0]
+	c.Assert(desc.VPCId, Equals, "vpc-a01106c2")
+	c.Assert(desc.CreatedTime.Equal(time.Date(2013, 5, 24, 21, 15, 31, 280e6, time.UTC)), Equals, true)
+	c.Assert(desc.CanonicalHostedZoneName, Equals, "vpclb-1234567890.us-east-1.elb.amazonaws.com")
+	c.Assert(desc.CanonicalHostedZoneNameId, Equals, "Z35SXDOTRQ7X7K")
+	c.Assert(desc.Subnets, DeepEquals, []string{"subnet-15ca247d"})
+	c.Assert(desc.SecurityGroups, DeepEquals, []string{"sg-1a2b3c4d"})
+	c.Assert(desc.ListenerDescriptions[0].PolicyNames, DeepEquals, []string{"my-cookie-policy"})
+	c.Assert(desc.Policies, DeepEquals, elb.Policies{
+		LBCookieStickinessPolicies: []elb.LBCookieStickinessPolicies{{CookieExpirationPeriod: 60, PolicyName: "my-cookie-policy"}},
+		OtherPolicies:              []string{"my-backend-policy"},
+	})
+	c.Assert(desc.BackendServerDescriptions, DeepEquals, []elb.BackendServerDescriptions{
+		{InstancePort: 443, PolicyNames: []string{"my-backend-policy"}},
+	})
+	c.Assert(desc.BackendPolicyNames(443), DeepEquals, []string{"my-backend-policy"})
+}
+
 func (s *S) TestDescribeLoadBalancersByName(c *C) {
 	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
 	s.elb.DescribeLoadBalancers("somelb")
@@ -239,6 +277,42 @@ func (s *S) TestDescribeLoadBalancersByName(c *C) {
 	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "somelb")
 }
 
+func (s *S) TestDescribeLoadBalancersPage(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancersWithNextMarker)
+	resp, err := s.elb.DescribeLoadBalancersPage("somemarker", 1)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	c.Assert(values.Get("Marker"), Equals, "somemarker")
+	c.Assert(values.Get("PageSize"), Equals, "1")
+	c.Assert(resp.NextMarker, Equals, "otherlb")
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+}
+
+func (s *S) TestDescribeLoadBalancersPageOmitsEmptyMarker(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	_, err := s.elb.DescribeLoadBalancersPage("", 0)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	_, ok := values["Marker"]
+	c.Assert(ok, Equals, false)
+	_, ok = values["PageSize"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) TestDescribeLoadBalancersAll(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancersWithNextMarker)
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	descs, err := s.elb.DescribeLoadBalancersAll()
+	c.Assert(err, IsNil)
+	c.Assert(descs, HasLen, 2)
+	values := testServer.WaitRequest().URL.Query()
+	_, ok := values["Marker"]
+	c.Assert(ok, Equals, false)
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Marker"), Equals, "otherlb")
+}
+
 func (s *S) TestDescribeLoadBalancersBadRequest(c *C) {
 	testServer.PrepareResponse(400, nil, DescribeLoadBalancersBadRequest)
 	resp, err := s.elb.DescribeLoadBalancers()
@@ -247,6 +321,89 @@ func (s *S) TestDescribeLoadBalancersBadRequest(c *C) {
 	c.Assert(err, ErrorMatches, `^Cannot find Load Balancer absentlb \(LoadBalancerNotFound\)$`)
 }
 
+func (s *S) TestDecodeErrorKeepsBody(c *C) {
+	testServer.PrepareResponse(200, nil, "<html><body>Bad Gateway</body>")
+	_, err := s.elb.DescribeLoadBalancers()
+	c.Assert(err, ErrorMatches, `elb: can't decode response: .*; body: "<html><body>Bad Gateway</body>"`)
+	e, ok := err.(*elb.DecodeError)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Body, Equals, "<html><body>Bad Gateway</body>")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestDecodeErrorTruncatesBody(c *C) {
+	testServer.PrepareResponse(200, nil, "<"+strings.Repeat("x", 1000))
+	_, err := s.elb.DescribeLoadBalancers()
+	e, ok := err.(*elb.DecodeError)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Body, HasLen, 512+len("..."))
+	c.Assert(strings.HasSuffix(e.Body, "..."), Equals, true)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestTransportError(c *C) {
+	e := elb.New(s.elb.Auth, s.elb.Region, elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1}))
+	body := "<html><head><title>503 Service Unavailable</title></head><body>down</body></html>"
+	testServer.PrepareResponse(503, map[string]string{"Content-Type": "text/html"}, body)
+	_, err := e.DescribeLoadBalancers()
+	c.Assert(err, ErrorMatches, `elb: unexpected response 503 Service Unavailable \(text/html\): ".*down.*"`)
+	terr, ok := err.(*elb.TransportError)
+	c.Assert(ok, Equals, true)
+	c.Assert(terr.StatusCode, Equals, 503)
+	c.Assert(terr.Body, Equals, body)
+	c.Assert(elb.IsRetryable(err), Equals, true)
+	testServer.WaitRequest()
+
+	// A truncated error document.
+	testServer.PrepareResponse(400, nil, DescribeLoadBalancersBadRequest[:100])
+	_, err = e.DescribeLoadBalancers()
+	terr, ok = err.(*elb.TransportError)
+	c.Assert(ok, Equals, true)
+	c.Assert(terr.Err, NotNil)
+	c.Assert(elb.IsRetryable(err), Equals, false)
+	testServer.WaitRequest()
+
+	// A plain text body.
+	testServer.PrepareResponse(502, nil, "Bad Gateway")
+	_, err = e.DescribeLoadBalancers()
+	terr, ok = err.(*elb.TransportError)
+	c.Assert(ok, Equals, true)
+	c.Assert(terr.Body, Equals, "Bad Gateway")
+	testServer.WaitRequest()
+
+	// An empty body keeps being reported with the status.
+	testServer.PrepareResponse(503, nil, "")
+	_, err = e.DescribeLoadBalancers()
+	_, ok = err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	testServer.WaitRequest()
+}
+
+func (s *S) TestErrorPreservesRequestId(c *C) {
+	testServer.PrepareResponse(400, nil, DescribeLoadBalancersBadRequest)
+	_, err := s.elb.DescribeLoadBalancers("absentlb")
+	c.Assert(err, NotNil)
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.StatusCode, Equals, 400)
+	c.Assert(e.RequestId, Equals, "f14f348e-50f7-11e2-9831-f770dd71c209")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrLoadBalancerNotFound)
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+	c.Assert(elb.IsDuplicateLoadBalancerName(err), Equals, false)
+	c.Assert(elb.IsThrottling(err), Equals, false)
+}
+
+func (s *S) TestErrorPredicates(c *C) {
+	c.Assert(elb.ErrorCode(errors.New("some error")), Equals, "")
+	c.Assert(elb.IsLoadBalancerNotFound(nil), Equals, false)
+	dup := &elb.Error{StatusCode: 400, Code: elb.ErrDuplicateLoadBalancerName}
+	c.Assert(elb.IsDuplicateLoadBalancerName(dup), Equals, true)
+	c.Assert(elb.IsThrottling(&elb.Error{StatusCode: 400, Code: "Throttling"}), Equals, true)
+	c.Assert(elb.IsThrottling(&elb.Error{StatusCode: 400, Code: "RequestLimitExceeded"}), Equals, true)
+	wrapped := fmt.Errorf("cannot create: %w", dup)
+	c.Assert(elb.IsDuplicateLoadBalancerName(wrapped), Equals, true)
+}
+
 func (s *S) TestDescribeInstanceHealth(c *C) {
 	testServer.PrepareResponse(200, nil, DescribeInstanceHealth)
 	resp, err := s.elb.DescribeInstanceHealth("testlb", "i-b44db8ca")
@@ -259,12 +416,97 @@ func (s *S) TestDescribeInstanceHealth(c *C) {
 	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
 	c.Assert(values.Get("Instances.member.1.InstanceId"), Equals, "i-b44db8ca")
 	c.Assert(len(resp.InstanceStates) > 0, Equals, true)
+	c.Assert(resp.RequestId, Equals, "da0d0f9e-5669-11e2-9f81-319facce7423")
 	c.Assert(resp.InstanceStates[0].Description, Equals, "Instance registration is still in progress.")
 	c.Assert(resp.InstanceStates[0].InstanceId, Equals, "i-b44db8ca")
-	c.Assert(resp.InstanceStates[0].State, Equals, "OutOfService")
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
 	c.Assert(resp.InstanceStates[0].ReasonCode, Equals, "ELB")
 }
 
+func (s *S) TestNormalize(c *C) {
+	c.Assert(elb.NormalizeProtocol("https"), Equals, elb.ProtocolHTTPS)
+	c.Assert(elb.NormalizeProtocol("Tcp"), Equals, elb.ProtocolTCP)
+	c.Assert(elb.NormalizeProtocol("udp"), Equals, "udp")
+	c.Assert(elb.NormalizeScheme("Internal"), Equals, elb.SchemeInternal)
+	c.Assert(elb.NormalizeScheme("INTERNET-FACING"), Equals, elb.SchemeInternetFacing)
+	c.Assert(elb.NormalizeScheme("other"), Equals, "other")
+	c.Assert(elb.NormalizeHealthState("inservice"), Equals, elb.StateInService)
+	c.Assert(elb.NormalizeHealthState("OUTOFSERVICE"), Equals, elb.StateOutOfService)
+	c.Assert(elb.NormalizeHealthState("Draining"), Equals, elb.HealthState("Draining"))
+	c.Assert(elb.StateInService, Equals, elb.StateInService)
+}
+
+func (s *S) TestCreateLoadBalancerNormalizes(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLoadBalancer)
+	createLB := &elb.CreateLoadBalancer{
+		Name:      "testlb",
+		Subnets:   []string{"subnet-1"},
+		Scheme:    "Internal",
+		Listeners: []elb.Listener{{InstancePort: 443, Protocol: "ssl", LoadBalancerPort: 443, SSLCertificateId: "cert"}},
+	}
+	_, err := s.elb.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Scheme"), Equals, elb.SchemeInternal)
+	c.Assert(values.Get("Listeners.member.1.Protocol"), Equals, elb.ProtocolSSL)
+	c.Assert(values.Get("Listeners.member.1.InstanceProtocol"), Equals, "")
+	// The options of the caller are left untouched.
+	c.Assert(createLB.Scheme, Equals, "Internal")
+	c.Assert(createLB.Listeners[0].Protocol, Equals, "ssl")
+}
+
+func (s *S) TestDescribeNormalizes(c *C) {
+	body := strings.NewReplacer(
+		"<Protocol>HTTP</Protocol>", "<Protocol>http</Protocol>",
+		"<Scheme>internet-facing</Scheme>", "<Scheme>Internet-Facing</Scheme>",
+	).Replace(DescribeLoadBalancers)
+	c.Assert(body, Not(Equals), DescribeLoadBalancers)
+	testServer.PrepareResponse(200, nil, body)
+	resp, err := s.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	c.Assert(resp.LoadBalancerDescriptions[0].Scheme, Equals, elb.SchemeInternetFacing)
+	c.Assert(resp.LoadBalancerDescriptions[0].ListenerDescriptions[0].Listener.Protocol, Equals, elb.ProtocolHTTP)
+	testServer.PrepareResponse(200, nil, strings.Replace(DescribeInstanceHealth, "OutOfService", "outofservice", 1))
+	health, err := s.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	c.Assert(health.InstanceStates[0].State, Equals, elb.StateOutOfService)
+}
+
+func (s *S) TestDescribeInstanceHealthEncodesAllInstances(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeInstanceHealth)
+	_, err := s.elb.DescribeInstanceHealth("testlb", "i-b44db8ca", "i-461ecf38")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Instances.member.1.InstanceId"), Equals, "i-b44db8ca")
+	c.Assert(values.Get("Instances.member.2.InstanceId"), Equals, "i-461ecf38")
+}
+
+func (s *S) TestDescribeInstanceHealthFiltered(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeInstanceHealth)
+	testServer.PrepareResponse(200, nil, DescribeInstanceHealth)
+	filter := &elb.InstanceHealthFilter{States: []elb.HealthState{elb.StateInService}}
+	resp, err := s.elb.DescribeInstanceHealthFiltered("testlb", filter)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	_, ok := values["Instances.member.1.InstanceId"]
+	c.Assert(ok, Equals, false)
+	c.Assert(resp.InstanceStates, HasLen, 0)
+	filter.States = append(filter.States, elb.StateOutOfService)
+	resp, err = s.elb.DescribeInstanceHealthFiltered("testlb", filter)
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	c.Assert(resp.InstanceStates, HasLen, 1)
+	c.Assert(resp.InstanceStates[0].IsHealthy(), Equals, false)
+}
+
+func (s *S) TestInstanceStateIsHealthy(c *C) {
+	c.Assert(elb.InstanceState{State: elb.StateInService}.IsHealthy(), Equals, true)
+	c.Assert(elb.InstanceState{State: elb.StateOutOfService}.IsHealthy(), Equals, false)
+	c.Assert(elb.InstanceState{State: elb.StateUnknown}.IsHealthy(), Equals, false)
+}
+
 func (s *S) TestDescribeInstanceHealthBadRequest(c *C) {
 	testServer.PrepareResponse(400, nil, DescribeInstanceHealthBadRequest)
 	resp, err := s.elb.DescribeInstanceHealth("testlb", "i-foooo")
@@ -316,3 +558,702 @@ func (s *S) TestConfigureHealthCheckBadRequest(c *C) {
 	c.Assert(err, NotNil)
 	c.Assert(err, ErrorMatches, ".*foolb.*(LoadBalancerNotFound).*")
 }
+
+func (s *S) TestSignatureVersionDefaults(c *C) {
+	c.Assert(s.elb.SignatureVersion(), Equals, elb.SignatureV2)
+	c.Assert(elb.New(aws.Auth{}, aws.USEast).SignatureVersion(), Equals, elb.SignatureV2)
+	c.Assert(elb.New(aws.Auth{}, aws.EUCentral).SignatureVersion(), Equals, elb.SignatureV4)
+	c.Assert(elb.New(aws.Auth{}, aws.CNNorth).SignatureVersion(), Equals, elb.SignatureV4)
+	e := elb.New(aws.Auth{}, aws.EUCentral, elb.WithSignatureVersion(elb.SignatureV2))
+	c.Assert(e.SignatureVersion(), Equals, elb.SignatureV2)
+}
+
+func (s *S) TestSignatureVersionOfCustomRegion(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	region := elb.LookupRegion("private-1").WithEndpoint(testServer.URL)
+	e := elb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region.AWSRegion())
+	c.Assert(e.SignatureVersion(), Equals, elb.SignatureV4)
+	e = elb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region.AWSRegion(), elb.WithSignatureVersion(elb.SignatureV2))
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.URL.Query().Get("SignatureVersion"), Equals, "2")
+	c.Assert(req.Header.Get("Authorization"), Equals, "")
+}
+
+func (s *S) TestRegions(c *C) {
+	r, ok := elb.Regions["us-gov-west-1"]
+	c.Assert(ok, Equals, true)
+	c.Assert(r.Endpoint, Equals, "https://elasticloadbalancing.us-gov-west-1.amazonaws.com")
+	c.Assert(r.Partition, Equals, elb.PartitionGovCloud)
+	c.Assert(r.SignatureV4Only, Equals, false)
+	c.Assert(elb.Regions["cn-northwest-1"].Endpoint, Equals, "https://elasticloadbalancing.cn-northwest-1.amazonaws.com.cn")
+	c.Assert(elb.Regions["ap-southeast-5"].SignatureV4Only, Equals, true)
+	c.Assert(aws.USEast.ELBEndpoint, Equals, "https://elasticloadbalancing.us-east-1.amazonaws.com")
+	_, ok = elb.Regions["moon-1"]
+	c.Assert(ok, Equals, false)
+	for name := range aws.Regions {
+		_, ok := elb.Regions[name]
+		c.Assert(ok, Equals, true, Commentf("%s", name))
+	}
+	e := elb.New(aws.Auth{}, elb.Regions["us-east-2"].AWSRegion())
+	c.Assert(e.Region.ELBEndpoint, Equals, "https://elasticloadbalancing.us-east-2.amazonaws.com")
+	c.Assert(e.Region.EC2Endpoint, Equals, "https://ec2.us-east-2.amazonaws.com")
+	c.Assert(e.SignatureVersion(), Equals, elb.SignatureV4)
+}
+
+func (s *S) TestRegionWithEndpoint(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	region := aws.EUCentral
+	region.ELBEndpoint = testServer.URL
+	e := elb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region)
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	c.Assert(req.Header.Get("Authorization"), Matches, ".*/eu-central-1/elasticloadbalancing/.*")
+}
+
+func (s *S) TestWithEndpointAndDisableSSL(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	endpoint := strings.Replace(testServer.URL, "http://", "https://", 1)
+	e := elb.New(s.elb.Auth, aws.USEast, elb.WithEndpoint(endpoint), elb.WithDisableSSL())
+	c.Assert(e.Region.Name, Equals, "us-east-1")
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancer")
+}
+
+func (s *S) TestWithInsecureSkipVerify(c *C) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, DeleteLoadBalancer)
+	}))
+	// Silence the handshake error caused by the rejected certificate.
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	e := elb.New(s.elb.Auth, aws.USEast, elb.WithEndpoint(srv.URL), noRetry)
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, ErrorMatches, ".*certificate.*")
+	e = elb.New(s.elb.Auth, aws.USEast, elb.WithEndpoint(srv.URL), elb.WithInsecureSkipVerify(), noRetry)
+	_, err = e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestWithLogger(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	var buf bytes.Buffer
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123", Token: "secret-token"}
+	e := elb.New(auth, s.elb.Region, elb.WithLogger(log.New(&buf, "", 0)))
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("SecurityToken"), Equals, "secret-token")
+	out := buf.String()
+	c.Assert(out, Matches, "(?s)elb: DeleteLoadBalancer request=[0-9a-f-]{36} .*LoadBalancerName=testlb.*: 200 \\(.*\\)\n.*<DeleteLoadBalancerResponse.*")
+	c.Assert(strings.Contains(out, "Signature=REDACTED"), Equals, true)
+	c.Assert(strings.Contains(out, "SecurityToken=REDACTED"), Equals, true)
+	c.Assert(strings.Contains(out, values.Get("Signature")), Equals, false)
+	c.Assert(strings.Contains(out, "secret-token"), Equals, false)
+}
+
+type recordedRequest struct {
+	action, errCode string
+}
+
+type recordingMetrics struct {
+	started []string
+	done    []recordedRequest
+}
+
+func (m *recordingMetrics) OnRequestStart(action string) {
+	m.started = append(m.started, action)
+}
+
+func (m *recordingMetrics) OnRequestDone(action string, d time.Duration, errCode string) {
+	m.done = append(m.done, recordedRequest{action, errCode})
+}
+
+func (s *S) TestWithMetrics(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	testServer.PrepareResponse(400, nil, DescribeLoadBalancersBadRequest)
+	m := &recordingMetrics{}
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	e := elb.New(s.elb.Auth, s.elb.Region, elb.WithMetrics(m), noRetry)
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	_, err = e.DescribeLoadBalancers("absentlb")
+	c.Assert(err, NotNil)
+	c.Assert(m.started, DeepEquals, []string{"DeleteLoadBalancer", "DescribeLoadBalancers"})
+	c.Assert(m.done, DeepEquals, []recordedRequest{
+		{"DeleteLoadBalancer", ""},
+		{"DescribeLoadBalancers", "LoadBalancerNotFound"},
+	})
+	testServer.WaitRequest()
+	testServer.WaitRequest()
+}
+
+func (s *S) TestExpvarMetrics(c *C) {
+	m := elb.NewExpvarMetrics("elb_test")
+	m.OnRequestStart("DescribeLoadBalancers")
+	m.OnRequestDone("DescribeLoadBalancers", 1500*time.Microsecond, "")
+	m.OnRequestStart("DescribeLoadBalancers")
+	m.OnRequestDone("DescribeLoadBalancers", 500*time.Microsecond, "Throttling")
+	c.Assert(m.Requests.Get("DescribeLoadBalancers").String(), Equals, "2")
+	c.Assert(m.Errors.Get("DescribeLoadBalancers.Throttling").String(), Equals, "1")
+	c.Assert(m.LatencyMs.Get("DescribeLoadBalancers").String(), Equals, "2")
+}
+
+func (s *S) TestRequestSignedWithV4(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	region := aws.Region{Name: "eu-central-1", ELBEndpoint: testServer.URL}
+	e := elb.New(aws.Auth{AccessKey: "abc", SecretKey: "123"}, region)
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	values := req.URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancer")
+	c.Assert(values.Get("Signature"), Equals, "")
+	c.Assert(values.Get("AWSAccessKeyId"), Equals, "")
+	c.Assert(req.Header.Get("X-Amz-Date"), Not(Equals), "")
+	c.Assert(req.Header.Get("Authorization"), Matches, `AWS4-HMAC-SHA256 Credential=abc/\d{8}/eu-central-1/elasticloadbalancing/aws4_request, SignedHeaders=host;x-amz-date, Signature=[0-9a-f]{64}`)
+}
+
+func (s *S) TestRequestSignedWithProviderCredentials(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	provider := aws.StaticProvider{AccessKey: "provided", SecretKey: "secret", Token: "token"}
+	e := elb.New(aws.Auth{}, aws.Region{ELBEndpoint: testServer.URL}, elb.WithCredentials(provider))
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("AWSAccessKeyId"), Equals, "provided")
+	c.Assert(values.Get("SecurityToken"), Equals, "token")
+}
+
+func (s *S) TestNewFromChain(c *C) {
+	os.Setenv("AWS_ACCESS_KEY_ID", "chained")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	e, err := elb.NewFromChain(aws.Region{ELBEndpoint: testServer.URL})
+	c.Assert(err, IsNil)
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	_, err = e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("AWSAccessKeyId"), Equals, "chained")
+}
+
+func (s *S) TestDescribeLoadBalancerAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancerAttributes)
+	resp, err := s.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancerAttributes")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	expected := elb.LoadBalancerAttributes{
+		CrossZoneLoadBalancing: &elb.CrossZoneLoadBalancing{Enabled: true},
+		AccessLog: &elb.AccessLog{
+			Enabled:        true,
+			S3BucketName:   "my-loadbalancer-logs",
+			S3BucketPrefix: "testlb",
+			EmitInterval:   60,
+		},
+		ConnectionDraining: &elb.ConnectionDraining{Enabled: true, Timeout: 300},
+		ConnectionSettings: &elb.ConnectionSettings{IdleTimeout: 30},
+	}
+	c.Assert(resp.LoadBalancerAttributes, DeepEquals, expected)
+}
+
+func (s *S) TestModifyLoadBalancerAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, ModifyLoadBalancerAttributes)
+	attrs := elb.LoadBalancerAttributes{
+		CrossZoneLoadBalancing: &elb.CrossZoneLoadBalancing{Enabled: true},
+		ConnectionSettings:     &elb.ConnectionSettings{IdleTimeout: 30},
+	}
+	resp, err := s.elb.ModifyLoadBalancerAttributes("testlb", &attrs)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "ModifyLoadBalancerAttributes")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("LoadBalancerAttributes.CrossZoneLoadBalancing.Enabled"), Equals, "true")
+	c.Assert(values.Get("LoadBalancerAttributes.ConnectionSettings.IdleTimeout"), Equals, "30")
+	_, ok := values["LoadBalancerAttributes.AccessLog.Enabled"]
+	c.Assert(ok, Equals, false)
+	_, ok = values["LoadBalancerAttributes.ConnectionDraining.Enabled"]
+	c.Assert(ok, Equals, false)
+	c.Assert(resp.LoadBalancerName, Equals, "testlb")
+	c.Assert(resp.LoadBalancerAttributes.CrossZoneLoadBalancing, DeepEquals, &elb.CrossZoneLoadBalancing{Enabled: true})
+}
+
+func (s *S) TestAddTags(c *C) {
+	testServer.PrepareResponse(200, nil, AddTags)
+	tags := []elb.Tag{{Key: "project", Value: "lima"}, {Key: "department", Value: "digital-media"}}
+	resp, err := s.elb.AddTags([]string{"testlb", "otherlb"}, tags)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "AddTags")
+	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "testlb")
+	c.Assert(values.Get("LoadBalancerNames.member.2"), Equals, "otherlb")
+	c.Assert(values.Get("Tags.member.1.Key"), Equals, "project")
+	c.Assert(values.Get("Tags.member.1.Value"), Equals, "lima")
+	c.Assert(values.Get("Tags.member.2.Key"), Equals, "department")
+	c.Assert(values.Get("Tags.member.2.Value"), Equals, "digital-media")
+	c.Assert(resp.RequestId, Equals, "360e81f7-1100-11e4-b6ed-0f30EXAMPLE")
+}
+
+func (s *S) TestRemoveTags(c *C) {
+	testServer.PrepareResponse(200, nil, RemoveTags)
+	_, err := s.elb.RemoveTags([]string{"testlb"}, []string{"project"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "RemoveTags")
+	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "testlb")
+	c.Assert(values.Get("Tags.member.1.Key"), Equals, "project")
+	c.Assert(values.Get("Tags.member.1.Value"), Equals, "")
+}
+
+func (s *S) TestDescribeTags(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeTags)
+	resp, err := s.elb.DescribeTags("testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeTags")
+	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "testlb")
+	expected := []elb.TagDescription{
+		{
+			LoadBalancerName: "testlb",
+			Tags:             []elb.Tag{{Key: "project", Value: "lima"}, {Key: "department", Value: "digital-media"}},
+		},
+	}
+	c.Assert(resp.TagDescriptions, DeepEquals, expected)
+}
+
+func (s *S) TestCreateLoadBalancerListeners(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLoadBalancerListeners)
+	listeners := []elb.Listener{
+		{
+			InstancePort:     80,
+			InstanceProtocol: "HTTP",
+			LoadBalancerPort: 443,
+			Protocol:         "HTTPS",
+			SSLCertificateId: "arn:aws:iam::123456789012:server-certificate/my-server-cert",
+		},
+	}
+	resp, err := s.elb.CreateLoadBalancerListeners("testlb", listeners)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateLoadBalancerListeners")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("Listeners.member.1.InstancePort"), Equals, "80")
+	c.Assert(values.Get("Listeners.member.1.InstanceProtocol"), Equals, "HTTP")
+	c.Assert(values.Get("Listeners.member.1.LoadBalancerPort"), Equals, "443")
+	c.Assert(values.Get("Listeners.member.1.Protocol"), Equals, "HTTPS")
+	c.Assert(values.Get("Listeners.member.1.SSLCertificateId"), Equals, "arn:aws:iam::123456789012:server-certificate/my-server-cert")
+	c.Assert(resp.RequestId, Equals, "1549581b-12b7-11e3-895e-1334aEXAMPLE")
+}
+
+func (s *S) TestCreateLoadBalancerListenersDuplicate(c *C) {
+	testServer.PrepareResponse(400, nil, CreateLoadBalancerListenersDuplicate)
+	listeners := []elb.Listener{{InstancePort: 8080, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}}
+	resp, err := s.elb.CreateLoadBalancerListeners("testlb", listeners)
+	c.Assert(resp, IsNil)
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Code, Equals, "DuplicateListener")
+	c.Assert(e.StatusCode, Equals, 400)
+}
+
+func (s *S) TestCreateLoadBalancerListenersInvalid(c *C) {
+	tests := []struct {
+		listener elb.Listener
+		err      string
+	}{
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 80, Protocol: "UDP"}, `.*unsupported protocol "UDP".*`},
+		{elb.Listener{InstancePort: 80, InstanceProtocol: "UDP", LoadBalancerPort: 80, Protocol: "TCP"}, `.*unsupported instance protocol "UDP".*`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 0, Protocol: "HTTP"}, `.*load balancer port 0 out of range.*`},
+		{elb.Listener{InstancePort: 65536, LoadBalancerPort: 80, Protocol: "HTTP"}, `.*instance port 65536 out of range.*`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "HTTPS"}, `.*SSLCertificateId is required for HTTPS listeners`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "ssl"}, `.*SSLCertificateId is required for SSL listeners`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "arn:aws:acm"}, `.*invalid certificate ARN arn:aws:acm`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "arn:aws:acm::123456789012:certificate/abcd"}, `.*invalid ACM certificate ARN .*`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "arn:aws:iam::123456789012:user/bob"}, `.*invalid IAM server certificate ARN .*`},
+		{elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "arn:aws:s3:::bucket/cert"}, `.*certificate ARN arn:aws:s3:::bucket/cert is neither an IAM nor an ACM one`},
+	}
+	for _, t := range tests {
+		listeners := []elb.Listener{{InstancePort: 80, LoadBalancerPort: 80, Protocol: "HTTP"}, t.listener}
+		_, err := s.elb.CreateLoadBalancerListeners("testlb", listeners)
+		c.Check(err, ErrorMatches, "elb: invalid listener 2 "+t.err)
+		e, ok := err.(*elb.ListenerError)
+		c.Assert(ok, Equals, true)
+		c.Check(e.Index, Equals, 2)
+		c.Check(t.listener.Validate(), ErrorMatches, "elb: invalid listener "+t.err)
+		_, err = s.elb.CreateLoadBalancer(&elb.CreateLoadBalancer{Name: "testlb", Listeners: listeners})
+		c.Check(err, FitsTypeOf, &elb.ListenerError{})
+	}
+	valid := elb.Listener{InstancePort: 80, LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "cert"}
+	c.Assert(valid.Validate(), IsNil)
+	valid.SSLCertificateId = elb.ACMCertificateARN("us-east-1", "123456789012", "abcd")
+	c.Assert(valid.Validate(), IsNil)
+}
+
+func (s *S) TestCreateLoadBalancerListenersWithoutValidation(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLoadBalancerListeners)
+	e := elb.New(s.elb.Auth, s.elb.Region, elb.WithoutListenerValidation(), elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1}))
+	listeners := []elb.Listener{{InstancePort: 80, LoadBalancerPort: 80, Protocol: "UDP"}}
+	_, err := e.CreateLoadBalancerListeners("testlb", listeners)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Listeners.member.1.Protocol"), Equals, "UDP")
+}
+
+func (s *S) TestDeleteLoadBalancerListeners(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancerListeners)
+	_, err := s.elb.DeleteLoadBalancerListeners("testlb", 80, 443)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancerListeners")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("LoadBalancerPorts.member.1"), Equals, "80")
+	c.Assert(values.Get("LoadBalancerPorts.member.2"), Equals, "443")
+}
+
+func (s *S) TestSetLoadBalancerListenerSSLCertificate(c *C) {
+	testServer.PrepareResponse(200, nil, SetLoadBalancerListenerSSLCertificate)
+	certId := "arn:aws:iam::123456789012:server-certificate/new-server-cert"
+	_, err := s.elb.SetLoadBalancerListenerSSLCertificate("testlb", 443, certId)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "SetLoadBalancerListenerSSLCertificate")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("LoadBalancerPort"), Equals, "443")
+	c.Assert(values.Get("SSLCertificateId"), Equals, certId)
+}
+
+func (s *S) TestSetLoadBalancerListenerSSLCertificateNotFound(c *C) {
+	testServer.PrepareResponse(400, nil, SetLoadBalancerListenerSSLCertificateNotFound)
+	certId := "arn:aws:iam::123456789012:server-certificate/absent"
+	resp, err := s.elb.SetLoadBalancerListenerSSLCertificate("testlb", 443, certId)
+	c.Assert(resp, IsNil)
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Code, Equals, elb.ErrCertificateNotFound)
+}
+
+func (s *S) TestApplySecurityGroups(c *C) {
+	testServer.PrepareResponse(200, nil, ApplySecurityGroupsToLoadBalancer)
+	resp, err := s.elb.ApplySecurityGroups("testlb", []string{"sg-fc448899", "sg-a1b2c3d4"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "ApplySecurityGroupsToLoadBalancer")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("SecurityGroups.member.1"), Equals, "sg-fc448899")
+	c.Assert(values.Get("SecurityGroups.member.2"), Equals, "sg-a1b2c3d4")
+	c.Assert(resp.SecurityGroups, DeepEquals, []string{"sg-fc448899", "sg-a1b2c3d4"})
+}
+
+func (s *S) TestAttachLoadBalancerToSubnets(c *C) {
+	testServer.PrepareResponse(200, nil, AttachLoadBalancerToSubnets)
+	resp, err := s.elb.AttachLoadBalancerToSubnets("testlb", []string{"subnet-3561b05e"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "AttachLoadBalancerToSubnets")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("Subnets.member.1"), Equals, "subnet-3561b05e")
+	c.Assert(resp.Subnets, DeepEquals, []string{"subnet-119f0078", "subnet-3561b05e"})
+}
+
+func (s *S) TestAttachLoadBalancerToSubnetsNotFound(c *C) {
+	testServer.PrepareResponse(400, nil, AttachLoadBalancerToSubnetsNotFound)
+	resp, err := s.elb.AttachLoadBalancerToSubnets("testlb", []string{"subnet-absent"})
+	c.Assert(resp, IsNil)
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Code, Equals, elb.ErrSubnetNotFound)
+}
+
+func (s *S) TestDetachLoadBalancerFromSubnets(c *C) {
+	testServer.PrepareResponse(200, nil, DetachLoadBalancerFromSubnets)
+	resp, err := s.elb.DetachLoadBalancerFromSubnets("testlb", []string{"subnet-3561b05e"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DetachLoadBalancerFromSubnets")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("Subnets.member.1"), Equals, "subnet-3561b05e")
+	c.Assert(resp.Subnets, DeepEquals, []string{"subnet-119f0078"})
+}
+
+func (s *S) TestEnableAvailabilityZonesForLoadBalancer(c *C) {
+	testServer.PrepareResponse(200, nil, EnableAvailabilityZonesForLoadBalancer)
+	resp, err := s.elb.EnableAvailabilityZonesForLoadBalancer("testlb", []string{"us-east-1c"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "EnableAvailabilityZonesForLoadBalancer")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("AvailabilityZones.member.1"), Equals, "us-east-1c")
+	c.Assert(resp.AvailZones, DeepEquals, []string{"us-east-1a", "us-east-1c"})
+}
+
+func (s *S) TestDisableAvailabilityZonesForLoadBalancer(c *C) {
+	testServer.PrepareResponse(200, nil, DisableAvailabilityZonesForLoadBalancer)
+	resp, err := s.elb.DisableAvailabilityZonesForLoadBalancer("testlb", []string{"us-east-1c"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DisableAvailabilityZonesForLoadBalancer")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("AvailabilityZones.member.1"), Equals, "us-east-1c")
+	c.Assert(resp.AvailZones, DeepEquals, []string{"us-east-1a"})
+}
+
+func (s *S) TestCreateLBCookieStickinessPolicy(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLBCookieStickinessPolicy)
+	resp, err := s.elb.CreateLBCookieStickinessPolicy("testlb", "MyLoadBalancerCookiePolicy", 60)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateLBCookieStickinessPolicy")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("PolicyName"), Equals, "MyLoadBalancerCookiePolicy")
+	c.Assert(values.Get("CookieExpirationPeriod"), Equals, "60")
+	c.Assert(resp.RequestId, Equals, "99a693e9-12b8-11e3-9ad6-bf3e4EXAMPLE")
+}
+
+func (s *S) TestCreateLBCookieStickinessPolicyWithoutExpiration(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLBCookieStickinessPolicy)
+	_, err := s.elb.CreateLBCookieStickinessPolicy("testlb", "MyLoadBalancerCookiePolicy", 0)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	_, ok := values["CookieExpirationPeriod"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) TestCreateAppCookieStickinessPolicy(c *C) {
+	testServer.PrepareResponse(200, nil, CreateAppCookieStickinessPolicy)
+	_, err := s.elb.CreateAppCookieStickinessPolicy("testlb", "MyAppCookiePolicy", "MyAppCookie")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateAppCookieStickinessPolicy")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("PolicyName"), Equals, "MyAppCookiePolicy")
+	c.Assert(values.Get("CookieName"), Equals, "MyAppCookie")
+}
+
+func (s *S) TestDeleteLoadBalancerPolicy(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancerPolicy)
+	_, err := s.elb.DeleteLoadBalancerPolicy("testlb", "MyAppCookiePolicy")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancerPolicy")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("PolicyName"), Equals, "MyAppCookiePolicy")
+}
+
+func (s *S) TestSetLoadBalancerPoliciesOfListener(c *C) {
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesOfListener)
+	_, err := s.elb.SetLoadBalancerPoliciesOfListener("testlb", 80, []string{"MyAppCookiePolicy"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "SetLoadBalancerPoliciesOfListener")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("LoadBalancerPort"), Equals, "80")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "MyAppCookiePolicy")
+}
+
+func (s *S) TestSetLoadBalancerPoliciesOfListenerClearingPolicies(c *C) {
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesOfListener)
+	_, err := s.elb.SetLoadBalancerPoliciesOfListener("testlb", 80, nil)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	policyNames, ok := values["PolicyNames"]
+	c.Assert(ok, Equals, true)
+	c.Assert(policyNames, DeepEquals, []string{""})
+}
+
+func (s *S) TestCreateLoadBalancerPolicy(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLoadBalancerPolicy)
+	attrs := []elb.PolicyAttribute{{AttributeName: "ProxyProtocol", AttributeValue: "true"}}
+	_, err := s.elb.CreateLoadBalancerPolicy("testlb", "EnableProxyProtocol", "ProxyProtocolPolicyType", attrs)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateLoadBalancerPolicy")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("PolicyName"), Equals, "EnableProxyProtocol")
+	c.Assert(values.Get("PolicyTypeName"), Equals, "ProxyProtocolPolicyType")
+	c.Assert(values.Get("PolicyAttributes.member.1.AttributeName"), Equals, "ProxyProtocol")
+	c.Assert(values.Get("PolicyAttributes.member.1.AttributeValue"), Equals, "true")
+}
+
+func (s *S) TestDescribeLoadBalancerPolicies(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancerPolicies)
+	resp, err := s.elb.DescribeLoadBalancerPolicies("testlb", "EnableProxyProtocol")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancerPolicies")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "EnableProxyProtocol")
+	expected := []elb.PolicyDescription{
+		{
+			PolicyName:     "EnableProxyProtocol",
+			PolicyTypeName: "ProxyProtocolPolicyType",
+			PolicyAttributeDescriptions: []elb.PolicyAttributeDescription{
+				{AttributeName: "ProxyProtocol", AttributeValue: "true"},
+			},
+		},
+	}
+	c.Assert(resp.PolicyDescriptions, DeepEquals, expected)
+}
+
+func (s *S) TestDescribeLoadBalancerPolicyTypes(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancerPolicyTypes)
+	resp, err := s.elb.DescribeLoadBalancerPolicyTypes("ProxyProtocolPolicyType")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancerPolicyTypes")
+	c.Assert(values.Get("PolicyTypeNames.member.1"), Equals, "ProxyProtocolPolicyType")
+	c.Assert(resp.PolicyTypeDescriptions, HasLen, 1)
+	desc := resp.PolicyTypeDescriptions[0]
+	c.Assert(desc.PolicyTypeName, Equals, "ProxyProtocolPolicyType")
+	c.Assert(desc.PolicyAttributeTypeDescriptions, DeepEquals, []elb.PolicyAttributeTypeDescription{
+		{AttributeName: "ProxyProtocol", AttributeType: "Boolean", Cardinality: "ONE"},
+	})
+}
+
+func (s *S) TestSetLoadBalancerPoliciesForBackendServer(c *C) {
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesForBackendServer)
+	_, err := s.elb.SetLoadBalancerPoliciesForBackendServer("testlb", 80, []string{"EnableProxyProtocol"})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "SetLoadBalancerPoliciesForBackendServer")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(values.Get("InstancePort"), Equals, "80")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "EnableProxyProtocol")
+}
+
+func (s *S) TestEnableProxyProtocol(c *C) {
+	testServer.PrepareResponse(400, nil, CreateLoadBalancerPolicyDuplicate)
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancersVPC)
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesForBackendServer)
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesForBackendServer)
+	err := s.elb.EnableProxyProtocol("vpclb", []int{443, 8080})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateLoadBalancerPolicy")
+	c.Assert(values.Get("PolicyName"), Equals, elb.ProxyProtocolPolicyName)
+	c.Assert(values.Get("PolicyTypeName"), Equals, "ProxyProtocolPolicyType")
+	c.Assert(values.Get("PolicyAttributes.member.1.AttributeName"), Equals, "ProxyProtocol")
+	c.Assert(values.Get("PolicyAttributes.member.1.AttributeValue"), Equals, "true")
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("InstancePort"), Equals, "443")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "my-backend-policy")
+	c.Assert(values.Get("PolicyNames.member.2"), Equals, elb.ProxyProtocolPolicyName)
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("InstancePort"), Equals, "8080")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, elb.ProxyProtocolPolicyName)
+	c.Assert(values.Get("PolicyNames.member.2"), Equals, "")
+}
+
+func (s *S) TestDisableProxyProtocol(c *C) {
+	enabled := strings.Replace(DescribeLoadBalancersVPC,
+		"<member>my-backend-policy</member>",
+		"<member>my-backend-policy</member><member>EnableProxyProtocol</member>", -1)
+	testServer.PrepareResponse(200, nil, enabled)
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesForBackendServer)
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancerPolicy)
+	err := s.elb.DisableProxyProtocol("vpclb", []int{443, 8080})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "SetLoadBalancerPoliciesForBackendServer")
+	c.Assert(values.Get("InstancePort"), Equals, "443")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "my-backend-policy")
+	c.Assert(values.Get("PolicyNames.member.2"), Equals, "")
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancerPolicy")
+	c.Assert(values.Get("PolicyName"), Equals, elb.ProxyProtocolPolicyName)
+}
+
+func (s *S) TestPredefinedSSLPolicies(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancerPoliciesSSL)
+	names, err := s.elb.PredefinedSSLPolicies()
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancerPolicies")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "")
+	c.Assert(names, DeepEquals, []string{elb.SecurityPolicy2016, elb.SecurityPolicyTLS12})
+}
+
+func (s *S) TestSetListenerSSLPolicy(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancersVPC)
+	testServer.PrepareResponse(200, nil, CreateLoadBalancerPolicy)
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancerPoliciesSSL)
+	testServer.PrepareResponse(200, nil, SetLoadBalancerPoliciesOfListener)
+	err := s.elb.SetListenerSSLPolicy("vpclb", 80, elb.SecurityPolicyTLS12)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateLoadBalancerPolicy")
+	c.Assert(values.Get("PolicyName"), Equals, elb.SecurityPolicyTLS12)
+	c.Assert(values.Get("PolicyTypeName"), Equals, elb.SSLNegotiationPolicyType)
+	c.Assert(values.Get("PolicyAttributes.member.1.AttributeName"), Equals, "Reference-Security-Policy")
+	c.Assert(values.Get("PolicyAttributes.member.1.AttributeValue"), Equals, elb.SecurityPolicyTLS12)
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancerPolicies")
+	c.Assert(values.Get("LoadBalancerName"), Equals, "vpclb")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "my-cookie-policy")
+	values = testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "SetLoadBalancerPoliciesOfListener")
+	c.Assert(values.Get("LoadBalancerPort"), Equals, "80")
+	c.Assert(values.Get("PolicyNames.member.1"), Equals, "ELBSample-LBCookieStickinessPolicy")
+	c.Assert(values.Get("PolicyNames.member.2"), Equals, elb.SecurityPolicyTLS12)
+	c.Assert(values.Get("PolicyNames.member.3"), Equals, "")
+}
+
+func (s *S) TestSetListenerSSLPolicyListenerNotFound(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancersVPC)
+	err := s.elb.SetListenerSSLPolicy("vpclb", 443, elb.SecurityPolicyTLS12)
+	c.Assert(err, ErrorMatches, "elb: Load Balancer vpclb has no listener on port 443")
+	testServer.WaitRequest()
+}
+
+func (s *S) TestDescribeLoadBalancersWithContext(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	resp, err := s.elb.DescribeLoadBalancersWithContext(context.Background(), "testlb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "testlb")
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+}
+
+func (s *S) TestOperationWithCanceledContext(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err := s.elb.DeleteLoadBalancerWithContext(ctx, "testlb")
+	c.Assert(resp, IsNil)
+	c.Assert(err, NotNil)
+	c.Assert(errors.Is(err, context.Canceled), Equals, true)
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *S) TestWithHTTPClient(c *C) {
+	transport := &recordingTransport{}
+	client := elb.New(s.elb.Auth, s.elb.Region, elb.WithHTTPClient(&http.Client{Transport: transport}))
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	_, err := client.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	c.Assert(transport.requests, HasLen, 1)
+	c.Assert(transport.requests[0].URL.Query().Get("Action"), Equals, "DeleteLoadBalancer")
+}
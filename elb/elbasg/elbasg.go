@@ -0,0 +1,66 @@
+// This package attaches classic Load Balancers to Auto Scaling groups,
+// implementing the elb.AutoScaling interface on top of the Auto Scaling
+// Query API. The client is an elb.ELB sending its requests to Auto
+// Scaling, and accepts the same options.
+package elbasg
+
+import (
+	"context"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+)
+
+const apiVersion = "2011-01-01"
+
+type AutoScaling struct {
+	elb *elb.ELB
+}
+
+var _ elb.AutoScaling = (*AutoScaling)(nil)
+
+// New creates a new Auto Scaling client for the given region.
+//
+// Requests are sent to the Auto Scaling endpoint of the region, unless
+// elb.WithEndpoint says otherwise, and are always signed with Signature
+// Version 4.
+func New(auth aws.Auth, region aws.Region, options ...elb.Option) *AutoScaling {
+	options = append([]elb.Option{elb.WithEndpoint(elb.ServiceEndpoint(region.Name, "autoscaling"))}, options...)
+	options = append(options, elb.WithSignatureVersion(elb.SignatureV4), elb.WithSigningName("autoscaling"))
+	return &AutoScaling{elb: elb.New(auth, region, options...)}
+}
+
+type loadBalancersRequest struct {
+	AutoScalingGroupName string
+	LoadBalancerNames    []string `elb:"LoadBalancerNames.member"`
+}
+
+type SimpleResp struct {
+	RequestId string `xml:"ResponseMetadata>RequestId"`
+}
+
+func (as *AutoScaling) call(ctx context.Context, action string, req, resp interface{}) error {
+	params := map[string]string{"Action": action}
+	if err := elb.EncodeParams(params, req); err != nil {
+		return err
+	}
+	return as.elb.Query(ctx, apiVersion, params, resp)
+}
+
+// AttachLoadBalancers attaches classic Load Balancers to the Auto Scaling
+// group. At most 10 Load Balancers may be attached by a single call, see
+// elb.AttachToAutoScalingGroup for more.
+//
+// See http://docs.aws.amazon.com/autoscaling/ec2/APIReference/API_AttachLoadBalancers.html
+// for more details.
+func (as *AutoScaling) AttachLoadBalancers(ctx context.Context, groupName string, lbNames []string) error {
+	return as.call(ctx, "AttachLoadBalancers", &loadBalancersRequest{groupName, lbNames}, new(SimpleResp))
+}
+
+// DetachLoadBalancers detaches classic Load Balancers from the Auto
+// Scaling group.
+//
+// See http://docs.aws.amazon.com/autoscaling/ec2/APIReference/API_DetachLoadBalancers.html
+// for more details.
+func (as *AutoScaling) DetachLoadBalancers(ctx context.Context, groupName string, lbNames []string) error {
+	return as.call(ctx, "DetachLoadBalancers", &loadBalancersRequest{groupName, lbNames}, new(SimpleResp))
+}
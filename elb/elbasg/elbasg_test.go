@@ -0,0 +1,53 @@
+package elbasg_test
+
+import (
+	"context"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elb/elbasg"
+	. "launchpad.net/gocheck"
+	"strings"
+)
+
+type S struct {
+	HTTPSuite
+	as *elbasg.AutoScaling
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpSuite(c *C) {
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	s.as = elbasg.New(auth, aws.USEast, elb.WithEndpoint(testServer.URL), noRetry)
+}
+
+func (s *S) TestAttachLoadBalancers(c *C) {
+	testServer.PrepareResponse(200, nil, AttachLoadBalancers)
+	err := s.as.AttachLoadBalancers(context.Background(), "web", []string{"weblb", "adminlb"})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	values := req.URL.Query()
+	c.Assert(values.Get("Action"), Equals, "AttachLoadBalancers")
+	c.Assert(values.Get("Version"), Equals, "2011-01-01")
+	c.Assert(values.Get("AutoScalingGroupName"), Equals, "web")
+	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "weblb")
+	c.Assert(values.Get("LoadBalancerNames.member.2"), Equals, "adminlb")
+	c.Assert(strings.Contains(req.Header.Get("Authorization"), "/us-east-1/autoscaling/aws4_request"), Equals, true)
+}
+
+func (s *S) TestDetachLoadBalancers(c *C) {
+	testServer.PrepareResponse(200, nil, DetachLoadBalancers)
+	err := elb.DetachFromAutoScalingGroup(context.Background(), s.as, "web", "weblb")
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DetachLoadBalancers")
+	c.Assert(values.Get("LoadBalancerNames.member.1"), Equals, "weblb")
+}
+
+func (s *S) TestAttachLoadBalancersError(c *C) {
+	testServer.PrepareResponse(400, nil, ValidationError)
+	err := s.as.AttachLoadBalancers(context.Background(), "absent", []string{"weblb"})
+	testServer.WaitRequest()
+	c.Assert(elb.ErrorCode(err), Equals, "ValidationError")
+}
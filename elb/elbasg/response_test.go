@@ -0,0 +1,30 @@
+package elbasg_test
+
+var AttachLoadBalancers = `
+<AttachLoadBalancersResponse xmlns="http://autoscaling.amazonaws.com/doc/2011-01-01/">
+  <AttachLoadBalancersResult/>
+  <ResponseMetadata>
+    <RequestId>e5f6a7b8-c4a8-11e2-a7a8-f1ebd5a7f0f1</RequestId>
+  </ResponseMetadata>
+</AttachLoadBalancersResponse>
+`
+
+var DetachLoadBalancers = `
+<DetachLoadBalancersResponse xmlns="http://autoscaling.amazonaws.com/doc/2011-01-01/">
+  <DetachLoadBalancersResult/>
+  <ResponseMetadata>
+    <RequestId>f6a7b8c9-c4a8-11e2-a7a8-f1ebd5a7f0f1</RequestId>
+  </ResponseMetadata>
+</DetachLoadBalancersResponse>
+`
+
+var ValidationError = `
+<ErrorResponse xmlns="http://autoscaling.amazonaws.com/doc/2011-01-01/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>ValidationError</Code>
+    <Message>AutoScalingGroup name not found - null</Message>
+  </Error>
+  <RequestId>a7b8c9d0-c4a8-11e2-a7a8-f1ebd5a7f0f1</RequestId>
+</ErrorResponse>
+`
@@ -0,0 +1,199 @@
+// This package reads the CloudWatch metrics that Elastic Load Balancing
+// publishes for classic Load Balancers, in the AWS/ELB namespace.
+//
+// Requests go through the transport of the elb package: credentials,
+// retries and the HTTP client are configured with the same elb.Option
+// values.
+package elbcw
+
+import (
+	"context"
+	"fmt"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"sort"
+	"time"
+)
+
+const apiVersion = "2010-08-01"
+
+// Namespace is the CloudWatch namespace of the classic ELB metrics.
+const Namespace = "AWS/ELB"
+
+// Names of the standard ELB metrics.
+const (
+	RequestCount       = "RequestCount"
+	Latency            = "Latency"
+	HTTPCodeBackend2XX = "HTTPCode_Backend_2XX"
+	HTTPCodeBackend4XX = "HTTPCode_Backend_4XX"
+	HTTPCodeBackend5XX = "HTTPCode_Backend_5XX"
+	HTTPCodeELB5XX     = "HTTPCode_ELB_5XX"
+	HealthyHostCount   = "HealthyHostCount"
+	UnHealthyHostCount = "UnHealthyHostCount"
+	SurgeQueueLength   = "SurgeQueueLength"
+	SpilloverCount     = "SpilloverCount"
+)
+
+// Statistics computed by CloudWatch over each period.
+const (
+	SampleCount = "SampleCount"
+	Average     = "Average"
+	Sum         = "Sum"
+	Minimum     = "Minimum"
+	Maximum     = "Maximum"
+)
+
+// DefaultStatistics maps the standard ELB metrics to the statistic that is
+// meaningful for them: counts are summed, while latency and host counts
+// are averaged.
+var DefaultStatistics = map[string]string{
+	RequestCount:       Sum,
+	Latency:            Average,
+	HTTPCodeBackend2XX: Sum,
+	HTTPCodeBackend4XX: Sum,
+	HTTPCodeBackend5XX: Sum,
+	HTTPCodeELB5XX:     Sum,
+	HealthyHostCount:   Average,
+	UnHealthyHostCount: Average,
+	SurgeQueueLength:   Maximum,
+	SpilloverCount:     Sum,
+}
+
+type CloudWatch struct {
+	elb *elb.ELB
+}
+
+// New creates a new CloudWatch client for the given region.
+//
+// Requests are sent to the CloudWatch endpoint of the region, unless
+// elb.WithEndpoint says otherwise, and are always signed with Signature
+// Version 4.
+func New(auth aws.Auth, region aws.Region, options ...elb.Option) *CloudWatch {
+	options = append([]elb.Option{elb.WithEndpoint(elb.ServiceEndpoint(region.Name, "monitoring"))}, options...)
+	options = append(options, elb.WithSignatureVersion(elb.SignatureV4), elb.WithSigningName("monitoring"))
+	return &CloudWatch{elb: elb.New(auth, region, options...)}
+}
+
+// Datapoint holds the statistics of a metric over one period. Only the
+// statistics requested are set.
+type Datapoint struct {
+	Timestamp   time.Time `xml:"Timestamp"`
+	SampleCount float64   `xml:"SampleCount"`
+	Average     float64   `xml:"Average"`
+	Sum         float64   `xml:"Sum"`
+	Minimum     float64   `xml:"Minimum"`
+	Maximum     float64   `xml:"Maximum"`
+	Unit        string    `xml:"Unit"`
+}
+
+// Value returns the given statistic of the datapoint.
+func (d Datapoint) Value(statistic string) float64 {
+	switch statistic {
+	case SampleCount:
+		return d.SampleCount
+	case Average:
+		return d.Average
+	case Sum:
+		return d.Sum
+	case Minimum:
+		return d.Minimum
+	case Maximum:
+		return d.Maximum
+	}
+	return 0
+}
+
+// The GetMetricStatistics type encapsulates options for the respective
+// request in AWS.
+//
+// See http://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_GetMetricStatistics.html
+// for more details.
+type GetMetricStatistics struct {
+	LoadBalancerName string
+	MetricName       string
+	StartTime        time.Time
+	EndTime          time.Time
+	// Period is rounded down to seconds, and must be a multiple of 60
+	// seconds.
+	Period     time.Duration
+	Statistics []string
+	Unit       string
+}
+
+type GetMetricStatisticsResp struct {
+	Label      string      `xml:"GetMetricStatisticsResult>Label"`
+	Datapoints []Datapoint `xml:"GetMetricStatisticsResult>Datapoints>member"`
+	RequestId  string      `xml:"ResponseMetadata>RequestId"`
+}
+
+type dimension struct {
+	Name  string
+	Value string
+}
+
+// Get statistics of a metric of a Load Balancer. Datapoints are sorted by
+// Timestamp, CloudWatch returns them in no particular order.
+//
+// See http://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_GetMetricStatistics.html
+// for more details.
+func (cw *CloudWatch) GetMetricStatistics(options *GetMetricStatistics) (*GetMetricStatisticsResp, error) {
+	return cw.GetMetricStatisticsWithContext(context.Background(), options)
+}
+
+// GetMetricStatisticsWithContext is like GetMetricStatistics, but the
+// request is bound to ctx.
+func (cw *CloudWatch) GetMetricStatisticsWithContext(ctx context.Context, options *GetMetricStatistics) (*GetMetricStatisticsResp, error) {
+	req := struct {
+		Namespace  string
+		MetricName string
+		Dimensions []dimension `elb:"Dimensions.member"`
+		StartTime  time.Time
+		EndTime    time.Time
+		Period     int
+		Statistics []string `elb:"Statistics.member"`
+		Unit       string   `elb:",omitempty"`
+	}{
+		Namespace:  Namespace,
+		MetricName: options.MetricName,
+		Dimensions: []dimension{{"LoadBalancerName", options.LoadBalancerName}},
+		StartTime:  options.StartTime,
+		EndTime:    options.EndTime,
+		Period:     int(options.Period / time.Second),
+		Statistics: options.Statistics,
+		Unit:       options.Unit,
+	}
+	params := map[string]string{"Action": "GetMetricStatistics"}
+	if err := elb.EncodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	resp := new(GetMetricStatisticsResp)
+	if err := cw.elb.Query(ctx, apiVersion, params, resp); err != nil {
+		return nil, err
+	}
+	sort.Slice(resp.Datapoints, func(i, j int) bool {
+		return resp.Datapoints[i].Timestamp.Before(resp.Datapoints[j].Timestamp)
+	})
+	return resp, nil
+}
+
+// Metric returns the datapoints of one of the standard ELB metrics of a
+// Load Balancer between start and end, computed with the statistic of the
+// metric in DefaultStatistics.
+func (cw *CloudWatch) Metric(ctx context.Context, lbName, metric string, start, end time.Time, period time.Duration) ([]Datapoint, error) {
+	statistic, ok := DefaultStatistics[metric]
+	if !ok {
+		return nil, fmt.Errorf("elbcw: no default statistic for metric %s", metric)
+	}
+	resp, err := cw.GetMetricStatisticsWithContext(ctx, &GetMetricStatistics{
+		LoadBalancerName: lbName,
+		MetricName:       metric,
+		StartTime:        start,
+		EndTime:          end,
+		Period:           period,
+		Statistics:       []string{statistic},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Datapoints, nil
+}
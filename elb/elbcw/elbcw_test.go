@@ -0,0 +1,91 @@
+package elbcw_test
+
+import (
+	"context"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elb/elbcw"
+	. "launchpad.net/gocheck"
+	"strings"
+	"time"
+)
+
+type S struct {
+	HTTPSuite
+	cw *elbcw.CloudWatch
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpSuite(c *C) {
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	s.cw = elbcw.New(auth, aws.USEast, elb.WithEndpoint(testServer.URL), noRetry)
+}
+
+func (s *S) TestGetMetricStatistics(c *C) {
+	testServer.PrepareResponse(200, nil, GetMetricStatistics)
+	start := time.Date(2013, 5, 24, 21, 0, 0, 0, time.UTC)
+	resp, err := s.cw.GetMetricStatistics(&elbcw.GetMetricStatistics{
+		LoadBalancerName: "testlb",
+		MetricName:       elbcw.RequestCount,
+		StartTime:        start,
+		EndTime:          start.Add(10 * time.Minute),
+		Period:           5 * time.Minute,
+		Statistics:       []string{elbcw.Sum},
+	})
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	values := req.URL.Query()
+	c.Assert(values.Get("Action"), Equals, "GetMetricStatistics")
+	c.Assert(values.Get("Version"), Equals, "2010-08-01")
+	c.Assert(values.Get("Namespace"), Equals, "AWS/ELB")
+	c.Assert(values.Get("MetricName"), Equals, "RequestCount")
+	c.Assert(values.Get("Dimensions.member.1.Name"), Equals, "LoadBalancerName")
+	c.Assert(values.Get("Dimensions.member.1.Value"), Equals, "testlb")
+	c.Assert(values.Get("StartTime"), Equals, "2013-05-24T21:00:00Z")
+	c.Assert(values.Get("EndTime"), Equals, "2013-05-24T21:10:00Z")
+	c.Assert(values.Get("Period"), Equals, "300")
+	c.Assert(values.Get("Statistics.member.1"), Equals, "Sum")
+	c.Assert(values.Get("Unit"), Equals, "")
+	c.Assert(strings.Contains(req.Header.Get("Authorization"), "/us-east-1/monitoring/aws4_request"), Equals, true)
+	c.Assert(resp.Label, Equals, "RequestCount")
+	c.Assert(resp.RequestId, Equals, "a1b2c3d4-c4a8-11e2-a7a8-f1ebd5a7f0f1")
+	c.Assert(resp.Datapoints, DeepEquals, []elbcw.Datapoint{
+		{Timestamp: start, Sum: 1157, Unit: "Count"},
+		{Timestamp: start.Add(5 * time.Minute), Sum: 1320, Unit: "Count"},
+	})
+	c.Assert(resp.Datapoints[0].Value(elbcw.Sum), Equals, 1157.0)
+}
+
+func (s *S) TestMetric(c *C) {
+	testServer.PrepareResponse(200, nil, GetMetricStatistics)
+	start := time.Date(2013, 5, 24, 21, 0, 0, 0, time.UTC)
+	datapoints, err := s.cw.Metric(context.Background(), "testlb", elbcw.HealthyHostCount, start, start.Add(time.Hour), time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(datapoints, HasLen, 2)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("MetricName"), Equals, "HealthyHostCount")
+	c.Assert(values.Get("Statistics.member.1"), Equals, "Average")
+	c.Assert(values.Get("Period"), Equals, "60")
+}
+
+func (s *S) TestMetricUnknown(c *C) {
+	_, err := s.cw.Metric(context.Background(), "testlb", "BackendConnectionErrors", time.Now().Add(-time.Hour), time.Now(), time.Minute)
+	c.Assert(err, ErrorMatches, "elbcw: no default statistic for metric BackendConnectionErrors")
+}
+
+func (s *S) TestGetMetricStatisticsError(c *C) {
+	testServer.PrepareResponse(400, nil, InvalidParameterCombination)
+	_, err := s.cw.GetMetricStatistics(&elbcw.GetMetricStatistics{
+		LoadBalancerName: "testlb",
+		MetricName:       elbcw.Latency,
+		StartTime:        time.Now().Add(-48 * time.Hour),
+		EndTime:          time.Now(),
+		Period:           time.Minute,
+		Statistics:       []string{elbcw.Average, elbcw.Maximum},
+	})
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Statistics.member.2"), Equals, "Maximum")
+	c.Assert(elb.ErrorCode(err), Equals, "InvalidParameterCombination")
+}
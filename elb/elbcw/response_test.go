@@ -0,0 +1,35 @@
+package elbcw_test
+
+var GetMetricStatistics = `
+<GetMetricStatisticsResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/">
+  <GetMetricStatisticsResult>
+    <Datapoints>
+      <member>
+        <Timestamp>2013-05-24T21:05:00Z</Timestamp>
+        <Sum>1320.0</Sum>
+        <Unit>Count</Unit>
+      </member>
+      <member>
+        <Timestamp>2013-05-24T21:00:00Z</Timestamp>
+        <Sum>1157.0</Sum>
+        <Unit>Count</Unit>
+      </member>
+    </Datapoints>
+    <Label>RequestCount</Label>
+  </GetMetricStatisticsResult>
+  <ResponseMetadata>
+    <RequestId>a1b2c3d4-c4a8-11e2-a7a8-f1ebd5a7f0f1</RequestId>
+  </ResponseMetadata>
+</GetMetricStatisticsResponse>
+`
+
+var InvalidParameterCombination = `
+<ErrorResponse xmlns="http://monitoring.amazonaws.com/doc/2010-08-01/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>InvalidParameterCombination</Code>
+    <Message>You have requested up to 1,440 datapoints, which exceeds the limit of 1,440.</Message>
+  </Error>
+  <RequestId>b2c3d4e5-c4a8-11e2-a7a8-f1ebd5a7f0f1</RequestId>
+</ErrorResponse>
+`
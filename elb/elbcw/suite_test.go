@@ -0,0 +1,21 @@
+package elbcw_test
+
+import (
+	"github.com/flaviamissi/go-elb/internal/querytest"
+	. "launchpad.net/gocheck"
+	"testing"
+	"time"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var testServer = querytest.NewServer(5 * time.Second)
+
+// HTTPSuite discards the requests a test left to testServer.
+type HTTPSuite struct{}
+
+func (s *HTTPSuite) TearDownTest(c *C) {
+	testServer.FlushRequests()
+}
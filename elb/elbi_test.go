@@ -209,7 +209,7 @@ func (s *ClientTests) TestDescribeInstanceHealth(c *C) {
 	c.Assert(len(resp.InstanceStates) > 0, Equals, true)
 	c.Assert(resp.InstanceStates[0].Description, Equals, "Instance is in pending state.")
 	c.Assert(resp.InstanceStates[0].InstanceId, Equals, instId)
-	c.Assert(resp.InstanceStates[0].State, Equals, "OutOfService")
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
 	c.Assert(resp.InstanceStates[0].ReasonCode, Equals, "Instance")
 }
 
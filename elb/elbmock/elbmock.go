@@ -0,0 +1,69 @@
+// This package provides a mock of elb.API, for unit testing code that
+// depends on the client without running a fake server like the one of the
+// elbtest package.
+//
+// Each method of ELB records its call and then calls the function in the
+// field of the same name followed by Func. When the field is nil, the
+// method returns zero values:
+//
+//	m := &elbmock.ELB{}
+//	m.DescribeLoadBalancersFunc = func(names ...string) (*elb.DescribeLoadBalancerResp, error) {
+//		return nil, &elb.Error{Code: elb.ErrLoadBalancerNotFound}
+//	}
+//	run(m)
+//	calls := m.CallsTo("DescribeLoadBalancers")
+//
+// The mock is generated from the elb.API interface by gen.go.
+package elbmock
+
+//go:generate go run gen.go
+
+import (
+	"sync"
+)
+
+// Call is a call to a method of the mock, with its arguments. Variadic
+// arguments are recorded as a single slice.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder records the calls to the mock. It's safe for concurrent use.
+type recorder struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made so far, in order.
+func (r *recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made so far to the given method, in order.
+func (r *recorder) CallsTo(method string) []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var calls []Call
+	for _, call := range r.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// ResetCalls forgets the calls made so far.
+func (r *recorder) ResetCalls() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = nil
+}
@@ -0,0 +1,70 @@
+package elbmock_test
+
+import (
+	"context"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elb/elbmock"
+	. "launchpad.net/gocheck"
+	"testing"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+// dnsName is an example of code depending on elb.API.
+func dnsName(api elb.API, name string) (string, error) {
+	resp, err := api.DescribeLoadBalancersWithContext(context.Background(), name)
+	if err != nil {
+		return "", err
+	}
+	return resp.LoadBalancerDescriptions[0].DNSName, nil
+}
+
+func (s *S) TestFunc(c *C) {
+	m := &elbmock.ELB{}
+	m.DescribeLoadBalancersWithContextFunc = func(ctx context.Context, names ...string) (*elb.DescribeLoadBalancerResp, error) {
+		if names[0] != "testlb" {
+			return nil, &elb.Error{Code: elb.ErrLoadBalancerNotFound}
+		}
+		desc := elb.LoadBalancerDescription{LoadBalancerName: "testlb", DNSName: "testlb.example.com"}
+		return &elb.DescribeLoadBalancerResp{LoadBalancerDescriptions: []elb.LoadBalancerDescription{desc}}, nil
+	}
+	name, err := dnsName(m, "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "testlb.example.com")
+	_, err = dnsName(m, "absentlb")
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+}
+
+func (s *S) TestZeroValues(c *C) {
+	m := &elbmock.ELB{}
+	resp, err := m.DeleteLoadBalancer("testlb")
+	c.Assert(resp, IsNil)
+	c.Assert(err, IsNil)
+	dnsName, err := m.CreateLoadBalancerIfNotExists(&elb.CreateLoadBalancer{Name: "testlb"})
+	c.Assert(dnsName, Equals, "")
+	c.Assert(err, IsNil)
+}
+
+func (s *S) TestCalls(c *C) {
+	m := &elbmock.ELB{}
+	m.RegisterInstancesWithLoadBalancer([]string{"i-1"}, "testlb")
+	m.DescribeInstanceHealth("testlb", "i-1", "i-2")
+	m.RegisterInstancesWithLoadBalancer([]string{"i-2"}, "testlb")
+	calls := m.Calls()
+	c.Assert(calls, HasLen, 3)
+	c.Assert(calls[1], DeepEquals, elbmock.Call{
+		Method: "DescribeInstanceHealth",
+		Args:   []interface{}{"testlb", []string{"i-1", "i-2"}},
+	})
+	calls = m.CallsTo("RegisterInstancesWithLoadBalancer")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[1].Args, DeepEquals, []interface{}{[]string{"i-2"}, "testlb"})
+	m.ResetCalls()
+	c.Assert(m.Calls(), HasLen, 0)
+}
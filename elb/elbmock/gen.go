@@ -0,0 +1,190 @@
+//go:build ignore
+
+// This program generates mock.go from the elb.API interface. Run it with
+// go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../api.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	iface := findInterface(file, "API")
+	if iface == nil {
+		log.Fatal("API interface not found in ../api.go")
+	}
+	g := &generator{imports: map[string]bool{"github.com/flaviamissi/go-elb/elb": true}}
+	for _, method := range iface.Methods.List {
+		g.method(method.Names[0].Name, method.Type.(*ast.FuncType))
+	}
+	src, err := format.Source(g.file())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("mock.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == name {
+				iface, _ := ts.Type.(*ast.InterfaceType)
+				return iface
+			}
+		}
+	}
+	return nil
+}
+
+// packages holds the import paths of the packages used by the API.
+var packages = map[string]string{
+	"context": "context",
+	"time":    "time",
+}
+
+type generator struct {
+	fields  bytes.Buffer
+	methods bytes.Buffer
+	imports map[string]bool
+}
+
+// param is a parameter or result of a method.
+type param struct {
+	name string
+	typ  string
+}
+
+func (g *generator) method(name string, ft *ast.FuncType) {
+	params := g.fieldList(ft.Params, "p")
+	results := g.fieldList(ft.Results, "r")
+	variadic := len(params) > 0 && strings.HasPrefix(params[len(params)-1].typ, "...")
+	var decls, names, args []string
+	for i, p := range params {
+		decls = append(decls, p.name+" "+p.typ)
+		names = append(names, p.name)
+		args = append(args, p.name)
+		if variadic && i == len(params)-1 {
+			args[i] += "..."
+		}
+	}
+	var resultDecls, resultTypes []string
+	for _, r := range results {
+		resultDecls = append(resultDecls, r.name+" "+r.typ)
+		resultTypes = append(resultTypes, r.typ)
+	}
+	signature := fmt.Sprintf("func(%s) (%s)", strings.Join(decls, ", "), strings.Join(resultTypes, ", "))
+	fmt.Fprintf(&g.fields, "\t%sFunc %s\n", name, signature)
+
+	fmt.Fprintf(&g.methods, "\n// %s records the call and calls %sFunc, if set.\n", name, name)
+	fmt.Fprintf(&g.methods, "func (m *ELB) %s(%s) (%s) {\n", name, strings.Join(decls, ", "), strings.Join(resultDecls, ", "))
+	recordArgs := ""
+	if len(names) > 0 {
+		recordArgs = ", " + strings.Join(names, ", ")
+	}
+	fmt.Fprintf(&g.methods, "\tm.record(%q%s)\n", name, recordArgs)
+	fmt.Fprintf(&g.methods, "\tif m.%sFunc != nil {\n", name)
+	if len(results) > 0 {
+		fmt.Fprintf(&g.methods, "\t\treturn m.%sFunc(%s)\n", name, strings.Join(args, ", "))
+	} else {
+		fmt.Fprintf(&g.methods, "\t\tm.%sFunc(%s)\n", name, strings.Join(args, ", "))
+	}
+	fmt.Fprintf(&g.methods, "\t}\n\treturn\n}\n")
+}
+
+// fieldList returns the parameters or results in list, naming the ones
+// without a name with prefix and their index.
+func (g *generator) fieldList(list *ast.FieldList, prefix string) []param {
+	if list == nil {
+		return nil
+	}
+	var params []param
+	for _, field := range list.List {
+		typ := g.typeString(field.Type)
+		if len(field.Names) == 0 || prefix == "r" {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				params = append(params, param{fmt.Sprintf("%s%d", prefix, len(params)), typ})
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			params = append(params, param{name.Name, typ})
+		}
+	}
+	return params
+}
+
+// typeString returns the type expressed by expr, as seen from the elbmock
+// package.
+func (g *generator) typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return "elb." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		path, ok := packages[pkg]
+		if !ok {
+			log.Fatalf("unknown package %s", pkg)
+		}
+		g.imports[path] = true
+		return pkg + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + g.typeString(t.X)
+	case *ast.ArrayType:
+		return "[]" + g.typeString(t.Elt)
+	case *ast.Ellipsis:
+		return "..." + g.typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + g.typeString(t.Key) + "]" + g.typeString(t.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	}
+	log.Fatalf("unsupported type %T", expr)
+	return ""
+}
+
+func (g *generator) file() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go. DO NOT EDIT.\n\npackage elbmock\n\nimport (\n")
+	var paths []string
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+	buf.WriteString("// ELB is a mock of elb.API. Its zero value is ready to use.\n")
+	buf.WriteString("type ELB struct {\n\trecorder\n\n")
+	buf.Write(g.fields.Bytes())
+	buf.WriteString("}\n\nvar _ elb.API = (*ELB)(nil)\n")
+	buf.Write(g.methods.Bytes())
+	return buf.Bytes()
+}
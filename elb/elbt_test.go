@@ -1,10 +1,22 @@
 package elb_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/flaviamissi/go-elb/aws"
 	"github.com/flaviamissi/go-elb/elb"
 	"github.com/flaviamissi/go-elb/elb/elbtest"
+	"io/ioutil"
 	. "launchpad.net/gocheck"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // LocalServer represents a local elbtest fake server.
@@ -146,6 +158,410 @@ func (s *LocalServerSuite) TestRegisterInstanceWithLoadBalancerWithAbsentLoadBal
 	c.Assert(resp, IsNil)
 }
 
+func (s *LocalServerSuite) TestRegisterInstancesInBatches(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id := srv.NewInstance()
+		defer srv.RemoveInstance(id)
+		ids = append(ids, id)
+	}
+	ids = append(ids[:2], append([]string{"i-212"}, ids[2:]...)...)
+	srv.ResetHistory()
+	report := s.clientTests.elb.RegisterInstancesInBatches("testlb", ids, &elb.BatchOptions{BatchSize: 2})
+	c.Assert(srv.RequestsFor("RegisterInstancesWithLoadBalancer"), HasLen, 3)
+	c.Assert(report.Results, HasLen, 6)
+	for i, result := range report.Results {
+		c.Assert(result.InstanceId, Equals, ids[i])
+	}
+	failed := report.Failed()
+	c.Assert(failed, HasLen, 2)
+	c.Assert(failed[0].InstanceId, Equals, "i-212")
+	c.Assert(failed[1].InstanceId, Equals, ids[3])
+	c.Assert(elb.ErrorCode(failed[0].Err), Equals, elb.ErrInvalidInstance)
+	c.Assert(report.Err(), ErrorMatches, "elb: 2 of 6 instances not registered, .*")
+	health, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(health.InstanceStates, HasLen, 4)
+}
+
+func (s *LocalServerSuite) TestEnsureLoadBalancer(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	spec := &elb.LoadBalancerSpec{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners: []elb.Listener{
+			{InstancePort: 8080, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"},
+		},
+		HealthCheck: &elb.HealthCheck{
+			HealthyThreshold:   2,
+			Interval:           10,
+			Target:             "HTTP:8080/health",
+			Timeout:            5,
+			UnhealthyThreshold: 3,
+		},
+		Tags: []elb.Tag{{Key: "app", Value: "web"}, {Key: "env", Value: "test"}},
+	}
+	lb, err := s.clientTests.elb.EnsureLoadBalancer(spec)
+	c.Assert(err, IsNil)
+	c.Assert(lb.LoadBalancerName, Equals, "testlb")
+	c.Assert(lb.ListenerDescriptions, HasLen, 1)
+	c.Assert(lb.HealthCheck, Equals, *spec.HealthCheck)
+	s.srv.srv.ResetHistory()
+	_, err = s.clientTests.elb.EnsureLoadBalancer(spec)
+	c.Assert(err, IsNil)
+	for _, r := range s.srv.srv.Requests() {
+		c.Assert(r.Action, Matches, "Describe.*")
+	}
+	spec.AvailZones = []string{"us-east-1b"}
+	spec.Listeners = []elb.Listener{
+		{InstancePort: 8081, LoadBalancerPort: 80, Protocol: "HTTP"},
+		{InstancePort: 8443, LoadBalancerPort: 443, Protocol: "TCP"},
+	}
+	spec.HealthCheck.Target = "HTTP:8081/health"
+	spec.Tags = []elb.Tag{{Key: "app", Value: "api"}}
+	lb, err = s.clientTests.elb.EnsureLoadBalancer(spec)
+	c.Assert(err, IsNil)
+	c.Assert(lb.AvailZones, DeepEquals, []string{"us-east-1b"})
+	c.Assert(lb.ListenerDescriptions, HasLen, 2)
+	ports := map[int]int{}
+	for _, d := range lb.ListenerDescriptions {
+		ports[d.Listener.LoadBalancerPort] = d.Listener.InstancePort
+	}
+	c.Assert(ports, DeepEquals, map[int]int{80: 8081, 443: 8443})
+	c.Assert(lb.HealthCheck.Target, Equals, "HTTP:8081/health")
+	tags, err := s.clientTests.elb.DescribeTags("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(tags.TagDescriptions[0].Tags, DeepEquals, []elb.Tag{{Key: "app", Value: "api"}})
+}
+
+func (s *LocalServerSuite) TestEnsureLoadBalancerWithoutDescription(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	// DescribeLoadBalancers lags behind, as it may right after creation.
+	remove := srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		if action == "DescribeLoadBalancers" {
+			return elb.DescribeLoadBalancerResp{}, nil
+		}
+		return next(req)
+	})
+	defer remove()
+	_, err := s.clientTests.elb.EnsureLoadBalancer(&elb.LoadBalancerSpec{Name: "testlb"})
+	c.Assert(err, ErrorMatches, "elb: no description returned for Load Balancer testlb")
+}
+
+func (s *LocalServerSuite) TestEnsureLoadBalancerEmptySecurityGroups(c *C) {
+	srv := s.srv.srv
+	_, err := s.clientTests.elb.CreateLoadBalancer(&elb.CreateLoadBalancer{
+		Name:           "vpclb",
+		Subnets:        []string{"subnet-1"},
+		SecurityGroups: []string{"sg-1"},
+		Listeners:      []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+	})
+	c.Assert(err, IsNil)
+	defer srv.RemoveLoadBalancer("vpclb")
+	srv.ResetHistory()
+	lb, err := s.clientTests.elb.EnsureLoadBalancer(&elb.LoadBalancerSpec{Name: "vpclb", SecurityGroups: []string{}})
+	c.Assert(err, IsNil)
+	c.Assert(lb.SecurityGroups, DeepEquals, []string{"sg-1"})
+	for _, r := range srv.Requests() {
+		c.Assert(r.Action, Matches, "Describe.*")
+	}
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerIfNotExists(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	options := &elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a", "us-east-1b"},
+		Listeners: []elb.Listener{
+			{InstancePort: 8080, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"},
+		},
+	}
+	dnsName, err := s.clientTests.elb.CreateLoadBalancerIfNotExists(options)
+	c.Assert(err, IsNil)
+	c.Assert(dnsName, Not(Equals), "")
+	options.AvailZones = []string{"us-east-1b", "us-east-1a"}
+	options.Listeners[0].Protocol = "http"
+	again, err := s.clientTests.elb.CreateLoadBalancerIfNotExists(options)
+	c.Assert(err, IsNil)
+	c.Assert(again, Equals, dnsName)
+	s.clientTests.elb.EnableAvailabilityZonesForLoadBalancer("testlb", []string{"us-east-1c"})
+	options.Listeners[0].InstancePort = 8081
+	_, err = s.clientTests.elb.CreateLoadBalancerIfNotExists(options)
+	c.Assert(err, ErrorMatches, `elb: Load Balancer "testlb" already exists with different listeners, availability zones`)
+	conflict, ok := err.(*elb.ConflictError)
+	c.Assert(ok, Equals, true)
+	c.Assert(conflict.Changes.ListenersToAdd, DeepEquals, options.Listeners)
+	c.Assert(conflict.Changes.AvailZonesToRemove, DeepEquals, []string{"us-east-1c"})
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerIfNotExistsWithoutDescription(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	remove := srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		if action == "DescribeLoadBalancers" {
+			return elb.DescribeLoadBalancerResp{}, nil
+		}
+		return next(req)
+	})
+	defer remove()
+	_, err := s.clientTests.elb.CreateLoadBalancerIfNotExists(&elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+	})
+	c.Assert(err, ErrorMatches, "elb: no description returned for Load Balancer testlb")
+}
+
+func (s *LocalServerSuite) TestDeleteLoadBalancerSafe(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	instId := s.srv.srv.NewInstance()
+	defer s.srv.srv.RemoveInstance(instId)
+	s.srv.srv.RegisterInstance(instId, "testlb")
+	s.srv.srv.SetInstanceState("testlb", instId, elb.StateInService, "", "")
+	err := s.clientTests.elb.DeleteLoadBalancerSafe("testlb", nil)
+	c.Assert(err, ErrorMatches, `elb: Load Balancer "testlb" still has 1 instances in service`)
+	c.Assert(err.(*elb.InUseError).InstanceIds, DeepEquals, []string{instId})
+	c.Assert(s.srv.srv.LoadBalancer("testlb"), NotNil)
+	err = s.clientTests.elb.DeleteLoadBalancerSafe("testlb", &elb.DeleteOptions{Force: true})
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.srv.LoadBalancer("testlb"), IsNil)
+	err = s.clientTests.elb.DeleteLoadBalancerSafe("testlb", nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestProtectedNames(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithProtectedNames("prod-*", "testlb"))
+	s.srv.srv.ResetHistory()
+	_, err := client.DeleteLoadBalancer("testlb")
+	c.Assert(err, Equals, elb.ErrProtectedLoadBalancer)
+	err = client.DeleteLoadBalancerSafe("prod-web", &elb.DeleteOptions{Force: true})
+	c.Assert(err, Equals, elb.ErrProtectedLoadBalancer)
+	c.Assert(s.srv.srv.Requests(), HasLen, 0)
+	c.Assert(s.srv.srv.LoadBalancer("testlb"), NotNil)
+	_, err = client.DeleteLoadBalancer("staging-web")
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestReset(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	client := elb.New(s.clientTests.elb.Auth, aws.Region{ELBEndpoint: srv.URL()})
+	srv.NewLoadBalancer("testlb")
+	instId := srv.NewInstance()
+	srv.RegisterInstance(instId, "testlb")
+	srv.SetError("DescribeTags", &elb.Error{Code: "AccessDenied"}, 0)
+	_, err = client.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	srv.Reset()
+	c.Assert(srv.Requests(), HasLen, 0)
+	resp, err := client.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 0)
+	srv.NewLoadBalancer("testlb")
+	_, err = client.RegisterInstancesWithLoadBalancer([]string{instId}, "testlb")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidInstance)
+	_, err = client.DescribeTags("testlb")
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestUnknownAction(c *C) {
+	resp, err := http.Get(s.srv.srv.URL() + "/?Action=Unknown")
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 400)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(body), "Unrecognized Action"), Equals, true)
+}
+
+func (s *LocalServerSuite) TestStrictMode(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	srv.SetStrictMode(true)
+	region := aws.Region{ELBEndpoint: srv.URL()}
+	for _, v := range []elb.SignatureVersion{elb.SignatureV2, elb.SignatureV4} {
+		client := elb.New(aws.Auth{AccessKey: "key", SecretKey: "secret"}, region, elb.WithSignatureVersion(v))
+		_, err := client.DescribeLoadBalancers()
+		c.Check(err, IsNil)
+	}
+	tests := []struct {
+		query string
+		code  string
+	}{
+		{"Version=2012-06-01&SignatureVersion=2&Timestamp=" + time.Now().UTC().Format(time.RFC3339), "MissingAuthenticationToken"},
+		{"AWSAccessKeyId=key&Version=2012-06-01&SignatureVersion=1&Timestamp=" + time.Now().UTC().Format(time.RFC3339), "InvalidParameterValue"},
+		{"AWSAccessKeyId=key&SignatureVersion=2&Timestamp=" + time.Now().UTC().Format(time.RFC3339), "MissingParameter"},
+		{"AWSAccessKeyId=key&Version=2011-11-15&SignatureVersion=2&Timestamp=" + time.Now().UTC().Format(time.RFC3339), "NoSuchVersion"},
+		{"AWSAccessKeyId=key&Version=2012-06-01&SignatureVersion=2", "MissingParameter"},
+		{"AWSAccessKeyId=key&Version=2012-06-01&SignatureVersion=2&Timestamp=yesterday", "InvalidParameterValue"},
+		{"AWSAccessKeyId=key&Version=2012-06-01&SignatureVersion=2&Timestamp=2012-06-01T00:00:00Z", "RequestExpired"},
+	}
+	for _, t := range tests {
+		resp, err := http.Get(srv.URL() + "/?Action=DescribeLoadBalancers&" + t.query)
+		c.Assert(err, IsNil)
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.Assert(err, IsNil)
+		c.Check(strings.Contains(string(body), "<Code>"+t.code+"</Code>"), Equals, true, Commentf("query %s: %s", t.query, body))
+	}
+	srv.SetStrictMode(false)
+	resp, err := http.Get(srv.URL() + "/?Action=DescribeLoadBalancers")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 200)
+}
+
+func (s *LocalServerSuite) TestSignatureVerification(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	srv.SetAccessKeys(map[string]string{"key": "secret"})
+	region := aws.Region{Name: "us-west-2", ELBEndpoint: srv.URL()}
+	tests := []struct {
+		auth aws.Auth
+		code string
+	}{
+		{aws.Auth{AccessKey: "key", SecretKey: "secret"}, ""},
+		{aws.Auth{AccessKey: "key", SecretKey: "secret", Token: "token"}, ""},
+		{aws.Auth{AccessKey: "key", SecretKey: "wrong"}, "SignatureDoesNotMatch"},
+		{aws.Auth{AccessKey: "other", SecretKey: "secret"}, "InvalidClientTokenId"},
+	}
+	for _, t := range tests {
+		for _, v := range []elb.SignatureVersion{elb.SignatureV2, elb.SignatureV4} {
+			client := elb.New(t.auth, region, elb.WithSignatureVersion(v))
+			_, err := client.DescribeLoadBalancers()
+			if t.code == "" {
+				c.Check(err, IsNil, Commentf("V%d", v))
+			} else {
+				c.Check(elb.ErrorCode(err), Equals, t.code, Commentf("V%d", v))
+			}
+		}
+	}
+	resp, err := http.Get(srv.URL() + "/?Action=DescribeLoadBalancers")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, 403)
+}
+
+func (s *LocalServerSuite) TestLatency(c *C) {
+	srv := s.srv.srv
+	srv.SetLatency("DescribeLoadBalancers", 50*time.Millisecond)
+	defer srv.SetLatency("DescribeLoadBalancers", 0)
+	start := time.Now()
+	_, err := s.clientTests.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(time.Since(start) >= 50*time.Millisecond, Equals, true)
+}
+
+func (s *LocalServerSuite) TestFaults(c *C) {
+	srv := s.srv.srv
+	policy := elb.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	client := elb.New(s.clientTests.elb.Auth, s.srv.region, elb.WithRetryPolicy(policy))
+	defer srv.SetFault("DescribeLoadBalancers", elbtest.NoFault)
+
+	srv.ResetHistory()
+	srv.SetFault("DescribeLoadBalancers", elbtest.ServiceUnavailable)
+	_, err := client.DescribeLoadBalancers()
+	c.Assert(elb.IsThrottling(err), Equals, true)
+	c.Assert(err.(*elb.Error).StatusCode, Equals, 503)
+	c.Assert(srv.RequestsFor("DescribeLoadBalancers"), HasLen, 3)
+
+	srv.ResetHistory()
+	srv.SetFault("DescribeLoadBalancers", elbtest.ConnectionReset)
+	_, err = client.DescribeLoadBalancers()
+	c.Assert(err, NotNil)
+	c.Assert(elb.ErrorCode(err), Equals, "")
+	// net/http may retry a GET itself when a reused connection is reset.
+	c.Assert(len(srv.RequestsFor("DescribeLoadBalancers")) >= 3, Equals, true)
+
+	srv.SetFault("DescribeLoadBalancers", elbtest.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.DescribeLoadBalancersWithContext(ctx)
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true)
+
+	srv.SetFault("DescribeLoadBalancers", elbtest.NoFault)
+	srv.ResetHistory()
+	_, err = client.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(srv.RequestsFor("DescribeLoadBalancers"), HasLen, 1)
+}
+
+func (s *LocalServerSuite) TestConcurrentMutators(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 20; i++ {
+			s.clientTests.elb.DescribeInstanceHealth("testlb")
+		}
+		done <- true
+	}()
+	for i := 0; i < 20; i++ {
+		instId := srv.NewInstance()
+		srv.RegisterInstance(instId, "testlb")
+		srv.SetInstanceState("testlb", instId, elb.StateInService, "N/A", "N/A")
+		srv.DeregisterInstance(instId, "testlb")
+		srv.RemoveInstance(instId)
+	}
+	<-done
+}
+
+func (s *LocalServerSuite) TestMultiRegion(c *C) {
+	west, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer west.Quit()
+	east := s.srv.srv
+	east.NewLoadBalancer("testlb")
+	defer east.RemoveLoadBalancer("testlb")
+	west.NewLoadBalancer("testlb")
+	west.NewLoadBalancer("otherlb")
+	instId := west.NewInstance()
+	west.RegisterInstance(instId, "testlb")
+	regions := []aws.Region{
+		{Name: "us-west-2", ELBEndpoint: west.URL()},
+		{Name: "us-east-1", ELBEndpoint: east.URL()},
+	}
+	m := elb.NewMultiRegion(s.clientTests.elb.Auth, regions)
+	c.Assert(m.Regions(), DeepEquals, []string{"us-east-1", "us-west-2"})
+	lbs, err := m.DescribeLoadBalancers(context.Background(), "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(lbs, HasLen, 2)
+	c.Assert(lbs[0].Region, Equals, "us-east-1")
+	c.Assert(lbs[1].Region, Equals, "us-west-2")
+	c.Assert(lbs[1].LoadBalancerName, Equals, "testlb")
+	lbs, err = m.DescribeLoadBalancers(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(lbs, HasLen, 3)
+	states, err := m.DescribeInstanceHealth(context.Background(), "otherlb")
+	c.Assert(err, IsNil)
+	c.Assert(states, HasLen, 0)
+	states, err = m.DescribeInstanceHealth(context.Background(), "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(states, HasLen, 1)
+	c.Assert(states[0].Region, Equals, "us-west-2")
+	c.Assert(states[0].InstanceId, Equals, instId)
+	west.SetError("DescribeLoadBalancers", &elb.Error{Code: "AccessDenied"}, 0)
+	lbs, err = m.DescribeLoadBalancers(context.Background())
+	c.Assert(lbs, HasLen, 1)
+	mErr, ok := err.(*elb.MultiRegionError)
+	c.Assert(ok, Equals, true)
+	c.Assert(mErr.Errors, HasLen, 1)
+	c.Assert(elb.ErrorCode(mErr.Errors["us-west-2"]), Equals, "AccessDenied")
+}
+
 func (s *LocalServerSuite) TestDeregisterInstanceWithLoadBalancer(c *C) {
 	// there is no need to register the instance first, amazon returns the same response
 	// in both cases (instance registered or not)
@@ -157,6 +573,42 @@ func (s *LocalServerSuite) TestDeregisterInstanceWithLoadBalancer(c *C) {
 	resp, err := s.clientTests.elb.DeregisterInstancesFromLoadBalancer([]string{instId}, "testlb")
 	c.Assert(err, IsNil)
 	c.Assert(resp.RequestId, Not(Equals), "")
+	c.Assert(resp.InstanceIds, HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestDeregisterInstanceReturnsRemainingInstances(c *C) {
+	srv := s.srv.srv
+	inst1 := srv.NewInstance()
+	defer srv.RemoveInstance(inst1)
+	inst2 := srv.NewInstance()
+	defer srv.RemoveInstance(inst2)
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	_, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{inst1, inst2}, "testlb")
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DeregisterInstancesFromLoadBalancer([]string{inst1}, "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceIds, DeepEquals, []string{inst2})
+}
+
+func (s *LocalServerSuite) TestDeregisterAndDrain(c *C) {
+	srv := s.srv.srv
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	srv.RegisterInstance(instId, "testlb")
+	srv.SetInstanceState("testlb", instId, "InService", "N/A", "N/A")
+	err := s.clientTests.elb.EnableConnectionDraining("testlb", 30*time.Second)
+	c.Assert(err, IsNil)
+	attrs, err := s.clientTests.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(*attrs.LoadBalancerAttributes.ConnectionDraining, Equals, elb.ConnectionDraining{Enabled: true, Timeout: 30})
+	err = s.clientTests.elb.DeregisterAndDrain("testlb", []string{instId}, fastWaiter)
+	c.Assert(err, IsNil)
+	health, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(health.InstanceStates, HasLen, 0)
 }
 
 func (s *LocalServerSuite) TestDeregisterInstanceWithLoadBalancerWithAbsentLoadBalancer(c *C) {
@@ -187,7 +639,7 @@ func (s *LocalServerSuite) TestDescribeInstanceHealth(c *C) {
 	c.Assert(len(resp.InstanceStates) > 0, Equals, true)
 	c.Assert(resp.InstanceStates[0].Description, Equals, "Instance is in pending state.")
 	c.Assert(resp.InstanceStates[0].InstanceId, Equals, instId)
-	c.Assert(resp.InstanceStates[0].State, Equals, "OutOfService")
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
 	c.Assert(resp.InstanceStates[0].ReasonCode, Equals, "Instance")
 }
 
@@ -207,7 +659,7 @@ func (s *LocalServerSuite) TestDescribeInstanceHealthWithoutSpecifyingInstances(
 	c.Assert(len(resp.InstanceStates) > 0, Equals, true)
 	c.Assert(resp.InstanceStates[0].Description, Equals, "Instance is in pending state.")
 	c.Assert(resp.InstanceStates[0].InstanceId, Equals, instId)
-	c.Assert(resp.InstanceStates[0].State, Equals, "OutOfService")
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
 	c.Assert(resp.InstanceStates[0].ReasonCode, Equals, "Instance")
 }
 
@@ -230,7 +682,7 @@ func (s *LocalServerSuite) TestDescribeInstanceHealthChangingIt(c *C) {
 	c.Assert(len(resp.InstanceStates) > 0, Equals, true)
 	c.Assert(resp.InstanceStates[0].Description, Equals, "Instance has failed at least the UnhealthyThreshold number of health checks consecutively")
 	c.Assert(resp.InstanceStates[0].InstanceId, Equals, instId)
-	c.Assert(resp.InstanceStates[0].State, Equals, "OutOfService")
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
 	c.Assert(resp.InstanceStates[0].ReasonCode, Equals, "Instance")
 }
 
@@ -241,3 +693,2109 @@ func (s *LocalServerSuite) TestConfigureHealthCheck(c *C) {
 func (s *LocalServerSuite) TestConfigureHealthCheckBadRequest(c *C) {
 	s.clientTests.TestConfigureHealthCheckBadRequest(c)
 }
+
+func (s *LocalServerSuite) TestModifyAndDescribeLoadBalancerAttributes(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	resp, err := s.clientTests.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerAttributes.CrossZoneLoadBalancing.Enabled, Equals, false)
+	c.Assert(resp.LoadBalancerAttributes.ConnectionSettings.IdleTimeout, Equals, 60)
+	attrs := elb.LoadBalancerAttributes{
+		CrossZoneLoadBalancing: &elb.CrossZoneLoadBalancing{Enabled: true},
+		ConnectionDraining:     &elb.ConnectionDraining{Enabled: true, Timeout: 120},
+	}
+	_, err = s.clientTests.elb.ModifyLoadBalancerAttributes("testlb", &attrs)
+	c.Assert(err, IsNil)
+	resp, err = s.clientTests.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerAttributes.CrossZoneLoadBalancing.Enabled, Equals, true)
+	c.Assert(resp.LoadBalancerAttributes.ConnectionDraining, DeepEquals, &elb.ConnectionDraining{Enabled: true, Timeout: 120})
+	c.Assert(resp.LoadBalancerAttributes.ConnectionSettings.IdleTimeout, Equals, 60)
+}
+
+func (s *LocalServerSuite) TestSetCrossZoneLoadBalancing(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	err := s.clientTests.elb.EnableConnectionDraining("testlb", time.Minute)
+	c.Assert(err, IsNil)
+	err = s.clientTests.elb.SetCrossZoneLoadBalancing("testlb", true)
+	c.Assert(err, IsNil)
+	attrs := srv.LoadBalancer("testlb").Attributes
+	c.Assert(attrs.CrossZoneLoadBalancing.Enabled, Equals, true)
+	c.Assert(attrs.ConnectionDraining, DeepEquals, &elb.ConnectionDraining{Enabled: true, Timeout: 60})
+	err = s.clientTests.elb.SetCrossZoneLoadBalancing("testlb", false)
+	c.Assert(err, IsNil)
+	c.Assert(srv.LoadBalancer("testlb").Attributes.CrossZoneLoadBalancing.Enabled, Equals, false)
+
+	// The attribute is read back after the modification.
+	remove := srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		if action != "DescribeLoadBalancerAttributes" {
+			return next(req)
+		}
+		return elb.DescribeLoadBalancerAttributesResp{
+			LoadBalancerAttributes: elb.LoadBalancerAttributes{
+				CrossZoneLoadBalancing: &elb.CrossZoneLoadBalancing{Enabled: false},
+			},
+		}, nil
+	})
+	defer remove()
+	err = s.clientTests.elb.SetCrossZoneLoadBalancing("testlb", true)
+	c.Assert(errors.Is(err, elb.ErrAttributesNotApplied), Equals, true)
+	c.Assert(err, ErrorMatches, "elb: attributes not applied: CrossZoneLoadBalancing.Enabled of testlb isn't true")
+
+	err = s.clientTests.elb.SetCrossZoneLoadBalancing("absentlb", true)
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+}
+
+func (s *LocalServerSuite) TestModifyLoadBalancerAttributesRanges(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	tests := []struct {
+		attrs elb.LoadBalancerAttributes
+		code  string
+	}{
+		{elb.LoadBalancerAttributes{ConnectionSettings: &elb.ConnectionSettings{IdleTimeout: 4001}}, elb.ErrValidation},
+		{elb.LoadBalancerAttributes{ConnectionDraining: &elb.ConnectionDraining{Enabled: true, Timeout: 3601}}, elb.ErrValidation},
+		{elb.LoadBalancerAttributes{AccessLog: &elb.AccessLog{Enabled: true, S3BucketName: "logs", EmitInterval: 10}}, elb.ErrValidation},
+		{elb.LoadBalancerAttributes{AccessLog: &elb.AccessLog{Enabled: true}}, elb.ErrInvalidConfigurationRequest},
+	}
+	for _, t := range tests {
+		_, err := s.clientTests.elb.ModifyLoadBalancerAttributes("testlb", &t.attrs)
+		c.Check(elb.ErrorCode(err), Equals, t.code)
+	}
+	// Rejected requests leave the attributes untouched.
+	resp, err := s.clientTests.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerAttributes.ConnectionSettings.IdleTimeout, Equals, 60)
+	c.Assert(resp.LoadBalancerAttributes.ConnectionDraining.Timeout, Equals, 300)
+	c.Assert(resp.LoadBalancerAttributes.AccessLog.Enabled, Equals, false)
+	attrs := elb.LoadBalancerAttributes{
+		ConnectionSettings: &elb.ConnectionSettings{IdleTimeout: 4000},
+		ConnectionDraining: &elb.ConnectionDraining{Enabled: true, Timeout: 3600},
+		AccessLog:          &elb.AccessLog{Enabled: true, S3BucketName: "logs", EmitInterval: 5},
+	}
+	_, err = s.clientTests.elb.ModifyLoadBalancerAttributes("testlb", &attrs)
+	c.Assert(err, IsNil)
+	resp, err = s.clientTests.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerAttributes.ConnectionSettings.IdleTimeout, Equals, 4000)
+	c.Assert(resp.LoadBalancerAttributes.ConnectionDraining.Timeout, Equals, 3600)
+	c.Assert(resp.LoadBalancerAttributes.AccessLog, DeepEquals, attrs.AccessLog)
+}
+
+func (s *LocalServerSuite) TestDescribeLoadBalancerAttributesWithAbsentLoadBalancer(c *C) {
+	resp, err := s.clientTests.elb.DescribeLoadBalancerAttributes("absentlb")
+	c.Assert(resp, IsNil)
+	c.Assert(err, ErrorMatches, `^There is no ACTIVE Load Balancer named 'absentlb' \(LoadBalancerNotFound\)$`)
+}
+
+func (s *LocalServerSuite) TestAddDescribeAndRemoveTags(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	tags := []elb.Tag{{Key: "project", Value: "lima"}, {Key: "owner", Value: "ops"}}
+	_, err := s.clientTests.elb.AddTags([]string{"testlb"}, tags)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.AddTags([]string{"testlb"}, []elb.Tag{{Key: "owner", Value: "dev"}})
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeTags("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.TagDescriptions, HasLen, 1)
+	c.Assert(resp.TagDescriptions[0].Tags, DeepEquals, []elb.Tag{{Key: "project", Value: "lima"}, {Key: "owner", Value: "dev"}})
+	_, err = s.clientTests.elb.RemoveTags([]string{"testlb"}, []string{"project"})
+	c.Assert(err, IsNil)
+	resp, err = s.clientTests.elb.DescribeTags("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.TagDescriptions[0].Tags, DeepEquals, []elb.Tag{{Key: "owner", Value: "dev"}})
+}
+
+func (s *LocalServerSuite) TestAddTagsWithAbsentLoadBalancer(c *C) {
+	_, err := s.clientTests.elb.AddTags([]string{"absentlb"}, []elb.Tag{{Key: "k", Value: "v"}})
+	c.Assert(err, ErrorMatches, `^There is no ACTIVE Load Balancer named 'absentlb' \(LoadBalancerNotFound\)$`)
+}
+
+func (s *LocalServerSuite) TestAddTagsLimit(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	srv.NewLoadBalancer("otherlb")
+	defer srv.RemoveLoadBalancer("otherlb")
+	var tags []elb.Tag
+	for i := 0; i < 9; i++ {
+		tags = append(tags, elb.Tag{Key: fmt.Sprintf("key%d", i), Value: "v"})
+	}
+	_, err := s.clientTests.elb.AddTags([]string{"testlb"}, tags)
+	c.Assert(err, IsNil)
+	// Replacing the value of a tag doesn't count against the limit.
+	_, err = s.clientTests.elb.AddTags([]string{"testlb"}, []elb.Tag{{Key: "key0", Value: "w"}, {Key: "key9", Value: "v"}})
+	c.Assert(err, IsNil)
+	c.Assert(srv.Tags("testlb"), HasLen, 10)
+	_, err = s.clientTests.elb.AddTags([]string{"otherlb", "testlb"}, []elb.Tag{{Key: "key10", Value: "v"}})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrTooManyTags)
+	c.Assert(srv.Tags("testlb"), HasLen, 10)
+	c.Assert(srv.Tags("otherlb"), HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestAddTagsInvalid(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	tests := []struct {
+		tags []elb.Tag
+		code string
+	}{
+		{[]elb.Tag{{Key: "aws:owner", Value: "ops"}}, elb.ErrInvalidConfigurationRequest},
+		{[]elb.Tag{{Key: strings.Repeat("k", 129), Value: "v"}}, elb.ErrValidation},
+		{[]elb.Tag{{Key: "owner!", Value: "v"}}, elb.ErrValidation},
+		{[]elb.Tag{{Key: "owner", Value: strings.Repeat("v", 257)}}, elb.ErrValidation},
+		{[]elb.Tag{{Key: "owner", Value: "a"}, {Key: "owner", Value: "b"}}, elb.ErrDuplicateTagKeys},
+	}
+	for _, t := range tests {
+		_, err := s.clientTests.elb.AddTags([]string{"testlb"}, t.tags)
+		c.Check(elb.ErrorCode(err), Equals, t.code, Commentf("tags %v", t.tags))
+	}
+	c.Assert(srv.Tags("testlb"), HasLen, 0)
+	_, err := s.clientTests.elb.AddTags([]string{"testlb"}, []elb.Tag{{Key: "team/owner name", Value: "ops@example.com"}})
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.RemoveTags([]string{"testlb"}, []string{"aws:owner"})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+}
+
+func (s *LocalServerSuite) TestLoadScenario(c *C) {
+	srv := s.srv.srv
+	scenario := elbtest.Scenario{LoadBalancers: []elbtest.ScenarioLoadBalancer{{
+		CreateLoadBalancer: elb.CreateLoadBalancer{
+			Name:       "weblb",
+			AvailZones: []string{"us-east-1a"},
+			Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+		},
+		HealthCheck: &elb.HealthCheck{HealthyThreshold: 2, Interval: 10, Target: "HTTP:80/ping", Timeout: 5, UnhealthyThreshold: 2},
+		Instances: []elbtest.ScenarioInstance{
+			{InstanceId: "i-web1", State: elb.StateInService},
+			{InstanceId: "i-web2", State: elb.StateOutOfService, ReasonCode: "Instance", Description: "Instance has failed at least the UnhealthyThreshold number of health checks consecutively."},
+		},
+		Tags:       []elb.Tag{{Key: "env", Value: "prod"}},
+		Attributes: elb.LoadBalancerAttributes{ConnectionSettings: &elb.ConnectionSettings{IdleTimeout: 120}},
+	}}}
+	err := srv.LoadScenario(scenario)
+	c.Assert(err, IsNil)
+	defer srv.RemoveLoadBalancer("weblb")
+	resp, err := s.clientTests.elb.DescribeLoadBalancers("weblb")
+	c.Assert(err, IsNil)
+	lb := resp.LoadBalancerDescriptions[0]
+	c.Assert(lb.HealthCheck.Target, Equals, "HTTP:80/ping")
+	c.Assert(lb.Instances, DeepEquals, []elb.Instance{{InstanceId: "i-web1"}, {InstanceId: "i-web2"}})
+	health, err := s.clientTests.elb.DescribeInstanceHealth("weblb", "i-web1", "i-web2")
+	c.Assert(err, IsNil)
+	c.Assert(health.InstanceStates[0].State, Equals, elb.StateInService)
+	c.Assert(health.InstanceStates[1].State, Equals, elb.StateOutOfService)
+	c.Assert(srv.Tags("weblb"), DeepEquals, []elb.Tag{{Key: "env", Value: "prod"}})
+	attrs, err := s.clientTests.elb.DescribeLoadBalancerAttributes("weblb")
+	c.Assert(err, IsNil)
+	c.Assert(attrs.LoadBalancerAttributes.ConnectionSettings.IdleTimeout, Equals, 120)
+	c.Assert(attrs.LoadBalancerAttributes.ConnectionDraining.Timeout, Equals, 300)
+	// Loading the scenario again fails without touching the server.
+	err = srv.LoadScenario(scenario)
+	c.Assert(err, ErrorMatches, "elbtest: Load Balancer weblb already exists")
+}
+
+func (s *LocalServerSuite) TestLoadScenarioInvalid(c *C) {
+	srv := s.srv.srv
+	scenario := elbtest.Scenario{LoadBalancers: []elbtest.ScenarioLoadBalancer{
+		{CreateLoadBalancer: elb.CreateLoadBalancer{
+			Name:      "validlb",
+			Listeners: []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+		}},
+		{CreateLoadBalancer: elb.CreateLoadBalancer{
+			Name:      "invalidlb",
+			Listeners: []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+		}, Tags: []elb.Tag{{Key: "aws:owner", Value: "ops"}}},
+	}}
+	err := srv.LoadScenario(scenario)
+	c.Assert(err, ErrorMatches, "elbtest: Load Balancer invalidlb: .*")
+	_, err = s.clientTests.elb.DescribeLoadBalancers("validlb")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrLoadBalancerNotFound)
+}
+
+func (s *LocalServerSuite) TestLoadBalancerSnapshot(c *C) {
+	srv := s.srv.srv
+	c.Assert(srv.LoadBalancer("testlb"), IsNil)
+	createLB := s.createLoadBalancer(c)
+	defer srv.RemoveLoadBalancer(createLB.Name)
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	_, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{instId}, createLB.Name)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.AddTags([]string{createLB.Name}, []elb.Tag{{Key: "env", Value: "prod"}})
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.CreateLBCookieStickinessPolicy(createLB.Name, "lb-cookie", 0)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesOfListener(createLB.Name, 80, []string{"lb-cookie"})
+	c.Assert(err, IsNil)
+	lb := srv.LoadBalancer(createLB.Name)
+	c.Assert(lb, NotNil)
+	c.Assert(lb.Description.Instances, DeepEquals, []elb.Instance{{InstanceId: instId}})
+	c.Assert(lb.Description.ListenerDescriptions[0].PolicyNames, DeepEquals, []string{"lb-cookie"})
+	c.Assert(lb.Health, HasLen, 1)
+	c.Assert(lb.Health[0].State, Equals, elb.StateOutOfService)
+	c.Assert(lb.Tags, DeepEquals, []elb.Tag{{Key: "env", Value: "prod"}})
+	c.Assert(lb.Attributes.ConnectionSettings.IdleTimeout, Equals, 60)
+	c.Assert(lb.Policies, HasLen, 1)
+	// Snapshots don't share memory with the server.
+	lb.Description.ListenerDescriptions[0].PolicyNames[0] = "changed"
+	lb.Attributes.ConnectionSettings.IdleTimeout = 1
+	lb = srv.LoadBalancer(createLB.Name)
+	c.Assert(lb.Description.ListenerDescriptions[0].PolicyNames, DeepEquals, []string{"lb-cookie"})
+	c.Assert(lb.Attributes.ConnectionSettings.IdleTimeout, Equals, 60)
+	srv.NewLoadBalancer("another")
+	defer srv.RemoveLoadBalancer("another")
+	lbs := srv.LoadBalancers()
+	c.Assert(lbs, HasLen, 2)
+	c.Assert(lbs[0].Description.LoadBalancerName, Equals, "another")
+	c.Assert(lbs[1].Description.LoadBalancerName, Equals, createLB.Name)
+}
+
+func (s *LocalServerSuite) TestSnapshot(c *C) {
+	srv := s.srv.srv
+	defer srv.Reset()
+	srv.ResetHistory()
+	createLB := s.createLoadBalancer(c)
+	instId := srv.NewInstance()
+	_, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{instId}, createLB.Name)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.AddTags([]string{createLB.Name}, []elb.Tag{{Key: "env", Value: "prod"}})
+	c.Assert(err, IsNil)
+	srv.Namespace("team").NewLoadBalancer("other")
+	want := srv.LoadBalancer(createLB.Name)
+	data, err := srv.Snapshot()
+	c.Assert(err, IsNil)
+	srv.Reset()
+	c.Assert(srv.LoadBalancers(), HasLen, 0)
+	c.Assert(srv.LoadSnapshot(data), IsNil)
+	c.Assert(srv.LoadBalancer(createLB.Name), DeepEquals, want)
+	c.Assert(srv.Namespace("team").LoadBalancer("other"), NotNil)
+	c.Assert(srv.Requests(), HasLen, 3)
+	c.Assert(srv.Requests()[0].Action, Equals, "CreateLoadBalancer")
+	resp, err := s.clientTests.elb.DescribeInstanceHealth(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates, HasLen, 1)
+	c.Assert(resp.InstanceStates[0].InstanceId, Equals, instId)
+	c.Assert(srv.NewInstance(), Not(Equals), instId)
+	// A view on a namespace only sees and replaces its own namespace.
+	team := srv.Namespace("team")
+	data, err = team.Snapshot()
+	c.Assert(err, IsNil)
+	c.Assert(srv.Namespace("copy").LoadSnapshot(data), IsNil)
+	c.Assert(srv.Namespace("copy").LoadBalancer("other"), NotNil)
+	c.Assert(srv.LoadBalancer(createLB.Name), NotNil)
+}
+
+func (s *LocalServerSuite) TestLoadSnapshotInvalid(c *C) {
+	srv := s.srv.srv
+	defer srv.Reset()
+	srv.NewLoadBalancer("testlb")
+	err := srv.LoadSnapshot([]byte("{"))
+	c.Assert(err, ErrorMatches, "elbtest: invalid snapshot: .*")
+	err = srv.LoadSnapshot([]byte(`{"Namespaces": [{"LoadBalancers": [{}]}]}`))
+	c.Assert(err, ErrorMatches, "elbtest: snapshot has a Load Balancer without a name")
+	err = srv.Namespace("team").LoadSnapshot([]byte(`{"Namespaces": [{"Name": "a"}, {"Name": "b"}]}`))
+	c.Assert(err, ErrorMatches, "elbtest: snapshot has 2 namespaces, want at most 1")
+	c.Assert(srv.LoadBalancer("testlb"), NotNil)
+}
+
+func (s *LocalServerSuite) TestDumpOnServerError(c *C) {
+	srv := s.srv.srv
+	defer srv.Reset()
+	srv.NewLoadBalancer("testlb")
+	srv.ResetHistory()
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1}))
+	dir := c.MkDir()
+	srv.SetDumpOnServerError(dir)
+	srv.SetError("DescribeLoadBalancers", &elb.Error{StatusCode: 400, Code: "ValidationError"}, 1)
+	_, err := client.DescribeLoadBalancers("testlb")
+	c.Assert(err, NotNil)
+	c.Assert(srv.Dumps(), HasLen, 0)
+	srv.SetError("DescribeLoadBalancers", &elb.Error{StatusCode: 500, Code: "InternalFailure"}, 1)
+	_, err = client.DescribeLoadBalancers("testlb")
+	c.Assert(err, NotNil)
+	dumps := srv.Dumps()
+	c.Assert(dumps, HasLen, 1)
+	c.Assert(strings.HasPrefix(dumps[0], dir), Equals, true)
+	data, err := ioutil.ReadFile(dumps[0])
+	c.Assert(err, IsNil)
+	srv.Reset()
+	c.Assert(srv.LoadSnapshot(data), IsNil)
+	c.Assert(srv.LoadBalancer("testlb"), NotNil)
+	c.Assert(srv.Requests(), HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestUse(c *C) {
+	srv := s.srv.srv
+	defer srv.Reset()
+	srv.NewLoadBalancer("testlb")
+	var calls []string
+	srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		calls = append(calls, "outer "+action)
+		// Middleware may call the methods of the server.
+		c.Check(srv.Requests(), Not(HasLen), 0)
+		return next(req)
+	})
+	remove := srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		calls = append(calls, "inner "+action)
+		if action == "DescribeLoadBalancers" {
+			return elb.DescribeLoadBalancerResp{
+				LoadBalancerDescriptions: []elb.LoadBalancerDescription{{LoadBalancerName: "overridden"}},
+			}, nil
+		}
+		return next(req)
+	})
+	resp, err := s.clientTests.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+	c.Assert(resp.LoadBalancerDescriptions[0].LoadBalancerName, Equals, "overridden")
+	c.Assert(resp.RequestId, Not(Equals), "")
+	_, err = s.clientTests.elb.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(srv.LoadBalancer("testlb"), IsNil)
+	c.Assert(calls, DeepEquals, []string{
+		"outer DescribeLoadBalancers", "inner DescribeLoadBalancers",
+		"outer DeleteLoadBalancer", "inner DeleteLoadBalancer",
+	})
+	remove()
+	calls = nil
+	resp, err = s.clientTests.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 0)
+	c.Assert(calls, DeepEquals, []string{"outer DescribeLoadBalancers"})
+}
+
+func (s *LocalServerSuite) TestUseErrors(c *C) {
+	srv := s.srv.srv
+	defer srv.Reset()
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1}))
+	srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		if action == "DeleteLoadBalancer" {
+			return nil, errors.New("boom")
+		}
+		return nil, &elb.Error{StatusCode: 400, Code: "AccessDenied", Message: "denied"}
+	})
+	_, err := client.DescribeLoadBalancers()
+	c.Assert(err, ErrorMatches, `^denied \(AccessDenied\)$`)
+	_, err = client.DeleteLoadBalancer("testlb")
+	c.Assert(err, ErrorMatches, `^boom \(InternalFailure\)$`)
+	c.Assert(err.(*elb.Error).StatusCode, Equals, 500)
+}
+
+func (s *LocalServerSuite) TestUseNamespace(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	srv.SetNamespaceFunc(elbtest.NamespaceByHeader("X-Test-Namespace"))
+	var namespaces []string
+	for _, ns := range []string{"ns1", "ns2"} {
+		ns := ns
+		srv.Namespace(ns).Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+			namespaces = append(namespaces, ns)
+			return next(req)
+		})
+	}
+	for _, ns := range []string{"ns2", "ns3"} {
+		req, err := http.NewRequest("GET", srv.URL()+"/?Action=DescribeLoadBalancers", nil)
+		c.Assert(err, IsNil)
+		req.Header.Set("X-Test-Namespace", ns)
+		resp, err := http.DefaultClient.Do(req)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, 200)
+	}
+	c.Assert(namespaces, DeepEquals, []string{"ns2"})
+}
+
+func (s *LocalServerSuite) TestOperationID(c *C) {
+	defer s.srv.srv.Reset()
+	var buf bytes.Buffer
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithLogger(log.New(&buf, "", 0)))
+	spec := &elb.LoadBalancerSpec{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners:  []elb.Listener{{Protocol: elb.ProtocolHTTP, LoadBalancerPort: 80, InstanceProtocol: elb.ProtocolHTTP, InstancePort: 80}},
+		Tags:       []elb.Tag{{Key: "env", Value: "prod"}},
+	}
+	_, err := client.EnsureLoadBalancer(spec)
+	c.Assert(err, IsNil)
+	ops := regexp.MustCompile(`(?m)^elb: \w+ request=(\S+) operation=(\S+) `).FindAllStringSubmatch(buf.String(), -1)
+	c.Assert(len(ops) > 2, Equals, true)
+	tokens := make(map[string]bool)
+	for _, op := range ops {
+		c.Assert(op[2], Equals, ops[0][2])
+		tokens[op[1]] = true
+	}
+	c.Assert(tokens, HasLen, len(ops))
+	// An operation id set by the caller is kept.
+	buf.Reset()
+	_, err = client.EnsureLoadBalancerWithContext(elb.WithOperationID(context.Background(), "deploy-42"), spec)
+	c.Assert(err, IsNil)
+	c.Assert(buf.String(), Matches, `(?s)elb: DescribeLoadBalancers request=\S+ operation=deploy-42 .*`)
+}
+
+func (s *LocalServerSuite) TestDeleteLoadBalancerSafeOperationID(c *C) {
+	var buf bytes.Buffer
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithLogger(log.New(&buf, "", 0)))
+	s.srv.srv.NewLoadBalancer("testlb")
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	err := client.DeleteLoadBalancerSafe("testlb", nil)
+	c.Assert(err, IsNil)
+	ops := regexp.MustCompile(`(?m)^elb: (\w+) request=\S+ operation=(\S+) `).FindAllStringSubmatch(buf.String(), -1)
+	c.Assert(ops, HasLen, 2)
+	c.Assert(ops[0][1], Equals, "DescribeInstanceHealth")
+	c.Assert(ops[1][1], Equals, "DeleteLoadBalancer")
+	c.Assert(ops[1][2], Equals, ops[0][2])
+}
+
+func (s *LocalServerSuite) TestSubscribe(c *C) {
+	srv := s.srv.srv
+	var events []elbtest.Event
+	unsubscribe := srv.Subscribe(func(e elbtest.Event) {
+		// The change is visible to the subscriber.
+		c.Check(srv.LoadBalancer(e.LoadBalancerName), NotNil)
+		events = append(events, e)
+	})
+	createLB := s.createLoadBalancer(c)
+	defer srv.RemoveLoadBalancer(createLB.Name)
+	inst1, inst2 := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(inst1)
+	defer srv.RemoveInstance(inst2)
+	_, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{inst1, inst2}, createLB.Name)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{"i-absent"}, createLB.Name)
+	c.Assert(err, NotNil)
+	unsubscribe()
+	_, err = s.clientTests.elb.DeregisterInstancesFromLoadBalancer([]string{inst1}, createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 3)
+	c.Assert(events[0].Type, Equals, elbtest.LBCreated)
+	c.Assert(events[0].LoadBalancerName, Equals, createLB.Name)
+	c.Assert(events[1].Type, Equals, elbtest.InstanceRegistered)
+	c.Assert(events[1].InstanceId, Equals, inst1)
+	c.Assert(events[2].Type, Equals, elbtest.InstanceRegistered)
+	c.Assert(events[2].InstanceId, Equals, inst2)
+	c.Assert(events[2].RequestId, Equals, events[1].RequestId)
+}
+
+func (s *LocalServerSuite) TestTLSServer(c *C) {
+	srv, err := elbtest.NewTLSServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	c.Assert(srv.URL(), Matches, "https://.*")
+	c.Assert(srv.Certificate(), NotNil)
+	region := aws.Region{ELBEndpoint: srv.URL()}
+	client := elb.New(s.srv.auth, region, elb.WithHTTPClient(srv.Client()))
+	cert := "arn:aws:iam::123456789012:server-certificate/web"
+	createLB := &elb.CreateLoadBalancer{
+		Name:       "tlslb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: cert}},
+	}
+	_, err = client.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	resp, err := client.DescribeLoadBalancers("tlslb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].ListenerDescriptions[0].Listener.SSLCertificateId, Equals, cert)
+	// Clients that don't trust the certificate are rejected.
+	_, err = elb.New(s.srv.auth, region).DescribeLoadBalancers("tlslb")
+	c.Assert(err, ErrorMatches, ".*certificate.*")
+}
+
+func (s *LocalServerSuite) createLoadBalancer(c *C) *elb.CreateLoadBalancer {
+	createLB := &elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners: []elb.Listener{
+			{
+				InstancePort:     80,
+				InstanceProtocol: "http",
+				LoadBalancerPort: 80,
+				Protocol:         "http",
+			},
+		},
+	}
+	_, err := s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	return createLB
+}
+
+func (s *LocalServerSuite) TestCreateInternalLoadBalancer(c *C) {
+	createLB := &elb.CreateLoadBalancer{
+		Name:           "testlb",
+		Scheme:         elb.SchemeInternal,
+		Subnets:        []string{"subnet-1", "subnet-2"},
+		SecurityGroups: []string{"sg-1"},
+		Listeners:      []elb.Listener{{InstancePort: 80, LoadBalancerPort: 80, Protocol: "HTTP", InstanceProtocol: "HTTP"}},
+	}
+	resp, err := s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	c.Assert(resp.DNSName, Matches, "internal-testlb-.*")
+	descResp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	desc := descResp.LoadBalancerDescriptions[0]
+	c.Assert(desc.Scheme, Equals, elb.SchemeInternal)
+	c.Assert(desc.Subnets, DeepEquals, []string{"subnet-1", "subnet-2"})
+	c.Assert(desc.SecurityGroups, DeepEquals, []string{"sg-1"})
+	c.Assert(desc.DNSName, Equals, resp.DNSName)
+	c.Assert(desc.CanonicalHostedZoneName, Equals, "")
+	c.Assert(desc.CanonicalHostedZoneNameId, Not(Equals), "")
+}
+
+func (s *LocalServerSuite) TestCreateInternetFacingLoadBalancer(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	descResp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	desc := descResp.LoadBalancerDescriptions[0]
+	c.Assert(desc.Scheme, Equals, elb.SchemeInternetFacing)
+	c.Assert(desc.CanonicalHostedZoneName, Equals, desc.DNSName)
+	c.Assert(desc.CanonicalHostedZoneNameId, Not(Equals), "")
+}
+
+func (s *LocalServerSuite) TestDescribeLoadBalancersVPCFields(c *C) {
+	createLB := &elb.CreateLoadBalancer{
+		Name:      "vpclb",
+		Subnets:   []string{"subnet-1"},
+		Listeners: []elb.Listener{{InstancePort: 80, LoadBalancerPort: 80, Protocol: "HTTP", InstanceProtocol: "HTTP"}},
+	}
+	start := time.Now().Add(-time.Second)
+	_, err := s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	classicLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(classicLB.Name)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name, classicLB.Name)
+	c.Assert(err, IsNil)
+	vpc, classic := resp.LoadBalancerDescriptions[0], resp.LoadBalancerDescriptions[1]
+	c.Assert(vpc.VPCId, Matches, "vpc-.+")
+	c.Assert(vpc.CreatedTime.After(start), Equals, true)
+	c.Assert(vpc.CreatedTime.After(time.Now()), Equals, false)
+	c.Assert(classic.VPCId, Equals, "")
+	c.Assert(classic.CreatedTime.IsZero(), Equals, false)
+}
+
+type fakeRoute53 struct {
+	hostedZoneId string
+	changes      []elb.RecordChange
+}
+
+func (r *fakeRoute53) ChangeResourceRecordSets(ctx context.Context, hostedZoneId string, changes []elb.RecordChange) error {
+	r.hostedZoneId = hostedZoneId
+	r.changes = append(r.changes, changes...)
+	return nil
+}
+
+func (s *LocalServerSuite) TestAliasRecord(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	desc := &resp.LoadBalancerDescriptions[0]
+	target, err := desc.AliasTarget()
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, elb.AliasTarget{
+		HostedZoneId: desc.CanonicalHostedZoneNameId,
+		DNSName:      desc.DNSName + ".",
+	})
+	var r fakeRoute53
+	err = elb.UpsertAliasRecord(context.Background(), &r, "Z1PA6795UKMFR9", "www.example.com.", desc, true)
+	c.Assert(err, IsNil)
+	err = elb.DeleteAliasRecord(context.Background(), &r, "Z1PA6795UKMFR9", "www.example.com.", desc, true)
+	c.Assert(err, IsNil)
+	c.Assert(r.hostedZoneId, Equals, "Z1PA6795UKMFR9")
+	target.EvaluateTargetHealth = true
+	c.Assert(r.changes, DeepEquals, []elb.RecordChange{
+		{Action: elb.RecordUpsert, Name: "www.example.com.", Type: "A", AliasTarget: target},
+		{Action: elb.RecordDelete, Name: "www.example.com.", Type: "A", AliasTarget: target},
+	})
+}
+
+func (s *LocalServerSuite) TestDualStackAliasRecords(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	desc := &resp.LoadBalancerDescriptions[0]
+	c.Assert(desc.DualStackDNSName(), Equals, "dualstack."+desc.DNSName)
+	target, err := desc.DualStackAliasTarget()
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, elb.AliasTarget{
+		HostedZoneId: desc.CanonicalHostedZoneNameId,
+		DNSName:      "dualstack." + desc.DNSName + ".",
+	})
+	var r fakeRoute53
+	err = elb.UpsertDualStackAliasRecords(context.Background(), &r, "Z1PA6795UKMFR9", "www.example.com.", desc, false)
+	c.Assert(err, IsNil)
+	c.Assert(r.changes, DeepEquals, []elb.RecordChange{
+		{Action: elb.RecordUpsert, Name: "www.example.com.", Type: "A", AliasTarget: target},
+		{Action: elb.RecordUpsert, Name: "www.example.com.", Type: "AAAA", AliasTarget: target},
+	})
+	r.changes = nil
+	err = elb.DeleteDualStackAliasRecords(context.Background(), &r, "Z1PA6795UKMFR9", "www.example.com.", desc, false)
+	c.Assert(err, IsNil)
+	c.Assert(r.changes, HasLen, 2)
+	c.Assert(r.changes[1].Action, Equals, elb.RecordDelete)
+	c.Assert(r.changes[1].Type, Equals, "AAAA")
+}
+
+func (s *LocalServerSuite) TestDualStackDNSNameWithoutDNSName(c *C) {
+	desc := &elb.LoadBalancerDescription{LoadBalancerName: "testlb"}
+	c.Assert(desc.DualStackDNSName(), Equals, "")
+}
+
+func (s *LocalServerSuite) TestAliasRecordWithoutHostedZone(c *C) {
+	desc := &elb.LoadBalancerDescription{LoadBalancerName: "testlb", DNSName: "testlb.example.com"}
+	var r fakeRoute53
+	err := elb.UpsertAliasRecord(context.Background(), &r, "Z1PA6795UKMFR9", "www.example.com.", desc, false)
+	c.Assert(err, ErrorMatches, "elb: no canonical hosted zone for Load Balancer testlb")
+	c.Assert(r.changes, HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestEnableAccessLogs(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	err := s.clientTests.elb.EnableAccessLogs("testlb", "my-logs", "prod", 5*time.Minute)
+	c.Assert(err, IsNil)
+	attrs, err := s.clientTests.elb.DescribeLoadBalancerAttributes("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(*attrs.LoadBalancerAttributes.AccessLog, Equals, elb.AccessLog{
+		Enabled:        true,
+		S3BucketName:   "my-logs",
+		S3BucketPrefix: "prod",
+		EmitInterval:   5,
+	})
+}
+
+type fakeAutoScaling struct {
+	calls []string
+	err   error
+}
+
+func (as *fakeAutoScaling) AttachLoadBalancers(ctx context.Context, groupName string, lbNames []string) error {
+	as.calls = append(as.calls, fmt.Sprintf("attach %s %d", groupName, len(lbNames)))
+	return as.err
+}
+
+func (as *fakeAutoScaling) DetachLoadBalancers(ctx context.Context, groupName string, lbNames []string) error {
+	as.calls = append(as.calls, fmt.Sprintf("detach %s %d", groupName, len(lbNames)))
+	return as.err
+}
+
+func (s *LocalServerSuite) TestAttachToAutoScalingGroup(c *C) {
+	var names []string
+	for i := 0; i < 23; i++ {
+		names = append(names, fmt.Sprintf("lb%d", i))
+	}
+	var as fakeAutoScaling
+	err := elb.AttachToAutoScalingGroup(context.Background(), &as, "web", names...)
+	c.Assert(err, IsNil)
+	err = elb.DetachFromAutoScalingGroup(context.Background(), &as, "web", names[:3]...)
+	c.Assert(err, IsNil)
+	c.Assert(as.calls, DeepEquals, []string{"attach web 10", "attach web 10", "attach web 3", "detach web 3"})
+	as = fakeAutoScaling{err: errors.New("ValidationError")}
+	err = elb.AttachToAutoScalingGroup(context.Background(), &as, "web", names...)
+	c.Assert(err, ErrorMatches, "ValidationError")
+	c.Assert(as.calls, HasLen, 1)
+}
+
+func (s *LocalServerSuite) TestRegisterInstancesByTag(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	web1, web2 := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(web1)
+	defer srv.RemoveInstance(web2)
+	var filters map[string][]string
+	e := elb.EC2Func(func(ctx context.Context, f map[string][]string) ([]string, error) {
+		filters = f
+		return []string{web2, web1}, nil
+	})
+	ids, err := s.clientTests.elb.RegisterInstancesByTag(e, "testlb", map[string]string{"role": "web"})
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, []string{web1, web2})
+	c.Assert(filters, DeepEquals, map[string][]string{
+		"instance-state-name": {"running"},
+		"tag:role":            {"web"},
+	})
+	c.Assert(srv.LoadBalancer("testlb").Description.Instances, HasLen, 2)
+	none := elb.EC2Func(func(ctx context.Context, f map[string][]string) ([]string, error) {
+		return nil, nil
+	})
+	srv.ResetHistory()
+	ids, err = s.clientTests.elb.RegisterInstancesByTag(none, "testlb", map[string]string{"role": "db"})
+	c.Assert(err, IsNil)
+	c.Assert(ids, HasLen, 0)
+	c.Assert(srv.RequestsFor("RegisterInstancesWithLoadBalancer"), HasLen, 0)
+}
+
+type fakeS3 map[string]string
+
+func (s fakeS3) GetBucketPolicy(ctx context.Context, bucket string) (string, error) {
+	policy, ok := s[bucket]
+	if !ok {
+		return "", errors.New("NoSuchBucketPolicy")
+	}
+	return policy, nil
+}
+
+func (s *LocalServerSuite) TestCheckAccessLogBucketPolicy(c *C) {
+	s3 := fakeS3{
+		"account": `{
+			"Version": "2012-10-17",
+			"Statement": [{
+				"Effect": "Allow",
+				"Principal": {"AWS": "arn:aws:iam::127311923021:root"},
+				"Action": "s3:PutObject",
+				"Resource": "arn:aws:s3:::account/prod/AWSLogs/123456789012/*"
+			}]
+		}`,
+		"service": `{
+			"Statement": {
+				"Effect": "Allow",
+				"Principal": {"Service": "logdelivery.elasticloadbalancing.amazonaws.com"},
+				"Action": ["s3:GetObject", "s3:Put*"],
+				"Resource": ["arn:aws:s3:::service/*"]
+			}
+		}`,
+		"wrong-prefix": `{
+			"Statement": [{
+				"Effect": "Allow",
+				"Principal": {"AWS": "127311923021"},
+				"Action": "s3:PutObject",
+				"Resource": "arn:aws:s3:::wrong-prefix/staging/*"
+			}]
+		}`,
+		"wrong-principal": `{
+			"Statement": [{
+				"Effect": "Allow",
+				"Principal": {"AWS": "arn:aws:iam::797873946194:root"},
+				"Action": "s3:PutObject",
+				"Resource": "arn:aws:s3:::wrong-principal/*"
+			}]
+		}`,
+		"deny": `{
+			"Statement": [{
+				"Effect": "Deny",
+				"Principal": "*",
+				"Action": "s3:*",
+				"Resource": "arn:aws:s3:::deny/*"
+			}]
+		}`,
+	}
+	ctx := context.Background()
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "account", "prod", "123456789012"), IsNil)
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "service", "", "123456789012"), IsNil)
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "mx-central-1", "service", "prod", "123456789012"), IsNil)
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "account", "prod", "210987654321"), NotNil)
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "mx-central-1", "account", "prod", "123456789012"), NotNil)
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "wrong-principal", "", "123456789012"), NotNil)
+	c.Check(elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "deny", "", "123456789012"), NotNil)
+	err := elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "wrong-prefix", "prod", "123456789012")
+	c.Assert(err, FitsTypeOf, &elb.AccessLogPolicyError{})
+	c.Assert(err, ErrorMatches, `elb: policy of bucket wrong-prefix doesn't allow 127311923021 or logdelivery.elasticloadbalancing.amazonaws.com to s3:PutObject on arn:aws:s3:::wrong-prefix/prod/AWSLogs/123456789012/elasticloadbalancing/us-east-1/\*`)
+	err = elb.CheckAccessLogBucketPolicy(ctx, s3, "us-east-1", "absent", "", "123456789012")
+	c.Assert(err, ErrorMatches, "NoSuchBucketPolicy")
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerInvalidScheme(c *C) {
+	createLB := &elb.CreateLoadBalancer{
+		Name:       "testlb",
+		Scheme:     elb.SchemeInternal,
+		AvailZones: []string{"us-east-1a"},
+		Listeners:  []elb.Listener{{InstancePort: 80, LoadBalancerPort: 80, Protocol: "HTTP", InstanceProtocol: "HTTP"}},
+	}
+	_, err := s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+	createLB.Scheme = "external"
+	_, err = s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrValidation)
+}
+
+func (s *LocalServerSuite) TestCreateAndDeleteLoadBalancerListeners(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	listeners := []elb.Listener{{InstancePort: 8080, InstanceProtocol: "HTTP", LoadBalancerPort: 8080, Protocol: "HTTP"}}
+	_, err := s.clientTests.elb.CreateLoadBalancerListeners(createLB.Name, listeners)
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	lds := resp.LoadBalancerDescriptions[0].ListenerDescriptions
+	c.Assert(lds, HasLen, 2)
+	c.Assert(lds[1].Listener, DeepEquals, listeners[0])
+	_, err = s.clientTests.elb.DeleteLoadBalancerListeners(createLB.Name, 80)
+	c.Assert(err, IsNil)
+	resp, err = s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	lds = resp.LoadBalancerDescriptions[0].ListenerDescriptions
+	c.Assert(lds, HasLen, 1)
+	c.Assert(lds[0].Listener.LoadBalancerPort, Equals, 8080)
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerListenersDuplicate(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	listeners := []elb.Listener{{InstancePort: 8080, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}}
+	_, err := s.clientTests.elb.CreateLoadBalancerListeners(createLB.Name, listeners)
+	c.Assert(err, ErrorMatches, `^A listener already exists for testlb with LoadBalancerPort 80, .* \(DuplicateListener\)$`)
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerListenersDuplicateInRequest(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	listeners := []elb.Listener{
+		{InstancePort: 8080, InstanceProtocol: "HTTP", LoadBalancerPort: 8080, Protocol: "HTTP"},
+		{InstancePort: 8081, InstanceProtocol: "HTTP", LoadBalancerPort: 8080, Protocol: "HTTP"},
+	}
+	_, err := s.clientTests.elb.CreateLoadBalancerListeners(createLB.Name, listeners)
+	c.Assert(err, ErrorMatches, `.*\(DuplicateListener\)$`)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].ListenerDescriptions, HasLen, 1)
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerListenersValidation(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	invalid := []elb.Listener{
+		{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 8080, Protocol: "UDP"},
+		{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 22, Protocol: "TCP"},
+		{InstancePort: 0, InstanceProtocol: "HTTP", LoadBalancerPort: 8080, Protocol: "HTTP"},
+		{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 443, Protocol: "HTTPS"},
+	}
+	client := elb.New(s.clientTests.elb.Auth, s.srv.region, elb.WithoutListenerValidation())
+	for _, l := range invalid {
+		_, err := client.CreateLoadBalancerListeners(createLB.Name, []elb.Listener{l})
+		c.Check(err, ErrorMatches, `.*\(ValidationError\)$`)
+	}
+	l := elb.Listener{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "absent"}
+	_, err := s.clientTests.elb.CreateLoadBalancerListeners(createLB.Name, []elb.Listener{l})
+	c.Assert(err, ErrorMatches, `.*\(CertificateNotFound\)$`)
+}
+
+func (s *LocalServerSuite) TestSetLoadBalancerListenerSSLCertificate(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	oldCert := "arn:aws:iam::123456789012:server-certificate/old"
+	newCert := "arn:aws:iam::123456789012:server-certificate/new"
+	listeners := []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: oldCert}}
+	_, err := s.clientTests.elb.CreateLoadBalancerListeners(createLB.Name, listeners)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.SetLoadBalancerListenerSSLCertificate(createLB.Name, 443, newCert)
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].ListenerDescriptions[1].Listener.SSLCertificateId, Equals, newCert)
+}
+
+func (s *LocalServerSuite) TestSetLoadBalancerListenerSSLCertificateErrors(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	_, err := s.clientTests.elb.SetLoadBalancerListenerSSLCertificate(createLB.Name, 443, "arn:aws:iam::123456789012:server-certificate/new")
+	c.Assert(err, ErrorMatches, `.*\(ListenerNotFound\)$`)
+	_, err = s.clientTests.elb.SetLoadBalancerListenerSSLCertificate(createLB.Name, 80, "arn:aws:iam::123456789012:server-certificate/new")
+	c.Assert(err, ErrorMatches, `.*\(InvalidConfigurationRequest\)$`)
+	listeners := []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 443, Protocol: "HTTPS", SSLCertificateId: "arn:aws:iam::123456789012:server-certificate/old"}}
+	_, err = s.clientTests.elb.CreateLoadBalancerListeners(createLB.Name, listeners)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.SetLoadBalancerListenerSSLCertificate(createLB.Name, 443, "absent")
+	c.Assert(err, ErrorMatches, `.*\(CertificateNotFound\)$`)
+}
+
+func (s *LocalServerSuite) TestLoadBalancerPolicies(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	_, err := s.clientTests.elb.CreateLBCookieStickinessPolicy(createLB.Name, "lb-cookie", 60)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.CreateAppCookieStickinessPolicy(createLB.Name, "app-cookie", "session")
+	c.Assert(err, IsNil)
+	err = s.clientTests.elb.CreateSSLPolicy(createLB.Name, "tls", elb.SecurityPolicyTLS12)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.CreateLBCookieStickinessPolicy(createLB.Name, "lb-cookie", 0)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrDuplicatePolicyName)
+	_, err = s.clientTests.elb.CreateLoadBalancerPolicy(createLB.Name, "unknown", "UnknownPolicyType", nil)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrPolicyTypeNotFound)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].Policies, DeepEquals, elb.Policies{
+		AppCookieStickinessPolicies: []elb.AppCookieStickinessPolicies{{CookieName: "session", PolicyName: "app-cookie"}},
+		LBCookieStickinessPolicies:  []elb.LBCookieStickinessPolicies{{CookieExpirationPeriod: 60, PolicyName: "lb-cookie"}},
+		OtherPolicies:               []string{"tls"},
+	})
+	policies, err := s.clientTests.elb.DescribeLoadBalancerPolicies(createLB.Name, "app-cookie")
+	c.Assert(err, IsNil)
+	c.Assert(policies.PolicyDescriptions, DeepEquals, []elb.PolicyDescription{{
+		PolicyName:                  "app-cookie",
+		PolicyTypeName:              "AppCookieStickinessPolicyType",
+		PolicyAttributeDescriptions: []elb.PolicyAttributeDescription{{AttributeName: "CookieName", AttributeValue: "session"}},
+	}})
+	_, err = s.clientTests.elb.DescribeLoadBalancerPolicies(createLB.Name, "absent")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrPolicyNotFound)
+	names, err := s.clientTests.elb.PredefinedSSLPolicies()
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{elb.SecurityPolicy2016, elb.SecurityPolicyTLS11, elb.SecurityPolicyTLS12})
+	_, err = s.clientTests.elb.DeleteLoadBalancerPolicy(createLB.Name, "app-cookie")
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.DeleteLoadBalancerPolicy(createLB.Name, "app-cookie")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrPolicyNotFound)
+	resp, err = s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].Policies.AppCookieStickinessPolicies, HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestSetLoadBalancerPoliciesOfListener(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	_, err := s.clientTests.elb.CreateLBCookieStickinessPolicy(createLB.Name, "lb-cookie", 0)
+	c.Assert(err, IsNil)
+	err = s.clientTests.elb.CreateSSLPolicy(createLB.Name, "tls", elb.SecurityPolicyTLS12)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesOfListener(createLB.Name, 80, []string{"lb-cookie"})
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesOfListener(createLB.Name, 80, []string{"tls"})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesOfListener(createLB.Name, 80, []string{"absent"})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrPolicyNotFound)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesOfListener(createLB.Name, 443, nil)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrListenerNotFound)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].ListenerDescriptions[0].PolicyNames, DeepEquals, []string{"lb-cookie"})
+	_, err = s.clientTests.elb.DeleteLoadBalancerPolicy(createLB.Name, "lb-cookie")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesOfListener(createLB.Name, 80, nil)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.DeleteLoadBalancerPolicy(createLB.Name, "lb-cookie")
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestSetLoadBalancerPoliciesForBackendServer(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	err := s.clientTests.elb.EnableProxyProtocol(createLB.Name, []int{8080, 80})
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].BackendServerDescriptions, DeepEquals, []elb.BackendServerDescriptions{
+		{InstancePort: 80, PolicyNames: []string{elb.ProxyProtocolPolicyName}},
+		{InstancePort: 8080, PolicyNames: []string{elb.ProxyProtocolPolicyName}},
+	})
+	c.Assert(resp.LoadBalancerDescriptions[0].BackendPolicyNames(8080), DeepEquals, []string{elb.ProxyProtocolPolicyName})
+	c.Assert(resp.LoadBalancerDescriptions[0].BackendPolicyNames(443), IsNil)
+	_, err = s.clientTests.elb.CreateLBCookieStickinessPolicy(createLB.Name, "lb-cookie", 0)
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.SetLoadBalancerPoliciesForBackendServer(createLB.Name, 80, []string{"lb-cookie"})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+	err = s.clientTests.elb.DisableProxyProtocol(createLB.Name, []int{8080})
+	c.Assert(err, IsNil)
+	resp, err = s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].BackendServerDescriptions, DeepEquals, []elb.BackendServerDescriptions{
+		{InstancePort: 80, PolicyNames: []string{elb.ProxyProtocolPolicyName}},
+	})
+}
+
+func (s *LocalServerSuite) TestApplySecurityGroups(c *C) {
+	createLB := &elb.CreateLoadBalancer{
+		Name:           "vpclb",
+		Subnets:        []string{"subnet-1"},
+		SecurityGroups: []string{"sg-1"},
+		Listeners:      []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+	}
+	_, err := s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	resp, err := s.clientTests.elb.ApplySecurityGroups(createLB.Name, []string{"sg-2", "sg-3"})
+	c.Assert(err, IsNil)
+	c.Assert(resp.SecurityGroups, DeepEquals, []string{"sg-2", "sg-3"})
+	descResp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(descResp.LoadBalancerDescriptions[0].SecurityGroups, DeepEquals, []string{"sg-2", "sg-3"})
+}
+
+func (s *LocalServerSuite) TestApplySecurityGroupsOutsideVPC(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	_, err := s.clientTests.elb.ApplySecurityGroups(createLB.Name, []string{"sg-2"})
+	c.Assert(err, ErrorMatches, `.*\(InvalidConfigurationRequest\)$`)
+}
+
+func (s *LocalServerSuite) TestAttachAndDetachLoadBalancerSubnets(c *C) {
+	createLB := &elb.CreateLoadBalancer{
+		Name:      "vpclb",
+		Subnets:   []string{"subnet-1"},
+		Listeners: []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+	}
+	_, err := s.clientTests.elb.CreateLoadBalancer(createLB)
+	c.Assert(err, IsNil)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	attachResp, err := s.clientTests.elb.AttachLoadBalancerToSubnets(createLB.Name, []string{"subnet-2"})
+	c.Assert(err, IsNil)
+	c.Assert(attachResp.Subnets, DeepEquals, []string{"subnet-1", "subnet-2"})
+	detachResp, err := s.clientTests.elb.DetachLoadBalancerFromSubnets(createLB.Name, []string{"subnet-1"})
+	c.Assert(err, IsNil)
+	c.Assert(detachResp.Subnets, DeepEquals, []string{"subnet-2"})
+	descResp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(descResp.LoadBalancerDescriptions[0].Subnets, DeepEquals, []string{"subnet-2"})
+	_, err = s.clientTests.elb.DetachLoadBalancerFromSubnets(createLB.Name, []string{"subnet-2"})
+	c.Assert(err, ErrorMatches, `.*\(InvalidSubnet\)$`)
+	_, err = s.clientTests.elb.AttachLoadBalancerToSubnets(createLB.Name, []string{"absent"})
+	c.Assert(err, ErrorMatches, `.*\(SubnetNotFound\)$`)
+}
+
+func (s *LocalServerSuite) TestEnableAndDisableAvailabilityZones(c *C) {
+	createLB := s.createLoadBalancer(c)
+	defer s.srv.srv.RemoveLoadBalancer(createLB.Name)
+	enableResp, err := s.clientTests.elb.EnableAvailabilityZonesForLoadBalancer(createLB.Name, []string{"us-east-1b", "us-east-1c"})
+	c.Assert(err, IsNil)
+	c.Assert(enableResp.AvailZones, DeepEquals, []string{"us-east-1a", "us-east-1b", "us-east-1c"})
+	disableResp, err := s.clientTests.elb.DisableAvailabilityZonesForLoadBalancer(createLB.Name, []string{"us-east-1a"})
+	c.Assert(err, IsNil)
+	c.Assert(disableResp.AvailZones, DeepEquals, []string{"us-east-1b", "us-east-1c"})
+	descResp, err := s.clientTests.elb.DescribeLoadBalancers(createLB.Name)
+	c.Assert(err, IsNil)
+	c.Assert(descResp.LoadBalancerDescriptions[0].AvailZones, DeepEquals, []string{"us-east-1b", "us-east-1c"})
+}
+
+func (s *LocalServerSuite) TestDescribeLoadBalancersPagination(c *C) {
+	srv := s.srv.srv
+	for _, name := range []string{"lb1", "lb2", "lb3"} {
+		srv.NewLoadBalancer(name)
+		defer srv.RemoveLoadBalancer(name)
+	}
+	resp, err := s.clientTests.elb.DescribeLoadBalancersPage("", 2)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 2)
+	c.Assert(resp.LoadBalancerDescriptions[0].LoadBalancerName, Equals, "lb1")
+	c.Assert(resp.NextMarker, Equals, "lb3")
+	resp, err = s.clientTests.elb.DescribeLoadBalancersPage(resp.NextMarker, 2)
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+	c.Assert(resp.NextMarker, Equals, "")
+	descs, err := s.clientTests.elb.DescribeLoadBalancersAll()
+	c.Assert(err, IsNil)
+	c.Assert(descs, HasLen, 3)
+}
+
+func (s *LocalServerSuite) TestDescribeLoadBalancersDefaultPageSize(c *C) {
+	srv := s.srv.srv
+	for _, name := range []string{"lb1", "lb2", "lb3"} {
+		srv.NewLoadBalancer(name)
+		defer srv.RemoveLoadBalancer(name)
+	}
+	srv.SetPageSize(1)
+	defer srv.SetPageSize(0)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+	c.Assert(resp.NextMarker, Equals, "lb2")
+	srv.ResetHistory()
+	descs, err := s.clientTests.elb.DescribeLoadBalancersAll()
+	c.Assert(err, IsNil)
+	c.Assert(descs, HasLen, 3)
+	c.Assert(srv.RequestsFor("DescribeLoadBalancers"), HasLen, 3)
+}
+
+func (s *LocalServerSuite) TestFindLoadBalancers(c *C) {
+	srv := s.srv.srv
+	e := s.clientTests.elb
+	lbs := []struct {
+		name, scheme, app string
+	}{
+		{"app-x-1", elb.SchemeInternetFacing, "x"},
+		{"app-x-2", elb.SchemeInternal, "x"},
+		{"app-y-1", elb.SchemeInternetFacing, "y"},
+		{"other", elb.SchemeInternetFacing, ""},
+	}
+	for _, lb := range lbs {
+		_, err := e.CreateLoadBalancer(&elb.CreateLoadBalancer{
+			Name:           lb.name,
+			Scheme:         lb.scheme,
+			Subnets:        []string{"subnet-1"},
+			SecurityGroups: []string{"sg-1"},
+			Listeners:      []elb.Listener{{InstancePort: 80, LoadBalancerPort: 80, Protocol: "HTTP", InstanceProtocol: "HTTP"}},
+		})
+		c.Assert(err, IsNil)
+		defer srv.RemoveLoadBalancer(lb.name)
+		if lb.app != "" {
+			_, err = e.AddTags([]string{lb.name}, []elb.Tag{{Key: "app", Value: lb.app}})
+			c.Assert(err, IsNil)
+		}
+	}
+	srv.SetPageSize(1)
+	defer srv.SetPageSize(0)
+	names := func(descs []elb.LoadBalancerDescription) []string {
+		var names []string
+		for _, d := range descs {
+			names = append(names, d.LoadBalancerName)
+		}
+		return names
+	}
+
+	descs, err := e.FindLoadBalancers(&elb.LoadBalancerFilter{NamePrefix: "app-"})
+	c.Assert(err, IsNil)
+	c.Assert(names(descs), DeepEquals, []string{"app-x-1", "app-x-2", "app-y-1"})
+
+	srv.ResetHistory()
+	descs, err = e.FindLoadBalancers(&elb.LoadBalancerFilter{Tags: map[string]string{"app": "x"}})
+	c.Assert(err, IsNil)
+	c.Assert(names(descs), DeepEquals, []string{"app-x-1", "app-x-2"})
+	c.Assert(srv.RequestsFor("DescribeLoadBalancers"), HasLen, 4)
+	c.Assert(srv.RequestsFor("DescribeTags"), HasLen, 4)
+
+	// Tags are only described for the Load Balancers matching the
+	// other fields.
+	srv.ResetHistory()
+	descs, err = e.FindLoadBalancers(&elb.LoadBalancerFilter{
+		NamePrefix: "app-",
+		Scheme:     "Internet-Facing",
+		Tags:       map[string]string{"app": ""},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(names(descs), DeepEquals, []string{"app-x-1", "app-y-1"})
+	c.Assert(srv.RequestsFor("DescribeTags"), HasLen, 2)
+
+	descs, err = e.FindLoadBalancers(nil)
+	c.Assert(err, IsNil)
+	c.Assert(descs, HasLen, 4)
+}
+
+func (s *LocalServerSuite) TestDescribeLoadBalancersInvalidPageSize(c *C) {
+	_, err := s.clientTests.elb.DescribeLoadBalancersPage("", 401)
+	c.Assert(err, NotNil)
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.Code, Equals, "ValidationError")
+}
+
+func (s *LocalServerSuite) TestErrorCarriesRequestId(c *C) {
+	_, err := s.clientTests.elb.DescribeLoadBalancers("absentlb")
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+	c.Assert(err.(*elb.Error).RequestId, Matches, "req[0-9A-F]+")
+}
+
+func (s *LocalServerSuite) TestResponseCarriesRequestId(c *C) {
+	s.srv.srv.ResetHistory()
+	resp, err := s.clientTests.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	reqs := s.srv.srv.RequestsFor("DescribeLoadBalancers")
+	c.Assert(reqs, HasLen, 1)
+	c.Assert(resp.RequestId, Equals, reqs[0].RequestId)
+}
+
+var fastWaiter = &elb.WaiterConfig{Delay: time.Millisecond, MaxWait: 20 * time.Millisecond}
+
+func (s *LocalServerSuite) TestSummarizeHealth(c *C) {
+	srv := s.srv.srv
+	err := srv.LoadScenario(elbtest.Scenario{LoadBalancers: []elbtest.ScenarioLoadBalancer{{
+		CreateLoadBalancer: elb.CreateLoadBalancer{
+			Name:       "testlb",
+			AvailZones: []string{"us-east-1a"},
+			Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+		},
+		Instances: []elbtest.ScenarioInstance{
+			{InstanceId: "i-1", State: elb.StateInService},
+			{InstanceId: "i-2", State: elb.StateInService},
+			{InstanceId: "i-3", State: elb.StateInService},
+			{InstanceId: "i-4", State: elb.StateUnknown},
+		},
+	}}})
+	c.Assert(err, IsNil)
+	defer srv.RemoveLoadBalancer("testlb")
+	summary, err := s.clientTests.elb.SummarizeHealth("testlb", true)
+	c.Assert(err, IsNil)
+	c.Assert(summary.HealthyTargets, DeepEquals, []string{"i-1", "i-2", "i-3"})
+	c.Assert(summary.UnhealthyTargets, DeepEquals, []string{"i-4"})
+	c.Assert(summary.HealthRatio, Equals, 0.75)
+	srv.NewLoadBalancer("emptylb")
+	defer srv.RemoveLoadBalancer("emptylb")
+	summary, err = s.clientTests.elb.SummarizeHealth("emptylb", false)
+	c.Assert(err, IsNil)
+	c.Assert(summary, DeepEquals, &elb.HealthSummary{})
+	_, err = s.clientTests.elb.SummarizeHealth("emptylb", true)
+	c.Assert(err, Equals, elb.ErrNoInstances)
+}
+
+func (s *LocalServerSuite) TestWaitUntilInstanceInService(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	srv.RegisterInstance(instId, "testlb")
+	err := s.clientTests.elb.WaitUntilInstanceInService(context.Background(), "testlb", []string{instId}, fastWaiter)
+	c.Assert(err, Equals, elb.ErrWaitTimeout)
+	srv.ChangeInstanceState("testlb", elb.InstanceState{InstanceId: instId, State: "InService"})
+	err = s.clientTests.elb.WaitUntilInstanceInService(context.Background(), "testlb", []string{instId}, fastWaiter)
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestSwapInstances(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	blue, green := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(blue)
+	defer srv.RemoveInstance(green)
+	srv.RegisterInstance(blue, "testlb")
+	srv.SetInstanceState("testlb", blue, elb.StateInService, "N/A", "N/A")
+	srv.SetInServiceAfter(1)
+	defer srv.SetInServiceAfter(0)
+	opts := &elb.SwapOptions{Waiter: fastWaiter}
+	err := s.clientTests.elb.SwapInstances("testlb", []string{blue}, []string{green}, opts)
+	c.Assert(err, IsNil)
+	c.Assert(srv.LoadBalancer("testlb").Description.Instances, DeepEquals, []elb.Instance{{InstanceId: green}})
+}
+
+func (s *LocalServerSuite) TestSwapInstancesRollsBack(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	blue, green := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(blue)
+	defer srv.RemoveInstance(green)
+	srv.RegisterInstance(blue, "testlb")
+	srv.SetInstanceState("testlb", blue, elb.StateInService, "N/A", "N/A")
+	opts := &elb.SwapOptions{Waiter: fastWaiter}
+	// The green instance never becomes InService.
+	err := s.clientTests.elb.SwapInstances("testlb", []string{blue}, []string{green}, opts)
+	c.Assert(err, Equals, elb.ErrWaitTimeout)
+	c.Assert(srv.LoadBalancer("testlb").Description.Instances, DeepEquals, []elb.Instance{{InstanceId: blue}})
+	err = s.clientTests.elb.SwapInstances("testlb", []string{blue}, []string{green, "i-absent"}, opts)
+	c.Assert(err, ErrorMatches, `.*\(InvalidInstance\)$`)
+	c.Assert(srv.LoadBalancer("testlb").Description.Instances, DeepEquals, []elb.Instance{{InstanceId: blue}})
+}
+
+func (s *LocalServerSuite) TestSwapInstancesRollsBackOnCancel(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	blue, green := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(blue)
+	defer srv.RemoveInstance(green)
+	srv.RegisterInstance(blue, "testlb")
+	srv.SetInstanceState("testlb", blue, elb.StateInService, "N/A", "N/A")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The caller gives up while waiting for the green instance.
+	remove := srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		if action == "DescribeInstanceHealth" && ctx.Err() == nil {
+			cancel()
+			<-req.Context().Done()
+		}
+		return next(req)
+	})
+	defer remove()
+	err := s.clientTests.elb.SwapInstancesWithContext(ctx, "testlb", []string{blue}, []string{green}, &elb.SwapOptions{Waiter: fastWaiter})
+	c.Assert(errors.Is(err, context.Canceled), Equals, true)
+	c.Assert(srv.LoadBalancer("testlb").Description.Instances, DeepEquals, []elb.Instance{{InstanceId: blue}})
+}
+
+func (s *LocalServerSuite) TestRotateInstances(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	var oldIds, newIds []string
+	for i := 0; i < 3; i++ {
+		id := srv.NewInstance()
+		defer srv.RemoveInstance(id)
+		srv.RegisterInstance(id, "testlb")
+		srv.SetInstanceState("testlb", id, elb.StateInService, "N/A", "N/A")
+		oldIds = append(oldIds, id)
+	}
+	for i := 0; i < 2; i++ {
+		id := srv.NewInstance()
+		defer srv.RemoveInstance(id)
+		newIds = append(newIds, id)
+	}
+	srv.SetInServiceAfter(1)
+	defer srv.SetInServiceAfter(0)
+	var progress []elb.RotateProgress
+	opts := &elb.RotateOptions{
+		BatchSize: 2,
+		Waiter:    fastWaiter,
+		Progress:  func(p elb.RotateProgress) { progress = append(progress, p) },
+	}
+	err := s.clientTests.elb.RotateInstances("testlb", oldIds, newIds, opts)
+	c.Assert(err, IsNil)
+	c.Assert(progress, DeepEquals, []elb.RotateProgress{
+		{Batch: 1, Batches: 2, Added: newIds, Removed: oldIds[:2]},
+		{Batch: 2, Batches: 2, Added: []string{}, Removed: oldIds[2:]},
+	})
+	lb := srv.LoadBalancer("testlb")
+	c.Assert(lb.Description.Instances, DeepEquals, []elb.Instance{{InstanceId: newIds[0]}, {InstanceId: newIds[1]}})
+}
+
+func (s *LocalServerSuite) TestRotateInstancesRollsBack(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	old1, old2, new1 := srv.NewInstance(), srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(old1)
+	defer srv.RemoveInstance(old2)
+	defer srv.RemoveInstance(new1)
+	srv.RegisterInstance(old1, "testlb")
+	srv.RegisterInstance(old2, "testlb")
+	srv.SetInServiceAfter(1)
+	defer srv.SetInServiceAfter(0)
+	// The second batch fails, as its instance doesn't exist.
+	err := s.clientTests.elb.RotateInstances("testlb", []string{old1, old2}, []string{new1, "i-absent"}, &elb.RotateOptions{Waiter: fastWaiter})
+	c.Assert(err, FitsTypeOf, &elb.RotateError{})
+	c.Assert(err.(*elb.RotateError).Batch, Equals, 2)
+	c.Assert(err.(*elb.RotateError).RollbackErr, IsNil)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidInstance)
+	ids := []string{}
+	for _, inst := range srv.LoadBalancer("testlb").Description.Instances {
+		ids = append(ids, inst.InstanceId)
+	}
+	sort.Strings(ids)
+	c.Assert(ids, DeepEquals, []string{old1, old2})
+}
+
+func (s *LocalServerSuite) TestRotateInstancesRollsBackOnCancel(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	old1, old2, new1, new2 := srv.NewInstance(), srv.NewInstance(), srv.NewInstance(), srv.NewInstance()
+	for _, id := range []string{old1, old2, new1, new2} {
+		defer srv.RemoveInstance(id)
+	}
+	srv.RegisterInstance(old1, "testlb")
+	srv.RegisterInstance(old2, "testlb")
+	srv.SetInServiceAfter(1)
+	defer srv.SetInServiceAfter(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The caller gives up while the second batch waits for its instance.
+	var registered int32
+	remove := srv.Use(func(action string, req *http.Request, next elbtest.Handler) (interface{}, error) {
+		switch {
+		case action == "RegisterInstancesWithLoadBalancer" && req.Form.Get("Instances.member.1.InstanceId") == new2:
+			atomic.StoreInt32(&registered, 1)
+		case action == "DescribeInstanceHealth" && atomic.LoadInt32(&registered) == 1 && ctx.Err() == nil:
+			cancel()
+			<-req.Context().Done()
+		}
+		return next(req)
+	})
+	defer remove()
+	err := s.clientTests.elb.RotateInstancesWithContext(ctx, "testlb", []string{old1, old2}, []string{new1, new2}, &elb.RotateOptions{Waiter: fastWaiter})
+	c.Assert(err, FitsTypeOf, &elb.RotateError{})
+	c.Assert(err.(*elb.RotateError).Batch, Equals, 2)
+	c.Assert(errors.Is(err, context.Canceled), Equals, true)
+	c.Assert(err.(*elb.RotateError).RollbackErr, IsNil)
+	ids := []string{}
+	for _, inst := range srv.LoadBalancer("testlb").Description.Instances {
+		ids = append(ids, inst.InstanceId)
+	}
+	sort.Strings(ids)
+	c.Assert(ids, DeepEquals, []string{old1, old2})
+}
+
+func (s *LocalServerSuite) TestWaitUntilInstanceOutOfService(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	srv.RegisterInstance(instId, "testlb")
+	srv.ChangeInstanceState("testlb", elb.InstanceState{InstanceId: instId, State: "InService"})
+	err := s.clientTests.elb.WaitUntilInstanceOutOfService(context.Background(), "testlb", []string{instId}, fastWaiter)
+	c.Assert(err, Equals, elb.ErrWaitTimeout)
+	srv.DeregisterInstance(instId, "testlb")
+	err = s.clientTests.elb.WaitUntilInstanceOutOfService(context.Background(), "testlb", []string{instId}, fastWaiter)
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestWaitUntilLoadBalancerExistsAndDeleted(c *C) {
+	srv := s.srv.srv
+	err := s.clientTests.elb.WaitUntilLoadBalancerExists(context.Background(), "testlb", fastWaiter)
+	c.Assert(err, Equals, elb.ErrWaitTimeout)
+	err = s.clientTests.elb.WaitUntilLoadBalancerDeleted(context.Background(), "testlb", fastWaiter)
+	c.Assert(err, IsNil)
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	err = s.clientTests.elb.WaitUntilLoadBalancerExists(context.Background(), "testlb", fastWaiter)
+	c.Assert(err, IsNil)
+	err = s.clientTests.elb.WaitUntilLoadBalancerDeleted(context.Background(), "testlb", fastWaiter)
+	c.Assert(err, Equals, elb.ErrWaitTimeout)
+}
+
+func (s *LocalServerSuite) TestWaiterStopsWhenContextIsDone(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	cfg := &elb.WaiterConfig{Delay: time.Millisecond, MaxWait: time.Minute}
+	err := s.clientTests.elb.WaitUntilLoadBalancerExists(ctx, "testlb", cfg)
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true)
+}
+
+func (s *LocalServerSuite) TestSetError(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	srv.SetError("DescribeLoadBalancers", &elb.Error{Code: elb.ErrLoadBalancerNotFound, Message: "induced"}, 1)
+	_, err := s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, ErrorMatches, `^induced \(LoadBalancerNotFound\)$`)
+	c.Assert(err.(*elb.Error).StatusCode, Equals, 400)
+	_, err = s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestSetErrorIsRetried(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	policy := elb.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithRetryPolicy(policy))
+	srv.SetError("DescribeLoadBalancers", &elb.Error{StatusCode: 500, Code: "InternalFailure"}, 2)
+	_, err := client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	srv.SetError("DescribeLoadBalancers", &elb.Error{StatusCode: 400, Code: elb.ErrThrottling}, 3)
+	_, err = client.DescribeLoadBalancers("testlb")
+	c.Assert(elb.IsThrottling(err), Equals, true)
+}
+
+func (s *LocalServerSuite) TestClearErrors(c *C) {
+	srv := s.srv.srv
+	srv.SetError("DescribeLoadBalancers", &elb.Error{StatusCode: 503, Code: "ServiceUnavailable"}, 0)
+	policy := elb.RetryPolicy{MaxAttempts: 1}
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithRetryPolicy(policy))
+	for i := 0; i < 3; i++ {
+		_, err := client.DescribeLoadBalancers()
+		c.Assert(err, NotNil)
+	}
+	srv.ClearErrors()
+	_, err := client.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestRequestHistory(c *C) {
+	srv := s.srv.srv
+	srv.ResetHistory()
+	createLB := s.createLoadBalancer(c)
+	defer srv.RemoveLoadBalancer(createLB.Name)
+	_, err := s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	_, err = s.clientTests.elb.DescribeLoadBalancers("absentlb")
+	c.Assert(err, NotNil)
+	reqs := srv.Requests()
+	c.Assert(reqs, HasLen, 3)
+	c.Assert(reqs[0].Action, Equals, "CreateLoadBalancer")
+	c.Assert(reqs[0].Params.Get("LoadBalancerName"), Equals, "testlb")
+	c.Assert(reqs[1].Action, Equals, "DescribeLoadBalancers")
+	c.Assert(reqs[2].Params.Get("LoadBalancerNames.member.1"), Equals, "absentlb")
+	c.Assert(reqs[2].RequestId, Equals, err.(*elb.Error).RequestId)
+	c.Assert(reqs[1].Time.After(reqs[2].Time), Equals, false)
+	describes := srv.RequestsFor("DescribeLoadBalancers")
+	c.Assert(describes, HasLen, 2)
+	c.Assert(describes[0].RequestId, Equals, reqs[1].RequestId)
+	srv.ResetHistory()
+	c.Assert(srv.Requests(), HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestRegisterAndDeregisterInstancesAreReflectedInDescriptions(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	inst1, inst2 := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(inst1)
+	defer srv.RemoveInstance(inst2)
+	resp, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{inst1, inst2}, "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceIds, DeepEquals, []string{inst1, inst2})
+	// Registering an instance again doesn't duplicate it.
+	resp, err = s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{inst1}, "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceIds, DeepEquals, []string{inst1, inst2})
+	lbs, err := s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(lbs.LoadBalancerDescriptions[0].Instances, DeepEquals, []elb.Instance{{InstanceId: inst1}, {InstanceId: inst2}})
+	health, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(health.InstanceStates, HasLen, 2)
+	_, err = s.clientTests.elb.DeregisterInstancesFromLoadBalancer([]string{inst1}, "testlb")
+	c.Assert(err, IsNil)
+	lbs, err = s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(lbs.LoadBalancerDescriptions[0].Instances, DeepEquals, []elb.Instance{{InstanceId: inst2}})
+	health, err = s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(health.InstanceStates, HasLen, 1)
+	c.Assert(health.InstanceStates[0].InstanceId, Equals, inst2)
+}
+
+func (s *LocalServerSuite) TestSetInstanceState(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	srv.RegisterInstance(instId, "testlb")
+	srv.SetInstanceState("testlb", instId, "InService", "N/A", "N/A")
+	resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb", instId)
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates, DeepEquals, []elb.InstanceState{
+		{Description: "N/A", InstanceId: instId, ReasonCode: "N/A", State: "InService"},
+	})
+}
+
+func (s *LocalServerSuite) TestSetHealthFailure(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	elbInst, instInst := srv.NewInstance(), srv.NewInstance()
+	defer srv.RemoveInstance(elbInst)
+	defer srv.RemoveInstance(instInst)
+	srv.RegisterInstance(elbInst, "testlb")
+	srv.RegisterInstance(instInst, "testlb")
+	srv.SetHealthFailure("testlb", elbInst, elbtest.CauseELB)
+	srv.SetHealthFailure("testlb", instInst, elbtest.CauseInstance)
+	resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb", elbInst, instInst)
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates, DeepEquals, []elb.InstanceState{
+		{Description: "Instance registration is still in progress.", InstanceId: elbInst, ReasonCode: elb.ReasonCodeELB, State: elb.StateOutOfService},
+		{Description: "Instance has failed at least the UnhealthyThreshold number of health checks consecutively.", InstanceId: instInst, ReasonCode: elb.ReasonCodeInstance, State: elb.StateOutOfService},
+	})
+
+	// Instances that aren't registered are left alone.
+	other := srv.NewInstance()
+	defer srv.RemoveInstance(other)
+	srv.SetHealthFailure("testlb", other, elbtest.CauseInstance)
+	resp, err = s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates, HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestSetInServiceAfter(c *C) {
+	srv := s.srv.srv
+	srv.SetInServiceAfter(2)
+	defer srv.SetInServiceAfter(0)
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	_, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{instId}, "testlb")
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
+	resp, err = s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateInService)
+	err = s.clientTests.elb.WaitUntilInstanceInService(context.Background(), "testlb", []string{instId}, fastWaiter)
+	c.Assert(err, IsNil)
+}
+
+func (s *LocalServerSuite) TestSetInServiceAfterIgnoresExplicitStates(c *C) {
+	srv := s.srv.srv
+	srv.SetInServiceAfter(1)
+	defer srv.SetInServiceAfter(0)
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	srv.RegisterInstance(instId, "testlb")
+	srv.SetInstanceState("testlb", instId, "OutOfService", "Instance", "Instance has failed at least the UnhealthyThreshold number of health checks consecutively.")
+	resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateOutOfService)
+}
+
+func (s *LocalServerSuite) TestConfigureHealthCheckIsPersisted(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	hc := elb.HealthCheck{
+		HealthyThreshold:   3,
+		Interval:           10,
+		Target:             "HTTP:8080/health",
+		Timeout:            4,
+		UnhealthyThreshold: 5,
+	}
+	_, err := s.clientTests.elb.ConfigureHealthCheck("testlb", &hc)
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].HealthCheck, DeepEquals, hc)
+}
+
+func (s *LocalServerSuite) TestConfigureHealthCheckTargets(c *C) {
+	srv := s.srv.srv
+	srv.NewLoadBalancer("testlb")
+	defer srv.RemoveLoadBalancer("testlb")
+	hc := elb.HealthCheck{HealthyThreshold: 2, Interval: 30, Timeout: 5, UnhealthyThreshold: 2}
+	for _, target := range []string{elb.TCPTarget(80), elb.SSLTarget(443), elb.HTTPTarget(80, "/ping"), elb.HTTPSTarget(443, "/")} {
+		hc.Target = target
+		_, err := s.clientTests.elb.ConfigureHealthCheck("testlb", &hc)
+		c.Assert(err, IsNil)
+	}
+	tests := []struct {
+		target string
+		err    string
+	}{
+		{"HTTP:80", `HealthCheck HTTP Target must specify a port followed by a path that begins with a slash. e.g. HTTP:80/ping/this/path \(ValidationError\)`},
+		{"TCP:80/ping", `HealthCheck TCP Target must specify a port without a path. e.g. TCP:80 \(ValidationError\)`},
+		{"UDP:53", `HealthCheck Target protocol must be one of HTTP, HTTPS, TCP or SSL. e.g. TCP:80 \(ValidationError\)`},
+	}
+	for _, t := range tests {
+		hc.Target = t.target
+		_, err := s.clientTests.elb.ConfigureHealthCheck("testlb", &hc)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *LocalServerSuite) TestConfigureHealthCheckWithAbsentLoadBalancer(c *C) {
+	hc := elb.HealthCheck{Target: "HTTP:80/", HealthyThreshold: 2, Interval: 30, Timeout: 5, UnhealthyThreshold: 2}
+	_, err := s.clientTests.elb.ConfigureHealthCheck("absentlb", &hc)
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+}
+
+func (s *LocalServerSuite) TestCache(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithCache(time.Hour))
+	s.srv.srv.ResetHistory()
+	resp, err := client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].Instances, HasLen, 0)
+	// Results are copies, which callers may modify.
+	resp.LoadBalancerDescriptions[0].LoadBalancerName = "modified"
+	resp, err = client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].LoadBalancerName, Equals, "testlb")
+	_, err = client.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	_, err = client.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	_, err = client.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.srv.RequestsFor("DescribeLoadBalancers"), HasLen, 2)
+	c.Assert(s.srv.srv.RequestsFor("DescribeInstanceHealth"), HasLen, 1)
+
+	instId := s.srv.srv.NewInstance()
+	defer s.srv.srv.RemoveInstance(instId)
+	_, err = client.RegisterInstancesWithLoadBalancer([]string{instId}, "testlb")
+	c.Assert(err, IsNil)
+	resp, err = client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].Instances, HasLen, 1)
+	health, err := client.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(health.InstanceStates, HasLen, 1)
+	c.Assert(s.srv.srv.RequestsFor("DescribeLoadBalancers"), HasLen, 3)
+	c.Assert(s.srv.srv.RequestsFor("DescribeInstanceHealth"), HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestCacheExpires(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	client := elb.New(s.srv.auth, s.srv.region, elb.WithCache(10*time.Millisecond))
+	s.srv.srv.ResetHistory()
+	for i := 0; i < 2; i++ {
+		_, err := client.DescribeLoadBalancers("testlb")
+		c.Assert(err, IsNil)
+	}
+	c.Assert(s.srv.srv.RequestsFor("DescribeLoadBalancers"), HasLen, 1)
+	time.Sleep(20 * time.Millisecond)
+	_, err := client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.srv.RequestsFor("DescribeLoadBalancers"), HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestResetCompatibilityLevel(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	e := elb.New(s.srv.auth, aws.Region{ELBEndpoint: srv.URL()})
+	// A listener without InstancePort, sent as is since the client checks
+	// listeners.
+	params := func() map[string]string {
+		return map[string]string{
+			"Action":                              "CreateLoadBalancer",
+			"Listeners.member.1.Protocol":         "HTTP",
+			"Listeners.member.1.LoadBalancerPort": "80",
+			"AvailabilityZones.member.1":          "us-east-1a",
+		}
+	}
+	srv.SetCompatibilityLevel(elbtest.CompatibilityAWS)
+	err = e.Query(context.Background(), "2012-06-01", params(), new(elb.CreateLoadBalancerResp))
+	c.Assert(err, ErrorMatches, `2 validation errors detected: .*`)
+	srv.Reset()
+	err = e.Query(context.Background(), "2012-06-01", params(), new(elb.CreateLoadBalancerResp))
+	c.Assert(err, ErrorMatches, `Listeners.member.1.InstancePort is required. \(ValidationError\)`)
+}
+
+func (s *LocalServerSuite) TestCompatibilityLevel(c *C) {
+	defer s.srv.srv.SetCompatibilityLevel(elbtest.CompatibilityLegacy)
+	noName := &elb.CreateLoadBalancer{
+		AvailZones: []string{"us-east-1a"},
+		Listeners:  []elb.Listener{{InstancePort: 80, Protocol: "HTTP", LoadBalancerPort: 80}},
+	}
+	_, err := s.clientTests.elb.CreateLoadBalancer(noName)
+	c.Assert(err, ErrorMatches, `Listeners.member.1.InstanceProtocol is required. \(ValidationError\)`)
+
+	s.srv.srv.SetCompatibilityLevel(elbtest.CompatibilityAWS)
+	_, err = s.clientTests.elb.CreateLoadBalancer(noName)
+	c.Assert(err, ErrorMatches, `1 validation error detected: Value null at 'loadBalancerName' failed to satisfy constraint: Member must not be null \(ValidationError\)`)
+	// The client checks listeners, so they're sent as is.
+	params := map[string]string{
+		"Action":                              "CreateLoadBalancer",
+		"Listeners.member.1.Protocol":         "HTTP",
+		"Listeners.member.1.LoadBalancerPort": "80",
+		"Listeners.member.1.InstancePort":     "80",
+		"Listeners.member.2.Protocol":         "TCP",
+		"Subnets.member.1":                    "subnet-1",
+		"AvailabilityZones.member.1":          "us-east-1a",
+	}
+	err = s.clientTests.elb.Query(context.Background(), "2012-06-01", params, new(elb.CreateLoadBalancerResp))
+	c.Assert(err, ErrorMatches, `3 validation errors detected: `+
+		`Value null at 'loadBalancerName' failed to satisfy constraint: Member must not be null; `+
+		`Value null at 'listeners.2.member.loadBalancerPort' failed to satisfy constraint: Member must not be null; `+
+		`Value null at 'listeners.2.member.instancePort' failed to satisfy constraint: Member must not be null \(ValidationError\)`)
+
+	noName.Name = "testlb"
+	noName.Subnets = []string{"subnet-1"}
+	_, err = s.clientTests.elb.CreateLoadBalancer(noName)
+	c.Assert(err, ErrorMatches, `Only one of SubnetIds or AvailabilityZones may be specified \(ValidationError\)`)
+	noName.Subnets = nil
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	_, err = s.clientTests.elb.CreateLoadBalancer(noName)
+	c.Assert(err, IsNil)
+	lb := s.srv.srv.LoadBalancer("testlb")
+	c.Assert(lb.Description.ListenerDescriptions[0].Listener.InstanceProtocol, Equals, "HTTP")
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerWithManyZones(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	zones := []string{"us-east-1a", "us-east-1b", "us-east-1c"}
+	_, err := s.clientTests.elb.CreateLoadBalancer(&elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: zones,
+		Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", Protocol: "HTTP", LoadBalancerPort: 80}},
+	})
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].AvailZones, DeepEquals, zones)
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerWithManySubnets(c *C) {
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	subnets := []string{"subnet-1", "subnet-2"}
+	groups := []string{"sg-1", "sg-2", "sg-3"}
+	_, err := s.clientTests.elb.CreateLoadBalancer(&elb.CreateLoadBalancer{
+		Name:           "testlb",
+		Subnets:        subnets,
+		SecurityGroups: groups,
+		Listeners:      []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", Protocol: "HTTP", LoadBalancerPort: 80}},
+	})
+	c.Assert(err, IsNil)
+	resp, err := s.clientTests.elb.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions[0].Subnets, DeepEquals, subnets)
+	c.Assert(resp.LoadBalancerDescriptions[0].SecurityGroups, DeepEquals, groups)
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerCompositionChecksAllMembers(c *C) {
+	params := map[string]string{
+		"Action":                              "CreateLoadBalancer",
+		"LoadBalancerName":                    "testlb",
+		"Listeners.member.1.Protocol":         "HTTP",
+		"Listeners.member.1.LoadBalancerPort": "80",
+		"Listeners.member.1.InstanceProtocol": "HTTP",
+		"Listeners.member.1.InstancePort":     "80",
+		"AvailabilityZones.member.1":          "us-east-1a",
+		"Subnets.member.2":                    "subnet-2",
+	}
+	err := s.clientTests.elb.Query(context.Background(), "2012-06-01", params, new(elb.CreateLoadBalancerResp))
+	c.Assert(err, ErrorMatches, "Only one of .* may be specified .*")
+	delete(params, "AvailabilityZones.member.1")
+	defer s.srv.srv.RemoveLoadBalancer("testlb")
+	err = s.clientTests.elb.Query(context.Background(), "2012-06-01", params, new(elb.CreateLoadBalancerResp))
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.srv.LoadBalancer("testlb").Description.Subnets, DeepEquals, []string{"subnet-2"})
+}
+
+func (s *LocalServerSuite) TestSetRegion(c *C) {
+	srv := s.srv.srv
+	defer srv.SetRegion("us-east-1")
+	c.Assert(srv.Region().Name, Equals, "us-east-1")
+	srv.SetRegion("eu-west-1")
+	region := srv.Region()
+	c.Assert(region.Name, Equals, "eu-west-1")
+	c.Assert(region.ELBEndpoint, Equals, srv.URL())
+	client := elb.New(s.srv.auth, region)
+	defer srv.RemoveLoadBalancer("testlb")
+	dnsName, err := client.CreateLoadBalancerIfNotExists(&elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: []string{"eu-west-1a"},
+		Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", Protocol: "HTTP", LoadBalancerPort: 80}},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(dnsName, Equals, "testlb-some-aws-stuff.eu-west-1.elb.amazonaws.com")
+	resp, err := client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	target, err := resp.LoadBalancerDescriptions[0].DualStackAliasTarget()
+	c.Assert(err, IsNil)
+	c.Assert(target, Equals, elb.AliasTarget{
+		HostedZoneId: "Z32O12XQLNTSW2",
+		DNSName:      "dualstack.testlb-some-aws-stuff.eu-west-1.elb.amazonaws.com.",
+	})
+
+	srv.SetRegion("cn-north-1")
+	srv.NewLoadBalancer("cnlb")
+	defer srv.RemoveLoadBalancer("cnlb")
+	c.Assert(srv.LoadBalancer("cnlb").Description.DNSName, Equals, "cnlb-some-aws-stuff.cn-north-1.elb.amazonaws.com.cn")
+	c.Assert(elb.New(aws.Auth{}, srv.Region()).SignatureVersion(), Equals, elb.SignatureV4)
+}
+
+func (s *LocalServerSuite) TestClock(c *C) {
+	srv := s.srv.srv
+	t0 := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return t0 })
+	defer srv.SetClock(nil)
+	defer srv.SetInServiceDelay(0)
+	defer srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	c.Assert(srv.LoadBalancer("testlb").Description.CreatedTime.Equal(t0), Equals, true)
+	requests := srv.RequestsFor("CreateLoadBalancer")
+	c.Assert(requests[len(requests)-1].Time.Equal(t0), Equals, true)
+
+	srv.SetInServiceDelay(30 * time.Second)
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	_, err := s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{instId}, "testlb")
+	c.Assert(err, IsNil)
+	health := func() elb.InstanceState {
+		resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb", instId)
+		c.Assert(err, IsNil)
+		return resp.InstanceStates[0]
+	}
+	srv.AdvanceTime(29 * time.Second)
+	c.Assert(health().State, Equals, elb.StateOutOfService)
+	srv.AdvanceTime(time.Second)
+	c.Assert(health().State, Equals, elb.StateInService)
+}
+
+func (s *LocalServerSuite) TestClockConnectionDraining(c *C) {
+	srv := s.srv.srv
+	srv.SetClock(func() time.Time { return time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC) })
+	defer srv.SetClock(nil)
+	defer srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	err := s.clientTests.elb.EnableConnectionDraining("testlb", time.Minute)
+	c.Assert(err, IsNil)
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	srv.RegisterInstance(instId, "testlb")
+	srv.SetInstanceState("testlb", instId, elb.StateInService, "N/A", "N/A")
+	_, err = s.clientTests.elb.DeregisterInstancesFromLoadBalancer([]string{instId}, "testlb")
+	c.Assert(err, IsNil)
+
+	srv.AdvanceTime(59 * time.Second)
+	resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb", instId)
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates[0].State, Equals, elb.StateInService)
+	c.Assert(resp.InstanceStates[0].Description, Equals, "Instance deregistration currently in progress.")
+	resp, err = s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates, HasLen, 1)
+	c.Assert(srv.LoadBalancer("testlb").Description.Instances, HasLen, 0)
+
+	srv.AdvanceTime(time.Second)
+	resp, err = s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.InstanceStates, HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestLifecycle(c *C) {
+	srv := s.srv.srv
+	t0 := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return t0 })
+	defer srv.SetClock(nil)
+	srv.SetLifecycle(&elbtest.Lifecycle{
+		Registering:  10 * time.Second,
+		OutOfService: 20 * time.Second,
+		Draining:     30 * time.Second,
+	})
+	defer srv.SetLifecycle(nil)
+	defer srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	err := s.clientTests.elb.EnableConnectionDraining("testlb", time.Minute)
+	c.Assert(err, IsNil)
+	var events []elbtest.Event
+	unsubscribe := srv.Subscribe(func(e elbtest.Event) {
+		if e.Type == elbtest.InstancePhaseChanged {
+			events = append(events, e)
+		}
+	})
+	defer unsubscribe()
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+	health := func() []elb.InstanceState {
+		resp, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+		c.Assert(err, IsNil)
+		return resp.InstanceStates
+	}
+
+	_, err = s.clientTests.elb.RegisterInstancesWithLoadBalancer([]string{instId}, "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(srv.InstancePhase("testlb", instId), Equals, elbtest.PhaseRegistering)
+	state := health()[0]
+	c.Assert(state.State, Equals, elb.StateOutOfService)
+	c.Assert(state.ReasonCode, Equals, "ELB")
+
+	srv.AdvanceTime(10 * time.Second)
+	state = health()[0]
+	c.Assert(state.State, Equals, elb.StateOutOfService)
+	c.Assert(state.ReasonCode, Equals, "Instance")
+	c.Assert(srv.InstancePhase("testlb", instId), Equals, elbtest.PhaseOutOfService)
+
+	srv.AdvanceTime(20 * time.Second)
+	c.Assert(health()[0].State, Equals, elb.StateInService)
+
+	_, err = s.clientTests.elb.DeregisterInstancesFromLoadBalancer([]string{instId}, "testlb")
+	c.Assert(err, IsNil)
+	c.Assert(srv.InstancePhase("testlb", instId), Equals, elbtest.PhaseDraining)
+	state = health()[0]
+	c.Assert(state.State, Equals, elb.StateInService)
+	c.Assert(state.Description, Equals, "Instance deregistration currently in progress.")
+
+	srv.AdvanceTime(30 * time.Second)
+	c.Assert(health(), HasLen, 0)
+	c.Assert(srv.InstancePhase("testlb", instId), Equals, elbtest.PhaseDeregistered)
+
+	type transition struct{ from, to elbtest.LifecyclePhase }
+	var transitions []transition
+	for _, e := range events {
+		c.Assert(e.LoadBalancerName, Equals, "testlb")
+		c.Assert(e.InstanceId, Equals, instId)
+		transitions = append(transitions, transition{e.From, e.To})
+	}
+	c.Assert(transitions, DeepEquals, []transition{
+		{elbtest.PhaseDeregistered, elbtest.PhaseRegistering},
+		{elbtest.PhaseRegistering, elbtest.PhaseOutOfService},
+		{elbtest.PhaseOutOfService, elbtest.PhaseInService},
+		{elbtest.PhaseInService, elbtest.PhaseDraining},
+		{elbtest.PhaseDraining, elbtest.PhaseDeregistered},
+	})
+	c.Assert(events[1].Time.Equal(t0.Add(10*time.Second)), Equals, true)
+	c.Assert(events[2].Time.Equal(t0.Add(30*time.Second)), Equals, true)
+	c.Assert(events[4].Time.Equal(t0.Add(60*time.Second)), Equals, true)
+}
+
+func (s *LocalServerSuite) TestLifecycleOfHelpersNotifiesAtOnce(c *C) {
+	srv := s.srv.srv
+	srv.SetLifecycle(&elbtest.Lifecycle{Registering: time.Minute})
+	defer srv.SetLifecycle(nil)
+	defer srv.RemoveLoadBalancer("testlb")
+	s.createLoadBalancer(c)
+	var events []elbtest.Event
+	unsubscribe := srv.Subscribe(func(e elbtest.Event) {
+		if e.Type == elbtest.InstancePhaseChanged {
+			events = append(events, e)
+		}
+	})
+	defer unsubscribe()
+	instId := srv.NewInstance()
+	defer srv.RemoveInstance(instId)
+
+	srv.RegisterInstance(instId, "testlb")
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].To, Equals, elbtest.PhaseRegistering)
+	c.Assert(events[0].RequestId, Equals, "")
+	c.Assert(events[0].Params, IsNil)
+	_, err := s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 1)
+
+	srv.DeregisterInstance(instId, "testlb")
+	c.Assert(events, HasLen, 2)
+	c.Assert(events[1].To, Equals, elbtest.PhaseDeregistered)
+	c.Assert(events[1].RequestId, Equals, "")
+	_, err = s.clientTests.elb.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestNamespaces(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	srv.SetNamespaceFunc(elbtest.NamespaceByAccessKey)
+	region := aws.Region{ELBEndpoint: srv.URL()}
+	alice := elb.New(aws.Auth{AccessKey: "alice", SecretKey: "123"}, region)
+	bob := elb.New(aws.Auth{AccessKey: "bob", SecretKey: "123"}, region, elb.WithSignatureVersion(elb.SignatureV4))
+	create := &elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners:  []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", Protocol: "HTTP", LoadBalancerPort: 80}},
+	}
+	_, err = alice.CreateLoadBalancer(create)
+	c.Assert(err, IsNil)
+	create.Listeners[0].InstancePort = 8080
+	_, err = bob.CreateLoadBalancer(create)
+	c.Assert(err, IsNil)
+
+	inst := srv.Namespace("bob").NewInstance()
+	_, err = alice.RegisterInstancesWithLoadBalancer([]string{inst}, "testlb")
+	c.Assert(elb.ErrorCode(err), Equals, "InvalidInstance")
+	_, err = bob.RegisterInstancesWithLoadBalancer([]string{inst}, "testlb")
+	c.Assert(err, IsNil)
+
+	resp, err := alice.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+	c.Assert(resp.LoadBalancerDescriptions[0].ListenerDescriptions[0].Listener.InstancePort, Equals, 80)
+	c.Assert(resp.LoadBalancerDescriptions[0].Instances, HasLen, 0)
+	lb := srv.Namespace("bob").LoadBalancer("testlb")
+	c.Assert(lb.Description.ListenerDescriptions[0].Listener.InstancePort, Equals, 8080)
+	c.Assert(lb.Description.Instances, HasLen, 1)
+	c.Assert(srv.LoadBalancer("testlb"), IsNil)
+
+	c.Assert(srv.Requests(), HasLen, 5)
+	requests := srv.Namespace("alice").Requests()
+	c.Assert(requests, HasLen, 3)
+	c.Assert(requests[0].Namespace, Equals, "alice")
+	srv.Namespace("alice").Reset()
+	c.Assert(srv.Namespace("alice").LoadBalancer("testlb"), IsNil)
+	c.Assert(srv.Namespace("bob").LoadBalancer("testlb"), NotNil)
+	c.Assert(srv.Requests(), HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestNamespaceByHeader(c *C) {
+	srv, err := elbtest.NewServer()
+	c.Assert(err, IsNil)
+	defer srv.Quit()
+	srv.SetNamespaceFunc(elbtest.NamespaceByHeader("X-Test-Namespace"))
+	var events []elbtest.Event
+	srv.Namespace("ns1").Subscribe(func(e elbtest.Event) { events = append(events, e) })
+	srv.Namespace("ns1").NewLoadBalancer("testlb")
+	srv.Namespace("ns2").NewLoadBalancer("testlb")
+	for _, ns := range []string{"ns1", "ns2"} {
+		req, err := http.NewRequest("GET", srv.URL()+"/?Action=DeleteLoadBalancer&LoadBalancerName=testlb", nil)
+		c.Assert(err, IsNil)
+		req.Header.Set("X-Test-Namespace", ns)
+		resp, err := http.DefaultClient.Do(req)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, 200)
+	}
+	c.Assert(srv.Namespace("ns1").LoadBalancer("testlb"), IsNil)
+	c.Assert(srv.Namespace("ns2").LoadBalancer("testlb"), IsNil)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].Namespace, Equals, "ns1")
+}
@@ -0,0 +1,110 @@
+package elbtest
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SetClock makes the server read the current time from now instead of the
+// system clock, and cancels the time added by AdvanceTime. The clock dates
+// the Load Balancers and recorded requests, and drives the transitions set
+// by SetInServiceDelay and connection draining, so tests depending on them
+// don't have to sleep. A nil now restores the system clock.
+//
+// The clock of the server doesn't apply to the Timestamp of the requests
+// checked in strict mode, which clients set from the system clock.
+func (srv *Server) SetClock(now func() time.Time) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.clock = now
+	srv.clockOffset = 0
+}
+
+// AdvanceTime moves the clock of the server forward by d.
+func (srv *Server) AdvanceTime(d time.Duration) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.clockOffset += d
+}
+
+// now returns the current time according to the clock of the server.
+func (srv *Server) now() time.Time {
+	t := time.Now()
+	if srv.clock != nil {
+		t = srv.clock()
+	}
+	return t.Add(srv.clockOffset)
+}
+
+// SetInServiceDelay makes registered instances transition from the pending
+// OutOfService state to InService once d has elapsed on the clock of the
+// server since their registration, like SetInServiceAfter does after a
+// number of descriptions. Instances whose state was set explicitly don't
+// transition. A zero d, the default, disables the transition.
+func (srv *Server) SetInServiceDelay(d time.Duration) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.inServiceDelay = d
+}
+
+// drainingDescription is the description of the health of instances whose
+// connections are being drained.
+const drainingDescription = "Instance deregistration currently in progress."
+
+// startDraining keeps the instance, which is being deregistered from the
+// Load Balancer, described as InService until the connection draining
+// timeout of the Load Balancer elapses, if it's enabled.
+func (srv *Server) startDraining(lbName, instId string) {
+	cd := srv.lbAttributes(lbName).ConnectionDraining
+	if cd == nil || !cd.Enabled || cd.Timeout <= 0 {
+		return
+	}
+	timeout := time.Duration(cd.Timeout) * time.Second
+	if srv.lifecycle != nil && srv.lifecycle.Draining > 0 {
+		timeout = srv.lifecycle.Draining
+	}
+	until := srv.now().Add(timeout)
+	srv.draining[lbName+"/"+instId] = until
+	srv.endLifecycle(lbName, instId, until)
+}
+
+// drainingState returns the health of the instance if its connections are
+// being drained from the Load Balancer, or nil.
+func (srv *Server) drainingState(lbName, instId string) *elb.InstanceState {
+	key := lbName + "/" + instId
+	until, ok := srv.draining[key]
+	if !ok {
+		return nil
+	}
+	if !srv.now().Before(until) {
+		delete(srv.draining, key)
+		return nil
+	}
+	return &elb.InstanceState{
+		Description: drainingDescription,
+		InstanceId:  instId,
+		ReasonCode:  elb.ReasonCodeNone,
+		State:       elb.StateInService,
+	}
+}
+
+// drainingStates returns the health of the instances whose connections are
+// being drained from the Load Balancer, ordered by id.
+func (srv *Server) drainingStates(lbName string) []elb.InstanceState {
+	var ids []string
+	for key := range srv.draining {
+		if strings.HasPrefix(key, lbName+"/") {
+			ids = append(ids, key[len(lbName)+1:])
+		}
+	}
+	sort.Strings(ids)
+	var states []elb.InstanceState
+	for _, id := range ids {
+		if state := srv.drainingState(lbName, id); state != nil {
+			states = append(states, *state)
+		}
+	}
+	return states
+}
@@ -0,0 +1,179 @@
+package elbtest
+
+import (
+	"net/url"
+	"time"
+)
+
+// EventType identifies the kind of change an Event reports.
+type EventType string
+
+const (
+	LBCreated              EventType = "LBCreated"
+	LBDeleted              EventType = "LBDeleted"
+	InstanceRegistered     EventType = "InstanceRegistered"
+	InstanceDeregistered   EventType = "InstanceDeregistered"
+	HealthCheckConfigured  EventType = "HealthCheckConfigured"
+	AttributesModified     EventType = "AttributesModified"
+	ListenersCreated       EventType = "ListenersCreated"
+	ListenersDeleted       EventType = "ListenersDeleted"
+	ListenerCertificateSet EventType = "ListenerCertificateSet"
+	SecurityGroupsApplied  EventType = "SecurityGroupsApplied"
+	SubnetsAttached        EventType = "SubnetsAttached"
+	SubnetsDetached        EventType = "SubnetsDetached"
+	ZonesEnabled           EventType = "ZonesEnabled"
+	ZonesDisabled          EventType = "ZonesDisabled"
+	TagsAdded              EventType = "TagsAdded"
+	TagsRemoved            EventType = "TagsRemoved"
+	PolicyCreated          EventType = "PolicyCreated"
+	PolicyDeleted          EventType = "PolicyDeleted"
+	ListenerPoliciesSet    EventType = "ListenerPoliciesSet"
+	BackendPoliciesSet     EventType = "BackendPoliciesSet"
+
+	// InstancePhaseChanged reports a change of the lifecycle phase of an
+	// instance, see SetLifecycle.
+	InstancePhaseChanged EventType = "InstancePhaseChanged"
+)
+
+// eventTypes maps the actions that change the state of the server to the
+// type of the events they emit.
+var eventTypes = map[string]EventType{
+	"CreateLoadBalancer":                      LBCreated,
+	"DeleteLoadBalancer":                      LBDeleted,
+	"RegisterInstancesWithLoadBalancer":       InstanceRegistered,
+	"DeregisterInstancesFromLoadBalancer":     InstanceDeregistered,
+	"ConfigureHealthCheck":                    HealthCheckConfigured,
+	"ModifyLoadBalancerAttributes":            AttributesModified,
+	"CreateLoadBalancerListeners":             ListenersCreated,
+	"DeleteLoadBalancerListeners":             ListenersDeleted,
+	"SetLoadBalancerListenerSSLCertificate":   ListenerCertificateSet,
+	"ApplySecurityGroupsToLoadBalancer":       SecurityGroupsApplied,
+	"AttachLoadBalancerToSubnets":             SubnetsAttached,
+	"DetachLoadBalancerFromSubnets":           SubnetsDetached,
+	"EnableAvailabilityZonesForLoadBalancer":  ZonesEnabled,
+	"DisableAvailabilityZonesForLoadBalancer": ZonesDisabled,
+	"AddTags":                                 TagsAdded,
+	"RemoveTags":                              TagsRemoved,
+	"CreateLBCookieStickinessPolicy":          PolicyCreated,
+	"CreateAppCookieStickinessPolicy":         PolicyCreated,
+	"CreateLoadBalancerPolicy":                PolicyCreated,
+	"DeleteLoadBalancerPolicy":                PolicyDeleted,
+	"SetLoadBalancerPoliciesOfListener":       ListenerPoliciesSet,
+	"SetLoadBalancerPoliciesForBackendServer": BackendPoliciesSet,
+}
+
+// Event reports a change made to the state of the server by a successful
+// request.
+type Event struct {
+	Type             EventType
+	LoadBalancerName string
+
+	// Namespace is the namespace of the Load Balancer, as named by the
+	// function set with SetNamespaceFunc.
+	Namespace string
+
+	// InstanceId is the instance registered or deregistered, for
+	// InstanceRegistered and InstanceDeregistered events, or whose phase
+	// changed, for InstancePhaseChanged events.
+	InstanceId string
+
+	// From and To are the phases left and entered by the instance, for
+	// InstancePhaseChanged events.
+	From, To LifecyclePhase
+
+	// Params holds the parameters of the request that caused the change.
+	Params    url.Values
+	RequestId string
+	Time      time.Time
+}
+
+type subscriber struct {
+	f func(Event)
+	// scoped is set for the subscribers of a view returned by Namespace,
+	// which only receive the events of the named namespace.
+	scoped    bool
+	namespace string
+}
+
+// Subscribe makes the server call f with an event for each change made to
+// its state through the API, so tests can wait for a change instead of
+// polling the request history. Requests changing many Load Balancers or
+// instances emit an event for each of them.
+//
+// Events are delivered in the order the changes happened, in the goroutine
+// serving the request, before the response is sent: by the time a client
+// call returns, f has seen its events. f may call the methods of the
+// server, and should hand events over to the test goroutine through a
+// channel when it needs to block.
+//
+// The returned function stops the calls to f.
+func (srv *Server) Subscribe(f func(Event)) (unsubscribe func()) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	s := &subscriber{f: f, scoped: srv.scoped, namespace: srv.namespaceName}
+	srv.subscribers = append(srv.subscribers, s)
+	return func() {
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		for i, other := range srv.subscribers {
+			if other == s {
+				srv.subscribers = append(srv.subscribers[:i:i], srv.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// events returns the events emitted by the successful request req.
+func (srv *Server) events(req Request) []Event {
+	typ, ok := eventTypes[req.Action]
+	if !ok {
+		return nil
+	}
+	lbNames := srv.getParameters("LoadBalancerNames.member.", req.Params)
+	if name := req.Params.Get("LoadBalancerName"); name != "" {
+		lbNames = []string{name}
+	}
+	var events []Event
+	for _, lbName := range lbNames {
+		e := Event{
+			Type:             typ,
+			Namespace:        req.Namespace,
+			LoadBalancerName: lbName,
+			Params:           req.Params,
+			RequestId:        req.RequestId,
+			Time:             req.Time,
+		}
+		if typ != InstanceRegistered && typ != InstanceDeregistered {
+			events = append(events, e)
+			continue
+		}
+		for _, id := range instanceIds(req.Params) {
+			e.InstanceId = id
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// subscribersOf returns the subscribers receiving the events of the named
+// namespace.
+func (srv *Server) subscribersOf(namespace string) []*subscriber {
+	var subscribers []*subscriber
+	for _, s := range srv.subscribers {
+		if !s.scoped || s.namespace == namespace {
+			subscribers = append(subscribers, s)
+		}
+	}
+	return subscribers
+}
+
+// notify delivers events to the subscribers that were registered when
+// they happened. It must be called without holding the mutex.
+func notify(subscribers []*subscriber, events []Event) {
+	for _, e := range events {
+		for _, s := range subscribers {
+			s.f(e)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package elbtest
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	"net"
+	"net/http"
+	"time"
+)
+
+// FaultKind identifies a failure the server simulates instead of running
+// an action.
+type FaultKind int
+
+const (
+	// NoFault runs the action normally.
+	NoFault FaultKind = iota
+
+	// ConnectionReset makes the server reset the connection without
+	// writing a response.
+	ConnectionReset
+
+	// Timeout makes the server hang until the client gives up on the
+	// request.
+	Timeout
+
+	// ServiceUnavailable makes the server respond with HTTP 503 and a
+	// Throttling error.
+	ServiceUnavailable
+)
+
+// SetLatency makes the server wait for d before handling each call of the
+// given action. A zero d removes the latency.
+func (srv *Server) SetLatency(action string, d time.Duration) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if d <= 0 {
+		delete(srv.latencies, action)
+		return
+	}
+	srv.latencies[action] = d
+}
+
+// SetFault makes every call of the given action fail with the given fault
+// until SetFault is called again with NoFault. Requests failed by a fault
+// are still recorded in the history of the server, so the retries of a
+// client can be counted with RequestsFor.
+func (srv *Server) SetFault(action string, fault FaultKind) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if fault == NoFault {
+		delete(srv.faults, action)
+		return
+	}
+	srv.faults[action] = fault
+}
+
+// delay waits for d, returning false if the client gave up on req before.
+func delay(req *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-req.Context().Done():
+		return false
+	}
+}
+
+// fault simulates the given fault in response to req.
+func (srv *Server) fault(w http.ResponseWriter, req *http.Request, fault FaultKind, reqId string) {
+	switch fault {
+	case ConnectionReset:
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			panic("elbtest: connection can't be hijacked")
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			panic(err)
+		}
+		if tcp, ok := conn.(*net.TCPConn); ok {
+			// Discarding unsent data on close makes the kernel send a RST
+			// instead of a FIN.
+			tcp.SetLinger(0)
+		}
+		conn.Close()
+	case Timeout:
+		<-req.Context().Done()
+	case ServiceUnavailable:
+		srv.error(w, &elb.Error{
+			StatusCode: 503,
+			Code:       "Throttling",
+			Message:    "Rate exceeded",
+		}, reqId)
+	}
+}
@@ -0,0 +1,48 @@
+package elbtest
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+)
+
+// HealthFailureCause tells why an instance is OutOfService, as reported
+// by the ReasonCode of its health.
+type HealthFailureCause string
+
+const (
+	// CauseELB reports a failure caused by the Load Balancer, like a
+	// registration still in progress.
+	CauseELB HealthFailureCause = elb.ReasonCodeELB
+	// CauseInstance reports a failure caused by the instance, like failed
+	// health checks.
+	CauseInstance HealthFailureCause = elb.ReasonCodeInstance
+)
+
+// healthFailureDescriptions holds the descriptions AWS gives to the
+// failures of each cause.
+var healthFailureDescriptions = map[HealthFailureCause]string{
+	CauseELB:      "Instance registration is still in progress.",
+	CauseInstance: "Instance has failed at least the UnhealthyThreshold number of health checks consecutively.",
+}
+
+// SetHealthFailure makes DescribeInstanceHealth report the instance
+// registered with the Load Balancer as OutOfService because of cause, with
+// the reason code and description AWS gives to such failures, so code
+// branching on the ReasonCode of failing instances can be tested.
+//
+// If the instance isn't registered with the Load Balancer it does nothing.
+func (srv *Server) SetHealthFailure(lbName, instId string, cause HealthFailureCause) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.changeInstanceState(lbName, *healthFailure(instId, cause))
+}
+
+// healthFailure returns the health of an instance failing because of
+// cause.
+func healthFailure(instId string, cause HealthFailureCause) *elb.InstanceState {
+	return &elb.InstanceState{
+		Description: healthFailureDescriptions[cause],
+		InstanceId:  instId,
+		ReasonCode:  string(cause),
+		State:       elb.StateOutOfService,
+	}
+}
@@ -0,0 +1,224 @@
+package elbtest
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LifecyclePhase is a phase of the lifecycle of an instance registered
+// with a Load Balancer, as modelled by SetLifecycle.
+type LifecyclePhase string
+
+const (
+	// PhaseRegistering is the phase of instances whose registration is
+	// still in progress, described as OutOfService with the ELB reason
+	// code.
+	PhaseRegistering LifecyclePhase = "Registering"
+	// PhaseOutOfService is the phase of registered instances failing the
+	// health checks, described as OutOfService with the Instance reason
+	// code.
+	PhaseOutOfService LifecyclePhase = "OutOfService"
+	// PhaseInService is the phase of registered instances passing the
+	// health checks.
+	PhaseInService LifecyclePhase = "InService"
+	// PhaseDraining is the phase of deregistered instances whose
+	// connections are being drained, still described as InService.
+	PhaseDraining LifecyclePhase = "Draining"
+	// PhaseDeregistered is the phase of the instances that aren't
+	// registered with the Load Balancer.
+	PhaseDeregistered LifecyclePhase = "Deregistered"
+)
+
+// Lifecycle sets how long registered instances stay in each phase of
+// their lifecycle, see SetLifecycle. Zero durations make instances move
+// to the next phase at once.
+type Lifecycle struct {
+	// Registering is how long instances stay in PhaseRegistering before
+	// moving to PhaseOutOfService.
+	Registering time.Duration
+	// OutOfService is how long instances stay in PhaseOutOfService
+	// before passing the health checks and moving to PhaseInService.
+	OutOfService time.Duration
+	// Draining, if not zero, replaces the connection draining timeout of
+	// the Load Balancers having connection draining enabled, as the time
+	// deregistered instances stay in PhaseDraining. Instances of Load
+	// Balancers without connection draining are deregistered at once.
+	Draining time.Duration
+}
+
+// phaseState records the phase of an instance and when it entered it.
+type phaseState struct {
+	phase LifecyclePhase
+	since time.Time
+	// until is when a draining instance is deregistered.
+	until time.Time
+}
+
+// SetLifecycle makes instances registered from now on go through the
+// phases of l: Registering, OutOfService and InService once registered,
+// then Draining and Deregistered once deregistered. Phases change as time
+// passes on the clock of the server, see SetClock and AdvanceTime, and
+// each change is reported to the functions set with Subscribe as an
+// InstancePhaseChanged event, so tests of deployment orchestration can
+// observe the intermediate states of the instances.
+//
+// The lifecycle replaces the transitions set by SetInServiceAfter and
+// SetInServiceDelay. A state set with SetInstanceState is described until
+// the next change of phase of the instance. A nil l, the default,
+// disables the lifecycle.
+func (srv *Server) SetLifecycle(l *Lifecycle) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if l != nil {
+		lc := *l
+		l = &lc
+	}
+	srv.lifecycle = l
+}
+
+// InstancePhase returns the lifecycle phase of the instance registered
+// with the Load Balancer, after applying the changes of phase due by now.
+// Instances registered while the lifecycle was disabled have no phase,
+// and are reported as PhaseDeregistered.
+func (srv *Server) InstancePhase(lbName, instId string) LifecyclePhase {
+	srv.mutex.Lock()
+	srv.advanceInstance(lbName, instId)
+	phase := PhaseDeregistered
+	if p, ok := srv.phases[lbName+"/"+instId]; ok {
+		phase = p.phase
+	}
+	subscribers := srv.subscribersOf(srv.namespaceName)
+	events := srv.takeTransitions()
+	srv.mutex.Unlock()
+	notify(subscribers, events)
+	return phase
+}
+
+// setPhase moves the instance to phase at the given time, updating its
+// described health and recording the transition.
+func (srv *Server) setPhase(lbName, instId string, phase LifecyclePhase, at time.Time) {
+	key := lbName + "/" + instId
+	from := PhaseDeregistered
+	if p, ok := srv.phases[key]; ok {
+		from = p.phase
+	}
+	if phase == PhaseDeregistered {
+		delete(srv.phases, key)
+	} else {
+		srv.phases[key] = &phaseState{phase: phase, since: at}
+	}
+	if state := phaseInstanceState(instId, phase); state != nil {
+		srv.changeInstanceState(lbName, *state)
+	}
+	srv.transitions = append(srv.transitions, Event{
+		Type:             InstancePhaseChanged,
+		Namespace:        srv.namespaceName,
+		LoadBalancerName: lbName,
+		InstanceId:       instId,
+		From:             from,
+		To:               phase,
+		Time:             at,
+	})
+}
+
+// phaseInstanceState returns the health described for registered
+// instances in phase, or nil for the other phases.
+func phaseInstanceState(instId string, phase LifecyclePhase) *elb.InstanceState {
+	state := &elb.InstanceState{InstanceId: instId}
+	switch phase {
+	case PhaseRegistering:
+		state = healthFailure(instId, CauseELB)
+	case PhaseOutOfService:
+		state = healthFailure(instId, CauseInstance)
+	case PhaseInService:
+		state.State = elb.StateInService
+		state.ReasonCode = elb.ReasonCodeNone
+		state.Description = elb.ReasonCodeNone
+	default:
+		return nil
+	}
+	return state
+}
+
+// advanceInstance applies the changes of phase of the instance due by now.
+func (srv *Server) advanceInstance(lbName, instId string) {
+	if srv.lifecycle == nil {
+		return
+	}
+	key := lbName + "/" + instId
+	now := srv.now()
+	for {
+		p, ok := srv.phases[key]
+		if !ok {
+			return
+		}
+		var next LifecyclePhase
+		var at time.Time
+		switch p.phase {
+		case PhaseRegistering:
+			next, at = PhaseOutOfService, p.since.Add(srv.lifecycle.Registering)
+		case PhaseOutOfService:
+			next, at = PhaseInService, p.since.Add(srv.lifecycle.OutOfService)
+		case PhaseDraining:
+			next, at = PhaseDeregistered, p.until
+		default:
+			return
+		}
+		if now.Before(at) {
+			return
+		}
+		srv.setPhase(lbName, instId, next, at)
+	}
+}
+
+// advanceLifecycle applies the changes of phase due by now of the
+// instances of the Load Balancer, ordered by id.
+func (srv *Server) advanceLifecycle(lbName string) {
+	if srv.lifecycle == nil {
+		return
+	}
+	var ids []string
+	for key := range srv.phases {
+		if strings.HasPrefix(key, lbName+"/") {
+			ids = append(ids, key[len(lbName)+1:])
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		srv.advanceInstance(lbName, id)
+	}
+}
+
+// startLifecycle puts the newly registered instance in PhaseRegistering.
+func (srv *Server) startLifecycle(lbName, instId string) {
+	if srv.lifecycle == nil {
+		return
+	}
+	srv.setPhase(lbName, instId, PhaseRegistering, srv.now())
+	srv.advanceInstance(lbName, instId)
+}
+
+// endLifecycle puts the instance being deregistered in PhaseDraining
+// until the given time, or in PhaseDeregistered if until is zero.
+func (srv *Server) endLifecycle(lbName, instId string, until time.Time) {
+	p, ok := srv.phases[lbName+"/"+instId]
+	if !ok || p.phase == PhaseDraining {
+		return
+	}
+	if until.IsZero() {
+		srv.setPhase(lbName, instId, PhaseDeregistered, srv.now())
+		return
+	}
+	srv.setPhase(lbName, instId, PhaseDraining, srv.now())
+	srv.phases[lbName+"/"+instId].until = until
+}
+
+// takeTransitions returns the changes of phase recorded since the last
+// call.
+func (srv *Server) takeTransitions() []Event {
+	events := srv.transitions
+	srv.transitions = nil
+	return events
+}
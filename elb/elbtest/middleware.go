@@ -0,0 +1,67 @@
+package elbtest
+
+import (
+	"net/http"
+)
+
+// Handler runs an action of the server, returning the response struct to
+// encode or an error. An *elb.Error is sent to the client as is, and any
+// other error as a 500 InternalFailure.
+type Handler func(req *http.Request) (interface{}, error)
+
+// Middleware wraps the handling of the requests for action, as registered
+// by Use. It may run next, possibly with a modified request, or answer the
+// request itself.
+type Middleware func(action string, req *http.Request, next Handler) (interface{}, error)
+
+type middleware struct {
+	m Middleware
+	// scoped is set for the middleware of a view returned by Namespace,
+	// which only wraps the requests sent to the named namespace.
+	scoped    bool
+	namespace string
+}
+
+// Use adds m to the chain of middleware wrapping the actions of the
+// server, so tests can override the behavior of a single action, record
+// custom metrics or inject delays. Middleware added first runs first, and
+// the last one's next runs the action itself, after the checks of
+// SetAccessKeys, SetStrictMode and SetError. Latencies and faults set with
+// SetLatency and SetFault apply before the chain runs.
+//
+// Middleware runs in the goroutine serving the request without holding
+// the server's lock, so it may call the methods of the server and block.
+//
+// The returned function removes m from the chain.
+func (srv *Server) Use(m Middleware) (remove func()) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	mw := &middleware{m: m, scoped: srv.scoped, namespace: srv.namespaceName}
+	srv.middleware = append(srv.middleware, mw)
+	return func() {
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		for i, other := range srv.middleware {
+			if other == mw {
+				srv.middleware = append(srv.middleware[:i:i], srv.middleware[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// chain returns h wrapped by the middleware applying to the requests for
+// action sent to the named namespace.
+func (srv *Server) chain(action, namespace string, h Handler) Handler {
+	for i := len(srv.middleware) - 1; i >= 0; i-- {
+		mw := srv.middleware[i]
+		if mw.scoped && mw.namespace != namespace {
+			continue
+		}
+		next := h
+		h = func(req *http.Request) (interface{}, error) {
+			return mw.m(action, req, next)
+		}
+	}
+	return h
+}
@@ -0,0 +1,83 @@
+package elbtest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SetNamespaceFunc makes each request act on the Load Balancers and
+// instances of the namespace named by f, isolated from the other
+// namespaces like AWS accounts are, so that tests running in parallel can
+// share a server without their Load Balancer names colliding.
+// NamespaceByAccessKey and NamespaceByHeader return common namespaces.
+//
+// Settings like SetError or SetLatency apply to all the namespaces. A nil
+// f, the default, sends all the requests to the default namespace, whose
+// name is empty.
+func (srv *Server) SetNamespaceFunc(f func(req *http.Request) string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.namespaceOf = f
+}
+
+// NamespaceByAccessKey returns the access key that signed the request, so
+// that each access key gets its own namespace.
+func NamespaceByAccessKey(req *http.Request) string {
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, v4Algorithm+" ") {
+		credential := parseAuthorization(auth[len(v4Algorithm)+1:])["Credential"]
+		if i := strings.Index(credential, "/"); i >= 0 {
+			return credential[:i]
+		}
+		return credential
+	}
+	return req.Form.Get("AWSAccessKeyId")
+}
+
+// NamespaceByHeader returns a function naming namespaces after the value
+// of the given header of the requests.
+func NamespaceByHeader(name string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// Namespace returns a view of the server on the named namespace. Its
+// helpers, like NewLoadBalancer, RegisterInstance or LoadBalancer, act on
+// the Load Balancers and instances of the namespace, its Requests and
+// Subscribe only see the requests sent to the namespace, and its Reset only
+// wipes the namespace. The view shares the settings of the server.
+func (srv *Server) Namespace(name string) *Server {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return srv.view(name, true)
+}
+
+// view returns a view of the server on the named namespace, creating it if
+// needed.
+func (srv *Server) view(name string, scoped bool) *Server {
+	return &Server{core: srv.core, namespace: srv.namespaceNamed(name), namespaceName: name, scoped: scoped}
+}
+
+func (c *core) namespaceNamed(name string) *namespace {
+	ns, ok := c.namespaces[name]
+	if !ok {
+		ns = &namespace{}
+		ns.reset()
+		c.namespaces[name] = ns
+	}
+	return ns
+}
+
+// namespaceOfRequest returns the name of the namespace req is sent to.
+func (srv *Server) namespaceOfRequest(req *http.Request) string {
+	if srv.namespaceOf == nil {
+		return ""
+	}
+	return srv.namespaceOf(req)
+}
+
+// sees reports whether the requests sent to the named namespace are seen
+// by the view.
+func (srv *Server) sees(namespace string) bool {
+	return !srv.scoped || namespace == srv.namespaceName
+}
@@ -0,0 +1,436 @@
+package elbtest
+
+import (
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Types of the policies known by the server.
+const (
+	appCookieStickinessPolicyType         = "AppCookieStickinessPolicyType"
+	lbCookieStickinessPolicyType          = "LBCookieStickinessPolicyType"
+	proxyProtocolPolicyType               = "ProxyProtocolPolicyType"
+	backendServerAuthenticationPolicyType = "BackendServerAuthenticationPolicyType"
+	publicKeyPolicyType                   = "PublicKeyPolicyType"
+)
+
+// policyType describes a type of policy and where policies of that type
+// may be enabled: on listeners using one of protocols, or on backend
+// servers.
+type policyType struct {
+	description string
+	protocols   []string
+	backend     bool
+}
+
+var policyTypes = map[string]policyType{
+	appCookieStickinessPolicyType: {
+		description: "Stickiness policy with session lifetimes controlled by the lifetime of the application-generated cookie.",
+		protocols:   []string{elb.ProtocolHTTP, elb.ProtocolHTTPS},
+	},
+	lbCookieStickinessPolicyType: {
+		description: "Stickiness policy with session lifetimes controlled by the browser (user-agent) or a specified expiration period.",
+		protocols:   []string{elb.ProtocolHTTP, elb.ProtocolHTTPS},
+	},
+	elb.SSLNegotiationPolicyType: {
+		description: "Listener policy that defines the ciphers and protocols that will be accepted by the load balancer.",
+		protocols:   []string{elb.ProtocolHTTPS, elb.ProtocolSSL},
+	},
+	proxyProtocolPolicyType: {
+		description: "Policy that controls whether to include the IP address and port of the originating request for TCP messages.",
+		backend:     true,
+	},
+	backendServerAuthenticationPolicyType: {
+		description: "Policy that controls authentication to back-end server(s) and contains one or more policies, such as an instance of a PublicKeyPolicyType.",
+		backend:     true,
+	},
+	publicKeyPolicyType: {
+		description: "Policy containing a list of public keys to accept when authenticating the back-end server(s).",
+	},
+}
+
+// samplePolicies are the predefined security policies, described when
+// DescribeLoadBalancerPolicies is called without a Load Balancer name.
+var samplePolicies = []elb.PolicyDescription{
+	sslPolicy(elb.SecurityPolicy2016, true, true),
+	sslPolicy(elb.SecurityPolicyTLS11, false, true),
+	sslPolicy(elb.SecurityPolicyTLS12, false, false),
+}
+
+func sslPolicy(name string, tls10, tls11 bool) elb.PolicyDescription {
+	return elb.PolicyDescription{
+		PolicyName:     name,
+		PolicyTypeName: elb.SSLNegotiationPolicyType,
+		PolicyAttributeDescriptions: []elb.PolicyAttributeDescription{
+			{AttributeName: "Protocol-TLSv1", AttributeValue: strconv.FormatBool(tls10)},
+			{AttributeName: "Protocol-TLSv1.1", AttributeValue: strconv.FormatBool(tls11)},
+			{AttributeName: "Protocol-TLSv1.2", AttributeValue: "true"},
+			{AttributeName: "Server-Defined-Cipher-Order", AttributeValue: "true"},
+		},
+	}
+}
+
+func policyNotFound(name string) *elb.Error {
+	return &elb.Error{
+		StatusCode: 400,
+		Code:       elb.ErrPolicyNotFound,
+		Message:    fmt.Sprintf("There is no policy with name %s", name),
+	}
+}
+
+func findPolicy(policies []elb.PolicyDescription, name string) *elb.PolicyDescription {
+	for i := range policies {
+		if policies[i].PolicyName == name {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// policyAttributes returns the attributes given as
+// PolicyAttributes.member.N parameters.
+func policyAttributes(values url.Values) []elb.PolicyAttributeDescription {
+	var attrs []elb.PolicyAttributeDescription
+	for i := 1; ; i++ {
+		prefix := fmt.Sprintf("PolicyAttributes.member.%d.", i)
+		name := values.Get(prefix + "AttributeName")
+		if name == "" {
+			return attrs
+		}
+		attrs = append(attrs, elb.PolicyAttributeDescription{
+			AttributeName:  name,
+			AttributeValue: values.Get(prefix + "AttributeValue"),
+		})
+	}
+}
+
+// addPolicy creates a policy on the named Load Balancer, which must exist,
+// failing if the Load Balancer already has a policy with the same name.
+func (srv *Server) addPolicy(lbName string, p elb.PolicyDescription) error {
+	if findPolicy(srv.policies[lbName], p.PolicyName) != nil {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrDuplicatePolicyName,
+			Message:    fmt.Sprintf("Policy with the same name exists for this LoadBalancer. Please choose another name: %s", p.PolicyName),
+		}
+	}
+	srv.policies[lbName] = append(srv.policies[lbName], p)
+	srv.syncPolicies(lbName)
+	return nil
+}
+
+// syncPolicies updates the policies listed in the description of the named
+// Load Balancer after its policies changed.
+func (srv *Server) syncPolicies(lbName string) {
+	var policies elb.Policies
+	for _, p := range srv.policies[lbName] {
+		attrs := make(map[string]string)
+		for _, a := range p.PolicyAttributeDescriptions {
+			attrs[a.AttributeName] = a.AttributeValue
+		}
+		switch p.PolicyTypeName {
+		case appCookieStickinessPolicyType:
+			policies.AppCookieStickinessPolicies = append(policies.AppCookieStickinessPolicies, elb.AppCookieStickinessPolicies{
+				CookieName: attrs["CookieName"],
+				PolicyName: p.PolicyName,
+			})
+		case lbCookieStickinessPolicyType:
+			expiration, _ := strconv.Atoi(attrs["CookieExpirationPeriod"])
+			policies.LBCookieStickinessPolicies = append(policies.LBCookieStickinessPolicies, elb.LBCookieStickinessPolicies{
+				CookieExpirationPeriod: expiration,
+				PolicyName:             p.PolicyName,
+			})
+		default:
+			policies.OtherPolicies = append(policies.OtherPolicies, p.PolicyName)
+		}
+	}
+	srv.lbs[lbName].Policies = policies
+}
+
+func (srv *Server) createLBCookieStickinessPolicy(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName", "PolicyName"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	p := elb.PolicyDescription{
+		PolicyName:     req.FormValue("PolicyName"),
+		PolicyTypeName: lbCookieStickinessPolicyType,
+	}
+	if v := req.FormValue("CookieExpirationPeriod"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < 0 {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("Invalid CookieExpirationPeriod: %s", v),
+			}
+		}
+		p.PolicyAttributeDescriptions = []elb.PolicyAttributeDescription{{AttributeName: "CookieExpirationPeriod", AttributeValue: v}}
+	}
+	if err := srv.addPolicy(lbName, p); err != nil {
+		return nil, err
+	}
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) createAppCookieStickinessPolicy(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName", "PolicyName", "CookieName"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	p := elb.PolicyDescription{
+		PolicyName:     req.FormValue("PolicyName"),
+		PolicyTypeName: appCookieStickinessPolicyType,
+		PolicyAttributeDescriptions: []elb.PolicyAttributeDescription{
+			{AttributeName: "CookieName", AttributeValue: req.FormValue("CookieName")},
+		},
+	}
+	if err := srv.addPolicy(lbName, p); err != nil {
+		return nil, err
+	}
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) createLoadBalancerPolicy(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName", "PolicyName", "PolicyTypeName"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	typeName := req.FormValue("PolicyTypeName")
+	if _, ok := policyTypes[typeName]; !ok {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrPolicyTypeNotFound,
+			Message:    fmt.Sprintf("There is no policy type with name %s", typeName),
+		}
+	}
+	p := elb.PolicyDescription{
+		PolicyName:                  req.FormValue("PolicyName"),
+		PolicyTypeName:              typeName,
+		PolicyAttributeDescriptions: policyAttributes(req.Form),
+	}
+	// A security policy created from a predefined one gets its protocols
+	// and ciphers.
+	for _, a := range p.PolicyAttributeDescriptions {
+		if a.AttributeName != "Reference-Security-Policy" {
+			continue
+		}
+		ref := findPolicy(samplePolicies, a.AttributeValue)
+		if ref == nil {
+			return nil, policyNotFound(a.AttributeValue)
+		}
+		p.PolicyAttributeDescriptions = append(p.PolicyAttributeDescriptions, ref.PolicyAttributeDescriptions...)
+	}
+	if err := srv.addPolicy(lbName, p); err != nil {
+		return nil, err
+	}
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) deleteLoadBalancerPolicy(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName", "PolicyName"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	name := req.FormValue("PolicyName")
+	if findPolicy(srv.policies[lbName], name) == nil {
+		return nil, policyNotFound(name)
+	}
+	if srv.policyInUse(lbName, name) {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    fmt.Sprintf("Cannot delete policy %s, it is enabled on a listener or backend server", name),
+		}
+	}
+	policies := srv.policies[lbName]
+	for i := range policies {
+		if policies[i].PolicyName == name {
+			srv.policies[lbName] = append(policies[:i:i], policies[i+1:]...)
+			break
+		}
+	}
+	srv.syncPolicies(lbName)
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+// policyInUse reports whether the named policy is enabled on a listener or
+// backend server of the named Load Balancer.
+func (srv *Server) policyInUse(lbName, name string) bool {
+	lb := srv.lbs[lbName]
+	for _, ld := range lb.ListenerDescriptions {
+		if hasString(ld.PolicyNames, name) {
+			return true
+		}
+	}
+	for _, bd := range lb.BackendServerDescriptions {
+		if hasString(bd.PolicyNames, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (srv *Server) describeLoadBalancerPolicies(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	policies := samplePolicies
+	if lbName := req.FormValue("LoadBalancerName"); lbName != "" {
+		if err := srv.lbExists(lbName); err != nil {
+			return nil, err
+		}
+		policies = srv.policies[lbName]
+	}
+	var resp elb.DescribeLoadBalancerPoliciesResp
+	names := srv.getParameters("PolicyNames.member.", req.Form)
+	if len(names) == 0 {
+		resp.PolicyDescriptions = policies
+		return resp, nil
+	}
+	for _, name := range names {
+		p := findPolicy(policies, name)
+		if p == nil {
+			return nil, policyNotFound(name)
+		}
+		resp.PolicyDescriptions = append(resp.PolicyDescriptions, *p)
+	}
+	return resp, nil
+}
+
+func (srv *Server) describeLoadBalancerPolicyTypes(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	names := srv.getParameters("PolicyTypeNames.member.", req.Form)
+	if len(names) == 0 {
+		for name := range policyTypes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var resp elb.DescribeLoadBalancerPolicyTypesResp
+	for _, name := range names {
+		t, ok := policyTypes[name]
+		if !ok {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrPolicyTypeNotFound,
+				Message:    fmt.Sprintf("There is no policy type with name %s", name),
+			}
+		}
+		resp.PolicyTypeDescriptions = append(resp.PolicyTypeDescriptions, elb.PolicyTypeDescription{
+			PolicyTypeName: name,
+			Description:    t.description,
+		})
+	}
+	return resp, nil
+}
+
+// enabledPolicies returns the policies named in the PolicyNames.member.N
+// parameters of req, checking that they exist on the named Load Balancer
+// and that allowed accepts their types.
+func (srv *Server) enabledPolicies(lbName string, req *http.Request, allowed func(policyType) bool) ([]string, error) {
+	names := srv.getParameters("PolicyNames.member.", req.Form)
+	for _, name := range names {
+		p := findPolicy(srv.policies[lbName], name)
+		if p == nil {
+			return nil, policyNotFound(name)
+		}
+		if !allowed(policyTypes[p.PolicyTypeName]) {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrInvalidConfigurationRequest,
+				Message:    fmt.Sprintf("%s policies cannot be enabled here: %s", p.PolicyTypeName, name),
+			}
+		}
+	}
+	return names, nil
+}
+
+func (srv *Server) setLoadBalancerPoliciesOfListener(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName", "LoadBalancerPort"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	port, _ := strconv.Atoi(req.FormValue("LoadBalancerPort"))
+	ld := findListener(srv.lbs[lbName], port)
+	if ld == nil {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrListenerNotFound,
+			Message:    fmt.Sprintf("Unable to find a listener on LoadBalancerPort %d for LoadBalancer %s", port, lbName),
+		}
+	}
+	protocol := strings.ToUpper(ld.Listener.Protocol)
+	names, err := srv.enabledPolicies(lbName, req, func(t policyType) bool {
+		return hasString(t.protocols, protocol)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ld.PolicyNames = names
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) setLoadBalancerPoliciesForBackendServer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName", "InstancePort"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(req.FormValue("InstancePort"))
+	if err != nil {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("Invalid InstancePort: %s", req.FormValue("InstancePort")),
+		}
+	}
+	names, err := srv.enabledPolicies(lbName, req, func(t policyType) bool {
+		return t.backend
+	})
+	if err != nil {
+		return nil, err
+	}
+	lb := srv.lbs[lbName]
+	var backends []elb.BackendServerDescriptions
+	for _, bd := range lb.BackendServerDescriptions {
+		if bd.InstancePort != port {
+			backends = append(backends, bd)
+		}
+	}
+	if len(names) > 0 {
+		backends = append(backends, elb.BackendServerDescriptions{InstancePort: port, PolicyNames: names})
+	}
+	// Like AWS, describe the backends by instance port.
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].InstancePort < backends[j].InstancePort
+	})
+	lb.BackendServerDescriptions = backends
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
@@ -0,0 +1,77 @@
+package elbtest
+
+import (
+	"fmt"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"hash/crc32"
+	"strings"
+)
+
+// defaultRegion is the region where the server pretends to run, unless
+// changed with SetRegion.
+const defaultRegion = "us-east-1"
+
+// hostedZoneIds maps regions to the canonical hosted zone of their
+// classic Load Balancers, the one alias records pointing to them use.
+var hostedZoneIds = map[string]string{
+	"us-east-1":      "Z35SXDOTRQ7X7K",
+	"us-east-2":      "Z3AADJGX6KTTL2",
+	"us-west-1":      "Z368ELLRRE2KJ0",
+	"us-west-2":      "Z1H1FL5HABSF5",
+	"ca-central-1":   "ZQSVJUPU6J1EY",
+	"sa-east-1":      "Z2P70J7HTTTPLU",
+	"eu-west-1":      "Z32O12XQLNTSW2",
+	"eu-west-2":      "ZHURV8PSTC4K8",
+	"eu-west-3":      "Z3Q77PNBQS71R4",
+	"eu-central-1":   "Z215JYRZR1TBD5",
+	"ap-northeast-1": "Z14GRHDCWA56QT",
+	"ap-northeast-2": "ZWKZPGTI48KDX",
+	"ap-southeast-1": "Z1LMS91P8CMLE5",
+	"ap-southeast-2": "Z1GM3OXH4ZPM65",
+	"ap-south-1":     "ZP97RAFLXTNZK",
+}
+
+// SetRegion makes the server pretend to run in the named region, like
+// eu-west-1. The region shows in the DNS names and canonical hosted zones
+// of the Load Balancers created afterwards, and in Region. Regions of the
+// China partition get DNS names ending in amazonaws.com.cn, and regions
+// whose hosted zone isn't known by the server get a made-up one, stable
+// across runs. The default region is us-east-1.
+func (srv *Server) SetRegion(name string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.region = name
+}
+
+// Region returns the region where the server pretends to run, with the
+// URL of the server as endpoint, so that clients created with it reach the
+// server and sign their requests for the region.
+func (srv *Server) Region() aws.Region {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return elb.LookupRegion(srv.region).WithEndpoint(srv.url).AWSRegion()
+}
+
+// dnsName returns the DNS name of a new Load Balancer in the region of the
+// server.
+func (srv *Server) dnsName(lbName, scheme string) string {
+	suffix := "elb.amazonaws.com"
+	if strings.HasPrefix(srv.region, "cn-") {
+		suffix += ".cn"
+	}
+	name := fmt.Sprintf("%s-some-aws-stuff.%s.%s", lbName, srv.region, suffix)
+	if scheme == elb.SchemeInternal {
+		name = "internal-" + name
+	}
+	return name
+}
+
+// hostedZoneId returns the canonical hosted zone of the Load Balancers in
+// the region of the server.
+func (srv *Server) hostedZoneId() string {
+	if id, ok := hostedZoneIds[srv.region]; ok {
+		return id
+	}
+	return fmt.Sprintf("ZFAKE%08X", crc32.ChecksumIEEE([]byte(srv.region)))
+}
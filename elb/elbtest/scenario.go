@@ -0,0 +1,152 @@
+package elbtest
+
+import (
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"net/url"
+)
+
+// Scenario describes a fleet of Load Balancers the server can be seeded
+// with by LoadScenario. Its fields are plain data, so a scenario may also
+// be decoded from a JSON file with encoding/json.
+type Scenario struct {
+	LoadBalancers []ScenarioLoadBalancer
+}
+
+// ScenarioLoadBalancer describes a Load Balancer of a Scenario. It is
+// created like CreateLoadBalancer would, then configured with the
+// remaining fields. A nil HealthCheck keeps the default health check, and
+// nil attributes keep their default values.
+type ScenarioLoadBalancer struct {
+	elb.CreateLoadBalancer
+	HealthCheck *elb.HealthCheck
+	Instances   []ScenarioInstance
+	Tags        []elb.Tag
+	Attributes  elb.LoadBalancerAttributes
+}
+
+// ScenarioInstance describes an instance registered with a Load Balancer
+// of a Scenario. Instances unknown to the server are created, and an empty
+// InstanceId creates a new instance, like NewInstance. An empty State
+// leaves the instance in the pending OutOfService state.
+type ScenarioInstance struct {
+	InstanceId  string
+	State       elb.HealthState
+	ReasonCode  string
+	Description string
+}
+
+// LoadScenario adds the Load Balancers described by s to the server,
+// alongside the ones it already has.
+//
+// The scenario is validated as a whole before any Load Balancer is added,
+// so that an invalid scenario leaves the server untouched.
+func (srv *Server) LoadScenario(s Scenario) error {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	seen := make(map[string]bool)
+	for _, lb := range s.LoadBalancers {
+		if err := srv.validateScenario(lb, seen); err != nil {
+			return err
+		}
+	}
+	for _, lb := range s.LoadBalancers {
+		srv.loadScenario(lb)
+	}
+	return nil
+}
+
+func (srv *Server) validateScenario(lb ScenarioLoadBalancer, seen map[string]bool) error {
+	name := lb.Name
+	if name == "" {
+		return fmt.Errorf("elbtest: scenario has a Load Balancer without a name")
+	}
+	if _, ok := srv.lbs[name]; ok || seen[name] {
+		return fmt.Errorf("elbtest: Load Balancer %s already exists", name)
+	}
+	seen[name] = true
+	if lb.Scheme == elb.SchemeInternal && len(lb.Subnets) == 0 {
+		return fmt.Errorf("elbtest: internal Load Balancer %s has no subnets", name)
+	}
+	if len(lb.Listeners) == 0 {
+		return fmt.Errorf("elbtest: Load Balancer %s has no listeners", name)
+	}
+	for _, l := range lb.Listeners {
+		if err := validateListener(l); err != nil {
+			return fmt.Errorf("elbtest: Load Balancer %s: %v", name, err)
+		}
+	}
+	if len(lb.Tags) > maxTags {
+		return fmt.Errorf("elbtest: Load Balancer %s can't have more than %d tags", name, maxTags)
+	}
+	if err := validateTags(lb.Tags); err != nil {
+		return fmt.Errorf("elbtest: Load Balancer %s: %v", name, err)
+	}
+	attrs := scenarioAttributes(lb.Attributes)
+	if err := validateAttributes(attrs.AccessLog, attrs.ConnectionDraining, attrs.ConnectionSettings); err != nil {
+		return fmt.Errorf("elbtest: Load Balancer %s: %v", name, err)
+	}
+	return nil
+}
+
+// scenarioAttributes returns the default attributes overridden by the
+// non-nil attributes of a.
+func scenarioAttributes(a elb.LoadBalancerAttributes) *elb.LoadBalancerAttributes {
+	attrs := defaultAttributes()
+	if a.CrossZoneLoadBalancing != nil {
+		v := *a.CrossZoneLoadBalancing
+		attrs.CrossZoneLoadBalancing = &v
+	}
+	if a.AccessLog != nil {
+		v := *a.AccessLog
+		attrs.AccessLog = &v
+	}
+	if a.ConnectionDraining != nil {
+		v := *a.ConnectionDraining
+		attrs.ConnectionDraining = &v
+	}
+	if a.ConnectionSettings != nil {
+		v := *a.ConnectionSettings
+		attrs.ConnectionSettings = &v
+	}
+	return attrs
+}
+
+// loadScenario adds a Load Balancer of a validated scenario.
+func (srv *Server) loadScenario(s ScenarioLoadBalancer) {
+	params := make(map[string]string)
+	if err := elb.EncodeParams(params, &s.CreateLoadBalancer); err != nil {
+		panic(err)
+	}
+	form := make(url.Values)
+	for k, v := range params {
+		form.Set(k, v)
+	}
+	lb := srv.addLoadBalancer(form)
+	if s.HealthCheck != nil {
+		lb.HealthCheck = *s.HealthCheck
+	}
+	for _, inst := range s.Instances {
+		id := inst.InstanceId
+		if id == "" {
+			srv.instCount++
+			id = fmt.Sprintf("i-%d", srv.instCount)
+		}
+		if srv.instanceExists(id) != nil {
+			srv.instances = append(srv.instances, id)
+		}
+		srv.registerInstance(lb.LoadBalancerName, id)
+		if inst.State != "" {
+			srv.changeInstanceState(lb.LoadBalancerName, elb.InstanceState{
+				Description: inst.Description,
+				InstanceId:  id,
+				ReasonCode:  inst.ReasonCode,
+				State:       inst.State,
+			})
+		}
+	}
+	for _, tag := range s.Tags {
+		srv.tags[lb.LoadBalancerName] = setTag(srv.tags[lb.LoadBalancerName], tag)
+	}
+	srv.attributes[lb.LoadBalancerName] = scenarioAttributes(s.Attributes)
+}
@@ -4,29 +4,116 @@
 package elbtest
 
 import (
+	"crypto/x509"
 	"encoding/xml"
 	"fmt"
 	"github.com/flaviamissi/go-elb/elb"
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // Server implements an ELB simulator for use in testing.
+//
+// A Server is a view of the simulator on one of its namespaces, which
+// isolate Load Balancers and instances like AWS accounts do. See
+// SetNamespaceFunc and Namespace.
 type Server struct {
+	*core
+	*namespace
+	// namespaceName is the name of the namespace of the view.
+	namespaceName string
+	// scoped is set for the views returned by Namespace, whose history,
+	// events and Reset are limited to their namespace.
+	scoped bool
+}
+
+// core holds the state of the server shared by all the namespaces.
+type core struct {
 	url            string
 	listener       net.Listener
+	certificate    *x509.Certificate
 	mutex          sync.Mutex
 	reqId          int
+	history        []Request
+	instCount      int
+	errors         map[string]*injectedError
+	inServiceAfter int
+	strict         bool
+	accessKeys     map[string]string
+	latencies      map[string]time.Duration
+	faults         map[string]FaultKind
+	pageSize       int
+	subscribers    []*subscriber
+	compatibility  CompatibilityLevel
+	region         string
+	clock          func() time.Time
+	clockOffset    time.Duration
+	inServiceDelay time.Duration
+	namespaces     map[string]*namespace
+	namespaceOf    func(req *http.Request) string
+	dumpDir        string
+	dumps          []string
+	middleware     []*middleware
+	lifecycle      *Lifecycle
+	// transitions holds the changes of phase recorded while handling a
+	// request, to be delivered along with its events.
+	transitions []Event
+}
+
+// namespace holds the Load Balancers and instances of a namespace.
+type namespace struct {
 	lbs            map[string]*elb.LoadBalancerDescription
-	lbsReqs        map[string]url.Values
 	instances      []string
 	instanceStates map[string][]*elb.InstanceState
-	instCount      int
+	attributes     map[string]*elb.LoadBalancerAttributes
+	tags           map[string][]elb.Tag
+	policies       map[string][]elb.PolicyDescription
+	healthPolls    map[string]int
+	registeredAt   map[string]time.Time
+	draining       map[string]time.Time
+	phases         map[string]*phaseState
+}
+
+// reset removes all the Load Balancers and instances of the namespace.
+func (ns *namespace) reset() {
+	ns.lbs = make(map[string]*elb.LoadBalancerDescription)
+	ns.instances = nil
+	ns.instanceStates = make(map[string][]*elb.InstanceState)
+	ns.attributes = make(map[string]*elb.LoadBalancerAttributes)
+	ns.tags = make(map[string][]elb.Tag)
+	ns.policies = make(map[string][]elb.PolicyDescription)
+	ns.healthPolls = make(map[string]int)
+	ns.registeredAt = make(map[string]time.Time)
+	ns.draining = make(map[string]time.Time)
+	ns.phases = make(map[string]*phaseState)
+}
+
+// Request records an operation received by the server.
+type Request struct {
+	Action    string
+	Params    url.Values
+	RequestId string
+	Time      time.Time
+
+	// Namespace is the namespace the request was sent to, as named by the
+	// function set with SetNamespaceFunc.
+	Namespace string
+}
+
+// injectedError is an error the server returns instead of running an
+// action, as set by SetError.
+type injectedError struct {
+	err   elb.Error
+	times int
 }
 
 // Starts and returns a new server
@@ -35,16 +122,26 @@ func NewServer() (*Server, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cannot listen on localhost: %v", err)
 	}
-	srv := &Server{
-		listener:       l,
-		url:            "http://" + l.Addr().String(),
-		lbs:            make(map[string]*elb.LoadBalancerDescription),
-		instanceStates: make(map[string][]*elb.InstanceState),
+	return newServer(l, "http://"+l.Addr().String()), nil
+}
+
+// newServer serves the fake API on l, which is reached at endpoint.
+func newServer(l net.Listener, endpoint string) *Server {
+	c := &core{
+		listener:   l,
+		url:        endpoint,
+		errors:     make(map[string]*injectedError),
+		latencies:  make(map[string]time.Duration),
+		faults:     make(map[string]FaultKind),
+		pageSize:   maxPageSize,
+		region:     defaultRegion,
+		namespaces: make(map[string]*namespace),
 	}
+	srv := &Server{core: c, namespace: c.namespaceNamed("")}
 	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		srv.serveHTTP(w, req)
 	}))
-	return srv, nil
+	return srv
 }
 
 // Quit closes down the server.
@@ -52,58 +149,227 @@ func (srv *Server) Quit() {
 	srv.listener.Close()
 }
 
+// SetError makes the server fail the next times calls of the given action
+// with err, without running it. If times is zero or negative, every call
+// fails until ClearErrors is called. A zero StatusCode in err defaults to
+// 400.
+//
+// Setting an error for an action replaces any error previously set for it.
+func (srv *Server) SetError(action string, err *elb.Error, times int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	e := *err
+	if e.StatusCode == 0 {
+		e.StatusCode = 400
+	}
+	srv.errors[action] = &injectedError{err: e, times: times}
+}
+
+// ClearErrors removes all the errors set by SetError.
+func (srv *Server) ClearErrors() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.errors = make(map[string]*injectedError)
+}
+
+// injectedError returns the error set for the given action, if any,
+// consuming one of its occurrences.
+func (srv *Server) injectedError(action string) *elb.Error {
+	injected, ok := srv.errors[action]
+	if !ok {
+		return nil
+	}
+	if injected.times > 0 {
+		injected.times--
+		if injected.times == 0 {
+			delete(srv.errors, action)
+		}
+	}
+	err := injected.err
+	return &err
+}
+
+// Requests returns all the requests received by the server since it was
+// started or its history was last reset, in the order they arrived.
+func (srv *Server) Requests() []Request {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	var reqs []Request
+	for _, r := range srv.history {
+		if srv.sees(r.Namespace) {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// RequestsFor returns the requests for the given action, in the order they
+// arrived.
+func (srv *Server) RequestsFor(action string) []Request {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	var reqs []Request
+	for _, r := range srv.history {
+		if r.Action == action && srv.sees(r.Namespace) {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// ResetHistory discards the requests recorded so far.
+func (srv *Server) ResetHistory() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.resetHistory()
+}
+
+// resetHistory discards the requests the view sees.
+func (srv *Server) resetHistory() {
+	var kept []Request
+	for _, r := range srv.history {
+		if !srv.sees(r.Namespace) {
+			kept = append(kept, r)
+		}
+	}
+	srv.history = kept
+}
+
 // URL returns the URL of the server.
 func (srv *Server) URL() string {
 	return srv.url
 }
 
 type xmlErrors struct {
-	XMLName string `xml:"ErrorResponse"`
-	Error   elb.Error
+	XMLName   string `xml:"ErrorResponse"`
+	Error     elb.Error
+	RequestId string
 }
 
-func (srv *Server) error(w http.ResponseWriter, err *elb.Error) {
+func (srv *Server) error(w http.ResponseWriter, err *elb.Error, reqId string) {
 	w.WriteHeader(err.StatusCode)
-	xmlErr := xmlErrors{Error: *err}
+	xmlErr := xmlErrors{Error: *err, RequestId: reqId}
 	if e := xml.NewEncoder(w).Encode(xmlErr); e != nil {
 		panic(e)
 	}
+	if err.StatusCode >= 500 {
+		srv.dumpState(reqId)
+	}
 }
 
 func (srv *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	req.ParseForm()
+	srv.mutex.Lock()
+	reqId := fmt.Sprintf("req%0X", srv.reqId)
+	srv.reqId++
+	r := Request{
+		Action:    req.Form.Get("Action"),
+		Namespace: srv.namespaceOfRequest(req),
+		Params:    req.Form,
+		RequestId: reqId,
+		Time:      srv.now(),
+	}
+	srv.history = append(srv.history, r)
+	latency := srv.latencies[r.Action]
+	fault := srv.faults[r.Action]
+	var subscribers []*subscriber
+	var events []Event
+	h := srv.chain(r.Action, r.Namespace, func(req *http.Request) (interface{}, error) {
+		resp, s, e, err := srv.dispatch(w, req, r)
+		subscribers, events = s, append(events, e...)
+		return resp, err
+	})
+	srv.mutex.Unlock()
+	if !delay(req, latency) {
+		return
+	}
+	if fault != NoFault {
+		srv.fault(w, req, fault, reqId)
+		return
+	}
+	resp, err := h(req)
+	notify(subscribers, events)
+	if err == nil {
+		if err := xml.NewEncoder(w).Encode(withRequestId(resp, reqId)); err != nil {
+			panic(err)
+		}
+		return
+	}
+	switch err := err.(type) {
+	case *elb.Error:
+		srv.error(w, err, reqId)
+	default:
+		srv.error(w, &elb.Error{StatusCode: 500, Code: "InternalFailure", Message: err.Error()}, reqId)
+	}
+}
+
+// dispatch runs the action of req, returning its response along with the
+// events it emitted and the subscribers to notify of them.
+func (srv *Server) dispatch(w http.ResponseWriter, req *http.Request, r Request) (interface{}, []*subscriber, []Event, error) {
 	srv.mutex.Lock()
 	defer srv.mutex.Unlock()
-	f := actions[req.Form.Get("Action")]
+	srv = srv.view(r.Namespace, false)
+	f := actions[r.Action]
 	if f == nil {
-		srv.error(w, &elb.Error{
+		return nil, nil, nil, &elb.Error{
 			StatusCode: 400,
 			Code:       "InvalidParameterValue",
 			Message:    "Unrecognized Action",
-		})
+		}
 	}
-	reqId := fmt.Sprintf("req%0X", srv.reqId)
-	srv.reqId++
-	if resp, err := f(srv, w, req, reqId); err == nil {
-		if err := xml.NewEncoder(w).Encode(resp); err != nil {
-			panic(err)
+	if len(srv.accessKeys) > 0 {
+		if err := srv.verifySignature(req); err != nil {
+			return nil, nil, nil, err
 		}
-	} else {
-		switch err.(type) {
-		case *elb.Error:
-			srv.error(w, err.(*elb.Error))
-		default:
-			panic(err)
+	}
+	if srv.strict {
+		if err := validateRequest(req); err != nil {
+			return nil, nil, nil, err
 		}
 	}
+	if err := srv.injectedError(r.Action); err != nil {
+		return nil, nil, nil, err
+	}
+	resp, err := f(srv, w, req, r.RequestId)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	events := srv.events(r)
+	for _, e := range srv.takeTransitions() {
+		e.Params, e.RequestId = r.Params, r.RequestId
+		events = append(events, e)
+	}
+	return resp, srv.subscribersOf(r.Namespace), events, nil
+}
+
+// withRequestId returns a copy of resp, a response struct, with its
+// RequestId field set to reqId, so handlers don't have to fill it in.
+func withRequestId(resp interface{}, reqId string) interface{} {
+	v := reflect.ValueOf(resp)
+	if v.Kind() != reflect.Struct {
+		return resp
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	if f := p.Elem().FieldByName("RequestId"); f.IsValid() && f.Kind() == reflect.String && f.String() == "" {
+		f.SetString(reqId)
+	}
+	return p.Interface()
 }
 
+// vpcId is the VPC of the subnets known by the server.
+const vpcId = "vpc-1a2b3c4d"
+
 func (srv *Server) createLoadBalancer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
 	composition := map[string]string{
 		"AvailabilityZones.member.1": "Subnets.member.1",
 	}
-	if err := srv.validateComposition(req, composition); err != nil {
-		return nil, err
+	// AWS checks the required parameters before their composition.
+	awsOrder := srv.compatibility == CompatibilityAWS
+	if !awsOrder {
+		if err := srv.validateComposition(req, composition); err != nil {
+			return nil, err
+		}
 	}
 	required := []string{
 		"Listeners.member.1.InstancePort",
@@ -115,23 +381,89 @@ func (srv *Server) createLoadBalancer(w http.ResponseWriter, req *http.Request,
 	if err := srv.validate(req, required); err != nil {
 		return nil, err
 	}
+	if awsOrder {
+		if err := srv.validateComposition(req, composition); err != nil {
+			return nil, err
+		}
+	}
+	switch scheme := req.FormValue("Scheme"); scheme {
+	case "", elb.SchemeInternetFacing:
+	case elb.SchemeInternal:
+		if !srv.hasParameter(req, "Subnets.member.1") {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrInvalidConfigurationRequest,
+				Message:    "Internal Load Balancers can only be created in a VPC.",
+			}
+		}
+	default:
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("Invalid value for Scheme: %s. Valid values are: internet-facing, internal.", scheme),
+		}
+	}
 	path := req.FormValue("Path")
 	if path == "" {
 		path = "/"
 	}
-	lbName := req.FormValue("LoadBalancerName")
-	srv.lbs[lbName] = srv.makeLoadBalancerDescription(req.Form)
-	srv.lbs[lbName].DNSName = fmt.Sprintf("%s-some-aws-stuff.us-east-1.elb.amazonaws.com", lbName)
+	if existing, ok := srv.lbs[req.FormValue("LoadBalancerName")]; ok {
+		// Like AWS, creating a Load Balancer again is harmless as long as
+		// the configuration is the same.
+		desired := srv.makeLoadBalancerDescription(req.Form)
+		desired.HealthCheck = elb.HealthCheck{}
+		if len(desired.SecurityGroups) == 0 {
+			desired.SecurityGroups = nil
+		}
+		if !elb.Diff(*desired, *existing).Empty() {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrDuplicateLoadBalancerName,
+				Message:    "The specified load balancer name already exists for this account.",
+			}
+		}
+		return elb.CreateLoadBalancerResp{DNSName: existing.DNSName}, nil
+	}
+	lb := srv.addLoadBalancer(req.Form)
 	return elb.CreateLoadBalancerResp{
-		DNSName: srv.lbs[lbName].DNSName,
+		DNSName: lb.DNSName,
 	}, nil
 }
 
+// addLoadBalancer creates the Load Balancer described by the parameters of
+// a CreateLoadBalancer request, which must have been validated.
+func (srv *Server) addLoadBalancer(form url.Values) *elb.LoadBalancerDescription {
+	lbName := form.Get("LoadBalancerName")
+	lb := srv.makeLoadBalancerDescription(form)
+	lb.DNSName = srv.dnsName(lbName, lb.Scheme)
+	if lb.Scheme != elb.SchemeInternal {
+		// Internal Load Balancers have no canonical hosted zone name.
+		lb.CanonicalHostedZoneName = lb.DNSName
+	}
+	lb.CanonicalHostedZoneNameId = srv.hostedZoneId()
+	for i := range lb.ListenerDescriptions {
+		l := &lb.ListenerDescriptions[i].Listener
+		if l.InstanceProtocol == "" {
+			// Like AWS, default to the protocol of the same layer.
+			l.InstanceProtocol = elb.ProtocolTCP
+			if l.Protocol == elb.ProtocolHTTP || l.Protocol == elb.ProtocolHTTPS {
+				l.InstanceProtocol = elb.ProtocolHTTP
+			}
+		}
+	}
+	if len(lb.Subnets) > 0 {
+		lb.VPCId = vpcId
+	}
+	lb.CreatedTime = srv.now().UTC().Truncate(time.Millisecond)
+	srv.lbs[lbName] = lb
+	return lb
+}
+
 func (srv *Server) deleteLoadBalancer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
 	if err := srv.validate(req, []string{"LoadBalancerName"}); err != nil {
 		return nil, err
 	}
-	srv.RemoveLoadBalancer(req.FormValue("LoadBalancerName"))
+	srv.removeLoadBalancer(req.FormValue("LoadBalancerName"))
 	return elb.SimpleResp{RequestId: reqId}, nil
 }
 
@@ -144,22 +476,22 @@ func (srv *Server) registerInstancesWithLoadBalancer(w http.ResponseWriter, req
 	if err := srv.lbExists(lbName); err != nil {
 		return nil, err
 	}
-	instIds := []string{}
-	instances := []elb.Instance{}
-	i := 1
-	instId := req.FormValue(fmt.Sprintf("Instances.member.%d.InstanceId", i))
-	for instId != "" {
+	instIds := instanceIds(req.Form)
+	for _, instId := range instIds {
 		if err := srv.instanceExists(instId); err != nil {
 			return nil, err
 		}
-		instIds = append(instIds, instId)
-		instances = append(instances, elb.Instance{InstanceId: instId})
-		i++
-		instId = req.FormValue(fmt.Sprintf("Instances.member.%d.InstanceId", i))
 	}
-	srv.instanceStates[lbName] = append(srv.instanceStates[lbName], srv.makeInstanceState(instId))
-	srv.lbs[lbName].Instances = append(srv.lbs[lbName].Instances, instances...)
-	return elb.RegisterInstancesResp{InstanceIds: instIds}, nil
+	for _, instId := range instIds {
+		srv.registerInstance(lbName, instId)
+	}
+	// The response lists all the instances registered with the Load
+	// Balancer, not only the ones in the request.
+	registered := []string{}
+	for _, instance := range srv.lbs[lbName].Instances {
+		registered = append(registered, instance.InstanceId)
+	}
+	return elb.RegisterInstancesResp{InstanceIds: registered}, nil
 }
 
 func (srv *Server) deregisterInstancesFromLoadBalancer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
@@ -171,20 +503,23 @@ func (srv *Server) deregisterInstancesFromLoadBalancer(w http.ResponseWriter, re
 	if err := srv.lbExists(lbName); err != nil {
 		return nil, err
 	}
-	i := 1
-	lb := srv.lbs[lbName]
-	instId := req.FormValue(fmt.Sprintf("Instances.member.%d.InstanceId", i))
-	for instId != "" {
+	instIds := instanceIds(req.Form)
+	for _, instId := range instIds {
 		if err := srv.instanceExists(instId); err != nil {
 			return nil, err
 		}
-		i++
-		removeInstanceFromLB(lb, instId)
-		instId = req.FormValue(fmt.Sprintf("Instances.member.%d.InstanceId", i))
 	}
-	srv.lbs[lbName] = lb
-	srv.removeInstanceStatesFromLoadBalancer(lbName, instId)
-	return elb.SimpleResp{RequestId: reqId}, nil
+	for _, instId := range instIds {
+		if isRegistered(srv.lbs[lbName], instId) {
+			srv.startDraining(lbName, instId)
+		}
+		srv.deregisterInstance(lbName, instId)
+	}
+	remaining := []string{}
+	for _, instance := range srv.lbs[lbName].Instances {
+		remaining = append(remaining, instance.InstanceId)
+	}
+	return elb.DeregisterInstancesResp{InstanceIds: remaining, RequestId: reqId}, nil
 }
 
 func (srv *Server) describeLoadBalancers(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
@@ -205,13 +540,59 @@ func (srv *Server) describeLoadBalancers(w http.ResponseWriter, req *http.Reques
 		lbName = req.FormValue(fmt.Sprintf("LoadBalancerNames.member.%d", i))
 	}
 	if lbsDesc == nil {
-		for _, lb := range srv.lbs {
-			lbsDesc = append(lbsDesc, *lb)
+		names := make([]string, 0, len(srv.lbs))
+		for name := range srv.lbs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lbsDesc = append(lbsDesc, *srv.lbs[name])
 		}
 	}
-	resp := elb.DescribeLoadBalancerResp{
-		LoadBalancerDescriptions: lbsDesc,
+	return paginateLoadBalancers(lbsDesc, req.FormValue("Marker"), req.FormValue("PageSize"), srv.pageSize)
+}
+
+// paginateLoadBalancers returns the page of descs starting at marker, which
+// is the name of the first Load Balancer in the page. defaultSize is used
+// when the request has no PageSize.
+func paginateLoadBalancers(descs []elb.LoadBalancerDescription, marker, pageSize string, defaultSize int) (interface{}, error) {
+	size := defaultSize
+	if pageSize != "" {
+		n, err := strconv.Atoi(pageSize)
+		if err != nil || n < 1 || n > maxPageSize {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("Value '%s' at 'pageSize' failed to satisfy constraint: Member must have value between 1 and 400", pageSize),
+			}
+		}
+		size = n
+	}
+	start := 0
+	if marker != "" {
+		start = -1
+		for i, desc := range descs {
+			if desc.LoadBalancerName == marker {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       "InvalidNextToken",
+				Message:    fmt.Sprintf("Invalid marker: %s", marker),
+			}
+		}
+	}
+	resp := elb.DescribeLoadBalancerResp{}
+	end := start + size
+	if end < len(descs) {
+		resp.NextMarker = descs[end].LoadBalancerName
+	} else {
+		end = len(descs)
 	}
+	resp.LoadBalancerDescriptions = descs[start:end]
 	return resp, nil
 }
 
@@ -220,26 +601,53 @@ func (srv *Server) describeLoadBalancers(w http.ResponseWriter, req *http.Reques
 //
 // For example, for the prefix "Subnets.member.", it will return a slice
 // containing the value of keys "Subnets.member.1", "Subnets.member.2" ...
-// "Subnets.member.N". The prefix must include the trailing dot.
+// "Subnets.member.N", ordered by index. Gaps between indexes are allowed.
+// The prefix must include the trailing dot.
 func (srv *Server) getParameters(prefix string, values url.Values) []string {
-	i, key := 1, ""
-	var k = func(n int) string {
-		return fmt.Sprintf(prefix+"%d", n)
+	var indexes []int
+	for key := range values {
+		if !strings.HasPrefix(key, prefix) || values.Get(key) == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(key[len(prefix):]); err == nil && n > 0 {
+			indexes = append(indexes, n)
+		}
 	}
+	sort.Ints(indexes)
 	var result []string
-	for i, key = 2, k(i); values.Get(key) != ""; i, key = i+1, k(i) {
-		result = append(result, values.Get(key))
+	for _, n := range indexes {
+		result = append(result, values.Get(prefix+strconv.Itoa(n)))
 	}
 	return result
 }
 
+// hasParameter reports whether the request holds field. A member of a list
+// parameter, like Subnets.member.1, stands for any member of the list.
+func (srv *Server) hasParameter(req *http.Request, field string) bool {
+	if list, sub := splitMember(field); list != "" && sub == "" {
+		return len(srv.getParameters(list+".member.", req.Form)) > 0
+	}
+	return req.FormValue(field) != ""
+}
+
 func (srv *Server) makeInstanceState(id string) *elb.InstanceState {
 	return &elb.InstanceState{
 		Description: "Instance is in pending state.",
 		InstanceId:  id,
-		State:       "OutOfService",
-		ReasonCode:  "Instance",
+		State:       elb.StateOutOfService,
+		ReasonCode:  elb.ReasonCodeInstance,
+	}
+}
+
+// isRegistered reports whether the instance is registered with the Load
+// Balancer.
+func isRegistered(lb *elb.LoadBalancerDescription, id string) bool {
+	for _, instance := range lb.Instances {
+		if instance.InstanceId == id {
+			return true
+		}
 	}
+	return false
 }
 
 func removeInstanceFromLB(lb *elb.LoadBalancerDescription, id string) {
@@ -256,6 +664,40 @@ func removeInstanceFromLB(lb *elb.LoadBalancerDescription, id string) {
 	}
 }
 
+// instanceIds returns the values of the Instances.member.N.InstanceId
+// parameters of a request.
+func instanceIds(form url.Values) []string {
+	var ids []string
+	for i := 1; form.Get(fmt.Sprintf("Instances.member.%d.InstanceId", i)) != ""; i++ {
+		ids = append(ids, form.Get(fmt.Sprintf("Instances.member.%d.InstanceId", i)))
+	}
+	return ids
+}
+
+// registerInstance adds the instance to the Load Balancer, in the pending
+// state. Registering an instance twice is a no-op.
+func (srv *Server) registerInstance(lbName, instId string) {
+	lb := srv.lbs[lbName]
+	if isRegistered(lb, instId) {
+		return
+	}
+	lb.Instances = append(lb.Instances, elb.Instance{InstanceId: instId})
+	srv.instanceStates[lbName] = append(srv.instanceStates[lbName], srv.makeInstanceState(instId))
+	srv.registeredAt[lbName+"/"+instId] = srv.now()
+	delete(srv.draining, lbName+"/"+instId)
+	srv.startLifecycle(lbName, instId)
+}
+
+// deregisterInstance removes the instance and its health state from the
+// Load Balancer.
+func (srv *Server) deregisterInstance(lbName, instId string) {
+	srv.endLifecycle(lbName, instId, time.Time{})
+	removeInstanceFromLB(srv.lbs[lbName], instId)
+	srv.removeInstanceStatesFromLoadBalancer(lbName, instId)
+	delete(srv.healthPolls, lbName+"/"+instId)
+	delete(srv.registeredAt, lbName+"/"+instId)
+}
+
 func (srv *Server) removeInstanceStatesFromLoadBalancer(lb, id string) {
 	for i, state := range srv.instanceStates[lb] {
 		if state.InstanceId == id {
@@ -267,7 +709,7 @@ func (srv *Server) removeInstanceStatesFromLoadBalancer(lb, id string) {
 	}
 }
 
-func (srv *Server) makeLoadBalancerDescription(value url.Values) *elb.LoadBalancerDescription {
+func (srv *Server) makeListenerDescriptions(value url.Values) []elb.ListenerDescription {
 	lds := []elb.ListenerDescription{}
 	i := 1
 	protocol := value.Get(fmt.Sprintf("Listeners.member.%d.Protocol", i))
@@ -281,25 +723,30 @@ func (srv *Server) makeLoadBalancerDescription(value url.Values) *elb.LoadBalanc
 				InstanceProtocol: strings.ToUpper(value.Get(key + "InstanceProtocol")),
 				LoadBalancerPort: lLBPort,
 				InstancePort:     lInstPort,
+				SSLCertificateId: value.Get(key + "SSLCertificateId"),
 			},
 		}
 		i++
 		protocol = value.Get(fmt.Sprintf("Listeners.member.%d.Protocol", i))
 		lds = append(lds, lDescription)
 	}
+	return lds
+}
+
+func (srv *Server) makeLoadBalancerDescription(value url.Values) *elb.LoadBalancerDescription {
 	sourceSecGroup := srv.makeSourceSecGroup(value)
 	lbDesc := elb.LoadBalancerDescription{
 		AvailZones:           srv.getParameters("AvailabilityZones.member.", value),
 		Subnets:              srv.getParameters("Subnets.member.", value),
 		SecurityGroups:       srv.getParameters("SecurityGroups.member.", value),
 		HealthCheck:          srv.makeHealthCheck(value),
-		ListenerDescriptions: lds,
+		ListenerDescriptions: srv.makeListenerDescriptions(value),
 		Scheme:               value.Get("Scheme"),
 		SourceSecurityGroup:  sourceSecGroup,
 		LoadBalancerName:     value.Get("LoadBalancerName"),
 	}
 	if lbDesc.Scheme == "" {
-		lbDesc.Scheme = "internet-facing"
+		lbDesc.Scheme = elb.SchemeInternetFacing
 	}
 	return &lbDesc
 }
@@ -353,31 +800,68 @@ func (srv *Server) describeInstanceHealth(w http.ResponseWriter, req *http.Reque
 	if err := srv.lbExists(req.FormValue("LoadBalancerName")); err != nil {
 		return nil, err
 	}
+	lbName := req.FormValue("LoadBalancerName")
+	srv.advanceLifecycle(lbName)
 	resp := elb.DescribeInstanceHealthResp{
 		InstanceStates: []elb.InstanceState{},
 	}
-	for _, state := range srv.instanceStates[req.FormValue("LoadBalancerName")] {
-		resp.InstanceStates = append(resp.InstanceStates, *state)
+	instIds := instanceIds(req.Form)
+	if len(instIds) == 0 {
+		for _, state := range srv.instanceStates[lbName] {
+			srv.pollInstanceHealth(lbName, state)
+			resp.InstanceStates = append(resp.InstanceStates, *state)
+		}
+		resp.InstanceStates = append(resp.InstanceStates, srv.drainingStates(lbName)...)
+		return resp, nil
 	}
-	i := 1
-	instanceId := req.FormValue("Instances.member.1.InstanceId")
-	for instanceId != "" {
-		if err := srv.instanceExists(instanceId); err != nil {
+	for _, instId := range instIds {
+		if err := srv.instanceExists(instId); err != nil {
 			return nil, err
 		}
-		is := elb.InstanceState{
-			Description: "Instance is in pending state.",
-			InstanceId:  instanceId,
-			State:       "OutOfService",
-			ReasonCode:  "Instance",
+		state := srv.drainingState(lbName, instId)
+		if state == nil {
+			state = srv.makeInstanceState(instId)
 		}
-		resp.InstanceStates = append(resp.InstanceStates, is)
-		i++
-		instanceId = req.FormValue(fmt.Sprintf("Instances.member.%d.InstanceId", i))
+		for _, s := range srv.instanceStates[lbName] {
+			if s.InstanceId == instId {
+				srv.pollInstanceHealth(lbName, s)
+				state = s
+				break
+			}
+		}
+		resp.InstanceStates = append(resp.InstanceStates, *state)
 	}
 	return resp, nil
 }
 
+// pollInstanceHealth counts a health description of a pending instance,
+// moving it to InService once it has been described as many times as set
+// by SetInServiceAfter, or once it has been registered for as long as set
+// by SetInServiceDelay.
+func (srv *Server) pollInstanceHealth(lbName string, state *elb.InstanceState) {
+	if srv.lifecycle != nil || *state != *srv.makeInstanceState(state.InstanceId) {
+		return
+	}
+	key := lbName + "/" + state.InstanceId
+	ready := false
+	if srv.inServiceAfter > 0 {
+		srv.healthPolls[key]++
+		ready = srv.healthPolls[key] >= srv.inServiceAfter
+	}
+	if registered, ok := srv.registeredAt[key]; ok && srv.inServiceDelay > 0 {
+		ready = ready || !srv.now().Before(registered.Add(srv.inServiceDelay))
+	}
+	if ready {
+		delete(srv.healthPolls, key)
+		*state = elb.InstanceState{
+			Description: "N/A",
+			InstanceId:  state.InstanceId,
+			ReasonCode:  elb.ReasonCodeNone,
+			State:       elb.StateInService,
+		}
+	}
+}
+
 func (srv *Server) configureHealthCheck(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
 	required := []string{
 		"LoadBalancerName",
@@ -391,65 +875,750 @@ func (srv *Server) configureHealthCheck(w http.ResponseWriter, req *http.Request
 		return nil, err
 	}
 	target := req.FormValue("HealthCheck.Target")
-	r, err := regexp.Compile(`[\w]+:[\d]+\/+`)
-	if err != nil {
-		panic(err)
-	}
-	if m := r.FindStringSubmatch(target); m == nil {
-		return nil, &elb.Error{
-			StatusCode: 400,
-			Code:       "ValidationError",
-			Message:    "HealthCheck HTTP Target must specify a port followed by a path that begins with a slash. e.g. HTTP:80/ping/this/path",
-		}
+	if err := validateTarget(target); err != nil {
+		return nil, err
 	}
 	ht, _ := strconv.Atoi(req.FormValue("HealthCheck.HealthyThreshold"))
 	interval, _ := strconv.Atoi(req.FormValue("HealthCheck.Interval"))
 	timeout, _ := strconv.Atoi(req.FormValue("HealthCheck.Timeout"))
 	ut, _ := strconv.Atoi(req.FormValue("HealthCheck.UnhealthyThreshold"))
-	return elb.HealthCheckResp{
-		HealthCheck: &elb.HealthCheck{
-			HealthyThreshold:   ht,
-			Interval:           interval,
-			Target:             target,
-			Timeout:            timeout,
-			UnhealthyThreshold: ut,
-		},
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	hc := elb.HealthCheck{
+		HealthyThreshold:   ht,
+		Interval:           interval,
+		Target:             target,
+		Timeout:            timeout,
+		UnhealthyThreshold: ut,
+	}
+	srv.lbs[lbName].HealthCheck = hc
+	return elb.HealthCheckResp{HealthCheck: &hc}, nil
+}
+
+// validateTarget checks a health check target like AWS does, returning
+// its messages.
+func validateTarget(target string) error {
+	if _, err := elb.ParseTarget(target); err == nil {
+		return nil
+	}
+	protocol := elb.NormalizeProtocol(strings.SplitN(target, ":", 2)[0])
+	msg := "HealthCheck Target protocol must be one of HTTP, HTTPS, TCP or SSL. e.g. TCP:80"
+	switch protocol {
+	case elb.ProtocolHTTP, elb.ProtocolHTTPS:
+		msg = "HealthCheck " + protocol + " Target must specify a port followed by a path that begins with a slash. e.g. " + protocol + ":80/ping/this/path"
+	case elb.ProtocolTCP, elb.ProtocolSSL:
+		msg = "HealthCheck " + protocol + " Target must specify a port without a path. e.g. " + protocol + ":80"
+	}
+	return &elb.Error{StatusCode: 400, Code: elb.ErrValidation, Message: msg}
+}
+
+func (srv *Server) describeLoadBalancerAttributes(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	return elb.DescribeLoadBalancerAttributesResp{
+		LoadBalancerAttributes: *srv.lbAttributes(lbName),
 	}, nil
 }
 
-func (srv *Server) instanceExists(id string) error {
-	for _, instId := range srv.instances {
-		if instId == id {
-			return nil
+func (srv *Server) modifyLoadBalancerAttributes(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerName"}); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	current := srv.lbAttributes(lbName)
+	cz, al, cd, cs := *current.CrossZoneLoadBalancing, *current.AccessLog, *current.ConnectionDraining, *current.ConnectionSettings
+	prefix := "LoadBalancerAttributes."
+	var err error
+	setBool := func(name string, b *bool) {
+		if v := req.FormValue(prefix + name); v != "" && err == nil {
+			if v != "true" && v != "false" {
+				err = attributeError("%s must be true or false", name)
+			}
+			*b = v == "true"
+		}
+	}
+	setInt := func(name string, n *int) {
+		if v := req.FormValue(prefix + name); v != "" && err == nil {
+			if *n, err = strconv.Atoi(v); err != nil {
+				err = attributeError("%s must be an integer", name)
+			}
+		}
+	}
+	setString := func(name string, s *string) {
+		if v := req.FormValue(prefix + name); v != "" {
+			*s = v
 		}
 	}
+	setBool("CrossZoneLoadBalancing.Enabled", &cz.Enabled)
+	setBool("AccessLog.Enabled", &al.Enabled)
+	setString("AccessLog.S3BucketName", &al.S3BucketName)
+	setString("AccessLog.S3BucketPrefix", &al.S3BucketPrefix)
+	setInt("AccessLog.EmitInterval", &al.EmitInterval)
+	setBool("ConnectionDraining.Enabled", &cd.Enabled)
+	setInt("ConnectionDraining.Timeout", &cd.Timeout)
+	setInt("ConnectionSettings.IdleTimeout", &cs.IdleTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAttributes(&al, &cd, &cs); err != nil {
+		return nil, err
+	}
+	attrs := &elb.LoadBalancerAttributes{
+		CrossZoneLoadBalancing: &cz,
+		AccessLog:              &al,
+		ConnectionDraining:     &cd,
+		ConnectionSettings:     &cs,
+	}
+	srv.attributes[lbName] = attrs
+	return elb.ModifyLoadBalancerAttributesResp{
+		LoadBalancerName:       lbName,
+		LoadBalancerAttributes: *attrs,
+	}, nil
+}
+
+func attributeError(format string, args ...interface{}) error {
 	return &elb.Error{
 		StatusCode: 400,
-		Code:       "InvalidInstance",
-		Message:    fmt.Sprintf("InvalidInstance found in [%s]. Invalid id: \"%s\"", id, id),
+		Code:       elb.ErrValidation,
+		Message:    fmt.Sprintf(format, args...),
 	}
 }
 
-func (srv *Server) lbExists(name string) error {
-	if _, ok := srv.lbs[name]; !ok {
+// validateAttributes checks the attributes of a Load Balancer against the
+// ranges accepted by AWS.
+func validateAttributes(al *elb.AccessLog, cd *elb.ConnectionDraining, cs *elb.ConnectionSettings) error {
+	if cs.IdleTimeout < 1 || cs.IdleTimeout > 4000 {
+		return attributeError("IdleTimeout must be between 1 and 4000 seconds, got %d", cs.IdleTimeout)
+	}
+	if cd.Timeout < 1 || cd.Timeout > 3600 {
+		return attributeError("ConnectionDraining timeout must be between 1 and 3600 seconds, got %d", cd.Timeout)
+	}
+	if al.EmitInterval != 0 && al.EmitInterval != 5 && al.EmitInterval != 60 {
+		return attributeError("AccessLog EmitInterval must be 5 or 60 minutes, got %d", al.EmitInterval)
+	}
+	if al.Enabled && al.S3BucketName == "" {
 		return &elb.Error{
 			StatusCode: 400,
-			Code:       "LoadBalancerNotFound",
-			Message:    fmt.Sprintf("There is no ACTIVE Load Balancer named '%s'", name),
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    "S3BucketName is required to enable access logs",
 		}
 	}
 	return nil
 }
 
-func (srv *Server) validate(req *http.Request, required []string) error {
-	for _, field := range required {
-		if req.FormValue(field) == "" {
-			return &elb.Error{
-				StatusCode: 400,
-				Code:       "ValidationError",
-				Message:    fmt.Sprintf("%s is required.", field),
-			}
-		}
+// lbAttributes returns the attributes of the given load balancer, creating
+// them with the AWS defaults when needed.
+func (srv *Server) lbAttributes(lbName string) *elb.LoadBalancerAttributes {
+	attrs, ok := srv.attributes[lbName]
+	if !ok {
+		attrs = defaultAttributes()
+		srv.attributes[lbName] = attrs
+	}
+	return attrs
+}
+
+// defaultAttributes returns the attributes of a newly created Load
+// Balancer.
+func defaultAttributes() *elb.LoadBalancerAttributes {
+	return &elb.LoadBalancerAttributes{
+		CrossZoneLoadBalancing: &elb.CrossZoneLoadBalancing{},
+		AccessLog:              &elb.AccessLog{},
+		ConnectionDraining:     &elb.ConnectionDraining{Timeout: 300},
+		ConnectionSettings:     &elb.ConnectionSettings{IdleTimeout: 60},
+	}
+}
+
+func (srv *Server) createLoadBalancerListeners(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	required := []string{
+		"LoadBalancerName",
+		"Listeners.member.1.InstancePort",
+		"Listeners.member.1.Protocol",
+		"Listeners.member.1.LoadBalancerPort",
+	}
+	if err := srv.validate(req, required); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	lb := srv.lbs[lbName]
+	var added []elb.ListenerDescription
+	for _, ld := range srv.makeListenerDescriptions(req.Form) {
+		if ld.Listener.InstanceProtocol == "" {
+			ld.Listener.InstanceProtocol = ld.Listener.Protocol
+		}
+		if err := validateListener(ld.Listener); err != nil {
+			return nil, err
+		}
+		for _, other := range added {
+			if other.Listener.LoadBalancerPort == ld.Listener.LoadBalancerPort {
+				return nil, &elb.Error{
+					StatusCode: 400,
+					Code:       elb.ErrDuplicateListener,
+					Message:    fmt.Sprintf("Duplicate LoadBalancerPort %d in the request", ld.Listener.LoadBalancerPort),
+				}
+			}
+		}
+		if current := findListener(lb, ld.Listener.LoadBalancerPort); current != nil {
+			if current.Listener != ld.Listener {
+				return nil, &elb.Error{
+					StatusCode: 400,
+					Code:       elb.ErrDuplicateListener,
+					Message:    fmt.Sprintf("A listener already exists for %s with LoadBalancerPort %d, but with a different InstancePort, Protocol, or SSLCertificateId", lbName, ld.Listener.LoadBalancerPort),
+				}
+			}
+			continue
+		}
+		added = append(added, ld)
+	}
+	lb.ListenerDescriptions = append(lb.ListenerDescriptions, added...)
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) deleteLoadBalancerListeners(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	required := []string{"LoadBalancerName", "LoadBalancerPorts.member.1"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	lb := srv.lbs[lbName]
+	for _, p := range srv.getParameters("LoadBalancerPorts.member.", req.Form) {
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("Invalid LoadBalancerPort: %s", p),
+			}
+		}
+		for i, ld := range lb.ListenerDescriptions {
+			if ld.Listener.LoadBalancerPort == port {
+				lb.ListenerDescriptions = append(lb.ListenerDescriptions[:i], lb.ListenerDescriptions[i+1:]...)
+				break
+			}
+		}
+	}
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) setLoadBalancerListenerSSLCertificate(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	required := []string{"LoadBalancerName", "LoadBalancerPort", "SSLCertificateId"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	port, _ := strconv.Atoi(req.FormValue("LoadBalancerPort"))
+	ld := findListener(srv.lbs[lbName], port)
+	if ld == nil {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrListenerNotFound,
+			Message:    fmt.Sprintf("Unable to find a listener on LoadBalancerPort %d for LoadBalancer %s", port, lbName),
+		}
+	}
+	if !isSecureProtocol(ld.Listener.Protocol) {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    fmt.Sprintf("The listener on LoadBalancerPort %d is not an HTTPS or SSL listener", port),
+		}
+	}
+	certId := req.FormValue("SSLCertificateId")
+	if err := certificateExists(certId); err != nil {
+		return nil, err
+	}
+	ld.Listener.SSLCertificateId = certId
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) applySecurityGroupsToLoadBalancer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	required := []string{"LoadBalancerName", "SecurityGroups.member.1"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, err
+	}
+	lb := srv.lbs[lbName]
+	if len(lb.Subnets) == 0 {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    "Security groups can only be applied to load balancers in a VPC",
+		}
+	}
+	groups := srv.getParameters("SecurityGroups.member.", req.Form)
+	for _, g := range groups {
+		if !strings.HasPrefix(g, "sg-") {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrInvalidSecurityGroup,
+				Message:    fmt.Sprintf("One or more of the specified security groups do not exist: %s", g),
+			}
+		}
+	}
+	lb.SecurityGroups = groups
+	return elb.ApplySecurityGroupsResp{SecurityGroups: groups}, nil
+}
+
+func (srv *Server) attachLoadBalancerToSubnets(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	lb, subnets, err := srv.vpcLoadBalancerSubnets(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, subnet := range subnets {
+		if !contains(lb.Subnets, subnet) {
+			lb.Subnets = append(lb.Subnets, subnet)
+		}
+	}
+	return elb.AttachLoadBalancerToSubnetsResp{Subnets: lb.Subnets}, nil
+}
+
+func (srv *Server) detachLoadBalancerFromSubnets(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	lb, subnets, err := srv.vpcLoadBalancerSubnets(req)
+	if err != nil {
+		return nil, err
+	}
+	var remaining []string
+	for _, subnet := range lb.Subnets {
+		if !contains(subnets, subnet) {
+			remaining = append(remaining, subnet)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidSubnet,
+			Message:    "A load balancer must remain attached to at least one subnet",
+		}
+	}
+	lb.Subnets = remaining
+	return elb.DetachLoadBalancerFromSubnetsResp{Subnets: lb.Subnets}, nil
+}
+
+func (srv *Server) enableAvailabilityZonesForLoadBalancer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	lb, zones, err := srv.classicLoadBalancerZones(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, zone := range zones {
+		if !contains(lb.AvailZones, zone) {
+			lb.AvailZones = append(lb.AvailZones, zone)
+		}
+	}
+	return elb.EnableAvailabilityZonesResp{AvailZones: lb.AvailZones}, nil
+}
+
+func (srv *Server) disableAvailabilityZonesForLoadBalancer(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	lb, zones, err := srv.classicLoadBalancerZones(req)
+	if err != nil {
+		return nil, err
+	}
+	var remaining []string
+	for _, zone := range lb.AvailZones {
+		if !contains(zones, zone) {
+			remaining = append(remaining, zone)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    "Cannot remove all AvailabilityZones from a LoadBalancer",
+		}
+	}
+	lb.AvailZones = remaining
+	return elb.DisableAvailabilityZonesResp{AvailZones: lb.AvailZones}, nil
+}
+
+// classicLoadBalancerZones validates an availability zone change request,
+// returning the target load balancer and the requested zones.
+func (srv *Server) classicLoadBalancerZones(req *http.Request) (*elb.LoadBalancerDescription, []string, error) {
+	required := []string{"LoadBalancerName", "AvailabilityZones.member.1"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, nil, err
+	}
+	lb := srv.lbs[lbName]
+	if len(lb.Subnets) > 0 {
+		return nil, nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    "Availability zones cannot be managed for load balancers in a VPC",
+		}
+	}
+	return lb, srv.getParameters("AvailabilityZones.member.", req.Form), nil
+}
+
+// vpcLoadBalancerSubnets validates a subnet attachment request, returning
+// the target load balancer and the requested subnets.
+func (srv *Server) vpcLoadBalancerSubnets(req *http.Request) (*elb.LoadBalancerDescription, []string, error) {
+	required := []string{"LoadBalancerName", "Subnets.member.1"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, nil, err
+	}
+	lbName := req.FormValue("LoadBalancerName")
+	if err := srv.lbExists(lbName); err != nil {
+		return nil, nil, err
+	}
+	lb := srv.lbs[lbName]
+	if len(lb.Subnets) == 0 {
+		return nil, nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    "Subnets can only be managed for load balancers in a VPC",
+		}
+	}
+	subnets := srv.getParameters("Subnets.member.", req.Form)
+	for _, subnet := range subnets {
+		if !strings.HasPrefix(subnet, "subnet-") {
+			return nil, nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrSubnetNotFound,
+				Message:    fmt.Sprintf("One or more subnets were not found: %s", subnet),
+			}
+		}
+	}
+	return lb, subnets, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateListener checks the protocols and ports of a listener, and that
+// secure listeners refer to a certificate.
+func validateListener(l elb.Listener) error {
+	for _, protocol := range []string{l.Protocol, l.InstanceProtocol} {
+		switch strings.ToUpper(protocol) {
+		case elb.ProtocolHTTP, elb.ProtocolHTTPS, elb.ProtocolTCP, elb.ProtocolSSL:
+		default:
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("Invalid protocol: %s", protocol),
+			}
+		}
+	}
+	switch port := l.LoadBalancerPort; {
+	case port == 25, port == 80, port == 443, port == 465, port == 587:
+	case port >= 1024 && port <= 65535:
+	default:
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("LoadBalancerPort %d is not allowed. Valid ports are 25, 80, 443, 465, 587 and 1024 to 65535", port),
+		}
+	}
+	if l.InstancePort < 1 || l.InstancePort > 65535 {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("InstancePort %d must be between 1 and 65535", l.InstancePort),
+		}
+	}
+	if !isSecureProtocol(l.Protocol) {
+		return nil
+	}
+	if l.SSLCertificateId == "" {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("Listener protocol %s requires an SSLCertificateId", l.Protocol),
+		}
+	}
+	return certificateExists(l.SSLCertificateId)
+}
+
+func isSecureProtocol(protocol string) bool {
+	protocol = strings.ToUpper(protocol)
+	return protocol == elb.ProtocolHTTPS || protocol == elb.ProtocolSSL
+}
+
+// certificateExists simulates the lookup of a server certificate. Any ARN
+// is considered to exist.
+func certificateExists(certId string) error {
+	if _, err := elb.ParseARN(certId); err != nil {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrCertificateNotFound,
+			Message:    fmt.Sprintf("Server Certificate not found for the key: %s", certId),
+		}
+	}
+	return nil
+}
+
+// findListener returns the listener bound to the given port of the load
+// balancer, or nil if there is none.
+func findListener(lb *elb.LoadBalancerDescription, port int) *elb.ListenerDescription {
+	for i := range lb.ListenerDescriptions {
+		if lb.ListenerDescriptions[i].Listener.LoadBalancerPort == port {
+			return &lb.ListenerDescriptions[i]
+		}
+	}
+	return nil
+}
+
+// Limits and format of the tags of a Load Balancer.
+const (
+	maxTags           = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+	maxDescribeTags   = 20
+)
+
+var validTag = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// tagParams returns the tags of a request, given as Tags.member.N.Key and
+// Tags.member.N.Value.
+func tagParams(req *http.Request) []elb.Tag {
+	var tags []elb.Tag
+	for i := 1; req.FormValue(fmt.Sprintf("Tags.member.%d.Key", i)) != ""; i++ {
+		key := fmt.Sprintf("Tags.member.%d.", i)
+		tags = append(tags, elb.Tag{Key: req.FormValue(key + "Key"), Value: req.FormValue(key + "Value")})
+	}
+	return tags
+}
+
+// validateTagKey checks the format of a tag key: up to 128 letters,
+// digits, whitespace or _.:/=+-@ characters, not starting with the
+// reserved "aws:" prefix.
+func validateTagKey(key string) error {
+	if utf8.RuneCountInString(key) > maxTagKeyLength || !validTag.MatchString(key) {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("Tag key '%s' is not valid", key),
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(key), "aws:") {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    fmt.Sprintf("Tag keys starting with 'aws:' are reserved for internal use: %s", key),
+		}
+	}
+	return nil
+}
+
+func validateTags(tags []elb.Tag) error {
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if err := validateTagKey(tag.Key); err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(tag.Value) > maxTagValueLength || !validTag.MatchString(tag.Value) {
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("Value of tag '%s' is not valid", tag.Key),
+			}
+		}
+		if seen[tag.Key] {
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrDuplicateTagKeys,
+				Message:    fmt.Sprintf("Tag key '%s' is given more than once", tag.Key),
+			}
+		}
+		seen[tag.Key] = true
+	}
+	return nil
+}
+
+func (srv *Server) addTags(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	required := []string{"LoadBalancerNames.member.1", "Tags.member.1.Key"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, err
+	}
+	lbNames := srv.getParameters("LoadBalancerNames.member.", req.Form)
+	for _, lbName := range lbNames {
+		if err := srv.lbExists(lbName); err != nil {
+			return nil, err
+		}
+	}
+	tags := tagParams(req)
+	if err := validateTags(tags); err != nil {
+		return nil, err
+	}
+	// The request is rejected as a whole when any of the Load Balancers
+	// would end up with too many tags.
+	for _, lbName := range lbNames {
+		count := len(srv.tags[lbName])
+		for _, tag := range tags {
+			if !hasTag(srv.tags[lbName], tag.Key) {
+				count++
+			}
+		}
+		if count > maxTags {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrTooManyTags,
+				Message:    fmt.Sprintf("Load Balancer %s can't have more than %d tags", lbName, maxTags),
+			}
+		}
+	}
+	for _, lbName := range lbNames {
+		for _, tag := range tags {
+			srv.tags[lbName] = setTag(srv.tags[lbName], tag)
+		}
+	}
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) removeTags(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	required := []string{"LoadBalancerNames.member.1", "Tags.member.1.Key"}
+	if err := srv.validate(req, required); err != nil {
+		return nil, err
+	}
+	lbNames := srv.getParameters("LoadBalancerNames.member.", req.Form)
+	for _, lbName := range lbNames {
+		if err := srv.lbExists(lbName); err != nil {
+			return nil, err
+		}
+	}
+	var keys []string
+	for _, tag := range tagParams(req) {
+		if err := validateTagKey(tag.Key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, tag.Key)
+	}
+	for _, key := range keys {
+		for _, lbName := range lbNames {
+			srv.tags[lbName] = unsetTag(srv.tags[lbName], key)
+		}
+	}
+	return elb.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) describeTags(w http.ResponseWriter, req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req, []string{"LoadBalancerNames.member.1"}); err != nil {
+		return nil, err
+	}
+	lbNames := srv.getParameters("LoadBalancerNames.member.", req.Form)
+	if len(lbNames) > maxDescribeTags {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrValidation,
+			Message:    fmt.Sprintf("At most %d Load Balancers may be described at once", maxDescribeTags),
+		}
+	}
+	var resp elb.DescribeTagsResp
+	for _, lbName := range lbNames {
+		if err := srv.lbExists(lbName); err != nil {
+			return nil, err
+		}
+		resp.TagDescriptions = append(resp.TagDescriptions, elb.TagDescription{
+			LoadBalancerName: lbName,
+			Tags:             srv.tags[lbName],
+		})
+	}
+	return resp, nil
+}
+
+// Tags returns the tags of the named Load Balancer, in the order they were
+// added.
+func (srv *Server) Tags(lbName string) []elb.Tag {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return append([]elb.Tag(nil), srv.tags[lbName]...)
+}
+
+func hasTag(tags []elb.Tag, key string) bool {
+	for _, t := range tags {
+		if t.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setTag adds tag to tags, replacing the value of any tag with the same key.
+func setTag(tags []elb.Tag, tag elb.Tag) []elb.Tag {
+	for i, t := range tags {
+		if t.Key == tag.Key {
+			tags[i] = tag
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+func unsetTag(tags []elb.Tag, key string) []elb.Tag {
+	for i, t := range tags {
+		if t.Key == key {
+			return append(tags[:i], tags[i+1:]...)
+		}
+	}
+	return tags
+}
+
+func (srv *Server) instanceExists(id string) error {
+	for _, instId := range srv.instances {
+		if instId == id {
+			return nil
+		}
+	}
+	return &elb.Error{
+		StatusCode: 400,
+		Code:       elb.ErrInvalidInstance,
+		Message:    fmt.Sprintf("InvalidInstance found in [%s]. Invalid id: \"%s\"", id, id),
+	}
+}
+
+func (srv *Server) lbExists(name string) error {
+	if _, ok := srv.lbs[name]; !ok {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrLoadBalancerNotFound,
+			Message:    fmt.Sprintf("There is no ACTIVE Load Balancer named '%s'", name),
+		}
+	}
+	return nil
+}
+
+// validate checks that the request holds the required fields. The first
+// member of a list parameter, like Subnets.member.1, stands for any of its
+// members.
+func (srv *Server) validate(req *http.Request, required []string) error {
+	if srv.compatibility == CompatibilityAWS {
+		return awsValidate(req.Form, required)
+	}
+	for _, field := range required {
+		if !srv.hasParameter(req, field) {
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("%s is required.", field),
+			}
+		}
 	}
 	return nil
 }
@@ -458,25 +1627,37 @@ func (srv *Server) validate(req *http.Request, required []string) error {
 //
 // Some fields cannot be together in the same request, such as AvailabilityZones and Subnets.
 // A sample map with the above requirement would be
-//    c := map[string]string{
-//        "AvailabilityZones.member.1": "Subnets.member.1",
-//    }
+//
+//	c := map[string]string{
+//	    "AvailabilityZones.member.1": "Subnets.member.1",
+//	}
 //
 // The server also requires that at least one of those fields are specified.
+// Like in validate, the first member of a list stands for any of its
+// members.
 func (srv *Server) validateComposition(req *http.Request, composition map[string]string) error {
 	for k, v := range composition {
-		if req.FormValue(k) != "" && req.FormValue(v) != "" {
+		kName, vName := k, v
+		if srv.compatibility == CompatibilityAWS {
+			kName, vName = awsNames[k], awsNames[v]
+		}
+		hasK, hasV := srv.hasParameter(req, k), srv.hasParameter(req, v)
+		if hasK && hasV {
+			msg := fmt.Sprintf("Only one of %s or %s may be specified", kName, vName)
+			if srv.compatibility == CompatibilityAWS {
+				msg = fmt.Sprintf("Only one of %s or %s may be specified", vName, kName)
+			}
 			return &elb.Error{
 				StatusCode: 400,
-				Code:       "ValidationError",
-				Message:    fmt.Sprintf("Only one of %s or %s may be specified", k, v),
+				Code:       elb.ErrValidation,
+				Message:    msg,
 			}
 		}
-		if req.FormValue(k) == "" && req.FormValue(v) == "" {
+		if !hasK && !hasV {
 			return &elb.Error{
 				StatusCode: 400,
-				Code:       "ValidationError",
-				Message:    fmt.Sprintf("Either %s or %s must be specified", k, v),
+				Code:       elb.ErrValidation,
+				Message:    fmt.Sprintf("Either %s or %s must be specified", kName, vName),
 			}
 		}
 	}
@@ -485,6 +1666,8 @@ func (srv *Server) validateComposition(req *http.Request, composition map[string
 
 // Creates a fake instance in the server
 func (srv *Server) NewInstance() string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
 	srv.instCount++
 	instId := fmt.Sprintf("i-%d", srv.instCount)
 	srv.instances = append(srv.instances, instId)
@@ -495,6 +1678,8 @@ func (srv *Server) NewInstance() string {
 //
 // If no instance is found it does nothing
 func (srv *Server) RemoveInstance(instId string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
 	for i, id := range srv.instances {
 		if id == instId {
 			srv.instances[i], srv.instances = srv.instances[len(srv.instances)-1], srv.instances[:len(srv.instances)-1]
@@ -504,36 +1689,77 @@ func (srv *Server) RemoveInstance(instId string) {
 
 // Creates a fake load balancer in the fake server
 func (srv *Server) NewLoadBalancer(name string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
 	srv.lbs[name] = &elb.LoadBalancerDescription{
 		LoadBalancerName: name,
-		DNSName:          fmt.Sprintf("%s-some-aws-stuff.sa-east-1.amazonaws.com", name),
+		DNSName:          srv.dnsName(name, elb.SchemeInternetFacing),
+		CreatedTime:      srv.now().UTC().Truncate(time.Millisecond),
 	}
 }
 
 // Removes a fake load balancer from the fake server
 func (srv *Server) RemoveLoadBalancer(name string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.removeLoadBalancer(name)
+}
+
+func (srv *Server) removeLoadBalancer(name string) {
 	delete(srv.lbs, name)
+	delete(srv.attributes, name)
+	delete(srv.tags, name)
+	delete(srv.policies, name)
+	delete(srv.instanceStates, name)
+	for key := range srv.healthPolls {
+		if strings.HasPrefix(key, name+"/") {
+			delete(srv.healthPolls, key)
+		}
+	}
+	for key := range srv.phases {
+		if strings.HasPrefix(key, name+"/") {
+			delete(srv.phases, key)
+		}
+	}
+	for _, m := range []map[string]time.Time{srv.registeredAt, srv.draining} {
+		for key := range m {
+			if strings.HasPrefix(key, name+"/") {
+				delete(m, key)
+			}
+		}
+	}
 }
 
 // Register a fake instance with a fake Load Balancer
 //
 // If the Load Balancer does not exists it does nothing
 func (srv *Server) RegisterInstance(instId, lbName string) {
-	lb, ok := srv.lbs[lbName]
-	if !ok {
-		fmt.Println("lb not found :/")
-		return
+	srv.mutex.Lock()
+	if _, ok := srv.lbs[lbName]; ok {
+		srv.registerInstance(lbName, instId)
 	}
-	lb.Instances = append(lb.Instances, elb.Instance{InstanceId: instId})
-	srv.instanceStates[lbName] = append(srv.instanceStates[lbName], srv.makeInstanceState(instId))
+	subscribers := srv.subscribersOf(srv.namespaceName)
+	events := srv.takeTransitions()
+	srv.mutex.Unlock()
+	notify(subscribers, events)
 }
 
 func (srv *Server) DeregisterInstance(instId, lbName string) {
-	removeInstanceFromLB(srv.lbs[lbName], instId)
-	srv.removeInstanceStatesFromLoadBalancer(lbName, instId)
+	srv.mutex.Lock()
+	srv.deregisterInstance(lbName, instId)
+	subscribers := srv.subscribersOf(srv.namespaceName)
+	events := srv.takeTransitions()
+	srv.mutex.Unlock()
+	notify(subscribers, events)
 }
 
 func (srv *Server) ChangeInstanceState(lb string, state elb.InstanceState) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.changeInstanceState(lb, state)
+}
+
+func (srv *Server) changeInstanceState(lb string, state elb.InstanceState) {
 	states := srv.instanceStates[lb]
 	for i, s := range states {
 		if s.InstanceId == state.InstanceId {
@@ -543,12 +1769,208 @@ func (srv *Server) ChangeInstanceState(lb string, state elb.InstanceState) {
 	}
 }
 
+// SetInstanceState sets the health state of an instance registered with
+// the Load Balancer, as reported by DescribeInstanceHealth.
+//
+// If the instance isn't registered with the Load Balancer it does nothing.
+func (srv *Server) SetInstanceState(lbName, instId string, state elb.HealthState, reasonCode, description string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.changeInstanceState(lbName, elb.InstanceState{
+		Description: description,
+		InstanceId:  instId,
+		ReasonCode:  reasonCode,
+		State:       state,
+	})
+}
+
+// Reset wipes the state of the server: Load Balancers, instances, request
+// history and injected errors, so it can be reused between test cases.
+// Settings like SetInServiceAfter, SetLifecycle, SetLatency, SetFault,
+// SetRegion, SetClock, SetNamespaceFunc and SetCompatibilityLevel are reset
+// to their defaults too. On a view returned by Namespace, only the Load Balancers,
+// instances and requests of the namespace are wiped.
+func (srv *Server) Reset() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if srv.scoped {
+		srv.namespace.reset()
+		srv.resetHistory()
+		return
+	}
+	for _, ns := range srv.namespaces {
+		ns.reset()
+	}
+	srv.history = nil
+	srv.errors = make(map[string]*injectedError)
+	srv.inServiceAfter = 0
+	srv.strict = false
+	srv.accessKeys = nil
+	srv.latencies = make(map[string]time.Duration)
+	srv.faults = make(map[string]FaultKind)
+	srv.pageSize = maxPageSize
+	srv.subscribers = nil
+	srv.compatibility = CompatibilityLegacy
+	srv.region = defaultRegion
+	srv.clock = nil
+	srv.clockOffset = 0
+	srv.inServiceDelay = 0
+	srv.namespaceOf = nil
+	srv.dumpDir = ""
+	srv.dumps = nil
+	srv.middleware = nil
+	srv.lifecycle = nil
+	srv.transitions = nil
+}
+
+// SetInServiceAfter makes registered instances transition from the pending
+// OutOfService state to InService after they have been described n times
+// by DescribeInstanceHealth, like they would once passing the health
+// checks of a real Load Balancer. Instances whose state was set explicitly
+// don't transition. A zero n, the default, disables the transition.
+func (srv *Server) SetInServiceAfter(n int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.inServiceAfter = n
+}
+
+// SetStrictMode makes the server validate the common parameters of every
+// request the way the real endpoint does, rejecting requests with missing
+// credentials, an unsupported SignatureVersion or Version, or a Timestamp
+// that is malformed or more than 15 minutes away from the current time.
+// Requests signed with Signature Version 4 carry their credentials in the
+// Authorization header instead. Strict mode is disabled by default.
+func (srv *Server) SetStrictMode(strict bool) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.strict = strict
+}
+
+// apiVersion is the version of the ELB API implemented by the server.
+const apiVersion = "2012-06-01"
+
+// maxClockSkew is how far the Timestamp of a request may be from the
+// current time.
+const maxClockSkew = 15 * time.Minute
+
+// validateRequest checks the common parameters of req, returning the error
+// the real endpoint would return for it.
+func validateRequest(req *http.Request) *elb.Error {
+	v4 := strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !v4 {
+		if req.Form.Get("AWSAccessKeyId") == "" {
+			return &elb.Error{
+				StatusCode: 403,
+				Code:       "MissingAuthenticationToken",
+				Message:    "Request is missing Authentication Token",
+			}
+		}
+		if v := req.Form.Get("SignatureVersion"); v != "2" {
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       "InvalidParameterValue",
+				Message:    fmt.Sprintf("Value (%s) for parameter SignatureVersion is invalid.", v),
+			}
+		}
+	}
+	switch version := req.Form.Get("Version"); version {
+	case apiVersion:
+	case "":
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       "MissingParameter",
+			Message:    "The request must contain the parameter Version",
+		}
+	default:
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       "NoSuchVersion",
+			Message:    fmt.Sprintf("The requested version (%s) of service AmazonElasticLoadBalancing does not exist", version),
+		}
+	}
+	var (
+		t   time.Time
+		err error
+	)
+	if ts := req.Form.Get("Timestamp"); ts != "" {
+		t, err = time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       "InvalidParameterValue",
+				Message:    fmt.Sprintf("Value (%s) for parameter Timestamp is invalid.", ts),
+			}
+		}
+	} else if date := req.Header.Get("X-Amz-Date"); v4 && date != "" {
+		t, err = time.Parse("20060102T150405Z", date)
+		if err != nil {
+			return &elb.Error{
+				StatusCode: 400,
+				Code:       "IncompleteSignature",
+				Message:    fmt.Sprintf("Date must be in ISO-8601 'basic format'. Got '%s'.", date),
+			}
+		}
+	} else {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       "MissingParameter",
+			Message:    "The request must contain the parameter Timestamp",
+		}
+	}
+	if skew := time.Since(t); skew > maxClockSkew || skew < -maxClockSkew {
+		return &elb.Error{
+			StatusCode: 400,
+			Code:       "RequestExpired",
+			Message:    "Request has expired.",
+		}
+	}
+	return nil
+}
+
+// maxPageSize is the largest PageSize accepted by DescribeLoadBalancers,
+// and the size of its pages by default.
+const maxPageSize = 400
+
+// SetPageSize sets how many Load Balancers DescribeLoadBalancers returns
+// per page when the request doesn't have a PageSize, so pagination can be
+// exercised with a handful of Load Balancers. A zero or negative n restores
+// the default of 400.
+func (srv *Server) SetPageSize(n int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if n <= 0 || n > maxPageSize {
+		n = maxPageSize
+	}
+	srv.pageSize = n
+}
+
 var actions = map[string]func(*Server, http.ResponseWriter, *http.Request, string) (interface{}, error){
-	"CreateLoadBalancer":                  (*Server).createLoadBalancer,
-	"DeleteLoadBalancer":                  (*Server).deleteLoadBalancer,
-	"RegisterInstancesWithLoadBalancer":   (*Server).registerInstancesWithLoadBalancer,
-	"DeregisterInstancesFromLoadBalancer": (*Server).deregisterInstancesFromLoadBalancer,
-	"DescribeLoadBalancers":               (*Server).describeLoadBalancers,
-	"DescribeInstanceHealth":              (*Server).describeInstanceHealth,
-	"ConfigureHealthCheck":                (*Server).configureHealthCheck,
+	"CreateLoadBalancer":                      (*Server).createLoadBalancer,
+	"DeleteLoadBalancer":                      (*Server).deleteLoadBalancer,
+	"RegisterInstancesWithLoadBalancer":       (*Server).registerInstancesWithLoadBalancer,
+	"DeregisterInstancesFromLoadBalancer":     (*Server).deregisterInstancesFromLoadBalancer,
+	"DescribeLoadBalancers":                   (*Server).describeLoadBalancers,
+	"DescribeInstanceHealth":                  (*Server).describeInstanceHealth,
+	"ConfigureHealthCheck":                    (*Server).configureHealthCheck,
+	"DescribeLoadBalancerAttributes":          (*Server).describeLoadBalancerAttributes,
+	"ModifyLoadBalancerAttributes":            (*Server).modifyLoadBalancerAttributes,
+	"CreateLoadBalancerListeners":             (*Server).createLoadBalancerListeners,
+	"DeleteLoadBalancerListeners":             (*Server).deleteLoadBalancerListeners,
+	"SetLoadBalancerListenerSSLCertificate":   (*Server).setLoadBalancerListenerSSLCertificate,
+	"ApplySecurityGroupsToLoadBalancer":       (*Server).applySecurityGroupsToLoadBalancer,
+	"AttachLoadBalancerToSubnets":             (*Server).attachLoadBalancerToSubnets,
+	"DetachLoadBalancerFromSubnets":           (*Server).detachLoadBalancerFromSubnets,
+	"EnableAvailabilityZonesForLoadBalancer":  (*Server).enableAvailabilityZonesForLoadBalancer,
+	"DisableAvailabilityZonesForLoadBalancer": (*Server).disableAvailabilityZonesForLoadBalancer,
+	"CreateLBCookieStickinessPolicy":          (*Server).createLBCookieStickinessPolicy,
+	"CreateAppCookieStickinessPolicy":         (*Server).createAppCookieStickinessPolicy,
+	"CreateLoadBalancerPolicy":                (*Server).createLoadBalancerPolicy,
+	"DeleteLoadBalancerPolicy":                (*Server).deleteLoadBalancerPolicy,
+	"DescribeLoadBalancerPolicies":            (*Server).describeLoadBalancerPolicies,
+	"DescribeLoadBalancerPolicyTypes":         (*Server).describeLoadBalancerPolicyTypes,
+	"SetLoadBalancerPoliciesOfListener":       (*Server).setLoadBalancerPoliciesOfListener,
+	"SetLoadBalancerPoliciesForBackendServer": (*Server).setLoadBalancerPoliciesForBackendServer,
+	"AddTags":      (*Server).addTags,
+	"RemoveTags":   (*Server).removeTags,
+	"DescribeTags": (*Server).describeTags,
 }
@@ -0,0 +1,176 @@
+package elbtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const v4Algorithm = "AWS4-HMAC-SHA256"
+
+// SetAccessKeys makes the server verify the Signature Version 2 and 4
+// signatures of every request against the given secret keys, indexed by
+// access key. Unsigned and mis-signed requests are rejected with
+// SignatureDoesNotMatch, and requests signed with an access key missing
+// from keys with InvalidClientTokenId. A nil or empty keys disables the
+// verification, which is the default.
+func (srv *Server) SetAccessKeys(keys map[string]string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.accessKeys = make(map[string]string, len(keys))
+	for k, v := range keys {
+		srv.accessKeys[k] = v
+	}
+}
+
+// verifySignature checks the signature of req against the access keys set
+// with SetAccessKeys.
+func (srv *Server) verifySignature(req *http.Request) *elb.Error {
+	var accessKey, expected, signature string
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, v4Algorithm+" ") {
+		fields := parseAuthorization(auth[len(v4Algorithm)+1:])
+		scope := strings.Split(fields["Credential"], "/")
+		if len(scope) != 5 || fields["SignedHeaders"] == "" {
+			return signatureDoesNotMatch()
+		}
+		accessKey, signature = scope[0], fields["Signature"]
+		secretKey, ok := srv.accessKeys[accessKey]
+		if !ok {
+			return invalidClientTokenId()
+		}
+		expected = signV4(req, secretKey, scope[1], scope[2], scope[3], strings.Split(fields["SignedHeaders"], ";"))
+	} else {
+		accessKey, signature = req.Form.Get("AWSAccessKeyId"), req.Form.Get("Signature")
+		if accessKey == "" || signature == "" {
+			return signatureDoesNotMatch()
+		}
+		secretKey, ok := srv.accessKeys[accessKey]
+		if !ok {
+			return invalidClientTokenId()
+		}
+		expected = signV2(req, secretKey)
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return signatureDoesNotMatch()
+	}
+	return nil
+}
+
+func signatureDoesNotMatch() *elb.Error {
+	return &elb.Error{
+		StatusCode: 403,
+		Code:       "SignatureDoesNotMatch",
+		Message:    "The request signature we calculated does not match the signature you provided. Check your AWS Secret Access Key and signing method. Consult the service documentation for details.",
+	}
+}
+
+func invalidClientTokenId() *elb.Error {
+	return &elb.Error{
+		StatusCode: 403,
+		Code:       "InvalidClientTokenId",
+		Message:    "The security token included in the request is invalid.",
+	}
+}
+
+// signV2 computes the Signature Version 2 signature of req.
+func signV2(req *http.Request, secretKey string) string {
+	var keys, sarray []string
+	for k := range req.Form {
+		if k != "Signature" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sarray = append(sarray, aws.Encode(k)+"="+aws.Encode(req.Form.Get(k)))
+	}
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	payload := req.Method + "\n" + req.Host + "\n" + path + "\n" + strings.Join(sarray, "&")
+	return base64.StdEncoding.EncodeToString(hmacSHA256([]byte(secretKey), payload))
+}
+
+// signV4 computes the Signature Version 4 signature of req over the given
+// signed headers.
+func signV4(req *http.Request, secretKey, day, region, service string, signedHeaders []string) string {
+	var canonicalHeaders []string
+	for _, name := range signedHeaders {
+		value := strings.TrimSpace(strings.Join(req.Header[http.CanonicalHeaderKey(name)], ","))
+		if name == "host" {
+			value = req.Host
+		}
+		canonicalHeaders = append(canonicalHeaders, name+":"+value+"\n")
+	}
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		strings.Join(canonicalHeaders, ""),
+		strings.Join(signedHeaders, ";"),
+		hexSHA256(""),
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		v4Algorithm,
+		req.Header.Get("X-Amz-Date"),
+		strings.Join([]string{day, region, service, "aws4_request"}, "/"),
+		hexSHA256(canonicalRequest),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// parseAuthorization parses the comma separated key=value fields of a
+// Signature Version 4 Authorization header.
+func parseAuthorization(s string) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) == 2 {
+			fields[kv[0]] = kv[1]
+		}
+	}
+	return fields
+}
+
+func canonicalQuery(values map[string][]string) string {
+	var keys, sarray []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			sarray = append(sarray, aws.Encode(k)+"="+aws.Encode(v))
+		}
+	}
+	return strings.Join(sarray, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write([]byte(data))
+	return hash.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	hash := sha256.New()
+	hash.Write([]byte(data))
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package elbtest
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	"sort"
+)
+
+// LoadBalancer is a snapshot of the state of a Load Balancer of the
+// server, as returned by Server.LoadBalancer. It shares no memory with
+// the server, so it isn't affected by later operations and may be
+// changed freely.
+type LoadBalancer struct {
+	// Description holds the Load Balancer as described by
+	// DescribeLoadBalancers, including its listeners, registered
+	// instances and the policies enabled on them.
+	Description elb.LoadBalancerDescription
+
+	// Health holds the health state of each registered instance.
+	Health []elb.InstanceState
+
+	Tags       []elb.Tag
+	Attributes elb.LoadBalancerAttributes
+	Policies   []elb.PolicyDescription
+}
+
+// LoadBalancer returns a snapshot of the named Load Balancer, or nil if the
+// server has no Load Balancer with that name.
+func (srv *Server) LoadBalancer(name string) *LoadBalancer {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if _, ok := srv.lbs[name]; !ok {
+		return nil
+	}
+	lb := srv.snapshot(name)
+	return &lb
+}
+
+// LoadBalancers returns snapshots of all the Load Balancers of the server,
+// sorted by name.
+func (srv *Server) LoadBalancers() []LoadBalancer {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	names := make([]string, 0, len(srv.lbs))
+	for name := range srv.lbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lbs := make([]LoadBalancer, len(names))
+	for i, name := range names {
+		lbs[i] = srv.snapshot(name)
+	}
+	return lbs
+}
+
+func (srv *Server) snapshot(name string) LoadBalancer {
+	lb := LoadBalancer{
+		Description: copyDescription(srv.lbs[name]),
+		Tags:        append([]elb.Tag(nil), srv.tags[name]...),
+	}
+	for _, state := range srv.instanceStates[name] {
+		lb.Health = append(lb.Health, *state)
+	}
+	attrs, ok := srv.attributes[name]
+	if !ok {
+		attrs = defaultAttributes()
+	}
+	lb.Attributes = copyAttributes(attrs)
+	for _, p := range srv.policies[name] {
+		p.PolicyAttributeDescriptions = append([]elb.PolicyAttributeDescription(nil), p.PolicyAttributeDescriptions...)
+		lb.Policies = append(lb.Policies, p)
+	}
+	return lb
+}
+
+func copyDescription(lb *elb.LoadBalancerDescription) elb.LoadBalancerDescription {
+	desc := *lb
+	desc.AvailZones = append([]string(nil), lb.AvailZones...)
+	desc.SecurityGroups = append([]string(nil), lb.SecurityGroups...)
+	desc.Subnets = append([]string(nil), lb.Subnets...)
+	desc.Instances = append([]elb.Instance(nil), lb.Instances...)
+	desc.ListenerDescriptions = nil
+	for _, ld := range lb.ListenerDescriptions {
+		ld.PolicyNames = append([]string(nil), ld.PolicyNames...)
+		desc.ListenerDescriptions = append(desc.ListenerDescriptions, ld)
+	}
+	desc.BackendServerDescriptions = nil
+	for _, bd := range lb.BackendServerDescriptions {
+		bd.PolicyNames = append([]string(nil), bd.PolicyNames...)
+		desc.BackendServerDescriptions = append(desc.BackendServerDescriptions, bd)
+	}
+	desc.Policies = elb.Policies{
+		AppCookieStickinessPolicies: append([]elb.AppCookieStickinessPolicies(nil), lb.Policies.AppCookieStickinessPolicies...),
+		LBCookieStickinessPolicies:  append([]elb.LBCookieStickinessPolicies(nil), lb.Policies.LBCookieStickinessPolicies...),
+		OtherPolicies:               append([]string(nil), lb.Policies.OtherPolicies...),
+	}
+	return desc
+}
+
+func copyAttributes(attrs *elb.LoadBalancerAttributes) elb.LoadBalancerAttributes {
+	var c elb.LoadBalancerAttributes
+	if attrs.CrossZoneLoadBalancing != nil {
+		v := *attrs.CrossZoneLoadBalancing
+		c.CrossZoneLoadBalancing = &v
+	}
+	if attrs.AccessLog != nil {
+		v := *attrs.AccessLog
+		c.AccessLog = &v
+	}
+	if attrs.ConnectionDraining != nil {
+		v := *attrs.ConnectionDraining
+		c.ConnectionDraining = &v
+	}
+	if attrs.ConnectionSettings != nil {
+		v := *attrs.ConnectionSettings
+		c.ConnectionSettings = &v
+	}
+	return c
+}
@@ -0,0 +1,217 @@
+package elbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// State is the state of the server as serialized by Snapshot and restored
+// by LoadSnapshot. It holds the Load Balancers and instances of each
+// namespace along with the requests the server received. Settings like
+// SetError or SetLatency, and in-flight timers like connection draining,
+// aren't part of it.
+type State struct {
+	Namespaces []NamespaceState
+	Requests   []Request
+}
+
+// NamespaceState is the state of a namespace of the server. The default
+// namespace has an empty Name.
+type NamespaceState struct {
+	Name          string
+	LoadBalancers []LoadBalancer
+	Instances     []string
+}
+
+// Snapshot returns the state of the server serialized to JSON, so that it
+// can be written out when a test fails and later restored with
+// LoadSnapshot. A view returned by Namespace only serializes its own
+// namespace and the requests sent to it.
+func (srv *Server) Snapshot() ([]byte, error) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return json.MarshalIndent(srv.state(), "", "\t")
+}
+
+// LoadSnapshot replaces the Load Balancers, instances and requests of the
+// server with the ones serialized in data by Snapshot, leaving its
+// settings untouched. A view returned by Namespace only replaces its own
+// namespace, and data must then hold a single namespace, whatever its
+// name.
+//
+// The snapshot is validated as a whole before anything is replaced, so
+// that an invalid snapshot leaves the server untouched.
+func (srv *Server) LoadSnapshot(data []byte) error {
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("elbtest: invalid snapshot: %v", err)
+	}
+	if err := validateState(state); err != nil {
+		return err
+	}
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if srv.scoped {
+		if len(state.Namespaces) > 1 {
+			return fmt.Errorf("elbtest: snapshot has %d namespaces, want at most 1", len(state.Namespaces))
+		}
+		srv.namespace.reset()
+		for _, ns := range state.Namespaces {
+			srv.loadNamespace(srv.namespace, ns)
+		}
+		srv.resetHistory()
+		for _, r := range state.Requests {
+			r.Namespace = srv.namespaceName
+			srv.history = append(srv.history, r)
+		}
+		return nil
+	}
+	for _, ns := range srv.namespaces {
+		ns.reset()
+	}
+	for _, ns := range state.Namespaces {
+		srv.loadNamespace(srv.namespaceNamed(ns.Name), ns)
+	}
+	srv.history = append([]Request(nil), state.Requests...)
+	return nil
+}
+
+// SetDumpOnServerError makes the server write a snapshot of its state to
+// a new file in dir whenever it answers a request with a 5xx status, so
+// that a flaky test leaves behind the state the server was in when it
+// failed. The path of each file is printed to the standard error and
+// returned by Dumps. An empty dir, the default, disables the dumps.
+func (srv *Server) SetDumpOnServerError(dir string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.dumpDir = dir
+}
+
+// Dumps returns the paths of the files written since SetDumpOnServerError
+// was called, in the order they were written.
+func (srv *Server) Dumps() []string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return append([]string(nil), srv.dumps...)
+}
+
+// dumpState writes a snapshot of the whole server to a new file in the
+// directory set by SetDumpOnServerError, if any. Failing to write it is
+// reported on the standard error rather than to the client, whose request
+// has already failed.
+func (srv *Server) dumpState(reqId string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if srv.dumpDir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(srv.state(), "", "\t")
+	if err == nil {
+		var f *os.File
+		if f, err = os.CreateTemp(srv.dumpDir, "elbtest-"+reqId+"-*.json"); err == nil {
+			_, err = f.Write(data)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err == nil {
+				srv.dumps = append(srv.dumps, f.Name())
+				fmt.Fprintf(os.Stderr, "elbtest: state dumped to %s after request %s failed\n", f.Name(), reqId)
+				return
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "elbtest: can't dump state after request %s failed: %v\n", reqId, err)
+}
+
+// state returns the state seen by the view, sharing no memory with the
+// server.
+func (srv *Server) state() State {
+	var names []string
+	if srv.scoped {
+		names = []string{srv.namespaceName}
+	} else {
+		for name := range srv.namespaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var state State
+	for _, name := range names {
+		ns := srv.view(name, true)
+		lbNames := make([]string, 0, len(ns.lbs))
+		for lbName := range ns.lbs {
+			lbNames = append(lbNames, lbName)
+		}
+		sort.Strings(lbNames)
+		nsState := NamespaceState{
+			Name:      name,
+			Instances: append([]string(nil), ns.instances...),
+		}
+		for _, lbName := range lbNames {
+			nsState.LoadBalancers = append(nsState.LoadBalancers, ns.snapshot(lbName))
+		}
+		state.Namespaces = append(state.Namespaces, nsState)
+	}
+	for _, r := range srv.history {
+		if srv.sees(r.Namespace) {
+			state.Requests = append(state.Requests, r)
+		}
+	}
+	return state
+}
+
+func validateState(state State) error {
+	seen := make(map[string]bool)
+	for _, ns := range state.Namespaces {
+		if seen[ns.Name] {
+			return fmt.Errorf("elbtest: snapshot has namespace %q twice", ns.Name)
+		}
+		seen[ns.Name] = true
+		lbs := make(map[string]bool)
+		for _, lb := range ns.LoadBalancers {
+			name := lb.Description.LoadBalancerName
+			if name == "" {
+				return fmt.Errorf("elbtest: snapshot has a Load Balancer without a name")
+			}
+			if lbs[name] {
+				return fmt.Errorf("elbtest: snapshot has Load Balancer %s twice in namespace %q", name, ns.Name)
+			}
+			lbs[name] = true
+		}
+	}
+	return nil
+}
+
+// loadNamespace fills the empty namespace ns with the state s, making
+// sure NewInstance won't reuse the ids of its instances.
+func (srv *Server) loadNamespace(ns *namespace, s NamespaceState) {
+	ns.instances = append([]string(nil), s.Instances...)
+	for _, id := range s.Instances {
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, "i-")); err == nil && n > srv.instCount {
+			srv.instCount = n
+		}
+	}
+	for _, lb := range s.LoadBalancers {
+		name := lb.Description.LoadBalancerName
+		desc := copyDescription(&lb.Description)
+		ns.lbs[name] = &desc
+		for _, state := range lb.Health {
+			state := state
+			ns.instanceStates[name] = append(ns.instanceStates[name], &state)
+		}
+		attrs := copyAttributes(&lb.Attributes)
+		ns.attributes[name] = &attrs
+		if len(lb.Tags) > 0 {
+			ns.tags[name] = append([]elb.Tag(nil), lb.Tags...)
+		}
+		for _, p := range lb.Policies {
+			p.PolicyAttributeDescriptions = append([]elb.PolicyAttributeDescription(nil), p.PolicyAttributeDescriptions...)
+			ns.policies[name] = append(ns.policies[name], p)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package elbtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewTLSServer starts and returns a new server serving the API over HTTPS,
+// for code that requires https:// endpoints. The server presents a
+// self-signed certificate for localhost, which clients must trust: either
+// use the client returned by Client, or add Certificate to the root CAs
+// of their own transport.
+func NewTLSServer() (*Server, error) {
+	cert, err := selfSignedCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("cannot create certificate: %v", err)
+	}
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on localhost: %v", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv := newServer(tls.NewListener(l, config), "https://"+l.Addr().String())
+	srv.certificate = cert.Leaf
+	return srv, nil
+}
+
+// Certificate returns the certificate presented by a server started with
+// NewTLSServer, or nil for plain HTTP servers.
+func (srv *Server) Certificate() *x509.Certificate {
+	return srv.certificate
+}
+
+// Client returns an HTTP client that trusts the certificate of the server,
+// to be given to elb.WithHTTPClient. Plain HTTP servers get a client with
+// the default transport.
+func (srv *Server) Client() *http.Client {
+	if srv.certificate == nil {
+		return &http.Client{}
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.certificate)
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+}
+
+// selfSignedCertificate creates a certificate for the loopback addresses,
+// valid for a day.
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"elbtest"}},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
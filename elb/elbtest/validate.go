@@ -0,0 +1,184 @@
+package elbtest
+
+import (
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CompatibilityLevel selects how closely the server mimics the validation
+// of the parameters of requests by the real endpoint.
+type CompatibilityLevel int
+
+const (
+	// CompatibilityLegacy reports the first missing parameter in the
+	// order the server always checked them, with messages like
+	// "Listeners.member.1.InstancePort is required.". It's the default.
+	CompatibilityLegacy CompatibilityLevel = iota
+
+	// CompatibilityAWS reports all the missing parameters at once, like
+	// AWS does, starting with the name of the Load Balancer and with
+	// messages like "1 validation error detected: Value null at
+	// 'loadBalancerName' failed to satisfy constraint: Member must not be
+	// null". The parameters of each listener are checked, and the ones AWS
+	// doesn't require, like InstanceProtocol, aren't. Required parameters
+	// are checked before the ones that can't be given together, like
+	// AvailabilityZones and Subnets, whose errors use the AWS messages too.
+	CompatibilityAWS
+)
+
+// SetCompatibilityLevel sets how closely the server mimics the validation
+// of the real endpoint.
+func (srv *Server) SetCompatibilityLevel(level CompatibilityLevel) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.compatibility = level
+}
+
+// awsOptional holds the parameters of listeners that the server requires
+// in legacy mode, but AWS doesn't.
+var awsOptional = map[string]bool{
+	"InstanceProtocol": true,
+}
+
+// awsNames holds the names AWS gives to parameters in the errors about
+// their composition.
+var awsNames = map[string]string{
+	"AvailabilityZones.member.1": "AvailabilityZones",
+	"Subnets.member.1":           "SubnetIds",
+}
+
+// listenerMembers holds the parameters of listeners, in the order AWS
+// declares them.
+var listenerMembers = []string{"Protocol", "LoadBalancerPort", "InstanceProtocol", "InstancePort", "SSLCertificateId"}
+
+// awsValidate checks that the required parameters are in form, returning
+// the error AWS would return otherwise.
+//
+// Parameters of list members, like Listeners.member.1.InstancePort, are
+// checked for each member in the request, and the list itself is reported
+// when it has none.
+func awsValidate(form url.Values, required []string) error {
+	required = append([]string(nil), required...)
+	// AWS declares the name of the Load Balancers first.
+	sort.SliceStable(required, func(i, j int) bool {
+		return isNameParam(required[i]) && !isNameParam(required[j])
+	})
+	subs := make(map[string][]string)
+	for _, field := range required {
+		if list, sub := splitMember(field); sub != "" && !awsOptional[sub] {
+			subs[list] = append(subs[list], sub)
+		}
+	}
+	for _, s := range subs {
+		sort.SliceStable(s, func(i, j int) bool {
+			return memberIndex(s[i]) < memberIndex(s[j])
+		})
+	}
+	var missing []string
+	checked := make(map[string]bool)
+	for _, field := range required {
+		list, sub := splitMember(field)
+		switch {
+		case list == "":
+			if form.Get(field) == "" {
+				missing = append(missing, memberName(field))
+			}
+			continue
+		case awsOptional[sub] || checked[list]:
+			continue
+		}
+		checked[list] = true
+		n := countMembers(form, list)
+		if n == 0 {
+			missing = append(missing, memberName(list))
+		}
+		for i := 1; i <= n; i++ {
+			for _, sub := range subs[list] {
+				if form.Get(fmt.Sprintf("%s.member.%d.%s", list, i, sub)) == "" {
+					missing = append(missing, fmt.Sprintf("%s.%d.member.%s", memberName(list), i, memberName(sub)))
+				}
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	errs := make([]string, len(missing))
+	for i, member := range missing {
+		errs[i] = fmt.Sprintf("Value null at '%s' failed to satisfy constraint: Member must not be null", member)
+	}
+	plural := "s"
+	if len(errs) == 1 {
+		plural = ""
+	}
+	return &elb.Error{
+		StatusCode: 400,
+		Code:       elb.ErrValidation,
+		Message:    fmt.Sprintf("%d validation error%s detected: %s", len(errs), plural, strings.Join(errs, "; ")),
+	}
+}
+
+// memberIndex returns the position of a listener parameter in
+// listenerMembers, or its length for other parameters.
+func memberIndex(sub string) int {
+	for i, m := range listenerMembers {
+		if m == sub {
+			return i
+		}
+	}
+	return len(listenerMembers)
+}
+
+func isNameParam(field string) bool {
+	return field == "LoadBalancerName" || strings.HasPrefix(field, "LoadBalancerNames.")
+}
+
+// splitMember splits a parameter like Listeners.member.1.InstancePort into
+// the name of its list and the parameter of the member, which is empty
+// for parameters like SecurityGroups.member.1. The list is empty for
+// parameters outside of lists.
+func splitMember(field string) (list, sub string) {
+	i := strings.Index(field, ".member.")
+	if i < 0 {
+		return "", ""
+	}
+	rest := field[i+len(".member."):]
+	if j := strings.Index(rest, "."); j >= 0 {
+		sub = rest[j+1:]
+	}
+	return field[:i], sub
+}
+
+// countMembers returns the number of consecutive members of list in form.
+func countMembers(form url.Values, list string) int {
+	n := 0
+	for {
+		prefix := fmt.Sprintf("%s.member.%d", list, n+1)
+		found := false
+		for k := range form {
+			if k == prefix || strings.HasPrefix(k, prefix+".") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return n
+		}
+		n++
+	}
+}
+
+// memberName returns the name AWS gives to a parameter in validation
+// errors, e.g. loadBalancerName or healthCheck.target.
+func memberName(field string) string {
+	parts := strings.Split(field, ".")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToLower(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,146 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncodeParams adds the exported fields of v, a struct or a pointer to a
+// struct, to the parameters of a Query API request.
+//
+// Fields are named after the struct fields, unless an elb tag says
+// otherwise. Nested structs are encoded with their name as a prefix, e.g.
+// HealthCheck.Target, and lists as numbered members of their tag, which
+// should end in ".member", e.g. a Listeners field tagged
+// `elb:"Listeners.member"` is encoded as Listeners.member.1.Protocol and so
+// on. Nil pointers, interfaces and empty lists are skipped. Embedded
+// structs without tags are encoded as if their fields belonged to v.
+//
+// The tag may carry options after the name, separated by commas:
+//
+//	omitempty  skips the field when it holds the zero value of its type
+//	keepempty  encodes empty lists as a parameter with an empty value and
+//	           the name without ".member", which AWS uses to clear lists
+//
+// A field tagged with "-" is never encoded. Strings, integers, booleans and
+// time.Time values, in RFC 3339 UTC, are supported; encoding other types
+// fails.
+func EncodeParams(params map[string]string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("elb: can't encode %T as parameters", v)
+	}
+	return encodeStruct(params, "", rv)
+}
+
+type fieldOptions struct {
+	omitEmpty bool
+	keepEmpty bool
+}
+
+func parseTag(tag string) (string, fieldOptions) {
+	var opts fieldOptions
+	parts := strings.Split(tag, ",")
+	for _, o := range parts[1:] {
+		switch o {
+		case "omitempty":
+			opts.omitEmpty = true
+		case "keepempty":
+			opts.keepEmpty = true
+		}
+	}
+	return parts[0], opts
+}
+
+func encodeStruct(params map[string]string, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("elb")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			if err := encodeStruct(params, prefix, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name, opts := parseTag(tag)
+		if name == "" {
+			name = f.Name
+		}
+		if err := encodeValue(params, prefix+name, v.Field(i), opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeValue(params map[string]string, key string, v reflect.Value, opts fieldOptions) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeValue(params, key, v.Elem(), opts)
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			if opts.keepEmpty {
+				params[strings.TrimSuffix(key, ".member")] = ""
+			}
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeValue(params, key+"."+strconv.Itoa(i+1), v.Index(i), fieldOptions{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if opts.omitEmpty && v.IsZero() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			params[key] = t.UTC().Format(time.RFC3339)
+			return nil
+		}
+		return encodeStruct(params, key+".", v)
+	case reflect.String:
+		params[key] = v.String()
+	case reflect.Bool:
+		params[key] = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		params[key] = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		params[key] = strconv.FormatUint(v.Uint(), 10)
+	default:
+		return fmt.Errorf("elb: can't encode parameter %s of type %s", key, v.Type())
+	}
+	return nil
+}
+
+// call sends the request for action, whose parameters are encoded from req
+// by EncodeParams, and decodes the response into resp.
+func (elb *ELB) call(ctx context.Context, action string, req, resp interface{}) error {
+	params := map[string]string{"Action": action}
+	if err := EncodeParams(params, req); err != nil {
+		return err
+	}
+	return elb.query(ctx, params, resp)
+}
@@ -0,0 +1,99 @@
+package elb_test
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+	"time"
+)
+
+type condition struct {
+	Field  string
+	Values []string `elb:"Values.member"`
+}
+
+type common struct {
+	LoadBalancerName string
+}
+
+type encodeRequest struct {
+	common
+	Listeners   []elb.Listener    `elb:"Listeners.member"`
+	Conditions  []condition       `elb:"Conditions.member"`
+	HealthCheck *elb.HealthCheck  `elb:",omitempty"`
+	Ports       []int             `elb:"LoadBalancerPorts.member"`
+	PolicyNames []string          `elb:"PolicyNames.member,keepempty"`
+	Marker      string            `elb:",omitempty"`
+	Enabled     bool              `elb:"Attributes.Enabled"`
+	StartTime   time.Time         `elb:",omitempty"`
+	Ignored     string            `elb:"-"`
+	Attrs       *elb.AccessLog    `elb:"AccessLog"`
+	Tags        map[string]string `elb:"-"`
+	unexported  string
+}
+
+func (s *S) TestEncodeParams(c *C) {
+	req := encodeRequest{
+		common: common{LoadBalancerName: "testlb"},
+		Listeners: []elb.Listener{
+			{InstancePort: 80, InstanceProtocol: "http", LoadBalancerPort: 80, Protocol: "http"},
+			{InstancePort: 443, InstanceProtocol: "https", LoadBalancerPort: 443, Protocol: "https", SSLCertificateId: "cert"},
+		},
+		Conditions: []condition{{Field: "path-pattern", Values: []string{"/a", "/b"}}},
+		Ports:      []int{80, 443},
+		Enabled:    true,
+		Ignored:    "ignored",
+		Attrs:      &elb.AccessLog{Enabled: true, S3BucketName: "logs"},
+		unexported: "unexported",
+	}
+	params := map[string]string{}
+	err := elb.EncodeParams(params, &req)
+	c.Assert(err, IsNil)
+	c.Assert(params, DeepEquals, map[string]string{
+		"LoadBalancerName":                    "testlb",
+		"Listeners.member.1.InstancePort":     "80",
+		"Listeners.member.1.InstanceProtocol": "http",
+		"Listeners.member.1.LoadBalancerPort": "80",
+		"Listeners.member.1.Protocol":         "http",
+		"Listeners.member.2.InstancePort":     "443",
+		"Listeners.member.2.InstanceProtocol": "https",
+		"Listeners.member.2.LoadBalancerPort": "443",
+		"Listeners.member.2.Protocol":         "https",
+		"Listeners.member.2.SSLCertificateId": "cert",
+		"Conditions.member.1.Field":           "path-pattern",
+		"Conditions.member.1.Values.member.1": "/a",
+		"Conditions.member.1.Values.member.2": "/b",
+		"LoadBalancerPorts.member.1":          "80",
+		"LoadBalancerPorts.member.2":          "443",
+		"PolicyNames":                         "",
+		"Attributes.Enabled":                  "true",
+		"AccessLog.Enabled":                   "true",
+		"AccessLog.S3BucketName":              "logs",
+	})
+}
+
+func (s *S) TestEncodeParamsTime(c *C) {
+	req := struct {
+		StartTime time.Time
+	}{time.Date(2013, 5, 24, 18, 15, 31, 0, time.FixedZone("BRT", -3*3600))}
+	params := map[string]string{}
+	err := elb.EncodeParams(params, req)
+	c.Assert(err, IsNil)
+	c.Assert(params, DeepEquals, map[string]string{"StartTime": "2013-05-24T21:15:31Z"})
+}
+
+func (s *S) TestEncodeParamsNil(c *C) {
+	params := map[string]string{}
+	err := elb.EncodeParams(params, (*encodeRequest)(nil))
+	c.Assert(err, IsNil)
+	c.Assert(params, HasLen, 0)
+}
+
+func (s *S) TestEncodeParamsUnsupported(c *C) {
+	err := elb.EncodeParams(map[string]string{}, "testlb")
+	c.Assert(err, ErrorMatches, `elb: can't encode string as parameters`)
+	req := struct {
+		Weights map[string]int
+	}{map[string]int{"a": 1}}
+	err = elb.EncodeParams(map[string]string{}, req)
+	c.Assert(err, ErrorMatches, `elb: can't encode parameter Weights of type map\[string\]int`)
+}
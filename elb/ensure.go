@@ -0,0 +1,293 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LoadBalancerSpec is the desired state of a Load Balancer, as converged by
+// EnsureLoadBalancer.
+//
+// Nil fields are left untouched on existing Load Balancers, while empty
+// non-nil slices remove every item, except for SecurityGroups: a Load
+// Balancer in a VPC always has a security group, so an empty one is
+// treated like nil. Scheme only applies when the Load Balancer is created,
+// as it can't be changed afterwards.
+type LoadBalancerSpec struct {
+	Name           string       `json:"LoadBalancerName"`
+	Scheme         string       `json:"Scheme,omitempty"`
+	Listeners      []Listener   `json:"Listeners"`
+	AvailZones     []string     `json:"AvailabilityZones"`
+	Subnets        []string     `json:"Subnets"`
+	SecurityGroups []string     `json:"SecurityGroups"`
+	HealthCheck    *HealthCheck `json:"HealthCheck,omitempty"`
+	Tags           []Tag        `json:"Tags,omitempty"`
+}
+
+// EnsureLoadBalancer converges the Load Balancer named in spec to the
+// described state: it's created if missing, and otherwise its listeners,
+// availability zones, subnets, security groups, health check and tags are
+// changed where they differ from spec.
+//
+// It returns the description of the Load Balancer once converged. Changes
+// aren't transactional: on error, the Load Balancer may be partially
+// converged, and calling EnsureLoadBalancer again resumes the work.
+func (elb *ELB) EnsureLoadBalancer(spec *LoadBalancerSpec) (*LoadBalancerDescription, error) {
+	return elb.EnsureLoadBalancerWithContext(context.Background(), spec)
+}
+
+// EnsureLoadBalancerWithContext is like EnsureLoadBalancer, but the
+// requests are bound to ctx.
+func (elb *ELB) EnsureLoadBalancerWithContext(ctx context.Context, spec *LoadBalancerSpec) (*LoadBalancerDescription, error) {
+	ctx = withOperation(ctx)
+	lb, err := elb.describeLoadBalancer(ctx, spec.Name)
+	if IsLoadBalancerNotFound(err) {
+		err = elb.createFromSpec(ctx, spec)
+	} else if err == nil {
+		err = elb.updateFromSpec(ctx, spec, lb)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if spec.Tags != nil {
+		if err := elb.ensureTags(ctx, spec.Name, spec.Tags); err != nil {
+			return nil, err
+		}
+	}
+	return elb.describeLoadBalancer(ctx, spec.Name)
+}
+
+// describeLoadBalancer returns the description of the Load Balancer. An
+// empty description list, as may be returned for a Load Balancer just
+// created since DescribeLoadBalancers is eventually consistent, is
+// reported as an error.
+func (elb *ELB) describeLoadBalancer(ctx context.Context, lbName string) (*LoadBalancerDescription, error) {
+	resp, err := elb.DescribeLoadBalancersWithContext(ctx, lbName)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.LoadBalancerDescriptions) == 0 {
+		return nil, fmt.Errorf("elb: no description returned for Load Balancer %s", lbName)
+	}
+	return &resp.LoadBalancerDescriptions[0], nil
+}
+
+// ConflictError is returned by CreateLoadBalancerIfNotExists when a Load
+// Balancer with the same name exists, but with a different configuration.
+type ConflictError struct {
+	LoadBalancerName string
+	// Changes holds the differences between the requested Load Balancer
+	// and the existing one.
+	Changes *ChangeSet
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("elb: Load Balancer %q already exists with different %s",
+		e.LoadBalancerName, strings.Join(e.Changes.drifted(), ", "))
+}
+
+// CreateLoadBalancerIfNotExists creates the Load Balancer unless one with
+// the same name already exists, and returns its DNS name in both cases, so
+// that provisioning scripts can be run again safely.
+//
+// An existing Load Balancer is only accepted if its listeners, scheme,
+// availability zones, subnets and security groups match the request, as
+// compared by Diff. Otherwise a *ConflictError holding the differences is
+// returned.
+func (elb *ELB) CreateLoadBalancerIfNotExists(options *CreateLoadBalancer) (dnsName string, err error) {
+	return elb.CreateLoadBalancerIfNotExistsWithContext(context.Background(), options)
+}
+
+// CreateLoadBalancerIfNotExistsWithContext is like
+// CreateLoadBalancerIfNotExists, but the requests are bound to ctx.
+func (elb *ELB) CreateLoadBalancerIfNotExistsWithContext(ctx context.Context, options *CreateLoadBalancer) (dnsName string, err error) {
+	ctx = withOperation(ctx)
+	resp, err := elb.CreateLoadBalancerWithContext(ctx, options)
+	if err == nil {
+		return resp.DNSName, nil
+	}
+	if !IsDuplicateLoadBalancerName(err) {
+		return "", err
+	}
+	actual, err := elb.describeLoadBalancer(ctx, options.Name)
+	if err != nil {
+		return "", err
+	}
+	desired := LoadBalancerDescription{
+		LoadBalancerName:     options.Name,
+		AvailZones:           options.AvailZones,
+		ListenerDescriptions: make([]ListenerDescription, len(options.Listeners)),
+		Scheme:               options.Scheme,
+		SecurityGroups:       options.SecurityGroups,
+		Subnets:              options.Subnets,
+	}
+	for i, l := range options.Listeners {
+		desired.ListenerDescriptions[i].Listener = l
+	}
+	if changes := Diff(desired, *actual); !changes.Empty() {
+		return "", &ConflictError{LoadBalancerName: options.Name, Changes: changes}
+	}
+	return actual.DNSName, nil
+}
+
+func (elb *ELB) createFromSpec(ctx context.Context, spec *LoadBalancerSpec) error {
+	_, err := elb.CreateLoadBalancerWithContext(ctx, &CreateLoadBalancer{
+		Name:           spec.Name,
+		AvailZones:     spec.AvailZones,
+		Listeners:      spec.Listeners,
+		Scheme:         spec.Scheme,
+		SecurityGroups: spec.SecurityGroups,
+		Subnets:        spec.Subnets,
+	})
+	if err != nil {
+		return err
+	}
+	if spec.HealthCheck != nil {
+		_, err = elb.ConfigureHealthCheckWithContext(ctx, spec.Name, spec.HealthCheck)
+	}
+	return err
+}
+
+func (elb *ELB) updateFromSpec(ctx context.Context, spec *LoadBalancerSpec, lb *LoadBalancerDescription) error {
+	if spec.Listeners != nil {
+		if err := elb.ensureListeners(ctx, spec.Name, spec.Listeners, lb.ListenerDescriptions); err != nil {
+			return err
+		}
+	}
+	if spec.AvailZones != nil {
+		add, remove := diffStrings(lb.AvailZones, spec.AvailZones)
+		if len(add) > 0 {
+			if _, err := elb.EnableAvailabilityZonesForLoadBalancerWithContext(ctx, spec.Name, add); err != nil {
+				return err
+			}
+		}
+		if len(remove) > 0 {
+			if _, err := elb.DisableAvailabilityZonesForLoadBalancerWithContext(ctx, spec.Name, remove); err != nil {
+				return err
+			}
+		}
+	}
+	if spec.Subnets != nil {
+		add, remove := diffStrings(lb.Subnets, spec.Subnets)
+		if len(add) > 0 {
+			if _, err := elb.AttachLoadBalancerToSubnetsWithContext(ctx, spec.Name, add); err != nil {
+				return err
+			}
+		}
+		if len(remove) > 0 {
+			if _, err := elb.DetachLoadBalancerFromSubnetsWithContext(ctx, spec.Name, remove); err != nil {
+				return err
+			}
+		}
+	}
+	if len(spec.SecurityGroups) > 0 {
+		add, remove := diffStrings(lb.SecurityGroups, spec.SecurityGroups)
+		if len(add)+len(remove) > 0 {
+			if _, err := elb.ApplySecurityGroupsWithContext(ctx, spec.Name, spec.SecurityGroups); err != nil {
+				return err
+			}
+		}
+	}
+	if spec.HealthCheck != nil && !sameHealthCheck(*spec.HealthCheck, lb.HealthCheck) {
+		if _, err := elb.ConfigureHealthCheckWithContext(ctx, spec.Name, spec.HealthCheck); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureListeners recreates the listeners that differ from the desired
+// ones, except when only their certificate changed, which can be updated in
+// place.
+func (elb *ELB) ensureListeners(ctx context.Context, lbName string, desired []Listener, current []ListenerDescription) error {
+	add, remove, certificates := diffListeners(desired, current)
+	for _, l := range certificates {
+		if _, err := elb.SetLoadBalancerListenerSSLCertificateWithContext(ctx, lbName, l.LoadBalancerPort, l.SSLCertificateId); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		ports := make([]int, len(remove))
+		for i, l := range remove {
+			ports[i] = l.LoadBalancerPort
+		}
+		if _, err := elb.DeleteLoadBalancerListenersWithContext(ctx, lbName, ports...); err != nil {
+			return err
+		}
+	}
+	if len(add) > 0 {
+		if _, err := elb.CreateLoadBalancerListenersWithContext(ctx, lbName, add); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameListener reports whether two listeners are equivalent, ignoring their
+// certificates. An empty instance protocol defaults like it does in AWS:
+// to HTTP behind HTTP and HTTPS listeners, and to TCP otherwise.
+func sameListener(a, b Listener) bool {
+	return a.LoadBalancerPort == b.LoadBalancerPort &&
+		a.InstancePort == b.InstancePort &&
+		strings.EqualFold(a.Protocol, b.Protocol) &&
+		strings.EqualFold(instanceProtocol(a), instanceProtocol(b))
+}
+
+func instanceProtocol(l Listener) string {
+	if l.InstanceProtocol != "" {
+		return l.InstanceProtocol
+	}
+	switch NormalizeProtocol(l.Protocol) {
+	case ProtocolHTTP, ProtocolHTTPS:
+		return ProtocolHTTP
+	}
+	return ProtocolTCP
+}
+
+func (elb *ELB) ensureTags(ctx context.Context, lbName string, desired []Tag) error {
+	resp, err := elb.DescribeTagsWithContext(ctx, lbName)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]string)
+	for _, d := range resp.TagDescriptions {
+		for _, t := range d.Tags {
+			current[t.Key] = t.Value
+		}
+	}
+	add, remove := diffTags(desired, current)
+	if len(add) > 0 {
+		if _, err := elb.AddTagsWithContext(ctx, []string{lbName}, add); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		if _, err := elb.RemoveTagsWithContext(ctx, []string{lbName}, remove); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffStrings returns the values of desired missing from current, and the
+// values of current missing from desired.
+func diffStrings(current, desired []string) (add, remove []string) {
+	have := make(map[string]bool, len(current))
+	for _, v := range current {
+		have[v] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, v := range desired {
+		want[v] = true
+		if !have[v] {
+			add = append(add, v)
+		}
+	}
+	for _, v := range current {
+		if !want[v] {
+			remove = append(remove, v)
+		}
+	}
+	return add, remove
+}
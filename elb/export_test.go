@@ -1,9 +1,15 @@
 package elb
 
-import (
-	"github.com/flaviamissi/go-elb/aws"
-)
+import "time"
 
-func Sign(auth aws.Auth, method, path string, params map[string]string, host string) {
-	sign(auth, method, path, params, host)
+func NewRateLimiter(rate float64, burst int) *rateLimiter {
+	return newRateLimiter(rate, burst)
+}
+
+func (l *rateLimiter) Reserve(now time.Time) time.Duration {
+	return l.reserve(now)
+}
+
+func (l *rateLimiter) Last() time.Time {
+	return l.last
 }
@@ -0,0 +1,120 @@
+package elb
+
+import (
+	"context"
+	"strings"
+)
+
+// maxDescribeTags is the maximum number of Load Balancers DescribeTags
+// accepts at once.
+const maxDescribeTags = 20
+
+// LoadBalancerFilter selects Load Balancers in FindLoadBalancers. A Load
+// Balancer must match all the non-empty fields to be selected.
+type LoadBalancerFilter struct {
+	// NamePrefix selects the Load Balancers whose name starts with it.
+	NamePrefix string
+
+	// Scheme selects the internet-facing or the internal Load Balancers.
+	Scheme string
+
+	// Tags selects the Load Balancers having all the given tags, like
+	// {"app": "x"}. An empty value matches any value of the tag.
+	Tags map[string]string
+}
+
+// match reports whether d matches the name prefix and scheme of f.
+func (f *LoadBalancerFilter) match(d *LoadBalancerDescription) bool {
+	if !strings.HasPrefix(d.LoadBalancerName, f.NamePrefix) {
+		return false
+	}
+	return f.Scheme == "" || d.Scheme == NormalizeScheme(f.Scheme)
+}
+
+// matchTags reports whether tags hold all the tags of f.
+func (f *LoadBalancerFilter) matchTags(tags []Tag) bool {
+	for k, v := range f.Tags {
+		found := false
+		for _, tag := range tags {
+			if tag.Key == k && (v == "" || tag.Value == v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FindLoadBalancers returns the Load Balancers matching filter, following
+// the pages of DescribeLoadBalancers. The tags of a page are only
+// described, in batches, for the Load Balancers matching the other fields
+// of filter, and not at all when filter has no tags. A nil filter selects
+// all the Load Balancers.
+func (elb *ELB) FindLoadBalancers(filter *LoadBalancerFilter) ([]LoadBalancerDescription, error) {
+	return elb.FindLoadBalancersWithContext(context.Background(), filter)
+}
+
+// FindLoadBalancersWithContext is like FindLoadBalancers, but the requests
+// are bound to ctx.
+func (elb *ELB) FindLoadBalancersWithContext(ctx context.Context, filter *LoadBalancerFilter) ([]LoadBalancerDescription, error) {
+	ctx = withOperation(ctx)
+	if filter == nil {
+		filter = &LoadBalancerFilter{}
+	}
+	var found []LoadBalancerDescription
+	marker := ""
+	for {
+		resp, err := elb.DescribeLoadBalancersPageWithContext(ctx, marker, 0)
+		if err != nil {
+			return nil, err
+		}
+		var page []LoadBalancerDescription
+		for _, d := range resp.LoadBalancerDescriptions {
+			if filter.match(&d) {
+				page = append(page, d)
+			}
+		}
+		if len(filter.Tags) > 0 {
+			if page, err = elb.filterTags(ctx, filter, page); err != nil {
+				return nil, err
+			}
+		}
+		found = append(found, page...)
+		if resp.NextMarker == "" {
+			return found, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// filterTags returns the descriptions of descs whose tags match filter.
+func (elb *ELB) filterTags(ctx context.Context, filter *LoadBalancerFilter, descs []LoadBalancerDescription) ([]LoadBalancerDescription, error) {
+	tags := make(map[string][]Tag, len(descs))
+	for i := 0; i < len(descs); i += maxDescribeTags {
+		end := i + maxDescribeTags
+		if end > len(descs) {
+			end = len(descs)
+		}
+		names := make([]string, 0, end-i)
+		for _, d := range descs[i:end] {
+			names = append(names, d.LoadBalancerName)
+		}
+		resp, err := elb.DescribeTagsWithContext(ctx, names...)
+		if err != nil {
+			return nil, err
+		}
+		for _, td := range resp.TagDescriptions {
+			tags[td.LoadBalancerName] = td.Tags
+		}
+	}
+	var matched []LoadBalancerDescription
+	for _, d := range descs {
+		if filter.matchTags(tags[d.LoadBalancerName]) {
+			matched = append(matched, d)
+		}
+	}
+	return matched, nil
+}
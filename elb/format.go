@@ -0,0 +1,79 @@
+package elb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// String returns a compact summary of the listener, with the protocol and
+// port of the Load Balancer followed by the ones of the instances, e.g.
+// "HTTP:80→8080" or "HTTPS:443→HTTP:80". The instance protocol is omitted
+// when it's the same as the Load Balancer's.
+func (l Listener) String() string {
+	protocol := strings.ToUpper(l.Protocol)
+	instanceProtocol := strings.ToUpper(l.InstanceProtocol)
+	if instanceProtocol == "" || instanceProtocol == protocol {
+		return fmt.Sprintf("%s:%d→%d", protocol, l.LoadBalancerPort, l.InstancePort)
+	}
+	return fmt.Sprintf("%s:%d→%s:%d", protocol, l.LoadBalancerPort, instanceProtocol, l.InstancePort)
+}
+
+// String returns a compact summary of the health check, e.g.
+// "HTTP:80/ping every 30s, timeout 5s, healthy 2, unhealthy 3".
+func (hc HealthCheck) String() string {
+	return fmt.Sprintf("%s every %ds, timeout %ds, healthy %d, unhealthy %d",
+		hc.Target, hc.Interval, hc.Timeout, hc.HealthyThreshold, hc.UnhealthyThreshold)
+}
+
+// String returns the instance id and its state, followed by the reason
+// code when the instance isn't healthy, e.g. "i-1 OutOfService (Instance)".
+func (s InstanceState) String() string {
+	if s.ReasonCode == "" || s.IsHealthy() {
+		return fmt.Sprintf("%s %s", s.InstanceId, s.State)
+	}
+	return fmt.Sprintf("%s %s (%s)", s.InstanceId, s.State, s.ReasonCode)
+}
+
+// String returns a compact summary of the Load Balancer: its name, scheme,
+// listeners and number of instances, e.g.
+// "testlb (internet-facing) HTTP:80→8080, HTTPS:443→HTTP:80; 2 instances".
+func (d LoadBalancerDescription) String() string {
+	var b strings.Builder
+	b.WriteString(d.LoadBalancerName)
+	if d.Scheme != "" {
+		fmt.Fprintf(&b, " (%s)", d.Scheme)
+	}
+	listeners := make([]string, len(d.ListenerDescriptions))
+	for i, ld := range d.ListenerDescriptions {
+		listeners[i] = ld.Listener.String()
+	}
+	if len(listeners) > 0 {
+		b.WriteString(" " + strings.Join(listeners, ", "))
+	}
+	if len(d.Instances) == 1 {
+		b.WriteString("; 1 instance")
+	} else {
+		fmt.Fprintf(&b, "; %d instances", len(d.Instances))
+	}
+	return b.String()
+}
+
+// FormatTable lays out rows in columns aligned with two spaces, below the
+// header if it's not empty, and returns the result, ending in a newline
+// unless there are no lines at all. Columns are sized by the number of
+// runes of their cells, so arrows like the ones of Listener.String don't
+// misalign them.
+func FormatTable(header []string, rows [][]string) string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if len(header) > 0 {
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+	return buf.String()
+}
@@ -0,0 +1,58 @@
+package elb_test
+
+import (
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+)
+
+func (s *S) TestListenerString(c *C) {
+	l := elb.Listener{Protocol: "http", LoadBalancerPort: 80, InstanceProtocol: "HTTP", InstancePort: 8080}
+	c.Assert(l.String(), Equals, "HTTP:80→8080")
+	l = elb.Listener{Protocol: "HTTPS", LoadBalancerPort: 443, InstanceProtocol: "HTTP", InstancePort: 80}
+	c.Assert(l.String(), Equals, "HTTPS:443→HTTP:80")
+	l = elb.Listener{Protocol: "TCP", LoadBalancerPort: 22, InstancePort: 2222}
+	c.Assert(fmt.Sprint(l), Equals, "TCP:22→2222")
+}
+
+func (s *S) TestHealthCheckString(c *C) {
+	hc := elb.HealthCheck{Target: "HTTP:80/ping", Interval: 30, Timeout: 5, HealthyThreshold: 2, UnhealthyThreshold: 3}
+	c.Assert(hc.String(), Equals, "HTTP:80/ping every 30s, timeout 5s, healthy 2, unhealthy 3")
+}
+
+func (s *S) TestInstanceStateString(c *C) {
+	state := elb.InstanceState{InstanceId: "i-1", State: elb.StateInService, ReasonCode: "N/A"}
+	c.Assert(state.String(), Equals, "i-1 InService")
+	state = elb.InstanceState{InstanceId: "i-2", State: elb.StateOutOfService, ReasonCode: "Instance"}
+	c.Assert(state.String(), Equals, "i-2 OutOfService (Instance)")
+	state = elb.InstanceState{InstanceId: "i-3", State: elb.StateUnknown}
+	c.Assert(state.String(), Equals, "i-3 Unknown")
+}
+
+func (s *S) TestLoadBalancerDescriptionString(c *C) {
+	desc := elb.LoadBalancerDescription{
+		LoadBalancerName: "testlb",
+		Scheme:           elb.SchemeInternetFacing,
+		ListenerDescriptions: []elb.ListenerDescription{
+			{Listener: elb.Listener{Protocol: "HTTP", LoadBalancerPort: 80, InstanceProtocol: "HTTP", InstancePort: 8080}},
+			{Listener: elb.Listener{Protocol: "HTTPS", LoadBalancerPort: 443, InstanceProtocol: "HTTP", InstancePort: 80}},
+		},
+		Instances: []elb.Instance{{InstanceId: "i-1"}, {InstanceId: "i-2"}},
+	}
+	c.Assert(desc.String(), Equals, "testlb (internet-facing) HTTP:80→8080, HTTPS:443→HTTP:80; 2 instances")
+	desc = elb.LoadBalancerDescription{LoadBalancerName: "testlb", Instances: []elb.Instance{{InstanceId: "i-1"}}}
+	c.Assert(desc.String(), Equals, "testlb; 1 instance")
+}
+
+func (s *S) TestFormatTable(c *C) {
+	table := elb.FormatTable([]string{"INSTANCE", "LISTENER"}, [][]string{
+		{"i-1", "HTTP:80→8080"},
+		{"i-12345678", "TCP:22→2222"},
+	})
+	c.Assert(table, Equals, ""+
+		"INSTANCE    LISTENER\n"+
+		"i-1         HTTP:80→8080\n"+
+		"i-12345678  TCP:22→2222\n")
+	c.Assert(elb.FormatTable(nil, [][]string{{"a", "b"}}), Equals, "a  b\n")
+	c.Assert(elb.FormatTable(nil, nil), Equals, "")
+}
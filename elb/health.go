@@ -0,0 +1,58 @@
+package elb
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoInstances is returned by SummarizeHealth when instances are
+// required but none is registered with the Load Balancer.
+var ErrNoInstances = errors.New("elb: no instances registered with the Load Balancer")
+
+// HealthSummary partitions the instances registered with a Load Balancer
+// by health, as returned by SummarizeHealth.
+type HealthSummary struct {
+	// HealthyTargets holds the ids of the instances in the InService
+	// state, and UnhealthyTargets the ids of all the others.
+	HealthyTargets   []string
+	UnhealthyTargets []string
+
+	// HealthRatio is the fraction of the registered instances that are
+	// healthy, from 0 to 1. It's 0 when no instance is registered.
+	HealthRatio float64
+}
+
+// SummarizeHealth describes the health of all the instances registered
+// with the Load Balancer and partitions them into healthy and unhealthy
+// ones, the usual check before shifting traffic to the Load Balancer
+// during a deployment.
+//
+// When requireInstances is true, a Load Balancer without instances is
+// reported with ErrNoInstances rather than as an empty summary.
+func (elb *ELB) SummarizeHealth(lbName string, requireInstances bool) (*HealthSummary, error) {
+	return elb.SummarizeHealthWithContext(context.Background(), lbName, requireInstances)
+}
+
+// SummarizeHealthWithContext is like SummarizeHealth, but the request is
+// bound to ctx.
+func (elb *ELB) SummarizeHealthWithContext(ctx context.Context, lbName string, requireInstances bool) (*HealthSummary, error) {
+	resp, err := elb.DescribeInstanceHealthWithContext(ctx, lbName)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.InstanceStates) == 0 && requireInstances {
+		return nil, ErrNoInstances
+	}
+	var s HealthSummary
+	for _, state := range resp.InstanceStates {
+		if state.IsHealthy() {
+			s.HealthyTargets = append(s.HealthyTargets, state.InstanceId)
+		} else {
+			s.UnhealthyTargets = append(s.UnhealthyTargets, state.InstanceId)
+		}
+	}
+	if n := len(resp.InstanceStates); n > 0 {
+		s.HealthRatio = float64(len(s.HealthyTargets)) / float64(n)
+	}
+	return &s, nil
+}
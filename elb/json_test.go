@@ -0,0 +1,100 @@
+package elb_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+	"reflect"
+	"time"
+)
+
+// jsonRoundTrip encodes v as JSON, decodes it into a new value of the same
+// type and checks that nothing was lost.
+func jsonRoundTrip(c *C, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	c.Assert(err, IsNil)
+	decoded := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	c.Assert(json.Unmarshal(data, decoded), IsNil)
+	c.Assert(decoded, DeepEquals, v, Commentf("JSON: %s", data))
+	return data
+}
+
+func (s *S) TestJSONRoundTripResponses(c *C) {
+	responses := []struct {
+		body string
+		resp interface{}
+	}{
+		{CreateLoadBalancer, new(elb.CreateLoadBalancerResp)},
+		{DeleteLoadBalancer, new(elb.SimpleResp)},
+		{RegisterInstancesWithLoadBalancer, new(elb.RegisterInstancesResp)},
+		{DeregisterInstancesFromLoadBalancer, new(elb.DeregisterInstancesResp)},
+		{DescribeLoadBalancers, new(elb.DescribeLoadBalancerResp)},
+		{DescribeLoadBalancersVPC, new(elb.DescribeLoadBalancerResp)},
+		{DescribeLoadBalancersWithNextMarker, new(elb.DescribeLoadBalancerResp)},
+		{DescribeInstanceHealth, new(elb.DescribeInstanceHealthResp)},
+		{ConfigureHealthCheck, new(elb.HealthCheckResp)},
+		{DescribeLoadBalancerAttributes, new(elb.DescribeLoadBalancerAttributesResp)},
+		{ModifyLoadBalancerAttributes, new(elb.ModifyLoadBalancerAttributesResp)},
+		{DescribeTags, new(elb.DescribeTagsResp)},
+		{ApplySecurityGroupsToLoadBalancer, new(elb.ApplySecurityGroupsResp)},
+		{AttachLoadBalancerToSubnets, new(elb.AttachLoadBalancerToSubnetsResp)},
+		{DetachLoadBalancerFromSubnets, new(elb.DetachLoadBalancerFromSubnetsResp)},
+		{EnableAvailabilityZonesForLoadBalancer, new(elb.EnableAvailabilityZonesResp)},
+		{DisableAvailabilityZonesForLoadBalancer, new(elb.DisableAvailabilityZonesResp)},
+		{DescribeLoadBalancerPolicies, new(elb.DescribeLoadBalancerPoliciesResp)},
+		{DescribeLoadBalancerPolicyTypes, new(elb.DescribeLoadBalancerPolicyTypesResp)},
+	}
+	for _, r := range responses {
+		c.Assert(xml.Unmarshal([]byte(r.body), r.resp), IsNil)
+		jsonRoundTrip(c, r.resp)
+	}
+}
+
+func (s *S) TestJSONRoundTripRequests(c *C) {
+	jsonRoundTrip(c, &elb.CreateLoadBalancer{
+		Name:       "testlb",
+		AvailZones: []string{"us-east-1a"},
+		Listeners: []elb.Listener{{
+			InstancePort:     80,
+			InstanceProtocol: "HTTP",
+			LoadBalancerPort: 443,
+			Protocol:         "HTTPS",
+			SSLCertificateId: "arn:aws:iam::123456789012:server-certificate/cert",
+		}},
+		Scheme: elb.SchemeInternal,
+	})
+	jsonRoundTrip(c, &elb.LoadBalancerSpec{
+		Name:        "testlb",
+		Listeners:   []elb.Listener{{InstancePort: 80, InstanceProtocol: "HTTP", LoadBalancerPort: 80, Protocol: "HTTP"}},
+		Subnets:     []string{"subnet-1"},
+		HealthCheck: &elb.HealthCheck{HealthyThreshold: 2, Interval: 30, Target: "HTTP:80/", Timeout: 5, UnhealthyThreshold: 2},
+		Tags:        []elb.Tag{{Key: "env", Value: "prod"}},
+	})
+	jsonRoundTrip(c, &elb.LoadBalancerAttributes{
+		AccessLog:          &elb.AccessLog{Enabled: true, S3BucketName: "logs", EmitInterval: 5},
+		ConnectionDraining: &elb.ConnectionDraining{Enabled: true, Timeout: 300},
+	})
+	jsonRoundTrip(c, &elb.Error{StatusCode: 400, Code: "LoadBalancerNotFound", Message: "There is no ACTIVE Load Balancer named 'testlb'", RequestId: "req-1"})
+}
+
+func (s *S) TestJSONUsesAPINames(c *C) {
+	desc := elb.LoadBalancerDescription{
+		LoadBalancerName:          "testlb",
+		AvailZones:                []string{"us-east-1a"},
+		CanonicalHostedZoneNameId: "Z3DZXE0Q79N41H",
+		CreatedTime:               time.Date(2012, 12, 27, 11, 51, 52, 970000000, time.UTC),
+		Instances:                 []elb.Instance{{InstanceId: "i-1"}},
+	}
+	data := jsonRoundTrip(c, &desc)
+	var m map[string]interface{}
+	c.Assert(json.Unmarshal(data, &m), IsNil)
+	c.Assert(m["LoadBalancerName"], Equals, "testlb")
+	c.Assert(m["AvailabilityZones"], DeepEquals, []interface{}{"us-east-1a"})
+	c.Assert(m["CanonicalHostedZoneNameID"], Equals, "Z3DZXE0Q79N41H")
+	c.Assert(m["CreatedTime"], Equals, "2012-12-27T11:51:52.97Z")
+	c.Assert(m["Instances"], DeepEquals, []interface{}{map[string]interface{}{"InstanceId": "i-1"}})
+	data, err := json.Marshal(elb.LoadBalancerAttributes{})
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "{}")
+}
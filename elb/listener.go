@@ -0,0 +1,192 @@
+package elb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ListenerError is returned, without sending the request, when a listener
+// given to CreateLoadBalancer or CreateLoadBalancerListeners is invalid, or
+// when the certificate given to SetLoadBalancerListenerSSLCertificate is.
+type ListenerError struct {
+	// Index is the position of the invalid listener in the request,
+	// starting at 1, or 0 for errors returned by Listener.Validate and
+	// SetLoadBalancerListenerSSLCertificate.
+	Index int
+	// Listener is the invalid listener. Only its LoadBalancerPort and
+	// SSLCertificateId are set by SetLoadBalancerListenerSSLCertificate.
+	Listener Listener
+	Reason   string
+}
+
+func (e *ListenerError) Error() string {
+	name := "listener"
+	if e.Index > 0 {
+		name = fmt.Sprintf("listener %d", e.Index)
+	}
+	if e.Listener.Protocol == "" {
+		return fmt.Sprintf("elb: invalid %s on port %d: %s", name, e.Listener.LoadBalancerPort, e.Reason)
+	}
+	return fmt.Sprintf("elb: invalid %s (%s:%d -> %s:%d): %s", name,
+		e.Listener.Protocol, e.Listener.LoadBalancerPort,
+		e.Listener.InstanceProtocol, e.Listener.InstancePort, e.Reason)
+}
+
+// WithoutListenerValidation disables the client-side validation of
+// listeners, leaving it to AWS. It's meant for listener configurations
+// accepted by AWS that this package doesn't know about yet.
+func WithoutListenerValidation() Option {
+	return func(elb *ELB) {
+		elb.skipListenerValidation = true
+	}
+}
+
+// Protocols of listeners.
+const (
+	ProtocolHTTP  = "HTTP"
+	ProtocolHTTPS = "HTTPS"
+	ProtocolTCP   = "TCP"
+	ProtocolSSL   = "SSL"
+)
+
+var listenerProtocols = map[string]bool{
+	ProtocolHTTP:  true,
+	ProtocolHTTPS: true,
+	ProtocolTCP:   true,
+	ProtocolSSL:   true,
+}
+
+// NormalizeProtocol returns the canonical spelling of protocol, like
+// ProtocolHTTPS for "https", matching it case-insensitively. Unknown
+// protocols are returned as is.
+func NormalizeProtocol(protocol string) string {
+	if p := strings.ToUpper(protocol); listenerProtocols[p] {
+		return p
+	}
+	return protocol
+}
+
+// normalizeListeners returns a copy of listeners with their protocols
+// normalized by NormalizeProtocol.
+func normalizeListeners(listeners []Listener) []Listener {
+	if listeners == nil {
+		return nil
+	}
+	normalized := make([]Listener, len(listeners))
+	for i, l := range listeners {
+		l.Protocol = NormalizeProtocol(l.Protocol)
+		l.InstanceProtocol = NormalizeProtocol(l.InstanceProtocol)
+		normalized[i] = l
+	}
+	return normalized
+}
+
+// Validate checks that l would be accepted by AWS: its protocols must be
+// HTTP, HTTPS, TCP or SSL, its ports between 1 and 65535, and HTTPS and SSL
+// listeners need an SSLCertificateId. An empty InstanceProtocol is valid,
+// AWS derives it from Protocol. An SSLCertificateId starting with "arn:"
+// must be the ARN of an IAM server certificate or of an ACM certificate.
+func (l Listener) Validate() error {
+	if reason := l.invalidReason(); reason != "" {
+		return &ListenerError{Listener: l, Reason: reason}
+	}
+	return nil
+}
+
+func (l Listener) invalidReason() string {
+	protocol := strings.ToUpper(l.Protocol)
+	if !listenerProtocols[protocol] {
+		return fmt.Sprintf("unsupported protocol %q, must be one of HTTP, HTTPS, TCP or SSL", l.Protocol)
+	}
+	if l.InstanceProtocol != "" && !listenerProtocols[strings.ToUpper(l.InstanceProtocol)] {
+		return fmt.Sprintf("unsupported instance protocol %q, must be one of HTTP, HTTPS, TCP or SSL", l.InstanceProtocol)
+	}
+	if l.LoadBalancerPort < 1 || l.LoadBalancerPort > 65535 {
+		return fmt.Sprintf("load balancer port %d out of range 1-65535", l.LoadBalancerPort)
+	}
+	if l.InstancePort < 1 || l.InstancePort > 65535 {
+		return fmt.Sprintf("instance port %d out of range 1-65535", l.InstancePort)
+	}
+	if (protocol == ProtocolHTTPS || protocol == ProtocolSSL) && l.SSLCertificateId == "" {
+		return fmt.Sprintf("SSLCertificateId is required for %s listeners", protocol)
+	}
+	if strings.HasPrefix(l.SSLCertificateId, "arn:") {
+		return certificateARNReason(l.SSLCertificateId)
+	}
+	return ""
+}
+
+// certificateARNReason returns why certId isn't the ARN of an IAM server
+// certificate or of an ACM certificate, or an empty string.
+func certificateARNReason(certId string) string {
+	arn, err := ParseARN(certId)
+	if err != nil {
+		return fmt.Sprintf("invalid certificate ARN %s", certId)
+	}
+	switch arn.Service {
+	case "iam":
+		if arn.Region != "" || arn.AccountId == "" || !strings.HasPrefix(arn.Resource, "server-certificate/") {
+			return fmt.Sprintf("invalid IAM server certificate ARN %s", certId)
+		}
+	case "acm":
+		if arn.Region == "" || arn.AccountId == "" || !strings.HasPrefix(arn.Resource, "certificate/") {
+			return fmt.Sprintf("invalid ACM certificate ARN %s", certId)
+		}
+	default:
+		return fmt.Sprintf("certificate ARN %s is neither an IAM nor an ACM one", certId)
+	}
+	return ""
+}
+
+// validateListeners validates listeners, unless the client was created
+// with WithoutListenerValidation.
+func (elb *ELB) validateListeners(listeners []Listener) error {
+	if elb.skipListenerValidation {
+		return nil
+	}
+	for i, l := range listeners {
+		reason := l.invalidReason()
+		if reason == "" {
+			reason = elb.certificateRegionReason(l.SSLCertificateId)
+		}
+		if reason != "" {
+			return &ListenerError{Index: i + 1, Listener: l, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// validateCertificate validates the certificate certId set on the
+// listener bound to port, like validateListeners does. Certificate ids
+// other than ARNs are left to AWS.
+func (elb *ELB) validateCertificate(port int, certId string) error {
+	if elb.skipListenerValidation || !strings.HasPrefix(certId, "arn:") {
+		return nil
+	}
+	reason := certificateARNReason(certId)
+	if reason == "" {
+		reason = elb.certificateRegionReason(certId)
+	}
+	if reason != "" {
+		return &ListenerError{Listener: Listener{LoadBalancerPort: port, SSLCertificateId: certId}, Reason: reason}
+	}
+	return nil
+}
+
+// certificateRegionReason returns why AWS would reject the certificate
+// ARN certId in the region of the client, which happens when they belong
+// to different partitions, or when an ACM certificate is in another
+// region, or an empty string.
+func (elb *ELB) certificateRegionReason(certId string) string {
+	arn, err := ParseARN(certId)
+	if err != nil || elb.Region.Name == "" {
+		return ""
+	}
+	if p := PartitionOf(elb.Region.Name); arn.Partition != p {
+		return fmt.Sprintf("certificate %s is in partition %s, but region %s is in partition %s", certId, arn.Partition, elb.Region.Name, p)
+	}
+	if arn.Service == "acm" && arn.Region != elb.Region.Name {
+		return fmt.Sprintf("ACM certificate %s is in region %s, not %s", certId, arn.Region, elb.Region.Name)
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package elb
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Logger receives debug information about the requests sent by a client.
+// *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithLogger makes the client log every request it sends: the client
+// request token and operation id, the query string, with the signature and
+// session token redacted, the HTTP status, the beginning of the response
+// body and the latency. Each retry is logged separately. The body is logged
+// as it's decoded, so logging doesn't lift the limit set by
+// WithMaxResponseSize.
+func WithLogger(l Logger) Option {
+	return func(elb *ELB) {
+		elb.logger = l
+	}
+}
+
+// redactedParams lists the parameters that must never be logged.
+var redactedParams = []string{"Signature", "SecurityToken"}
+
+// redactedQuery returns the query string encoding params, with the
+// redacted parameters masked.
+func redactedQuery(params map[string]string) string {
+	safe := make(map[string]string, len(params))
+	for k, v := range params {
+		safe[k] = v
+	}
+	for _, k := range redactedParams {
+		if _, ok := safe[k]; ok {
+			safe[k] = "REDACTED"
+		}
+	}
+	return multimap(safe).Encode()
+}
+
+// logResponse logs a request and its response, if the client has a
+// logger. r is nil if the request failed with err, and err is otherwise
+// the error met reading the body. head holds the beginning of the body,
+// which is logged up to maxBodySnippet bytes.
+func (elb *ELB) logResponse(ctx context.Context, params map[string]string, r *http.Response, err error, latency time.Duration, head []byte) {
+	if elb.logger == nil {
+		return
+	}
+	query := redactedQuery(params)
+	tags := logTags(ctx)
+	if r == nil {
+		elb.logger.Printf("elb: %s %s %s: %v (%v)", params["Action"], tags, query, err, latency)
+		return
+	}
+	if err != nil {
+		elb.logger.Printf("elb: %s %s %s: %d, reading body: %v (%v)", params["Action"], tags, query, r.StatusCode, err, latency)
+		return
+	}
+	elb.logger.Printf("elb: %s %s %s: %d (%v)\n%s", params["Action"], tags, query, r.StatusCode, latency, bodySnippet(head))
+}
@@ -0,0 +1,87 @@
+package elb
+
+import (
+	"context"
+	"expvar"
+	"time"
+)
+
+// Metrics is notified of every request sent by a client, allowing callers
+// to plug counters and histograms, e.g. from Prometheus or statsd, without
+// wrapping each operation.
+//
+// Each attempt is reported separately, so a request that is retried twice
+// results in three calls to each method.
+type Metrics interface {
+	// OnRequestStart is called before a request for the given action is
+	// sent.
+	OnRequestStart(action string)
+	// OnRequestDone is called once the request completes. errCode is empty
+	// if the request succeeded, holds the AWS error code for API errors and
+	// "RequestError" for other failures, like network errors.
+	OnRequestDone(action string, d time.Duration, errCode string)
+}
+
+// WithMetrics makes the client report its requests to m.
+func WithMetrics(m Metrics) Option {
+	return func(elb *ELB) {
+		elb.metrics = m
+	}
+}
+
+// metricsErrorCode returns the code reported to Metrics for err.
+func metricsErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	if code := ErrorCode(err); code != "" {
+		return code
+	}
+	return "RequestError"
+}
+
+// ExpvarMetrics is a Metrics implementation that publishes its counters
+// through the expvar package.
+//
+// For each action, it keeps the number of requests, the number of errors
+// by code and the total latency in milliseconds, in maps keyed by action.
+type ExpvarMetrics struct {
+	Requests  *expvar.Map
+	Errors    *expvar.Map
+	LatencyMs *expvar.Map
+}
+
+// NewExpvarMetrics creates an ExpvarMetrics publishing its maps as
+// prefix+"_requests", prefix+"_errors" and prefix+"_latency_ms". As with
+// expvar.Publish, it panics if any of these names is already registered.
+func NewExpvarMetrics(prefix string) *ExpvarMetrics {
+	return &ExpvarMetrics{
+		Requests:  expvar.NewMap(prefix + "_requests"),
+		Errors:    expvar.NewMap(prefix + "_errors"),
+		LatencyMs: expvar.NewMap(prefix + "_latency_ms"),
+	}
+}
+
+func (m *ExpvarMetrics) OnRequestStart(action string) {
+	m.Requests.Add(action, 1)
+}
+
+func (m *ExpvarMetrics) OnRequestDone(action string, d time.Duration, errCode string) {
+	m.LatencyMs.AddFloat(action, float64(d)/float64(time.Millisecond))
+	if errCode != "" {
+		m.Errors.Add(action+"."+errCode, 1)
+	}
+}
+
+// send sends a single request, reporting it to the client metrics.
+func (elb *ELB) send(ctx context.Context, version string, params map[string]string, resp interface{}) error {
+	if elb.metrics == nil {
+		return elb.do(ctx, version, params, resp)
+	}
+	action := params["Action"]
+	elb.metrics.OnRequestStart(action)
+	start := time.Now()
+	err := elb.do(ctx, version, params, resp)
+	elb.metrics.OnRequestDone(action, time.Since(start), metricsErrorCode(err))
+	return err
+}
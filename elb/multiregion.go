@@ -0,0 +1,164 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"github.com/flaviamissi/go-elb/aws"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MultiRegion manages one client per region and fans requests out to all
+// of them concurrently, merging the results.
+type MultiRegion struct {
+	clients map[string]*ELB
+}
+
+// NewMultiRegion creates clients for the given regions, sharing auth and
+// options. Regions are identified by their names, which must be unique.
+func NewMultiRegion(auth aws.Auth, regions []aws.Region, options ...Option) *MultiRegion {
+	m := &MultiRegion{clients: make(map[string]*ELB, len(regions))}
+	for _, region := range regions {
+		m.clients[region.Name] = New(auth, region, options...)
+	}
+	return m
+}
+
+// Regions returns the names of the regions managed by m, sorted.
+func (m *MultiRegion) Regions() []string {
+	names := make([]string, 0, len(m.clients))
+	for name := range m.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Client returns the client of the named region, or nil if m doesn't manage
+// that region.
+func (m *MultiRegion) Client(region string) *ELB {
+	return m.clients[region]
+}
+
+// MultiRegionError holds the errors of the regions where a request failed.
+// The results of the other regions are still returned along with it.
+type MultiRegionError struct {
+	Errors map[string]error
+}
+
+func (e *MultiRegionError) Error() string {
+	regions := make([]string, 0, len(e.Errors))
+	for region := range e.Errors {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	msgs := make([]string, len(regions))
+	for i, region := range regions {
+		msgs[i] = fmt.Sprintf("%s: %v", region, e.Errors[region])
+	}
+	return "elb: request failed in " + strings.Join(msgs, "; ")
+}
+
+// each calls f for every region concurrently, and returns a
+// *MultiRegionError if any of the calls failed.
+func (m *MultiRegion) each(f func(region string, client *ELB) error) error {
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		errors = make(map[string]error)
+	)
+	for region, client := range m.clients {
+		wg.Add(1)
+		go func(region string, client *ELB) {
+			defer wg.Done()
+			if err := f(region, client); err != nil {
+				mutex.Lock()
+				errors[region] = err
+				mutex.Unlock()
+			}
+		}(region, client)
+	}
+	wg.Wait()
+	if len(errors) > 0 {
+		return &MultiRegionError{Errors: errors}
+	}
+	return nil
+}
+
+// RegionalLoadBalancer is the description of a Load Balancer along with
+// the region it lives in.
+type RegionalLoadBalancer struct {
+	Region string
+	LoadBalancerDescription
+}
+
+// DescribeLoadBalancers describes the Load Balancers of every region,
+// sorted by region. When names are given, only the Load Balancers with
+// these names are returned; a name missing from a region isn't an error.
+func (m *MultiRegion) DescribeLoadBalancers(ctx context.Context, names ...string) ([]RegionalLoadBalancer, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	results := make(map[string][]RegionalLoadBalancer)
+	var mutex sync.Mutex
+	err := m.each(func(region string, client *ELB) error {
+		descs, err := client.DescribeLoadBalancersAllWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		var lbs []RegionalLoadBalancer
+		for _, desc := range descs {
+			if len(wanted) == 0 || wanted[desc.LoadBalancerName] {
+				lbs = append(lbs, RegionalLoadBalancer{Region: region, LoadBalancerDescription: desc})
+			}
+		}
+		mutex.Lock()
+		results[region] = lbs
+		mutex.Unlock()
+		return nil
+	})
+	var lbs []RegionalLoadBalancer
+	for _, region := range m.Regions() {
+		lbs = append(lbs, results[region]...)
+	}
+	return lbs, err
+}
+
+// RegionalInstanceState is the health of an instance along with the region
+// of its Load Balancer.
+type RegionalInstanceState struct {
+	Region string
+	InstanceState
+}
+
+// DescribeInstanceHealth describes the health of the instances registered
+// with the named Load Balancer in every region where it exists, sorted by
+// region.
+func (m *MultiRegion) DescribeInstanceHealth(ctx context.Context, lbName string) ([]RegionalInstanceState, error) {
+	results := make(map[string][]RegionalInstanceState)
+	var mutex sync.Mutex
+	err := m.each(func(region string, client *ELB) error {
+		resp, err := client.DescribeInstanceHealthWithContext(ctx, lbName)
+		if IsLoadBalancerNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		states := make([]RegionalInstanceState, len(resp.InstanceStates))
+		for i, s := range resp.InstanceStates {
+			states[i] = RegionalInstanceState{Region: region, InstanceState: s}
+		}
+		mutex.Lock()
+		results[region] = states
+		mutex.Unlock()
+		return nil
+	})
+	var states []RegionalInstanceState
+	for _, region := range m.Regions() {
+		states = append(states, results[region]...)
+	}
+	return states, err
+}
@@ -0,0 +1,92 @@
+package elb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ErrProtectedLoadBalancer is returned, without sending the request, when
+// deleting a Load Balancer protected by WithProtectedNames.
+var ErrProtectedLoadBalancer = errors.New("elb: Load Balancer is protected against deletion")
+
+// WithProtectedNames makes the client refuse to delete the Load Balancers
+// whose names match any of the patterns, in the syntax of path.Match, e.g.
+// "prod-*". It guards against deleting production Load Balancers from
+// tests or scripts pointed at the wrong account or endpoint.
+func WithProtectedNames(patterns ...string) Option {
+	return func(elb *ELB) {
+		elb.protectedNames = append(elb.protectedNames, patterns...)
+	}
+}
+
+// isProtected reports whether the Load Balancer is protected against
+// deletion. Malformed patterns only match names equal to them.
+func (elb *ELB) isProtected(lbName string) bool {
+	for _, pattern := range elb.protectedNames {
+		if ok, err := path.Match(pattern, lbName); ok || (err != nil && pattern == lbName) {
+			return true
+		}
+	}
+	return false
+}
+
+// InUseError is returned by DeleteLoadBalancerSafe when the Load Balancer
+// still has instances in service.
+type InUseError struct {
+	LoadBalancerName string
+	// InstanceIds holds the ids of the instances in service.
+	InstanceIds []string
+}
+
+func (e *InUseError) Error() string {
+	return fmt.Sprintf("elb: Load Balancer %q still has %d instances in service", e.LoadBalancerName, len(e.InstanceIds))
+}
+
+// DeleteOptions configures DeleteLoadBalancerSafe.
+type DeleteOptions struct {
+	// Force deletes the Load Balancer even if it has instances in
+	// service. It doesn't override WithProtectedNames.
+	Force bool
+}
+
+// DeleteLoadBalancerSafe deletes the Load Balancer, unless it still has
+// instances in service, which is reported with an *InUseError. A nil
+// *DeleteOptions doesn't force the deletion.
+//
+// Like DeleteLoadBalancer, it refuses to delete the Load Balancers
+// protected by WithProtectedNames, with ErrProtectedLoadBalancer. Deleting
+// a Load Balancer that doesn't exist isn't an error.
+func (elb *ELB) DeleteLoadBalancerSafe(lbName string, opts *DeleteOptions) error {
+	return elb.DeleteLoadBalancerSafeWithContext(context.Background(), lbName, opts)
+}
+
+// DeleteLoadBalancerSafeWithContext is like DeleteLoadBalancerSafe, but
+// the requests are bound to ctx.
+func (elb *ELB) DeleteLoadBalancerSafeWithContext(ctx context.Context, lbName string, opts *DeleteOptions) error {
+	if elb.isProtected(lbName) {
+		return ErrProtectedLoadBalancer
+	}
+	ctx = withOperation(ctx)
+	if opts == nil || !opts.Force {
+		resp, err := elb.DescribeInstanceHealthWithContext(ctx, lbName)
+		if IsLoadBalancerNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var inService []string
+		for _, state := range resp.InstanceStates {
+			if state.IsHealthy() {
+				inService = append(inService, state.InstanceId)
+			}
+		}
+		if len(inService) > 0 {
+			return &InUseError{LoadBalancerName: lbName, InstanceIds: inService}
+		}
+	}
+	_, err := elb.DeleteLoadBalancerWithContext(ctx, lbName)
+	return err
+}
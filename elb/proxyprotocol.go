@@ -0,0 +1,112 @@
+package elb
+
+import (
+	"context"
+)
+
+// ProxyProtocolPolicyName is the name of the policy created by
+// EnableProxyProtocol.
+const ProxyProtocolPolicyName = "EnableProxyProtocol"
+
+// EnableProxyProtocol makes the Load Balancer prepend a PROXY protocol
+// header to the TCP connections it opens to the given backend ports, so
+// instances can tell the address of the clients.
+//
+// The ProxyProtocolPolicyName policy is created if needed, and added to
+// the policies already enabled for each backend port.
+func (elb *ELB) EnableProxyProtocol(lbName string, backendPorts []int) error {
+	return elb.EnableProxyProtocolWithContext(context.Background(), lbName, backendPorts)
+}
+
+// EnableProxyProtocolWithContext is like EnableProxyProtocol, but the
+// requests are bound to ctx.
+func (elb *ELB) EnableProxyProtocolWithContext(ctx context.Context, lbName string, backendPorts []int) error {
+	ctx = withOperation(ctx)
+	attrs := []PolicyAttribute{{AttributeName: "ProxyProtocol", AttributeValue: "true"}}
+	_, err := elb.CreateLoadBalancerPolicyWithContext(ctx, lbName, ProxyProtocolPolicyName, "ProxyProtocolPolicyType", attrs)
+	if err != nil && ErrorCode(err) != ErrDuplicatePolicyName {
+		return err
+	}
+	backends, err := elb.backendPolicies(ctx, lbName)
+	if err != nil {
+		return err
+	}
+	for _, port := range backendPorts {
+		policies := backends[port]
+		if containsString(policies, ProxyProtocolPolicyName) {
+			continue
+		}
+		policies = append(policies, ProxyProtocolPolicyName)
+		if _, err := elb.SetLoadBalancerPoliciesForBackendServerWithContext(ctx, lbName, port, policies); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisableProxyProtocol stops sending the PROXY protocol header to the given
+// backend ports, leaving their other policies enabled. The
+// ProxyProtocolPolicyName policy is deleted once no backend port uses it.
+func (elb *ELB) DisableProxyProtocol(lbName string, backendPorts []int) error {
+	return elb.DisableProxyProtocolWithContext(context.Background(), lbName, backendPorts)
+}
+
+// DisableProxyProtocolWithContext is like DisableProxyProtocol, but the
+// requests are bound to ctx.
+func (elb *ELB) DisableProxyProtocolWithContext(ctx context.Context, lbName string, backendPorts []int) error {
+	ctx = withOperation(ctx)
+	backends, err := elb.backendPolicies(ctx, lbName)
+	if err != nil {
+		return err
+	}
+	for _, port := range backendPorts {
+		if !containsString(backends[port], ProxyProtocolPolicyName) {
+			continue
+		}
+		var policies []string
+		for _, name := range backends[port] {
+			if name != ProxyProtocolPolicyName {
+				policies = append(policies, name)
+			}
+		}
+		if _, err := elb.SetLoadBalancerPoliciesForBackendServerWithContext(ctx, lbName, port, policies); err != nil {
+			return err
+		}
+		backends[port] = policies
+	}
+	for _, policies := range backends {
+		if containsString(policies, ProxyProtocolPolicyName) {
+			return nil
+		}
+	}
+	_, err = elb.DeleteLoadBalancerPolicyWithContext(ctx, lbName, ProxyProtocolPolicyName)
+	if ErrorCode(err) == ErrPolicyNotFound {
+		return nil
+	}
+	return err
+}
+
+// backendPolicies returns the names of the policies enabled for each
+// backend port of the Load Balancer.
+func (elb *ELB) backendPolicies(ctx context.Context, lbName string) (map[int][]string, error) {
+	resp, err := elb.DescribeLoadBalancersWithContext(ctx, lbName)
+	if err != nil {
+		return nil, err
+	}
+	backends := make(map[int][]string)
+	for _, desc := range resp.LoadBalancerDescriptions {
+		for _, b := range desc.BackendServerDescriptions {
+			backends[b.InstancePort] = b.PolicyNames
+		}
+	}
+	return backends, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
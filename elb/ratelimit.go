@@ -0,0 +1,92 @@
+package elb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit makes the client send at most rate requests per second,
+// allowing bursts of up to burst requests. Requests over the limit wait
+// for their turn, or until their context is done. Retries count as
+// requests.
+//
+// The limit is shared by all the goroutines using the client, which helps
+// staying under the account limits of AWS during mass deployments instead
+// of relying on retries once requests are throttled. A rate lower than or
+// equal to zero disables the limit, and burst is at least 1.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(elb *ELB) {
+		if rate <= 0 {
+			elb.limiter = nil
+			return
+		}
+		elb.limiter = newRateLimiter(rate, burst)
+	}
+}
+
+// rateLimiter is a token bucket, filled with rate tokens per second up to
+// burst tokens. Each request takes a token.
+type rateLimiter struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token at now, returning how long the caller must wait
+// before using it. The bucket goes into debt when empty, so that waiting
+// callers are served in order.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a token taken by reserve but never used.
+func (l *rateLimiter) cancel() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.tokens++
+}
+
+// wait blocks until the caller may send a request, returning early with
+// the context error if ctx is done first. A nil limiter never blocks.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	d := l.reserve(time.Now())
+	if d == 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, d); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package elb_test
+
+import (
+	"context"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+	"time"
+)
+
+func (s *S) TestRateLimiterBurst(c *C) {
+	l := elb.NewRateLimiter(10, 2)
+	now := l.Last()
+	c.Assert(l.Reserve(now), Equals, time.Duration(0))
+	c.Assert(l.Reserve(now), Equals, time.Duration(0))
+	c.Assert(l.Reserve(now), Equals, 100*time.Millisecond)
+	c.Assert(l.Reserve(now), Equals, 200*time.Millisecond)
+}
+
+func (s *S) TestRateLimiterRefill(c *C) {
+	l := elb.NewRateLimiter(10, 2)
+	now := l.Last()
+	l.Reserve(now)
+	l.Reserve(now)
+	c.Assert(l.Reserve(now.Add(50*time.Millisecond)), Equals, 50*time.Millisecond)
+	// The bucket never holds more than burst tokens.
+	now = now.Add(time.Hour)
+	c.Assert(l.Reserve(now), Equals, time.Duration(0))
+	c.Assert(l.Reserve(now), Equals, time.Duration(0))
+	c.Assert(l.Reserve(now), Equals, 100*time.Millisecond)
+}
+
+func (s *LocalServerSuite) TestRateLimit(c *C) {
+	client := elb.New(s.clientTests.elb.Auth, s.srv.region, elb.WithRateLimit(50, 1))
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.DescribeLoadBalancers()
+		c.Assert(err, IsNil)
+	}
+	c.Assert(time.Since(start) >= 35*time.Millisecond, Equals, true)
+}
+
+func (s *LocalServerSuite) TestRateLimitContext(c *C) {
+	client := elb.New(s.clientTests.elb.Auth, s.srv.region, elb.WithRateLimit(0.01, 1))
+	_, err := client.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+	s.srv.srv.ResetHistory()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.DescribeLoadBalancersWithContext(ctx)
+	c.Assert(err, Equals, context.DeadlineExceeded)
+	c.Assert(s.srv.srv.RequestsFor("DescribeLoadBalancers"), HasLen, 0)
+}
@@ -0,0 +1,116 @@
+package elb
+
+import (
+	"github.com/flaviamissi/go-elb/aws"
+	"strings"
+)
+
+// Partitions of AWS, as found in ARNs.
+const (
+	PartitionAWS      = "aws"
+	PartitionChina    = "aws-cn"
+	PartitionGovCloud = "aws-us-gov"
+)
+
+// Region is an AWS region where Elastic Load Balancing is available, along
+// with the endpoint of its API.
+type Region struct {
+	Name     string
+	Endpoint string
+	// Partition is the partition of the region, as found in ARNs.
+	Partition string
+	// SignatureV4Only is set for the regions that reject requests signed
+	// with Signature Version 2.
+	SignatureV4Only bool
+}
+
+// AWSRegion returns the aws.Region expected by New: the region of the same
+// name in aws.Regions, if any, sending ELB requests to the endpoint of r.
+func (r Region) AWSRegion() aws.Region {
+	region := aws.Regions[r.Name]
+	region.Name = r.Name
+	region.ELBEndpoint = r.Endpoint
+	return region
+}
+
+// WithEndpoint returns a copy of r that sends requests to endpoint, such as
+// a private cloud or a fake server used in tests. The region name is kept,
+// as it's part of Signature Version 4 signatures.
+func (r Region) WithEndpoint(endpoint string) Region {
+	r.Endpoint = endpoint
+	return r
+}
+
+// signatureV2Regions holds the regions launched before Signature Version 4,
+// which still accept Signature Version 2. All the others reject it.
+var signatureV2Regions = map[string]bool{
+	"us-east-1":      true,
+	"us-west-1":      true,
+	"us-west-2":      true,
+	"eu-west-1":      true,
+	"ap-northeast-1": true,
+	"ap-southeast-1": true,
+	"ap-southeast-2": true,
+	"sa-east-1":      true,
+	"us-gov-west-1":  true,
+}
+
+// Regions maps the names of the AWS regions, including the GovCloud and
+// China partitions, to their ELB endpoints. It's derived from aws.Regions.
+var Regions = map[string]Region{}
+
+func init() {
+	for name, r := range aws.Regions {
+		Regions[name] = Region{
+			Name:            name,
+			Endpoint:        r.ELBEndpoint,
+			Partition:       PartitionOf(name),
+			SignatureV4Only: !signatureV2Regions[name],
+		}
+	}
+}
+
+// PartitionOf returns the partition of the named region, as told by its
+// name.
+func PartitionOf(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionChina
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionGovCloud
+	}
+	return PartitionAWS
+}
+
+// LookupRegion returns the named region from Regions. Regions missing from
+// it, like the ones launched after this package was released, get the ELB
+// endpoint of their partition and Signature Version 4, which all the
+// regions launched since 2014 require. Private clouds and fake servers set
+// their endpoint with WithEndpoint, and use WithSignatureVersion if they
+// only accept Signature Version 2.
+func LookupRegion(name string) Region {
+	if r, ok := Regions[name]; ok {
+		return r
+	}
+	return Region{
+		Name:            name,
+		Endpoint:        ServiceEndpoint(name, "elasticloadbalancing"),
+		Partition:       PartitionOf(name),
+		SignatureV4Only: true,
+	}
+}
+
+// ServiceEndpoint returns the usual endpoint of the service in the named
+// region, e.g. https://autoscaling.cn-north-1.amazonaws.com.cn, which
+// depends on the partition of the region. An empty name stands for
+// us-east-1.
+func ServiceEndpoint(region, service string) string {
+	if region == "" {
+		region = "us-east-1"
+	}
+	domain := "amazonaws.com"
+	if PartitionOf(region) == PartitionChina {
+		domain = "amazonaws.com.cn"
+	}
+	return "https://" + service + "." + region + "." + domain
+}
@@ -0,0 +1,80 @@
+package elb
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ClientRequestTokenHeader is the header carrying the client request
+// token of each request, a random UUID generated for each call and kept
+// across its retries. The AWS SDKs send the same header.
+const ClientRequestTokenHeader = "amz-sdk-invocation-id"
+
+type operationIDKey struct{}
+
+type requestTokenKey struct{}
+
+// WithOperationID returns a copy of ctx carrying id. The requests sent
+// with the returned context are tagged with id in the debug logs and in
+// the errors they return, so that the requests sent by a multi-step
+// operation can be correlated.
+//
+// Helpers sending many requests, like EnsureLoadBalancer or
+// RotateInstances, tag their requests with a new id when ctx has none.
+func WithOperationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, operationIDKey{}, id)
+}
+
+// OperationID returns the id set on ctx by WithOperationID, or an empty
+// string.
+func OperationID(ctx context.Context) string {
+	id, _ := ctx.Value(operationIDKey{}).(string)
+	return id
+}
+
+// withOperation returns ctx, with a new operation id if it has none.
+func withOperation(ctx context.Context) context.Context {
+	if OperationID(ctx) != "" {
+		return ctx
+	}
+	return WithOperationID(ctx, newToken())
+}
+
+// requestToken returns the client request token carried by ctx.
+func requestToken(ctx context.Context) string {
+	token, _ := ctx.Value(requestTokenKey{}).(string)
+	return token
+}
+
+// newToken returns a random UUID.
+func newToken() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// annotateError records the client request token and operation id of the
+// request carried by ctx in err, when it's an *Error.
+func annotateError(ctx context.Context, err error) {
+	var e *Error
+	if errors.As(err, &e) {
+		e.ClientRequestToken = requestToken(ctx)
+		e.OperationId = OperationID(ctx)
+	}
+}
+
+// logTags returns the tags identifying the request carried by ctx in the
+// debug logs.
+func logTags(ctx context.Context) string {
+	tags := "request=" + requestToken(ctx)
+	if id := OperationID(ctx); id != "" {
+		tags += " operation=" + id
+	}
+	return tags
+}
@@ -63,7 +63,11 @@ var RegisterInstancesWithLoadBalancerBadRequest = `
 var DeregisterInstancesFromLoadBalancer = `
 <DeregisterInstancesFromLoadBalancerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
     <DeregisterInstancesFromLoadBalancerResult>
-        <Instances/>
+        <Instances>
+            <member>
+                <InstanceId>i-315b7e51</InstanceId>
+            </member>
+        </Instances>
     </DeregisterInstancesFromLoadBalancerResult>
     <ResponseMetadata>
         <RequestId>d6490837-49fd-11e2-bba9-35ba56032fe1</RequestId>
@@ -82,6 +86,81 @@ var DeregisterInstancesFromLoadBalancerBadRequest = `
 </ErrorResponse>
 `
 
+var DescribeLoadBalancersVPC = `
+<DescribeLoadBalancersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeLoadBalancersResult>
+        <LoadBalancerDescriptions>
+            <member>
+                <SecurityGroups>
+                    <member>sg-1a2b3c4d</member>
+                </SecurityGroups>
+                <CreatedTime>2013-05-24T21:15:31.280Z</CreatedTime>
+                <LoadBalancerName>vpclb</LoadBalancerName>
+                <HealthCheck>
+                    <Interval>30</Interval>
+                    <Target>HTTP:80/</Target>
+                    <HealthyThreshold>2</HealthyThreshold>
+                    <Timeout>3</Timeout>
+                    <UnhealthyThreshold>2</UnhealthyThreshold>
+                </HealthCheck>
+                <VPCId>vpc-a01106c2</VPCId>
+                <ListenerDescriptions>
+                    <member>
+                        <PolicyNames>
+                            <member>my-cookie-policy</member>
+                        </PolicyNames>
+                        <Listener>
+                            <Protocol>HTTP</Protocol>
+                            <LoadBalancerPort>80</LoadBalancerPort>
+                            <InstanceProtocol>HTTP</InstanceProtocol>
+                            <InstancePort>80</InstancePort>
+                        </Listener>
+                    </member>
+                </ListenerDescriptions>
+                <Instances/>
+                <Policies>
+                    <AppCookieStickinessPolicies/>
+                    <OtherPolicies>
+                        <member>my-backend-policy</member>
+                    </OtherPolicies>
+                    <LBCookieStickinessPolicies>
+                        <member>
+                            <PolicyName>my-cookie-policy</PolicyName>
+                            <CookieExpirationPeriod>60</CookieExpirationPeriod>
+                        </member>
+                    </LBCookieStickinessPolicies>
+                </Policies>
+                <AvailabilityZones>
+                    <member>us-east-1a</member>
+                </AvailabilityZones>
+                <CanonicalHostedZoneName>vpclb-1234567890.us-east-1.elb.amazonaws.com</CanonicalHostedZoneName>
+                <CanonicalHostedZoneNameID>Z35SXDOTRQ7X7K</CanonicalHostedZoneNameID>
+                <Scheme>internet-facing</Scheme>
+                <SourceSecurityGroup>
+                    <OwnerAlias>123456789012</OwnerAlias>
+                    <GroupName>default</GroupName>
+                </SourceSecurityGroup>
+                <DNSName>vpclb-1234567890.us-east-1.elb.amazonaws.com</DNSName>
+                <BackendServerDescriptions>
+                    <member>
+                        <InstancePort>443</InstancePort>
+                        <PolicyNames>
+                            <member>my-backend-policy</member>
+                        </PolicyNames>
+                    </member>
+                </BackendServerDescriptions>
+                <Subnets>
+                    <member>subnet-15ca247d</member>
+                </Subnets>
+            </member>
+        </LoadBalancerDescriptions>
+    </DescribeLoadBalancersResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DescribeLoadBalancersResponse>
+`
+
 var DescribeLoadBalancers = `
 <DescribeLoadBalancersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
     <DescribeLoadBalancersResult>
@@ -203,3 +282,393 @@ var ConfigureHealthCheckBadRequest = `
     <RequestId>2d9fe4a5-5697-11e2-9415-e325c02171d7</RequestId>
 </ErrorResponse>
 `
+
+var DescribeLoadBalancerAttributes = `
+<DescribeLoadBalancerAttributesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeLoadBalancerAttributesResult>
+        <LoadBalancerAttributes>
+            <AccessLog>
+                <Enabled>true</Enabled>
+                <S3BucketName>my-loadbalancer-logs</S3BucketName>
+                <S3BucketPrefix>testlb</S3BucketPrefix>
+                <EmitInterval>60</EmitInterval>
+            </AccessLog>
+            <ConnectionDraining>
+                <Enabled>true</Enabled>
+                <Timeout>300</Timeout>
+            </ConnectionDraining>
+            <CrossZoneLoadBalancing>
+                <Enabled>true</Enabled>
+            </CrossZoneLoadBalancing>
+            <ConnectionSettings>
+                <IdleTimeout>30</IdleTimeout>
+            </ConnectionSettings>
+        </LoadBalancerAttributes>
+    </DescribeLoadBalancerAttributesResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DescribeLoadBalancerAttributesResponse>
+`
+
+var ModifyLoadBalancerAttributes = `
+<ModifyLoadBalancerAttributesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <ModifyLoadBalancerAttributesResult>
+        <LoadBalancerName>testlb</LoadBalancerName>
+        <LoadBalancerAttributes>
+            <CrossZoneLoadBalancing>
+                <Enabled>true</Enabled>
+            </CrossZoneLoadBalancing>
+            <ConnectionSettings>
+                <IdleTimeout>30</IdleTimeout>
+            </ConnectionSettings>
+        </LoadBalancerAttributes>
+    </ModifyLoadBalancerAttributesResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</ModifyLoadBalancerAttributesResponse>
+`
+
+var AddTags = `
+<AddTagsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <AddTagsResult/>
+    <ResponseMetadata>
+        <RequestId>360e81f7-1100-11e4-b6ed-0f30EXAMPLE</RequestId>
+    </ResponseMetadata>
+</AddTagsResponse>
+`
+
+var RemoveTags = `
+<RemoveTagsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <RemoveTagsResult/>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</RemoveTagsResponse>
+`
+
+var DescribeTags = `
+<DescribeTagsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeTagsResult>
+        <TagDescriptions>
+            <member>
+                <Tags>
+                    <member>
+                        <Key>project</Key>
+                        <Value>lima</Value>
+                    </member>
+                    <member>
+                        <Key>department</Key>
+                        <Value>digital-media</Value>
+                    </member>
+                </Tags>
+                <LoadBalancerName>testlb</LoadBalancerName>
+            </member>
+        </TagDescriptions>
+    </DescribeTagsResult>
+    <ResponseMetadata>
+        <RequestId>07b1ecbc-1100-11e3-acaf-dd7edEXAMPLE</RequestId>
+    </ResponseMetadata>
+</DescribeTagsResponse>
+`
+
+var CreateLoadBalancerListeners = `
+<CreateLoadBalancerListenersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <CreateLoadBalancerListenersResult/>
+    <ResponseMetadata>
+        <RequestId>1549581b-12b7-11e3-895e-1334aEXAMPLE</RequestId>
+    </ResponseMetadata>
+</CreateLoadBalancerListenersResponse>
+`
+
+var CreateLoadBalancerListenersDuplicate = `
+<ErrorResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <Error>
+        <Type>Sender</Type>
+        <Code>DuplicateListener</Code>
+        <Message>A listener already exists for testlb with LoadBalancerPort 80, but with a different InstancePort, Protocol, or SSLCertificateId</Message>
+    </Error>
+    <RequestId>a1c4a9c3-12b7-11e3-895e-1334aEXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var DeleteLoadBalancerListeners = `
+<DeleteLoadBalancerListenersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DeleteLoadBalancerListenersResult/>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DeleteLoadBalancerListenersResponse>
+`
+
+var SetLoadBalancerListenerSSLCertificate = `
+<SetLoadBalancerListenerSSLCertificateResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <SetLoadBalancerListenerSSLCertificateResult/>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</SetLoadBalancerListenerSSLCertificateResponse>
+`
+
+var SetLoadBalancerListenerSSLCertificateNotFound = `
+<ErrorResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <Error>
+        <Type>Sender</Type>
+        <Code>CertificateNotFound</Code>
+        <Message>Server Certificate not found for the key: arn:aws:iam::123456789012:server-certificate/absent</Message>
+    </Error>
+    <RequestId>c4b58c1a-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var ApplySecurityGroupsToLoadBalancer = `
+<ApplySecurityGroupsToLoadBalancerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <ApplySecurityGroupsToLoadBalancerResult>
+        <SecurityGroups>
+            <member>sg-fc448899</member>
+            <member>sg-a1b2c3d4</member>
+        </SecurityGroups>
+    </ApplySecurityGroupsToLoadBalancerResult>
+    <ResponseMetadata>
+        <RequestId>06b5decc-102a-11e3-9ad6-bf3e4EXAMPLE</RequestId>
+    </ResponseMetadata>
+</ApplySecurityGroupsToLoadBalancerResponse>
+`
+
+var AttachLoadBalancerToSubnets = `
+<AttachLoadBalancerToSubnetsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <AttachLoadBalancerToSubnetsResult>
+        <Subnets>
+            <member>subnet-119f0078</member>
+            <member>subnet-3561b05e</member>
+        </Subnets>
+    </AttachLoadBalancerToSubnetsResult>
+    <ResponseMetadata>
+        <RequestId>07b1ecbc-1100-11e3-acaf-dd7edEXAMPLE</RequestId>
+    </ResponseMetadata>
+</AttachLoadBalancerToSubnetsResponse>
+`
+
+var DetachLoadBalancerFromSubnets = `
+<DetachLoadBalancerFromSubnetsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DetachLoadBalancerFromSubnetsResult>
+        <Subnets>
+            <member>subnet-119f0078</member>
+        </Subnets>
+    </DetachLoadBalancerFromSubnetsResult>
+    <ResponseMetadata>
+        <RequestId>07b1ecbc-1100-11e3-acaf-dd7edEXAMPLE</RequestId>
+    </ResponseMetadata>
+</DetachLoadBalancerFromSubnetsResponse>
+`
+
+var AttachLoadBalancerToSubnetsNotFound = `
+<ErrorResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <Error>
+        <Type>Sender</Type>
+        <Code>SubnetNotFound</Code>
+        <Message>One or more subnets were not found: subnet-absent</Message>
+    </Error>
+    <RequestId>2d9fe4a5-5697-11e2-9415-e325c02171d7</RequestId>
+</ErrorResponse>
+`
+
+var EnableAvailabilityZonesForLoadBalancer = `
+<EnableAvailabilityZonesForLoadBalancerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <EnableAvailabilityZonesForLoadBalancerResult>
+        <AvailabilityZones>
+            <member>us-east-1a</member>
+            <member>us-east-1c</member>
+        </AvailabilityZones>
+    </EnableAvailabilityZonesForLoadBalancerResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</EnableAvailabilityZonesForLoadBalancerResponse>
+`
+
+var DisableAvailabilityZonesForLoadBalancer = `
+<DisableAvailabilityZonesForLoadBalancerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DisableAvailabilityZonesForLoadBalancerResult>
+        <AvailabilityZones>
+            <member>us-east-1a</member>
+        </AvailabilityZones>
+    </DisableAvailabilityZonesForLoadBalancerResult>
+    <ResponseMetadata>
+        <RequestId>ba6267d5-2566-11e3-9c6d-eb728EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DisableAvailabilityZonesForLoadBalancerResponse>
+`
+
+var CreateLBCookieStickinessPolicy = `
+<CreateLBCookieStickinessPolicyResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <CreateLBCookieStickinessPolicyResult/>
+    <ResponseMetadata>
+        <RequestId>99a693e9-12b8-11e3-9ad6-bf3e4EXAMPLE</RequestId>
+    </ResponseMetadata>
+</CreateLBCookieStickinessPolicyResponse>
+`
+
+var CreateAppCookieStickinessPolicy = `
+<CreateAppCookieStickinessPolicyResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <CreateAppCookieStickinessPolicyResult/>
+    <ResponseMetadata>
+        <RequestId>99a693e9-12b8-11e3-9ad6-bf3e4EXAMPLE</RequestId>
+    </ResponseMetadata>
+</CreateAppCookieStickinessPolicyResponse>
+`
+
+var DeleteLoadBalancerPolicy = `
+<DeleteLoadBalancerPolicyResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DeleteLoadBalancerPolicyResult/>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DeleteLoadBalancerPolicyResponse>
+`
+
+var SetLoadBalancerPoliciesOfListener = `
+<SetLoadBalancerPoliciesOfListenerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <SetLoadBalancerPoliciesOfListenerResult/>
+    <ResponseMetadata>
+        <RequestId>07b1ecbc-1100-11e3-acaf-dd7edEXAMPLE</RequestId>
+    </ResponseMetadata>
+</SetLoadBalancerPoliciesOfListenerResponse>
+`
+
+var CreateLoadBalancerPolicyDuplicate = `
+<ErrorResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <Error>
+        <Type>Sender</Type>
+        <Code>DuplicatePolicyName</Code>
+        <Message>Policy name already exists</Message>
+    </Error>
+    <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+</ErrorResponse>
+`
+
+var CreateLoadBalancerPolicy = `
+<CreateLoadBalancerPolicyResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <CreateLoadBalancerPolicyResult/>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</CreateLoadBalancerPolicyResponse>
+`
+
+var DescribeLoadBalancerPolicies = `
+<DescribeLoadBalancerPoliciesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeLoadBalancerPoliciesResult>
+        <PolicyDescriptions>
+            <member>
+                <PolicyName>EnableProxyProtocol</PolicyName>
+                <PolicyTypeName>ProxyProtocolPolicyType</PolicyTypeName>
+                <PolicyAttributeDescriptions>
+                    <member>
+                        <AttributeName>ProxyProtocol</AttributeName>
+                        <AttributeValue>true</AttributeValue>
+                    </member>
+                </PolicyAttributeDescriptions>
+            </member>
+        </PolicyDescriptions>
+    </DescribeLoadBalancerPoliciesResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DescribeLoadBalancerPoliciesResponse>
+`
+
+var DescribeLoadBalancerPoliciesSSL = `
+<DescribeLoadBalancerPoliciesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeLoadBalancerPoliciesResult>
+        <PolicyDescriptions>
+            <member>
+                <PolicyName>ELBSecurityPolicy-2016-08</PolicyName>
+                <PolicyTypeName>SSLNegotiationPolicyType</PolicyTypeName>
+                <PolicyAttributeDescriptions>
+                    <member>
+                        <AttributeName>Protocol-TLSv1.2</AttributeName>
+                        <AttributeValue>true</AttributeValue>
+                    </member>
+                </PolicyAttributeDescriptions>
+            </member>
+            <member>
+                <PolicyName>ELBSample-LBCookieStickinessPolicy</PolicyName>
+                <PolicyTypeName>LBCookieStickinessPolicyType</PolicyTypeName>
+                <PolicyAttributeDescriptions>
+                    <member>
+                        <AttributeName>CookieExpirationPeriod</AttributeName>
+                        <AttributeValue>0</AttributeValue>
+                    </member>
+                </PolicyAttributeDescriptions>
+            </member>
+            <member>
+                <PolicyName>ELBSecurityPolicy-TLS-1-2-2017-01</PolicyName>
+                <PolicyTypeName>SSLNegotiationPolicyType</PolicyTypeName>
+                <PolicyAttributeDescriptions>
+                    <member>
+                        <AttributeName>Protocol-TLSv1.2</AttributeName>
+                        <AttributeValue>true</AttributeValue>
+                    </member>
+                </PolicyAttributeDescriptions>
+            </member>
+        </PolicyDescriptions>
+    </DescribeLoadBalancerPoliciesResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DescribeLoadBalancerPoliciesResponse>
+`
+
+var DescribeLoadBalancerPolicyTypes = `
+<DescribeLoadBalancerPolicyTypesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeLoadBalancerPolicyTypesResult>
+        <PolicyTypeDescriptions>
+            <member>
+                <PolicyAttributeTypeDescriptions>
+                    <member>
+                        <AttributeName>ProxyProtocol</AttributeName>
+                        <AttributeType>Boolean</AttributeType>
+                        <Cardinality>ONE</Cardinality>
+                    </member>
+                </PolicyAttributeTypeDescriptions>
+                <PolicyTypeName>ProxyProtocolPolicyType</PolicyTypeName>
+                <Description>Policy that controls whether to include the IP address and port of the originating request for TCP messages. This policy operates on TCP listeners only.</Description>
+            </member>
+        </PolicyTypeDescriptions>
+    </DescribeLoadBalancerPolicyTypesResult>
+    <ResponseMetadata>
+        <RequestId>83c88b9d-12b7-11e3-8b82-87b12EXAMPLE</RequestId>
+    </ResponseMetadata>
+</DescribeLoadBalancerPolicyTypesResponse>
+`
+
+var SetLoadBalancerPoliciesForBackendServer = `
+<SetLoadBalancerPoliciesForBackendServerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <SetLoadBalancerPoliciesForBackendServerResult/>
+    <ResponseMetadata>
+        <RequestId>0eb9b381-dde0-11e2-8d78-6ddbaEXAMPLE</RequestId>
+    </ResponseMetadata>
+</SetLoadBalancerPoliciesForBackendServerResponse>
+`
+
+var DescribeLoadBalancersWithNextMarker = `
+<DescribeLoadBalancersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+    <DescribeLoadBalancersResult>
+        <LoadBalancerDescriptions>
+            <member>
+                <LoadBalancerName>somelb</LoadBalancerName>
+                <AvailabilityZones>
+                    <member>us-east-1a</member>
+                </AvailabilityZones>
+                <DNSName>somelb-1234567890.us-east-1.elb.amazonaws.com</DNSName>
+            </member>
+        </LoadBalancerDescriptions>
+        <NextMarker>otherlb</NextMarker>
+    </DescribeLoadBalancersResult>
+    <ResponseMetadata>
+        <RequestId>e2e81963-5055-11e2-99c7-434205631d9b</RequestId>
+    </ResponseMetadata>
+</DescribeLoadBalancersResponse>
+`
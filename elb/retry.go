@@ -0,0 +1,120 @@
+package elb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy defines how failed requests are retried.
+//
+// The delay before the nth retry grows exponentially from BaseDelay and is
+// capped at MaxDelay. With Jitter set, the actual delay is picked at random
+// between zero and that value, so that clients throttled at the same time
+// don't retry in lockstep.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts made for a single
+	// request, including the first one. Values lower than 1 mean 1.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      bool
+	// Retryable reports whether a request that failed with err should be
+	// retried. When nil, IsRetryable is used.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy is the policy used by clients created without
+// WithRetryPolicy. It mirrors the defaults of the AWS SDKs: three attempts
+// and exponential backoff with full jitter.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    20 * time.Second,
+	Jitter:      true,
+}
+
+// WithRetryPolicy makes the client retry failed requests according to p.
+// Use a policy with MaxAttempts set to 1 to disable retries.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(elb *ELB) {
+		elb.retryPolicy = &p
+	}
+}
+
+// retryableCodes lists the error codes returned by AWS for throttled or
+// transient failures.
+var retryableCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+	"RequestThrottled":                       true,
+	"RequestThrottledException":              true,
+	"ProvisionedThroughputExceededException": true,
+	"TooManyRequestsException":               true,
+	"InternalFailure":                        true,
+	"InternalError":                          true,
+	"ServiceUnavailable":                     true,
+	"RequestTimeout":                         true,
+	"RequestTimeoutException":                true,
+}
+
+// IsRetryable reports whether err is a transient failure: a throttling
+// error, a 5xx response or a network error. Errors caused by the
+// cancellation of the request context are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var elbErr *Error
+	if errors.As(err, &elbErr) {
+		return elbErr.StatusCode >= 500 || retryableCodes[elbErr.Code]
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// delay returns how long to wait before the given retry, starting at 0.
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay << uint(retry)
+	if retry >= 32 || d < 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if p.Jitter && d > 0 {
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	}
+	return d
+}
+
+func (elb *ELB) retryPolicyOrDefault() *RetryPolicy {
+	if elb.retryPolicy != nil {
+		return elb.retryPolicy
+	}
+	return &DefaultRetryPolicy
+}
+
+// sleepContext waits for d, returning early with the context error if ctx
+// is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
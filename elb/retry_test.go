@@ -0,0 +1,150 @@
+package elb_test
+
+import (
+	"context"
+	"errors"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+	"net/url"
+	"time"
+)
+
+type RetrySuite struct {
+	HTTPSuite
+	elb *elb.ELB
+}
+
+var _ = Suite(&RetrySuite{})
+
+func (s *RetrySuite) SetUpSuite(c *C) {
+	s.HTTPSuite.SetUpSuite(c)
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	policy := elb.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	s.elb = elb.New(auth, aws.Region{ELBEndpoint: testServer.URL}, elb.WithRetryPolicy(policy))
+}
+
+var Throttling = `
+<ErrorResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2012-06-01/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>Throttling</Code>
+    <Message>Rate exceeded</Message>
+  </Error>
+  <RequestId>a4b3c2d1-1234-11e2-aaaa-123456789012</RequestId>
+</ErrorResponse>
+`
+
+func (s *RetrySuite) TestRetryOnServerError(c *C) {
+	testServer.PrepareResponse(500, nil, "")
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	_, err := s.elb.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancer")
+}
+
+func (s *RetrySuite) TestRetriesShareClientRequestToken(c *C) {
+	for i := 0; i < 3; i++ {
+		testServer.PrepareResponse(400, nil, Throttling)
+	}
+	ctx := elb.WithOperationID(context.Background(), "deploy-42")
+	_, err := s.elb.DeleteLoadBalancerWithContext(ctx, "testlb")
+	token := testServer.WaitRequest().Header.Get(elb.ClientRequestTokenHeader)
+	c.Assert(token, Matches, "[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}")
+	for i := 1; i < 3; i++ {
+		c.Assert(testServer.WaitRequest().Header.Get(elb.ClientRequestTokenHeader), Equals, token)
+	}
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.ClientRequestToken, Equals, token)
+	c.Assert(e.OperationId, Equals, "deploy-42")
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	_, err = s.elb.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(testServer.WaitRequest().Header.Get(elb.ClientRequestTokenHeader), Not(Equals), token)
+}
+
+func (s *RetrySuite) TestRetryOnThrottling(c *C) {
+	testServer.PrepareResponse(400, nil, Throttling)
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	_, err := s.elb.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	testServer.WaitRequest()
+	testServer.WaitRequest()
+}
+
+func (s *RetrySuite) TestRetryGivesUpAfterMaxAttempts(c *C) {
+	for i := 0; i < 3; i++ {
+		testServer.PrepareResponse(503, nil, "")
+	}
+	_, err := s.elb.DeleteLoadBalancer("testlb")
+	c.Assert(err, NotNil)
+	e, ok := err.(*elb.Error)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.StatusCode, Equals, 503)
+	for i := 0; i < 3; i++ {
+		testServer.WaitRequest()
+	}
+}
+
+func (s *RetrySuite) TestNoRetryOnClientError(c *C) {
+	testServer.PrepareResponse(400, nil, DescribeLoadBalancersBadRequest)
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	_, err := s.elb.DescribeLoadBalancers("absentlb")
+	c.Assert(err, NotNil)
+	testServer.WaitRequest()
+	// Consume the unused response.
+	_, err = s.elb.DescribeLoadBalancers()
+	c.Assert(err, IsNil)
+}
+
+func (s *RetrySuite) TestCustomRetryable(c *C) {
+	policy := elb.RetryPolicy{
+		MaxAttempts: 3,
+		Retryable: func(err error) bool {
+			e, ok := err.(*elb.Error)
+			return ok && e.Code == "LoadBalancerNotFound"
+		},
+	}
+	client := elb.New(s.elb.Auth, s.elb.Region, elb.WithRetryPolicy(policy))
+	testServer.PrepareResponse(400, nil, DescribeLoadBalancersBadRequest)
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	resp, err := client.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancerDescriptions, HasLen, 1)
+}
+
+func (s *RetrySuite) TestIsRetryable(c *C) {
+	c.Assert(elb.IsRetryable(nil), Equals, false)
+	c.Assert(elb.IsRetryable(&elb.Error{StatusCode: 500}), Equals, true)
+	c.Assert(elb.IsRetryable(&elb.Error{StatusCode: 400, Code: "Throttling"}), Equals, true)
+	c.Assert(elb.IsRetryable(&elb.Error{StatusCode: 400, Code: "LoadBalancerNotFound"}), Equals, false)
+	c.Assert(elb.IsRetryable(&url.Error{Op: "Get", URL: "http://elb", Err: errors.New("connection refused")}), Equals, true)
+	c.Assert(elb.IsRetryable(&url.Error{Op: "Get", URL: "http://elb", Err: context.Canceled}), Equals, false)
+	c.Assert(elb.IsRetryable(errors.New("unexpected EOF")), Equals, false)
+	c.Assert(elb.IsRetryable(&elb.TransportError{StatusCode: 502}), Equals, true)
+	c.Assert(elb.IsRetryable(&elb.TransportError{StatusCode: 403}), Equals, false)
+}
+
+func (s *RetrySuite) TestThrottleStats(c *C) {
+	var stats elb.ThrottleStats
+	policy := elb.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	e := elb.New(s.elb.Auth, s.elb.Region, elb.WithRetryPolicy(policy), elb.WithThrottleObserver(&stats))
+	testServer.PrepareResponse(400, nil, Throttling)
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	_, err := e.DeleteLoadBalancer("testlb")
+	c.Assert(err, IsNil)
+	testServer.PrepareResponse(503, nil, "")
+	testServer.PrepareResponse(503, nil, "")
+	_, err = e.DescribeLoadBalancers()
+	c.Assert(err, NotNil)
+	for i := 0; i < 4; i++ {
+		testServer.WaitRequest()
+	}
+	c.Assert(stats.Counters(), DeepEquals, map[string]elb.ThrottleCounters{
+		"DeleteLoadBalancer":    {Retries: 1, Throttled: 1, Backoff: time.Millisecond},
+		"DescribeLoadBalancers": {Retries: 1, Backoff: time.Millisecond, GiveUps: 1},
+	})
+}
@@ -0,0 +1,168 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+)
+
+// RotateOptions configures RotateInstances. A nil *RotateOptions replaces
+// one instance at a time.
+type RotateOptions struct {
+	// BatchSize is the number of instances replaced at once. It defaults
+	// to 1.
+	BatchSize int
+	// Waiter configures the wait for the new instances to be InService
+	// and for the old ones to drain.
+	Waiter *WaiterConfig
+	// Progress, when set, is called after each batch is replaced.
+	Progress func(RotateProgress)
+}
+
+// RotateProgress reports the replacement of a batch by RotateInstances.
+type RotateProgress struct {
+	// Batch is the number of the batch replaced, from 1 to Batches.
+	Batch   int
+	Batches int
+	// Added holds the instances registered by the batch, and Removed the
+	// ones deregistered.
+	Added   []string
+	Removed []string
+}
+
+// RotateError is returned by RotateInstances when a batch fails.
+type RotateError struct {
+	// Batch is the number of the batch that failed, from 1.
+	Batch int
+	Err   error
+	// RollbackErr is the error that prevented restoring the old
+	// instances, if any.
+	RollbackErr error
+}
+
+func (e *RotateError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("elb: batch %d of rotation failed: %v; rollback failed: %v", e.Batch, e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("elb: batch %d of rotation failed: %v", e.Batch, e.Err)
+}
+
+func (e *RotateError) Unwrap() error {
+	return e.Err
+}
+
+// RotateInstances replaces the instances oldIds of the Load Balancer with
+// newIds, batch by batch, e.g. to refresh the AMI of a fleet. For each
+// batch, new instances are registered, and once they are InService as
+// many old instances are deregistered and drained, so that the capacity
+// of the Load Balancer never drops. When oldIds and newIds don't have the
+// same length, the last batches only add or only remove instances.
+//
+// If a batch fails, the rotation is rolled back: the old instances
+// removed so far are registered again and, once InService, the new
+// instances are deregistered. A *RotateError is returned.
+func (elb *ELB) RotateInstances(lbName string, oldIds, newIds []string, opts *RotateOptions) error {
+	return elb.RotateInstancesWithContext(context.Background(), lbName, oldIds, newIds, opts)
+}
+
+// RotateInstancesWithContext is like RotateInstances, but the requests are
+// bound to ctx. The rollback runs even if a batch failed because ctx was
+// canceled or its deadline passed, bounded by the MaxWait of the waiter
+// instead.
+func (elb *ELB) RotateInstancesWithContext(ctx context.Context, lbName string, oldIds, newIds []string, opts *RotateOptions) error {
+	ctx = withOperation(ctx)
+	var o RotateOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1
+	}
+	n := len(oldIds)
+	if len(newIds) > n {
+		n = len(newIds)
+	}
+	batches := (n + o.BatchSize - 1) / o.BatchSize
+	var added, removed []string
+	for i := 0; i < batches; i++ {
+		addBatch := batch(newIds, i, o.BatchSize)
+		removeBatch := batch(oldIds, i, o.BatchSize)
+		err := elb.replaceBatch(ctx, lbName, addBatch, removeBatch, o.Waiter, &added, &removed)
+		if err != nil {
+			// The batch may have failed because ctx is done, which must
+			// not prevent the rollback.
+			rollbackCtx, cancel := rollbackContext(ctx, o.Waiter)
+			defer cancel()
+			return &RotateError{
+				Batch:       i + 1,
+				Err:         err,
+				RollbackErr: elb.undoRotation(rollbackCtx, lbName, added, removed, o.Waiter),
+			}
+		}
+		if o.Progress != nil {
+			o.Progress(RotateProgress{Batch: i + 1, Batches: batches, Added: addBatch, Removed: removeBatch})
+		}
+	}
+	return nil
+}
+
+// rollbackContext returns a context carrying the values of ctx but not its
+// cancellation or deadline, bounded by the MaxWait of cfg instead.
+func rollbackContext(ctx context.Context, cfg *WaiterConfig) (context.Context, context.CancelFunc) {
+	maxWait := DefaultWaiterConfig.MaxWait
+	if cfg != nil && cfg.MaxWait > 0 {
+		maxWait = cfg.MaxWait
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), maxWait)
+}
+
+// batch returns the i-th batch of ids, which may be empty.
+func batch(ids []string, i, size int) []string {
+	start, end := i*size, (i+1)*size
+	if start > len(ids) {
+		start = len(ids)
+	}
+	if end > len(ids) {
+		end = len(ids)
+	}
+	return ids[start:end]
+}
+
+// replaceBatch registers add and, once it's InService, deregisters remove,
+// recording the instances actually added and removed.
+func (elb *ELB) replaceBatch(ctx context.Context, lbName string, add, remove []string, cfg *WaiterConfig, added, removed *[]string) error {
+	if len(add) > 0 {
+		if _, err := elb.RegisterInstancesWithLoadBalancerWithContext(ctx, add, lbName); err != nil {
+			return err
+		}
+		*added = append(*added, add...)
+		if err := elb.WaitUntilInstanceInService(ctx, lbName, add, cfg); err != nil {
+			return err
+		}
+	}
+	if len(remove) == 0 {
+		return nil
+	}
+	// Registering the instances again on rollback is harmless if they
+	// couldn't be deregistered.
+	*removed = append(*removed, remove...)
+	return elb.DeregisterAndDrainWithContext(ctx, lbName, remove, cfg)
+}
+
+// undoRotation registers the removed instances again and, once they are
+// InService, deregisters the added ones.
+func (elb *ELB) undoRotation(ctx context.Context, lbName string, added, removed []string, cfg *WaiterConfig) error {
+	if len(removed) > 0 {
+		if _, err := elb.RegisterInstancesWithLoadBalancerWithContext(ctx, removed, lbName); err != nil {
+			return err
+		}
+		if err := elb.WaitUntilInstanceInService(ctx, lbName, removed, cfg); err != nil {
+			return err
+		}
+	}
+	if len(added) > 0 {
+		if _, err := elb.DeregisterInstancesFromLoadBalancerWithContext(ctx, added, lbName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package elb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrServerCertificateNotFound is returned, wrapped along with the name of
+// the certificate, when no IAM server certificate has the requested name.
+var ErrServerCertificateNotFound = errors.New("elb: server certificate not found")
+
+// ServerCertificate describes an IAM server certificate.
+type ServerCertificate struct {
+	Name       string
+	Id         string
+	Arn        string
+	Path       string
+	UploadDate time.Time
+	Expiration time.Time
+}
+
+// IAM is the subset of the IAM API needed to manage server certificates.
+// Implement it on top of any IAM client to use FindServerCertificate,
+// EnsureServerCertificate and ResolveCertificates.
+type IAM interface {
+	// ListServerCertificates returns all the server certificates whose
+	// path starts with pathPrefix, following the pagination of the API.
+	//
+	// See http://docs.aws.amazon.com/IAM/latest/APIReference/API_ListServerCertificates.html
+	// for more details.
+	ListServerCertificates(ctx context.Context, pathPrefix string) ([]ServerCertificate, error)
+
+	// UploadServerCertificate uploads a server certificate, its chain and
+	// its private key, all PEM encoded. chain may be empty.
+	//
+	// See http://docs.aws.amazon.com/IAM/latest/APIReference/API_UploadServerCertificate.html
+	// for more details.
+	UploadServerCertificate(ctx context.Context, name, body, chain, privateKey string) (*ServerCertificate, error)
+}
+
+// FindServerCertificate returns the IAM server certificate with the given
+// name, or an error wrapping ErrServerCertificateNotFound.
+func FindServerCertificate(ctx context.Context, iam IAM, name string) (*ServerCertificate, error) {
+	certs, err := iam.ListServerCertificates(ctx, "/")
+	if err != nil {
+		return nil, err
+	}
+	for i := range certs {
+		if certs[i].Name == name {
+			return &certs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrServerCertificateNotFound, name)
+}
+
+// EnsureServerCertificate returns the IAM server certificate with the
+// given name, uploading it first if there is none. An existing
+// certificate is returned as is, even if its body differs: IAM can't
+// change the body of an uploaded certificate.
+func EnsureServerCertificate(ctx context.Context, iam IAM, name, body, chain, privateKey string) (*ServerCertificate, error) {
+	cert, err := FindServerCertificate(ctx, iam, name)
+	if !errors.Is(err, ErrServerCertificateNotFound) {
+		return cert, err
+	}
+	return iam.UploadServerCertificate(ctx, name, body, chain, privateKey)
+}
+
+// ResolveCertificates returns a copy of listeners where the
+// SSLCertificateIds that aren't ARNs are taken as the names of IAM server
+// certificates and replaced by their ARNs, so that listeners can refer to
+// certificates by name. The server certificates are listed at most once.
+func ResolveCertificates(ctx context.Context, iam IAM, listeners []Listener) ([]Listener, error) {
+	resolved := make([]Listener, len(listeners))
+	copy(resolved, listeners)
+	var arns map[string]string
+	for i, l := range resolved {
+		if l.SSLCertificateId == "" {
+			continue
+		}
+		if _, err := ParseARN(l.SSLCertificateId); err == nil {
+			continue
+		}
+		if arns == nil {
+			certs, err := iam.ListServerCertificates(ctx, "/")
+			if err != nil {
+				return nil, err
+			}
+			arns = make(map[string]string, len(certs))
+			for _, cert := range certs {
+				arns[cert.Name] = cert.Arn
+			}
+		}
+		arn, ok := arns[l.SSLCertificateId]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrServerCertificateNotFound, l.SSLCertificateId)
+		}
+		resolved[i].SSLCertificateId = arn
+	}
+	return resolved, nil
+}
@@ -0,0 +1,77 @@
+package elb_test
+
+import (
+	"context"
+	"errors"
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+)
+
+type fakeIAM struct {
+	certs    []elb.ServerCertificate
+	lists    int
+	uploaded []string
+}
+
+func (iam *fakeIAM) ListServerCertificates(ctx context.Context, pathPrefix string) ([]elb.ServerCertificate, error) {
+	iam.lists++
+	return iam.certs, nil
+}
+
+func (iam *fakeIAM) UploadServerCertificate(ctx context.Context, name, body, chain, privateKey string) (*elb.ServerCertificate, error) {
+	iam.uploaded = append(iam.uploaded, name)
+	cert := elb.ServerCertificate{Name: name, Path: "/", Arn: elb.ServerCertificateARN("us-east-1", "123456789012", name)}
+	iam.certs = append(iam.certs, cert)
+	return &cert, nil
+}
+
+func newFakeIAM() *fakeIAM {
+	return &fakeIAM{certs: []elb.ServerCertificate{
+		{Name: "www", Path: "/", Arn: "arn:aws:iam::123456789012:server-certificate/www"},
+		{Name: "api", Path: "/", Arn: "arn:aws:iam::123456789012:server-certificate/api"},
+	}}
+}
+
+func (s *S) TestFindServerCertificate(c *C) {
+	iam := newFakeIAM()
+	cert, err := elb.FindServerCertificate(context.Background(), iam, "api")
+	c.Assert(err, IsNil)
+	c.Assert(cert.Arn, Equals, "arn:aws:iam::123456789012:server-certificate/api")
+	_, err = elb.FindServerCertificate(context.Background(), iam, "missing")
+	c.Assert(errors.Is(err, elb.ErrServerCertificateNotFound), Equals, true)
+	c.Assert(err, ErrorMatches, "elb: server certificate not found: missing")
+}
+
+func (s *S) TestEnsureServerCertificate(c *C) {
+	iam := newFakeIAM()
+	cert, err := elb.EnsureServerCertificate(context.Background(), iam, "www", "body", "", "key")
+	c.Assert(err, IsNil)
+	c.Assert(cert.Name, Equals, "www")
+	c.Assert(iam.uploaded, HasLen, 0)
+	cert, err = elb.EnsureServerCertificate(context.Background(), iam, "new", "body", "", "key")
+	c.Assert(err, IsNil)
+	c.Assert(cert.Arn, Equals, "arn:aws:iam::123456789012:server-certificate/new")
+	c.Assert(iam.uploaded, DeepEquals, []string{"new"})
+}
+
+func (s *S) TestResolveCertificates(c *C) {
+	iam := newFakeIAM()
+	listeners := []elb.Listener{
+		{Protocol: "HTTP", LoadBalancerPort: 80, InstancePort: 80},
+		{Protocol: "HTTPS", LoadBalancerPort: 443, InstancePort: 80, SSLCertificateId: "www"},
+		{Protocol: "SSL", LoadBalancerPort: 8443, InstancePort: 8080, SSLCertificateId: "api"},
+		{Protocol: "HTTPS", LoadBalancerPort: 9443, InstancePort: 80, SSLCertificateId: "arn:aws:acm:us-east-1:123456789012:certificate/abcd"},
+	}
+	resolved, err := elb.ResolveCertificates(context.Background(), iam, listeners)
+	c.Assert(err, IsNil)
+	c.Assert(resolved[0].SSLCertificateId, Equals, "")
+	c.Assert(resolved[1].SSLCertificateId, Equals, "arn:aws:iam::123456789012:server-certificate/www")
+	c.Assert(resolved[2].SSLCertificateId, Equals, "arn:aws:iam::123456789012:server-certificate/api")
+	c.Assert(resolved[3].SSLCertificateId, Equals, "arn:aws:acm:us-east-1:123456789012:certificate/abcd")
+	c.Assert(iam.lists, Equals, 1)
+	// The listeners of the caller are left untouched.
+	c.Assert(listeners[1].SSLCertificateId, Equals, "www")
+	listeners[0].SSLCertificateId = "missing"
+	_, err = elb.ResolveCertificates(context.Background(), iam, listeners)
+	c.Assert(errors.Is(err, elb.ErrServerCertificateNotFound), Equals, true)
+}
@@ -0,0 +1,183 @@
+// This package signs requests to AWS query APIs, such as Elastic Load
+// Balancing, Auto Scaling or CloudWatch, with Signature Version 2 or 4.
+//
+// It's used by the elb package, and can be used by any other client of a
+// query API, as both signatures only depend on the request and the
+// credentials.
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/flaviamissi/go-elb/aws"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var b64 = base64.StdEncoding
+
+// Version is a signature version.
+type Version int
+
+const (
+	V2 Version = 2
+	V4 Version = 4
+)
+
+// Region tells how the requests sent to a service in a region are signed.
+type Region struct {
+	// Name is the name of the region, e.g. "eu-west-1", as found in the
+	// Signature Version 4 credential scope.
+	Name string
+	// Service is the signing name of the service, e.g.
+	// "elasticloadbalancing" or "autoscaling", as found in the Signature
+	// Version 4 credential scope.
+	Service string
+	// Version is the signature version. It defaults to V4, which all the
+	// regions accept.
+	Version Version
+}
+
+// Sign signs req in place for region, as of t.
+//
+// With Signature Version 2, the AWSAccessKeyId, SignatureVersion,
+// SignatureMethod and Signature parameters, and SecurityToken for
+// temporary credentials, are added to the query string of req. The other
+// parameters, including Timestamp, must already be set, and t is unused.
+//
+// With Signature Version 4, the X-Amz-Date and Authorization headers, and
+// X-Amz-Security-Token for temporary credentials, are added to req. The
+// query string of req must already be in its final form, and its body
+// empty.
+//
+// See http://docs.aws.amazon.com/general/latest/gr/signature-version-2.html
+// and http://docs.aws.amazon.com/general/latest/gr/signature-version-4.html
+// for more details.
+func Sign(req *http.Request, auth aws.Auth, region Region, t time.Time) {
+	if region.Version == V2 {
+		signV2(req, auth)
+		return
+	}
+	signV4(req, auth, region.Name, region.Service, t)
+}
+
+func signV2(req *http.Request, auth aws.Auth) {
+	params := req.URL.Query()
+	params.Set("AWSAccessKeyId", auth.AccessKey)
+	params.Set("SignatureVersion", "2")
+	params.Set("SignatureMethod", "HmacSHA256")
+	if auth.Token != "" {
+		params.Set("SecurityToken", auth.Token)
+	}
+	params.Del("Signature")
+	payload := req.Method + "\n" + host(req) + "\n" + path(req) + "\n" + canonicalQuery(params)
+	hash := hmac.New(sha256.New, []byte(auth.SecretKey))
+	hash.Write([]byte(payload))
+	params.Set("Signature", b64.EncodeToString(hash.Sum(nil)))
+	req.URL.RawQuery = params.Encode()
+}
+
+const (
+	v4Algorithm  = "AWS4-HMAC-SHA256"
+	v4DateFormat = "20060102T150405Z"
+)
+
+func signV4(req *http.Request, auth aws.Auth, region, service string, t time.Time) {
+	t = t.In(time.UTC)
+	date := t.Format(v4DateFormat)
+	req.Header.Set("X-Amz-Date", date)
+	if auth.Token != "" {
+		req.Header.Set("X-Amz-Security-Token", auth.Token)
+	}
+
+	var names []string
+	headers := map[string]string{"host": host(req)}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		headers[name] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders []string
+	for _, name := range names {
+		canonicalHeaders = append(canonicalHeaders, name+":"+headers[name]+"\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path(req),
+		canonicalQuery(req.URL.Query()),
+		strings.Join(canonicalHeaders, ""),
+		signedHeaders,
+		hexSHA256(""),
+	}, "\n")
+
+	scope := strings.Join([]string{date[:8], region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		v4Algorithm,
+		date,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+auth.SecretKey), date[:8])
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", v4Algorithm+
+		" Credential="+auth.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+}
+
+func host(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+func path(req *http.Request) string {
+	if req.URL.Path == "" {
+		return "/"
+	}
+	return req.URL.Path
+}
+
+func canonicalQuery(values url.Values) string {
+	var keys, sarray []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			sarray = append(sarray, aws.Encode(k)+"="+aws.Encode(v))
+		}
+	}
+	return strings.Join(sarray, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	hash := hmac.New(sha256.New, key)
+	hash.Write([]byte(data))
+	return hash.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	hash := sha256.New()
+	hash.Write([]byte(data))
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,145 @@
+package sign_test
+
+import (
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb/sign"
+	. "launchpad.net/gocheck"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+var testAuth = aws.Auth{AccessKey: "user", SecretKey: "secret"}
+
+var v2Region = sign.Region{Name: "us-east-1", Service: "service", Version: sign.V2}
+
+// v2Vectors are golden Signature Version 2 signatures, the last one being
+// the example of the SimpleDB documentation.
+var v2Vectors = []struct {
+	name      string
+	auth      aws.Auth
+	url       string
+	signature string
+}{
+	{"basic", testAuth, "http://localhost/path", "6lSe5QyXum0jMVc7cOUz32/52ZnL7N5RyKRk/09yiK4="},
+	{"params", testAuth, "http://localhost/path?param1=value1&param2=value2&param3=value3", "XWOR4+0lmK8bD8CGDGZ4kfuSPbb2JibLJiCl/OPu1oU="},
+	{
+		"many params",
+		testAuth,
+		"http://localhost/path?param1=value10&param2=value2&param3=value3&param4=value4&param5=value5" +
+			"&param6=value6&param7=value7&param8=value8&param9=value9&param10=value1",
+		"di0sjxIvezUgQ1SIL6i+C/H8lL+U0CQ9frLIak8jkVg=",
+	},
+	{"escaping", testAuth, "http://localhost/path?Nonce=%2B+%2B", "bqffDELReIqwjg/W0DnsnVUmfLK4wXVLO4/LuG+1VFA="},
+	{
+		"simpledb example",
+		aws.Auth{AccessKey: "access", SecretKey: "secret"},
+		"https://sdb.amazonaws.com/?Timestamp=2009-02-01T12%3A53%3A20%2B00%3A00&Version=2007-11-07&Action=ListDomains",
+		"okj96/5ucWBSc1uR2zXVfm6mDHtgfNv657rRtt/aunQ=",
+	},
+}
+
+func (s *S) TestSignV2(c *C) {
+	for _, v := range v2Vectors {
+		req, err := http.NewRequest("GET", v.url, nil)
+		c.Assert(err, IsNil)
+		sign.Sign(req, v.auth, sign.Region{Version: sign.V2}, time.Time{})
+		params := req.URL.Query()
+		c.Assert(params.Get("SignatureVersion"), Equals, "2")
+		c.Assert(params.Get("SignatureMethod"), Equals, "HmacSHA256")
+		c.Assert(params.Get("AWSAccessKeyId"), Equals, v.auth.AccessKey)
+		c.Assert(params.Get("Signature"), Equals, v.signature, Commentf("vector %q", v.name))
+	}
+}
+
+func (s *S) TestSignV2KeepsParams(c *C) {
+	req, err := http.NewRequest("GET", "http://localhost/path?Nonce=%2B+%2B", nil)
+	c.Assert(err, IsNil)
+	sign.Sign(req, testAuth, v2Region, time.Time{})
+	c.Assert(req.URL.Query().Get("Nonce"), Equals, "+ +")
+	// Signing again replaces the signature.
+	sign.Sign(req, testAuth, v2Region, time.Time{})
+	c.Assert(req.URL.Query()["Signature"], DeepEquals, []string{"bqffDELReIqwjg/W0DnsnVUmfLK4wXVLO4/LuG+1VFA="})
+}
+
+func (s *S) TestSignV2WithSecurityToken(c *C) {
+	req, err := http.NewRequest("GET", "http://localhost/path", nil)
+	c.Assert(err, IsNil)
+	sign.Sign(req, aws.Auth{AccessKey: "user", SecretKey: "secret", Token: "token"}, v2Region, time.Time{})
+	c.Assert(req.URL.Query().Get("SecurityToken"), Equals, "token")
+	c.Assert(req.URL.Query().Get("Signature"), Not(Equals), "6lSe5QyXum0jMVc7cOUz32/52ZnL7N5RyKRk/09yiK4=")
+}
+
+var v4Auth = aws.Auth{AccessKey: "AKIDEXAMPLE", SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+// v4Vectors are golden Signature Version 4 signatures: the first ones come
+// from the AWS Signature Version 4 test suite, and the last one is the
+// IAM example of the signing documentation.
+var v4Vectors = []struct {
+	name          string
+	url           string
+	header        map[string]string
+	region        string
+	service       string
+	signedHeaders string
+	signature     string
+}{
+	{
+		"get-vanilla",
+		"https://example.amazonaws.com/",
+		nil,
+		"us-east-1", "service",
+		"host;x-amz-date",
+		"5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+	},
+	{
+		"get-vanilla-query-order-key-case",
+		"https://example.amazonaws.com/?Param2=value2&Param1=value1",
+		nil,
+		"us-east-1", "service",
+		"host;x-amz-date",
+		"b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+	},
+	{
+		"iam list users",
+		"https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+		"us-east-1", "iam",
+		"content-type;host;x-amz-date",
+		"5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+	},
+}
+
+func (s *S) TestSignV4(c *C) {
+	t := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	for _, v := range v4Vectors {
+		req, err := http.NewRequest("GET", v.url, nil)
+		c.Assert(err, IsNil)
+		for k, value := range v.header {
+			req.Header.Set(k, value)
+		}
+		sign.Sign(req, v4Auth, sign.Region{Name: v.region, Service: v.service}, t)
+		c.Assert(req.Header.Get("X-Amz-Date"), Equals, "20150830T123600Z")
+		expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/" + v.region + "/" + v.service + "/aws4_request, " +
+			"SignedHeaders=" + v.signedHeaders + ", Signature=" + v.signature
+		c.Assert(req.Header.Get("Authorization"), Equals, expected, Commentf("vector %q", v.name))
+	}
+}
+
+func (s *S) TestSignWithSecurityToken(c *C) {
+	req, err := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	c.Assert(err, IsNil)
+	auth := v4Auth
+	auth.Token = "token"
+	sign.Sign(req, auth, sign.Region{Name: "us-east-1", Service: "service", Version: sign.V4}, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	c.Assert(req.Header.Get("X-Amz-Security-Token"), Equals, "token")
+	c.Assert(req.Header.Get("Authorization"), Matches, ".*SignedHeaders=host;x-amz-date;x-amz-security-token, .*")
+}
@@ -0,0 +1,107 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// SSLNegotiationPolicyType is the type of the policies that set the TLS
+// versions and ciphers negotiated by HTTPS and SSL listeners.
+const SSLNegotiationPolicyType = "SSLNegotiationPolicyType"
+
+// Some of the security policies predefined by AWS. The complete list is
+// returned by PredefinedSSLPolicies.
+const (
+	SecurityPolicy2016  = "ELBSecurityPolicy-2016-08"
+	SecurityPolicyTLS11 = "ELBSecurityPolicy-TLS-1-1-2017-01"
+	SecurityPolicyTLS12 = "ELBSecurityPolicy-TLS-1-2-2017-01"
+)
+
+// PredefinedSSLPolicies returns the names of the security policies
+// predefined by AWS, sorted.
+func (elb *ELB) PredefinedSSLPolicies() ([]string, error) {
+	return elb.PredefinedSSLPoliciesWithContext(context.Background())
+}
+
+// PredefinedSSLPoliciesWithContext is like PredefinedSSLPolicies, but the
+// request is bound to ctx.
+func (elb *ELB) PredefinedSSLPoliciesWithContext(ctx context.Context) ([]string, error) {
+	resp, err := elb.DescribeLoadBalancerPoliciesWithContext(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, p := range resp.PolicyDescriptions {
+		if p.PolicyTypeName == SSLNegotiationPolicyType {
+			names = append(names, p.PolicyName)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// CreateSSLPolicy creates a policy on the Load Balancer with the same TLS
+// versions and ciphers as the predefined security policy referencePolicy.
+func (elb *ELB) CreateSSLPolicy(lbName, policyName, referencePolicy string) error {
+	return elb.CreateSSLPolicyWithContext(context.Background(), lbName, policyName, referencePolicy)
+}
+
+// CreateSSLPolicyWithContext is like CreateSSLPolicy, but the request is
+// bound to ctx.
+func (elb *ELB) CreateSSLPolicyWithContext(ctx context.Context, lbName, policyName, referencePolicy string) error {
+	attrs := []PolicyAttribute{{AttributeName: "Reference-Security-Policy", AttributeValue: referencePolicy}}
+	_, err := elb.CreateLoadBalancerPolicyWithContext(ctx, lbName, policyName, SSLNegotiationPolicyType, attrs)
+	return err
+}
+
+// SetListenerSSLPolicy makes the HTTPS or SSL listener bound to port
+// negotiate connections according to the predefined security policy
+// referencePolicy.
+//
+// A policy named after referencePolicy is created on the Load Balancer if
+// needed. It replaces the security policy previously enabled for the
+// listener, while its other policies, like stickiness, are kept.
+func (elb *ELB) SetListenerSSLPolicy(lbName string, port int, referencePolicy string) error {
+	return elb.SetListenerSSLPolicyWithContext(context.Background(), lbName, port, referencePolicy)
+}
+
+// SetListenerSSLPolicyWithContext is like SetListenerSSLPolicy, but the
+// requests are bound to ctx.
+func (elb *ELB) SetListenerSSLPolicyWithContext(ctx context.Context, lbName string, port int, referencePolicy string) error {
+	ctx = withOperation(ctx)
+	resp, err := elb.DescribeLoadBalancersWithContext(ctx, lbName)
+	if err != nil {
+		return err
+	}
+	var listener *ListenerDescription
+	for _, desc := range resp.LoadBalancerDescriptions {
+		for i, l := range desc.ListenerDescriptions {
+			if l.Listener.LoadBalancerPort == port {
+				listener = &desc.ListenerDescriptions[i]
+			}
+		}
+	}
+	if listener == nil {
+		return fmt.Errorf("elb: Load Balancer %s has no listener on port %d", lbName, port)
+	}
+	err = elb.CreateSSLPolicyWithContext(ctx, lbName, referencePolicy, referencePolicy)
+	if err != nil && ErrorCode(err) != ErrDuplicatePolicyName {
+		return err
+	}
+	var policies []string
+	if len(listener.PolicyNames) > 0 {
+		current, err := elb.DescribeLoadBalancerPoliciesWithContext(ctx, lbName, listener.PolicyNames...)
+		if err != nil {
+			return err
+		}
+		for _, p := range current.PolicyDescriptions {
+			if p.PolicyTypeName != SSLNegotiationPolicyType {
+				policies = append(policies, p.PolicyName)
+			}
+		}
+	}
+	policies = append(policies, referencePolicy)
+	_, err = elb.SetLoadBalancerPoliciesOfListenerWithContext(ctx, lbName, port, policies)
+	return err
+}
@@ -0,0 +1,126 @@
+package elb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxTargetPathLength is the maximum length of the path of a health check
+// target accepted by AWS.
+const maxTargetPathLength = 1024
+
+// Target is a health check target, like HTTP:80/ping, split into its
+// parts. Its String method formats it for HealthCheck.Target.
+type Target struct {
+	Protocol string
+	Port     int
+
+	// Path is required for HTTP and HTTPS targets, and must begin with a
+	// slash. TCP and SSL targets have no path.
+	Path string
+}
+
+// TargetError is returned by ParseTarget and Target.Validate when a health
+// check target is invalid.
+type TargetError struct {
+	Target string
+	Reason string
+}
+
+func (e *TargetError) Error() string {
+	return fmt.Sprintf("elb: invalid health check target %q: %s", e.Target, e.Reason)
+}
+
+// HTTPTarget returns the target of a health check requesting path on port
+// with HTTP. An empty path checks the root path.
+func HTTPTarget(port int, path string) string {
+	return Target{Protocol: ProtocolHTTP, Port: port, Path: targetPath(path)}.String()
+}
+
+// HTTPSTarget is like HTTPTarget, but the request is made with HTTPS.
+func HTTPSTarget(port int, path string) string {
+	return Target{Protocol: ProtocolHTTPS, Port: port, Path: targetPath(path)}.String()
+}
+
+// TCPTarget returns the target of a health check opening a TCP connection
+// to port.
+func TCPTarget(port int) string {
+	return Target{Protocol: ProtocolTCP, Port: port}.String()
+}
+
+// SSLTarget returns the target of a health check completing an SSL
+// handshake on port.
+func SSLTarget(port int) string {
+	return Target{Protocol: ProtocolSSL, Port: port}.String()
+}
+
+func targetPath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// ParseTarget parses a health check target like HTTP:80/ping, checking it
+// would be accepted by AWS, see Target.Validate. The protocol is matched
+// case-insensitively and normalized by NormalizeProtocol.
+func ParseTarget(s string) (Target, error) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return Target{}, &TargetError{Target: s, Reason: "missing port"}
+	}
+	t := Target{Protocol: NormalizeProtocol(s[:i])}
+	port := s[i+1:]
+	if j := strings.Index(port, "/"); j >= 0 {
+		port, t.Path = port[:j], port[j:]
+	}
+	var err error
+	if t.Port, err = strconv.Atoi(port); err != nil {
+		return Target{}, &TargetError{Target: s, Reason: fmt.Sprintf("invalid port %q", port)}
+	}
+	if reason := t.invalidReason(); reason != "" {
+		return Target{}, &TargetError{Target: s, Reason: reason}
+	}
+	return t, nil
+}
+
+// Validate checks that t would be accepted by AWS: its protocol must be
+// HTTP, HTTPS, TCP or SSL, its port between 1 and 65535, and HTTP and
+// HTTPS targets need a path beginning with a slash, which TCP and SSL
+// targets can't have.
+func (t Target) Validate() error {
+	if reason := t.invalidReason(); reason != "" {
+		return &TargetError{Target: t.String(), Reason: reason}
+	}
+	return nil
+}
+
+func (t Target) invalidReason() string {
+	protocol := NormalizeProtocol(t.Protocol)
+	if !listenerProtocols[protocol] {
+		return fmt.Sprintf("unsupported protocol %q, must be one of HTTP, HTTPS, TCP or SSL", t.Protocol)
+	}
+	if t.Port < 1 || t.Port > 65535 {
+		return fmt.Sprintf("port %d out of range 1-65535", t.Port)
+	}
+	switch protocol {
+	case ProtocolHTTP, ProtocolHTTPS:
+		if !strings.HasPrefix(t.Path, "/") {
+			return fmt.Sprintf("%s targets need a path beginning with a slash", protocol)
+		}
+		if len(t.Path) > maxTargetPathLength {
+			return fmt.Sprintf("path longer than %d characters", maxTargetPathLength)
+		}
+	default:
+		if t.Path != "" {
+			return fmt.Sprintf("%s targets can't have a path", protocol)
+		}
+	}
+	return ""
+}
+
+// String formats t like HTTP:80/ping.
+func (t Target) String() string {
+	return fmt.Sprintf("%s:%d%s", t.Protocol, t.Port, t.Path)
+}
@@ -0,0 +1,59 @@
+package elb_test
+
+import (
+	"github.com/flaviamissi/go-elb/elb"
+	. "launchpad.net/gocheck"
+	"strings"
+)
+
+func (s *S) TestTargetBuilders(c *C) {
+	c.Assert(elb.HTTPTarget(80, "/ping"), Equals, "HTTP:80/ping")
+	c.Assert(elb.HTTPTarget(8080, "health"), Equals, "HTTP:8080/health")
+	c.Assert(elb.HTTPTarget(80, ""), Equals, "HTTP:80/")
+	c.Assert(elb.HTTPSTarget(443, "/ping"), Equals, "HTTPS:443/ping")
+	c.Assert(elb.TCPTarget(22), Equals, "TCP:22")
+	c.Assert(elb.SSLTarget(443), Equals, "SSL:443")
+}
+
+func (s *S) TestParseTarget(c *C) {
+	t, err := elb.ParseTarget("http:80/ping/this/path")
+	c.Assert(err, IsNil)
+	c.Assert(t, Equals, elb.Target{Protocol: elb.ProtocolHTTP, Port: 80, Path: "/ping/this/path"})
+	c.Assert(t.String(), Equals, "HTTP:80/ping/this/path")
+	t, err = elb.ParseTarget("TCP:22")
+	c.Assert(err, IsNil)
+	c.Assert(t, Equals, elb.Target{Protocol: elb.ProtocolTCP, Port: 22})
+	for _, target := range []string{elb.HTTPTarget(80, "/"), elb.HTTPSTarget(443, "/a"), elb.TCPTarget(1), elb.SSLTarget(65535)} {
+		t, err := elb.ParseTarget(target)
+		c.Assert(err, IsNil)
+		c.Assert(t.String(), Equals, target)
+	}
+}
+
+func (s *S) TestParseTargetInvalid(c *C) {
+	tests := []struct {
+		target string
+		err    string
+	}{
+		{"HTTP", `missing port`},
+		{"HTTP:/ping", `invalid port ""`},
+		{"HTTP:eighty/ping", `invalid port "eighty"`},
+		{"UDP:53", `unsupported protocol "UDP", must be one of HTTP, HTTPS, TCP or SSL`},
+		{"HTTP:0/ping", `port 0 out of range 1-65535`},
+		{"TCP:65536", `port 65536 out of range 1-65535`},
+		{"HTTP:80", `HTTP targets need a path beginning with a slash`},
+		{"https:443", `HTTPS targets need a path beginning with a slash`},
+		{"TCP:80/ping", `TCP targets can't have a path`},
+		{"SSL:443/", `SSL targets can't have a path`},
+		{"HTTP:80/" + strings.Repeat("a", 1024), `path longer than 1024 characters`},
+	}
+	for _, t := range tests {
+		_, err := elb.ParseTarget(t.target)
+		c.Check(err, ErrorMatches, `elb: invalid health check target ".*": `+t.err, Commentf("%s", t.target))
+		_, ok := err.(*elb.TargetError)
+		c.Check(ok, Equals, true)
+	}
+	err := elb.Target{Protocol: "TCP", Port: 80, Path: "/ping"}.Validate()
+	c.Assert(err, ErrorMatches, `elb: invalid health check target "TCP:80/ping": TCP targets can't have a path`)
+	c.Assert(elb.Target{Protocol: "HTTP", Port: 80, Path: "/"}.Validate(), IsNil)
+}
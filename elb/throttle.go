@@ -0,0 +1,87 @@
+package elb
+
+import (
+	"sync"
+	"time"
+)
+
+// ThrottleObserver is notified of the retries made by a client, allowing
+// callers to see when the rate limits of AWS, rather than their own load,
+// bound the throughput of their requests.
+type ThrottleObserver interface {
+	// OnRetry is called when a request for the given action failed with a
+	// retryable error, before waiting delay to retry it. attempt is the
+	// number of the failed attempt, starting at 1.
+	OnRetry(action string, attempt int, delay time.Duration, err error)
+	// OnGiveUp is called when a request for the given action failed with
+	// a retryable error, but the retry policy allows no more attempts.
+	OnGiveUp(action string, attempts int, err error)
+}
+
+// WithThrottleObserver makes the client report its retries to o.
+func WithThrottleObserver(o ThrottleObserver) Option {
+	return func(elb *ELB) {
+		elb.throttleObserver = o
+	}
+}
+
+// ThrottleCounters holds the retries made for an action, as counted by
+// ThrottleStats.
+type ThrottleCounters struct {
+	// Retries is the number of retries performed, and Throttled the number
+	// of them caused by throttling errors, see IsThrottling.
+	Retries   int64
+	Throttled int64
+	// Backoff is the total time spent waiting before retrying.
+	Backoff time.Duration
+	// GiveUps is the number of requests that failed after exhausting
+	// their attempts.
+	GiveUps int64
+}
+
+// ThrottleStats is a ThrottleObserver that counts retries, time spent
+// backing off and give-ups by action. It's safe for concurrent use, and
+// the zero value is ready to use.
+type ThrottleStats struct {
+	mutex    sync.Mutex
+	counters map[string]ThrottleCounters
+}
+
+func (s *ThrottleStats) OnRetry(action string, attempt int, delay time.Duration, err error) {
+	s.update(action, func(c *ThrottleCounters) {
+		c.Retries++
+		if IsThrottling(err) {
+			c.Throttled++
+		}
+		c.Backoff += delay
+	})
+}
+
+func (s *ThrottleStats) OnGiveUp(action string, attempts int, err error) {
+	s.update(action, func(c *ThrottleCounters) {
+		c.GiveUps++
+	})
+}
+
+func (s *ThrottleStats) update(action string, f func(*ThrottleCounters)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.counters == nil {
+		s.counters = make(map[string]ThrottleCounters)
+	}
+	c := s.counters[action]
+	f(&c)
+	s.counters[action] = c
+}
+
+// Counters returns a copy of the counters, keyed by action. Actions
+// without retries are absent.
+func (s *ThrottleStats) Counters() map[string]ThrottleCounters {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	counters := make(map[string]ThrottleCounters, len(s.counters))
+	for action, c := range s.counters {
+		counters[action] = c
+	}
+	return counters
+}
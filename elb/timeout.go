@@ -0,0 +1,72 @@
+package elb
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TimeoutConfig bounds the time spent by the requests of a client in each
+// of their phases. Zero fields leave the phase unbounded, except Connect
+// and TLSHandshake which keep the defaults of http.DefaultTransport.
+type TimeoutConfig struct {
+	// Connect bounds the time to establish a TCP connection.
+	Connect time.Duration
+	// TLSHandshake bounds the time to complete the TLS handshake.
+	TLSHandshake time.Duration
+	// ResponseHeader bounds the time to receive the headers of a response
+	// once the request is sent, leaving the body unbounded so that big
+	// responses can be read at the pace of the network.
+	ResponseHeader time.Duration
+
+	// Operation bounds each call of the client as a whole, its retries
+	// and the delays between them included.
+	Operation time.Duration
+	// Actions overrides Operation for the given actions, like a longer
+	// deadline for DescribeLoadBalancers, which may return big responses,
+	// and a shorter one for DescribeInstanceHealth, which is polled.
+	Actions map[string]time.Duration
+}
+
+// WithTimeouts bounds the time spent by the requests of the client
+// according to cfg. The Connect, TLSHandshake and ResponseHeader timeouts
+// configure the HTTP transport created by New, so they have no effect
+// along with WithHTTPClient.
+func WithTimeouts(cfg TimeoutConfig) Option {
+	return func(elb *ELB) {
+		elb.timeouts = &cfg
+	}
+}
+
+// applyTimeouts configures the dial, TLS handshake and response header
+// timeouts of t from cfg.
+func applyTimeouts(t *http.Transport, cfg *TimeoutConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Connect > 0 {
+		dialer := &net.Dialer{Timeout: cfg.Connect, KeepAlive: 30 * time.Second}
+		t.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshake > 0 {
+		t.TLSHandshakeTimeout = cfg.TLSHandshake
+	}
+	t.ResponseHeaderTimeout = cfg.ResponseHeader
+}
+
+// operationContext returns ctx bound by the deadline of action, if the
+// client has one.
+func (elb *ELB) operationContext(ctx context.Context, action string) (context.Context, context.CancelFunc) {
+	if elb.timeouts == nil {
+		return ctx, func() {}
+	}
+	d, ok := elb.timeouts.Actions[action]
+	if !ok {
+		d = elb.timeouts.Operation
+	}
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
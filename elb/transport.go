@@ -0,0 +1,57 @@
+package elb
+
+import (
+	"net/http"
+	"time"
+)
+
+// TransportConfig tunes the HTTP transport created by New for each client
+// that isn't given its own HTTP client with WithHTTPClient. The transport
+// keeps connections to the endpoint open between requests, saving the TLS
+// handshake of each call, and is shared by all the operations of the
+// client.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept
+	// open to the endpoint. It bounds the number of concurrent requests
+	// that don't need a new connection.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// HTTP2 makes the transport negotiate HTTP/2 with endpoints that
+	// support it, multiplexing concurrent requests on a single connection.
+	HTTP2 bool
+	// DisableKeepAlives opens a new connection for each request.
+	DisableKeepAlives bool
+}
+
+// DefaultTransportConfig is the configuration used by clients created
+// without WithTransport. It mirrors the defaults of the AWS SDKs.
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConnsPerHost: 10,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// WithTransport makes New create the HTTP transport of the client from
+// cfg. It has no effect along with WithHTTPClient.
+func WithTransport(cfg TransportConfig) Option {
+	return func(elb *ELB) {
+		elb.transportConfig = &cfg
+	}
+}
+
+// newTransport returns a transport configured from cfg, keeping the other
+// settings, like proxies and dial timeouts, of http.DefaultTransport.
+func newTransport(cfg *TransportConfig) *http.Transport {
+	if cfg == nil {
+		cfg = &DefaultTransportConfig
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	if t.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+		t.MaxIdleConns = cfg.MaxIdleConnsPerHost
+	}
+	t.IdleConnTimeout = cfg.IdleConnTimeout
+	t.ForceAttemptHTTP2 = cfg.HTTP2
+	t.DisableKeepAlives = cfg.DisableKeepAlives
+	return t
+}
@@ -0,0 +1,146 @@
+package elb_test
+
+import (
+	"context"
+	"errors"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elb/elbtest"
+	. "launchpad.net/gocheck"
+	"net"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TransportSuite sends requests to a fake server over TLS, where reusing
+// connections saves a handshake per call.
+type TransportSuite struct {
+	srv  *elbtest.Server
+	auth aws.Auth
+}
+
+var _ = Suite(&TransportSuite{})
+
+func (s *TransportSuite) SetUpSuite(c *C) {
+	srv, err := elbtest.NewTLSServer()
+	c.Assert(err, IsNil)
+	s.srv = srv
+	s.auth = aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	s.srv.NewLoadBalancer("testlb")
+}
+
+func (s *TransportSuite) TearDownSuite(c *C) {
+	s.srv.Quit()
+}
+
+func (s *TransportSuite) client(options ...elb.Option) *elb.ELB {
+	options = append(options, elb.WithInsecureSkipVerify())
+	return elb.New(s.auth, aws.Region{ELBEndpoint: s.srv.URL()}, options...)
+}
+
+// reusedConns sends n requests and returns how many of them reused a
+// connection.
+func reusedConns(c *C, e *elb.ELB, n int) int {
+	var reused int32
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				atomic.AddInt32(&reused, 1)
+			}
+		},
+	})
+	for i := 0; i < n; i++ {
+		_, err := e.DescribeLoadBalancersWithContext(ctx, "testlb")
+		c.Assert(err, IsNil)
+	}
+	return int(atomic.LoadInt32(&reused))
+}
+
+func (s *TransportSuite) TestConnectionReuse(c *C) {
+	c.Assert(reusedConns(c, s.client(), 3), Equals, 2)
+	noKeepAlive := elb.WithTransport(elb.TransportConfig{DisableKeepAlives: true})
+	c.Assert(reusedConns(c, s.client(noKeepAlive), 3), Equals, 0)
+}
+
+func (s *TransportSuite) TestOperationTimeout(c *C) {
+	s.srv.SetLatency("DescribeLoadBalancers", 200*time.Millisecond)
+	defer s.srv.SetLatency("DescribeLoadBalancers", 0)
+	e := s.client(elb.WithTimeouts(elb.TimeoutConfig{
+		Operation: 50 * time.Millisecond,
+		Actions:   map[string]time.Duration{"DescribeInstanceHealth": time.Second},
+	}))
+	_, err := e.DescribeLoadBalancers("testlb")
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true, Commentf("%v", err))
+	_, err = e.DescribeInstanceHealth("testlb")
+	c.Assert(err, IsNil)
+
+	e = s.client(elb.WithTimeouts(elb.TimeoutConfig{
+		Operation: 50 * time.Millisecond,
+		Actions:   map[string]time.Duration{"DescribeLoadBalancers": time.Second},
+	}))
+	_, err = e.DescribeLoadBalancers("testlb")
+	c.Assert(err, IsNil)
+}
+
+func (s *TransportSuite) TestResponseHeaderTimeout(c *C) {
+	s.srv.SetLatency("DescribeLoadBalancers", 200*time.Millisecond)
+	defer s.srv.SetLatency("DescribeLoadBalancers", 0)
+	e := s.client(
+		elb.WithTimeouts(elb.TimeoutConfig{ResponseHeader: 50 * time.Millisecond}),
+		elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1}),
+	)
+	_, err := e.DescribeLoadBalancers("testlb")
+	c.Assert(err, ErrorMatches, ".*timeout awaiting response headers.*")
+}
+
+func (s *TransportSuite) TestTLSHandshakeTimeout(c *C) {
+	// A listener accepting connections without ever answering the
+	// handshake.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	defer l.Close()
+	go func() {
+		// The connections are kept open until the listener is closed.
+		var conns []net.Conn
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				break
+			}
+			conns = append(conns, conn)
+		}
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	e := elb.New(s.auth, aws.Region{ELBEndpoint: "https://" + l.Addr().String()},
+		elb.WithTimeouts(elb.TimeoutConfig{TLSHandshake: 50 * time.Millisecond}),
+		elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1}),
+	)
+	start := time.Now()
+	_, err = e.DescribeLoadBalancers("testlb")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "TLS handshake timeout"), Equals, true, Commentf("%v", err))
+	c.Assert(time.Since(start) < time.Second, Equals, true)
+}
+
+// BenchmarkKeepAlive and BenchmarkNewConnection compare the latency of
+// calls sharing connections with the one of calls doing a TLS handshake
+// each. Run them with go test -gocheck.b.
+func (s *TransportSuite) BenchmarkKeepAlive(c *C) {
+	benchmarkDescribe(c, s.client())
+}
+
+func (s *TransportSuite) BenchmarkNewConnection(c *C) {
+	benchmarkDescribe(c, s.client(elb.WithTransport(elb.TransportConfig{DisableKeepAlives: true})))
+}
+
+func benchmarkDescribe(c *C, e *elb.ELB) {
+	for i := 0; i < c.N; i++ {
+		if _, err := e.DescribeLoadBalancers("testlb"); err != nil {
+			c.Fatal(err)
+		}
+	}
+}
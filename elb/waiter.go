@@ -0,0 +1,115 @@
+package elb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrWaitTimeout is returned by waiters when the awaited condition isn't
+// met within the configured maximum wait.
+var ErrWaitTimeout = errors.New("elb: timed out waiting for condition")
+
+// WaiterConfig controls how a waiter polls ELB.
+type WaiterConfig struct {
+	// Delay is the time between two polls. It defaults to 15 seconds.
+	Delay time.Duration
+	// MaxWait is the maximum time spent waiting for the condition. It
+	// defaults to 10 minutes.
+	MaxWait time.Duration
+}
+
+// DefaultWaiterConfig is used by waiters given a nil configuration.
+var DefaultWaiterConfig = WaiterConfig{
+	Delay:   15 * time.Second,
+	MaxWait: 10 * time.Minute,
+}
+
+// wait calls done every cfg.Delay until it returns true or an error, ctx is
+// done or cfg.MaxWait is exceeded.
+func (elb *ELB) wait(ctx context.Context, cfg *WaiterConfig, done func(context.Context) (bool, error)) error {
+	c := DefaultWaiterConfig
+	if cfg != nil {
+		if cfg.Delay > 0 {
+			c.Delay = cfg.Delay
+		}
+		if cfg.MaxWait > 0 {
+			c.MaxWait = cfg.MaxWait
+		}
+	}
+	deadline := time.Now().Add(c.MaxWait)
+	for {
+		ok, err := done(ctx)
+		if err != nil || ok {
+			return err
+		}
+		if time.Now().Add(c.Delay).After(deadline) {
+			return ErrWaitTimeout
+		}
+		if err := sleepContext(ctx, c.Delay); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitUntilInstanceInService waits until all the given instances are
+// registered with the Load Balancer and in the InService state.
+func (elb *ELB) WaitUntilInstanceInService(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig) error {
+	return elb.waitForInstanceState(ctx, lbName, instanceIds, StateInService, cfg)
+}
+
+// WaitUntilInstanceOutOfService waits until none of the given instances is
+// in the InService state. Instances that are no longer registered with the
+// Load Balancer are considered out of service.
+func (elb *ELB) WaitUntilInstanceOutOfService(ctx context.Context, lbName string, instanceIds []string, cfg *WaiterConfig) error {
+	return elb.waitForInstanceState(ctx, lbName, instanceIds, StateOutOfService, cfg)
+}
+
+func (elb *ELB) waitForInstanceState(ctx context.Context, lbName string, instanceIds []string, state HealthState, cfg *WaiterConfig) error {
+	return elb.wait(ctx, cfg, func(ctx context.Context) (bool, error) {
+		resp, err := elb.DescribeInstanceHealthWithContext(ctx, lbName)
+		if err != nil {
+			return false, err
+		}
+		// Instances aren't given to DescribeInstanceHealth, as AWS fails
+		// for the ones that aren't registered.
+		states := make(map[string]HealthState, len(resp.InstanceStates))
+		for _, s := range resp.InstanceStates {
+			states[s.InstanceId] = s.State
+		}
+		for _, id := range instanceIds {
+			current, ok := states[id]
+			if state == StateInService && current != StateInService {
+				return false, nil
+			}
+			if state == StateOutOfService && ok && current == StateInService {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// WaitUntilLoadBalancerExists waits until the named Load Balancer can be
+// described.
+func (elb *ELB) WaitUntilLoadBalancerExists(ctx context.Context, lbName string, cfg *WaiterConfig) error {
+	return elb.wait(ctx, cfg, func(ctx context.Context) (bool, error) {
+		_, err := elb.DescribeLoadBalancersWithContext(ctx, lbName)
+		if IsLoadBalancerNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// WaitUntilLoadBalancerDeleted waits until the named Load Balancer no
+// longer exists.
+func (elb *ELB) WaitUntilLoadBalancerDeleted(ctx context.Context, lbName string, cfg *WaiterConfig) error {
+	return elb.wait(ctx, cfg, func(ctx context.Context) (bool, error) {
+		_, err := elb.DescribeLoadBalancersWithContext(ctx, lbName)
+		if IsLoadBalancerNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
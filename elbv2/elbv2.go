@@ -0,0 +1,548 @@
+// This package provides types and functions to interact with version 2 of
+// the Elastic Load Balancing API (2015-12-01), which manages Application
+// and Network Load Balancers.
+//
+// Requests go through the transport of the elb package: signing,
+// credentials, retries and the HTTP client are configured with the same
+// elb.Option values.
+package elbv2
+
+import (
+	"context"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"time"
+)
+
+const apiVersion = "2015-12-01"
+
+type ELBV2 struct {
+	elb *elb.ELB
+}
+
+// New creates a new ELBV2 client for the given region.
+//
+// Version 2 of the API only accepts requests signed with Signature Version
+// 4, which is always used regardless of the options.
+func New(auth aws.Auth, region aws.Region, options ...elb.Option) *ELBV2 {
+	options = append(options, elb.WithSignatureVersion(elb.SignatureV4))
+	return &ELBV2{elb: elb.New(auth, region, options...)}
+}
+
+func (c *ELBV2) query(ctx context.Context, params map[string]string, resp interface{}) error {
+	return c.elb.Query(ctx, apiVersion, params, resp)
+}
+
+// call sends the request for action, whose parameters are encoded from req
+// by elb.EncodeParams.
+func (c *ELBV2) call(ctx context.Context, action string, req, resp interface{}) error {
+	params := map[string]string{"Action": action}
+	if err := elb.EncodeParams(params, req); err != nil {
+		return err
+	}
+	return c.query(ctx, params, resp)
+}
+
+// Load Balancer types.
+const (
+	TypeApplication = "application"
+	TypeNetwork     = "network"
+)
+
+// Listener and target group protocols. Application Load Balancers use HTTP
+// and HTTPS, Network Load Balancers use TCP, TLS, UDP and TCP_UDP.
+const (
+	ProtocolHTTP   = "HTTP"
+	ProtocolHTTPS  = "HTTPS"
+	ProtocolTCP    = "TCP"
+	ProtocolTLS    = "TLS"
+	ProtocolUDP    = "UDP"
+	ProtocolTCPUDP = "TCP_UDP"
+)
+
+// IP address types of Load Balancers. Dualstack Load Balancers are
+// reachable over both IPv4 and IPv6, and need subnets with IPv6 CIDR
+// blocks.
+const (
+	IpAddressTypeIPv4      = "ipv4"
+	IpAddressTypeDualstack = "dualstack"
+)
+
+type SimpleResp struct {
+	RequestId string `xml:"ResponseMetadata>RequestId"`
+}
+
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+type LoadBalancerState struct {
+	Code   string `xml:"Code"`
+	Reason string `xml:"Reason"`
+}
+
+type AvailabilityZone struct {
+	SubnetId string `xml:"SubnetId"`
+	ZoneName string `xml:"ZoneName"`
+}
+
+type LoadBalancer struct {
+	LoadBalancerArn       string             `xml:"LoadBalancerArn"`
+	LoadBalancerName      string             `xml:"LoadBalancerName"`
+	DNSName               string             `xml:"DNSName"`
+	CanonicalHostedZoneId string             `xml:"CanonicalHostedZoneId"`
+	CreatedTime           time.Time          `xml:"CreatedTime"`
+	Scheme                string             `xml:"Scheme"`
+	Type                  string             `xml:"Type"`
+	IpAddressType         string             `xml:"IpAddressType"`
+	VpcId                 string             `xml:"VpcId"`
+	State                 LoadBalancerState  `xml:"State"`
+	AvailabilityZones     []AvailabilityZone `xml:"AvailabilityZones>member"`
+	SecurityGroups        []string           `xml:"SecurityGroups>member"`
+}
+
+// DualStackDNSName returns the DNS name of the Load Balancer resolving to
+// both its IPv4 (A) and IPv6 (AAAA) addresses, or an empty string if it
+// has no DNS name. It only resolves to IPv6 addresses when the IP address
+// type of the Load Balancer is IpAddressTypeDualstack.
+func (lb *LoadBalancer) DualStackDNSName() string {
+	if lb.DNSName == "" {
+		return ""
+	}
+	return elb.DualStackPrefix + lb.DNSName
+}
+
+// The CreateLoadBalancer type encapsulates options for the respective
+// request in AWS.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateLoadBalancer.html
+// for more details.
+type CreateLoadBalancer struct {
+	Name           string
+	Subnets        []string `elb:"Subnets.member"`
+	SecurityGroups []string `elb:"SecurityGroups.member"`
+	// Scheme is either "internet-facing" (the default) or "internal".
+	Scheme string `elb:",omitempty"`
+	// Type is either "application" (the default) or "network".
+	Type string `elb:",omitempty"`
+	// IpAddressType is either IpAddressTypeIPv4 (the default) or
+	// IpAddressTypeDualstack.
+	IpAddressType string `elb:",omitempty"`
+	Tags          []Tag  `elb:"Tags.member"`
+}
+
+type CreateLoadBalancerResp struct {
+	LoadBalancers []LoadBalancer `xml:"CreateLoadBalancerResult>LoadBalancers>member"`
+	RequestId     string         `xml:"ResponseMetadata>RequestId"`
+}
+
+// Create a Load Balancer.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateLoadBalancer.html
+// for more details.
+func (c *ELBV2) CreateLoadBalancer(options *CreateLoadBalancer) (*CreateLoadBalancerResp, error) {
+	return c.CreateLoadBalancerWithContext(context.Background(), options)
+}
+
+// CreateLoadBalancerWithContext is like CreateLoadBalancer, but the request
+// is bound to ctx.
+func (c *ELBV2) CreateLoadBalancerWithContext(ctx context.Context, options *CreateLoadBalancer) (*CreateLoadBalancerResp, error) {
+	resp := new(CreateLoadBalancerResp)
+	if err := c.call(ctx, "CreateLoadBalancer", options, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Delete the Load Balancer identified by the given ARN, along with its
+// listeners. Its target groups are kept.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_DeleteLoadBalancer.html
+// for more details.
+func (c *ELBV2) DeleteLoadBalancer(arn string) (*SimpleResp, error) {
+	return c.DeleteLoadBalancerWithContext(context.Background(), arn)
+}
+
+// DeleteLoadBalancerWithContext is like DeleteLoadBalancer, but the request
+// is bound to ctx.
+func (c *ELBV2) DeleteLoadBalancerWithContext(ctx context.Context, arn string) (*SimpleResp, error) {
+	params := map[string]string{
+		"Action":          "DeleteLoadBalancer",
+		"LoadBalancerArn": arn,
+	}
+	resp := new(SimpleResp)
+	if err := c.query(ctx, params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// The DescribeLoadBalancers type selects the Load Balancers to describe.
+// Load Balancers may be selected either by ARN or by name; all of them are
+// described when both are empty.
+type DescribeLoadBalancers struct {
+	LoadBalancerArns []string `elb:"LoadBalancerArns.member"`
+	Names            []string `elb:"Names.member"`
+	Marker           string   `elb:",omitempty"`
+	PageSize         int      `elb:",omitempty"`
+}
+
+type DescribeLoadBalancersResp struct {
+	LoadBalancers []LoadBalancer `xml:"DescribeLoadBalancersResult>LoadBalancers>member"`
+	NextMarker    string         `xml:"DescribeLoadBalancersResult>NextMarker"`
+	RequestId     string         `xml:"ResponseMetadata>RequestId"`
+}
+
+// Describe Load Balancers.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_DescribeLoadBalancers.html
+// for more details.
+func (c *ELBV2) DescribeLoadBalancers(options *DescribeLoadBalancers) (*DescribeLoadBalancersResp, error) {
+	return c.DescribeLoadBalancersWithContext(context.Background(), options)
+}
+
+// DescribeLoadBalancersWithContext is like DescribeLoadBalancers, but the
+// request is bound to ctx.
+func (c *ELBV2) DescribeLoadBalancersWithContext(ctx context.Context, options *DescribeLoadBalancers) (*DescribeLoadBalancersResp, error) {
+	resp := new(DescribeLoadBalancersResp)
+	if err := c.call(ctx, "DescribeLoadBalancers", options, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type SetIpAddressTypeResp struct {
+	IpAddressType string `xml:"SetIpAddressTypeResult>IpAddressType"`
+	RequestId     string `xml:"ResponseMetadata>RequestId"`
+}
+
+// Set the IP address type of the Load Balancer identified by the given
+// ARN, either IpAddressTypeIPv4 or IpAddressTypeDualstack.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_SetIpAddressType.html
+// for more details.
+func (c *ELBV2) SetIpAddressType(arn, ipAddressType string) (*SetIpAddressTypeResp, error) {
+	return c.SetIpAddressTypeWithContext(context.Background(), arn, ipAddressType)
+}
+
+// SetIpAddressTypeWithContext is like SetIpAddressType, but the request is
+// bound to ctx.
+func (c *ELBV2) SetIpAddressTypeWithContext(ctx context.Context, arn, ipAddressType string) (*SetIpAddressTypeResp, error) {
+	req := struct {
+		LoadBalancerArn string
+		IpAddressType   string
+	}{arn, ipAddressType}
+	resp := new(SetIpAddressTypeResp)
+	if err := c.call(ctx, "SetIpAddressType", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type Matcher struct {
+	HttpCode string `xml:"HttpCode"`
+}
+
+type TargetGroup struct {
+	TargetGroupArn             string   `xml:"TargetGroupArn"`
+	TargetGroupName            string   `xml:"TargetGroupName"`
+	Protocol                   string   `xml:"Protocol"`
+	Port                       int      `xml:"Port"`
+	VpcId                      string   `xml:"VpcId"`
+	TargetType                 string   `xml:"TargetType"`
+	HealthCheckProtocol        string   `xml:"HealthCheckProtocol"`
+	HealthCheckPort            string   `xml:"HealthCheckPort"`
+	HealthCheckPath            string   `xml:"HealthCheckPath"`
+	HealthCheckIntervalSeconds int      `xml:"HealthCheckIntervalSeconds"`
+	HealthCheckTimeoutSeconds  int      `xml:"HealthCheckTimeoutSeconds"`
+	HealthyThresholdCount      int      `xml:"HealthyThresholdCount"`
+	UnhealthyThresholdCount    int      `xml:"UnhealthyThresholdCount"`
+	Matcher                    Matcher  `xml:"Matcher"`
+	LoadBalancerArns           []string `xml:"LoadBalancerArns>member"`
+}
+
+// The CreateTargetGroup type encapsulates options for the respective
+// request in AWS. Zero values are left for AWS to default.
+//
+// Target groups of Network Load Balancers use the TCP, TLS, UDP or TCP_UDP
+// protocols. Their health checks may use TCP, the default for these
+// protocols, in which case HealthCheckPath and Matcher must be left empty.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateTargetGroup.html
+// for more details.
+type CreateTargetGroup struct {
+	Name                       string
+	Protocol                   string `elb:",omitempty"`
+	Port                       int    `elb:",omitempty"`
+	VpcId                      string `elb:",omitempty"`
+	TargetType                 string `elb:",omitempty"`
+	HealthCheckProtocol        string `elb:",omitempty"`
+	HealthCheckPort            string `elb:",omitempty"`
+	HealthCheckPath            string `elb:",omitempty"`
+	HealthCheckIntervalSeconds int    `elb:",omitempty"`
+	HealthCheckTimeoutSeconds  int    `elb:",omitempty"`
+	HealthyThresholdCount      int    `elb:",omitempty"`
+	UnhealthyThresholdCount    int    `elb:",omitempty"`
+	// Matcher lists the HTTP codes of a successful health check, e.g.
+	// "200" or "200-299".
+	Matcher string `elb:"Matcher.HttpCode,omitempty"`
+}
+
+type CreateTargetGroupResp struct {
+	TargetGroups []TargetGroup `xml:"CreateTargetGroupResult>TargetGroups>member"`
+	RequestId    string        `xml:"ResponseMetadata>RequestId"`
+}
+
+// Create a target group, to which Load Balancers route requests. An error
+// wrapping ErrInvalidParameterCombination is returned without sending the
+// request if the health check is TCP and has a path or a matcher.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateTargetGroup.html
+// for more details.
+func (c *ELBV2) CreateTargetGroup(options *CreateTargetGroup) (*CreateTargetGroupResp, error) {
+	return c.CreateTargetGroupWithContext(context.Background(), options)
+}
+
+// CreateTargetGroupWithContext is like CreateTargetGroup, but the request
+// is bound to ctx.
+func (c *ELBV2) CreateTargetGroupWithContext(ctx context.Context, options *CreateTargetGroup) (*CreateTargetGroupResp, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	resp := new(CreateTargetGroupResp)
+	if err := c.call(ctx, "CreateTargetGroup", options, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TargetDescription identifies a target of a target group. A zero Port
+// means the port of the target group.
+type TargetDescription struct {
+	Id   string `xml:"Id"`
+	Port int    `xml:"Port" elb:",omitempty"`
+}
+
+// Register targets with a target group.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_RegisterTargets.html
+// for more details.
+func (c *ELBV2) RegisterTargets(targetGroupArn string, targets []TargetDescription) (*SimpleResp, error) {
+	return c.RegisterTargetsWithContext(context.Background(), targetGroupArn, targets)
+}
+
+// RegisterTargetsWithContext is like RegisterTargets, but the request is
+// bound to ctx.
+func (c *ELBV2) RegisterTargetsWithContext(ctx context.Context, targetGroupArn string, targets []TargetDescription) (*SimpleResp, error) {
+	return c.changeTargets(ctx, "RegisterTargets", targetGroupArn, targets)
+}
+
+// Deregister targets from a target group.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_DeregisterTargets.html
+// for more details.
+func (c *ELBV2) DeregisterTargets(targetGroupArn string, targets []TargetDescription) (*SimpleResp, error) {
+	return c.DeregisterTargetsWithContext(context.Background(), targetGroupArn, targets)
+}
+
+// DeregisterTargetsWithContext is like DeregisterTargets, but the request
+// is bound to ctx.
+func (c *ELBV2) DeregisterTargetsWithContext(ctx context.Context, targetGroupArn string, targets []TargetDescription) (*SimpleResp, error) {
+	return c.changeTargets(ctx, "DeregisterTargets", targetGroupArn, targets)
+}
+
+func (c *ELBV2) changeTargets(ctx context.Context, action, targetGroupArn string, targets []TargetDescription) (*SimpleResp, error) {
+	req := struct {
+		TargetGroupArn string
+		Targets        []TargetDescription `elb:"Targets.member"`
+	}{targetGroupArn, targets}
+	resp := new(SimpleResp)
+	if err := c.call(ctx, action, &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// TargetGroupAttribute is a key/value setting of a target group, such as
+// "deregistration_delay.timeout_seconds" or "stickiness.enabled".
+type TargetGroupAttribute struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+type DescribeTargetGroupAttributesResp struct {
+	Attributes []TargetGroupAttribute `xml:"DescribeTargetGroupAttributesResult>Attributes>member"`
+	RequestId  string                 `xml:"ResponseMetadata>RequestId"`
+}
+
+// Describe the attributes of a target group.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_DescribeTargetGroupAttributes.html
+// for more details.
+func (c *ELBV2) DescribeTargetGroupAttributes(targetGroupArn string) (*DescribeTargetGroupAttributesResp, error) {
+	return c.DescribeTargetGroupAttributesWithContext(context.Background(), targetGroupArn)
+}
+
+// DescribeTargetGroupAttributesWithContext is like
+// DescribeTargetGroupAttributes, but the request is bound to ctx.
+func (c *ELBV2) DescribeTargetGroupAttributesWithContext(ctx context.Context, targetGroupArn string) (*DescribeTargetGroupAttributesResp, error) {
+	params := map[string]string{
+		"Action":         "DescribeTargetGroupAttributes",
+		"TargetGroupArn": targetGroupArn,
+	}
+	resp := new(DescribeTargetGroupAttributesResp)
+	if err := c.query(ctx, params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type ModifyTargetGroupAttributesResp struct {
+	Attributes []TargetGroupAttribute `xml:"ModifyTargetGroupAttributesResult>Attributes>member"`
+	RequestId  string                 `xml:"ResponseMetadata>RequestId"`
+}
+
+// Modify the attributes of a target group. The response holds all the
+// attributes of the target group, not only the modified ones.
+//
+// Stickiness is only supported by target groups of Application Load
+// Balancers: AWS rejects "stickiness.enabled" on TCP, TLS, UDP and TCP_UDP
+// target groups with an InvalidConfigurationRequest error. As the request
+// doesn't tell the protocol of the target group, it isn't checked before
+// sending it.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_ModifyTargetGroupAttributes.html
+// for more details.
+func (c *ELBV2) ModifyTargetGroupAttributes(targetGroupArn string, attrs []TargetGroupAttribute) (*ModifyTargetGroupAttributesResp, error) {
+	return c.ModifyTargetGroupAttributesWithContext(context.Background(), targetGroupArn, attrs)
+}
+
+// ModifyTargetGroupAttributesWithContext is like
+// ModifyTargetGroupAttributes, but the request is bound to ctx.
+func (c *ELBV2) ModifyTargetGroupAttributesWithContext(ctx context.Context, targetGroupArn string, attrs []TargetGroupAttribute) (*ModifyTargetGroupAttributesResp, error) {
+	req := struct {
+		TargetGroupArn string
+		Attributes     []TargetGroupAttribute `elb:"Attributes.member"`
+	}{targetGroupArn, attrs}
+	resp := new(ModifyTargetGroupAttributesResp)
+	if err := c.call(ctx, "ModifyTargetGroupAttributes", &req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Action is what a listener or a rule does with the requests it matches.
+// Only the "forward" type is supported.
+type Action struct {
+	Type           string `xml:"Type"`
+	TargetGroupArn string `xml:"TargetGroupArn" elb:",omitempty"`
+}
+
+type Certificate struct {
+	CertificateArn string `xml:"CertificateArn"`
+}
+
+type Listener struct {
+	ListenerArn     string        `xml:"ListenerArn"`
+	LoadBalancerArn string        `xml:"LoadBalancerArn"`
+	Port            int           `xml:"Port"`
+	Protocol        string        `xml:"Protocol"`
+	SslPolicy       string        `xml:"SslPolicy"`
+	Certificates    []Certificate `xml:"Certificates>member"`
+	DefaultActions  []Action      `xml:"DefaultActions>member"`
+}
+
+// The CreateListener type encapsulates options for the respective request
+// in AWS. Listeners of Application Load Balancers use HTTP or HTTPS, those
+// of Network Load Balancers TCP, TLS, UDP or TCP_UDP. HTTPS and TLS
+// listeners require a certificate.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateListener.html
+// for more details.
+type CreateListener struct {
+	LoadBalancerArn string
+	Protocol        string
+	Port            int
+	SslPolicy       string        `elb:",omitempty"`
+	Certificates    []Certificate `elb:"Certificates.member"`
+	DefaultActions  []Action      `elb:"DefaultActions.member"`
+}
+
+type CreateListenerResp struct {
+	Listeners []Listener `xml:"CreateListenerResult>Listeners>member"`
+	RequestId string     `xml:"ResponseMetadata>RequestId"`
+}
+
+// Create a listener for a Load Balancer. An error wrapping
+// ErrInvalidParameterCombination is returned without sending the request
+// if the protocol doesn't fit the type of the Load Balancer.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateListener.html
+// for more details.
+func (c *ELBV2) CreateListener(options *CreateListener) (*CreateListenerResp, error) {
+	return c.CreateListenerWithContext(context.Background(), options)
+}
+
+// CreateListenerWithContext is like CreateListener, but the request is
+// bound to ctx.
+func (c *ELBV2) CreateListenerWithContext(ctx context.Context, options *CreateListener) (*CreateListenerResp, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	resp := new(CreateListenerResp)
+	if err := c.call(ctx, "CreateListener", options, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RuleCondition matches requests on a field, like "path-pattern" or
+// "host-header", against a list of values.
+type RuleCondition struct {
+	Field  string   `xml:"Field"`
+	Values []string `xml:"Values>member" elb:"Values.member"`
+}
+
+type Rule struct {
+	RuleArn    string          `xml:"RuleArn"`
+	Priority   string          `xml:"Priority"`
+	IsDefault  bool            `xml:"IsDefault"`
+	Conditions []RuleCondition `xml:"Conditions>member"`
+	Actions    []Action        `xml:"Actions>member"`
+}
+
+// The CreateRule type encapsulates options for the respective request in
+// AWS.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateRule.html
+// for more details.
+type CreateRule struct {
+	ListenerArn string
+	Priority    int
+	Conditions  []RuleCondition `elb:"Conditions.member"`
+	Actions     []Action        `elb:"Actions.member"`
+}
+
+type CreateRuleResp struct {
+	Rules     []Rule `xml:"CreateRuleResult>Rules>member"`
+	RequestId string `xml:"ResponseMetadata>RequestId"`
+}
+
+// Create a rule for a listener of an Application Load Balancer.
+//
+// See http://docs.aws.amazon.com/elasticloadbalancing/latest/APIReference/API_CreateRule.html
+// for more details.
+func (c *ELBV2) CreateRule(options *CreateRule) (*CreateRuleResp, error) {
+	return c.CreateRuleWithContext(context.Background(), options)
+}
+
+// CreateRuleWithContext is like CreateRule, but the request is bound to
+// ctx.
+func (c *ELBV2) CreateRuleWithContext(ctx context.Context, options *CreateRule) (*CreateRuleResp, error) {
+	resp := new(CreateRuleResp)
+	if err := c.call(ctx, "CreateRule", options, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,271 @@
+package elbv2_test
+
+import (
+	"errors"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elbv2"
+	. "launchpad.net/gocheck"
+	"strings"
+	"time"
+)
+
+type S struct {
+	HTTPSuite
+	elbv2 *elbv2.ELBV2
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpSuite(c *C) {
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	s.elbv2 = elbv2.New(auth, aws.Region{Name: "us-west-2", ELBEndpoint: testServer.URL}, noRetry)
+}
+
+const (
+	lbArn = "arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-load-balancer/50dc6c495c0c9188"
+	tgArn = "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067"
+)
+
+func (s *S) TestCreateLoadBalancer(c *C) {
+	testServer.PrepareResponse(200, nil, CreateLoadBalancer)
+	options := &elbv2.CreateLoadBalancer{
+		Name:           "my-load-balancer",
+		Subnets:        []string{"subnet-8360a9e7", "subnet-b7d581c0"},
+		SecurityGroups: []string{"sg-5943793c"},
+		Tags:           []elbv2.Tag{{Key: "env", Value: "prod"}},
+	}
+	resp, err := s.elbv2.CreateLoadBalancer(options)
+	c.Assert(err, IsNil)
+	req := testServer.WaitRequest()
+	values := req.URL.Query()
+	c.Assert(values.Get("Version"), Equals, "2015-12-01")
+	c.Assert(values.Get("Action"), Equals, "CreateLoadBalancer")
+	c.Assert(values.Get("Name"), Equals, "my-load-balancer")
+	c.Assert(values.Get("Subnets.member.1"), Equals, "subnet-8360a9e7")
+	c.Assert(values.Get("Subnets.member.2"), Equals, "subnet-b7d581c0")
+	c.Assert(values.Get("SecurityGroups.member.1"), Equals, "sg-5943793c")
+	c.Assert(values.Get("Tags.member.1.Key"), Equals, "env")
+	c.Assert(values.Get("Tags.member.1.Value"), Equals, "prod")
+	_, ok := values["Scheme"]
+	c.Assert(ok, Equals, false)
+	// Version 2 of the API requires Signature Version 4.
+	c.Assert(values.Get("Signature"), Equals, "")
+	c.Assert(strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 "), Equals, true)
+	c.Assert(resp.LoadBalancers, HasLen, 1)
+	lb := resp.LoadBalancers[0]
+	c.Assert(lb.LoadBalancerArn, Equals, lbArn)
+	c.Assert(lb.Type, Equals, "application")
+	c.Assert(lb.State.Code, Equals, "provisioning")
+	c.Assert(lb.CreatedTime, Equals, time.Date(2016, 3, 25, 21, 29, 48, 850000000, time.UTC))
+	c.Assert(lb.AvailabilityZones, DeepEquals, []elbv2.AvailabilityZone{
+		{SubnetId: "subnet-8360a9e7", ZoneName: "us-west-2a"},
+		{SubnetId: "subnet-b7d581c0", ZoneName: "us-west-2b"},
+	})
+	c.Assert(lb.SecurityGroups, DeepEquals, []string{"sg-5943793c"})
+}
+
+func (s *S) TestDeleteLoadBalancer(c *C) {
+	testServer.PrepareResponse(200, nil, DeleteLoadBalancer)
+	resp, err := s.elbv2.DeleteLoadBalancer(lbArn)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeleteLoadBalancer")
+	c.Assert(values.Get("LoadBalancerArn"), Equals, lbArn)
+	c.Assert(resp.RequestId, Equals, "1549581b-12b7-11e3-895e-1334aEXAMPLE")
+}
+
+func (s *S) TestDeleteLoadBalancerNotFound(c *C) {
+	testServer.PrepareResponse(400, nil, LoadBalancerNotFound)
+	resp, err := s.elbv2.DeleteLoadBalancer(lbArn)
+	c.Assert(resp, IsNil)
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+	c.Assert(err.(*elb.Error).RequestId, Equals, "dc4c5b7e-f3a0-11e5-bb98-57195a6eb84a")
+}
+
+func (s *S) TestDescribeLoadBalancers(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	resp, err := s.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancers{
+		Names:    []string{"my-load-balancer"},
+		PageSize: 1,
+	})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	c.Assert(values.Get("Names.member.1"), Equals, "my-load-balancer")
+	c.Assert(values.Get("PageSize"), Equals, "1")
+	c.Assert(resp.NextMarker, Equals, "somemarker")
+	c.Assert(resp.LoadBalancers, HasLen, 1)
+	c.Assert(resp.LoadBalancers[0].State.Code, Equals, "active")
+}
+
+func (s *S) TestDescribeAllLoadBalancers(c *C) {
+	testServer.PrepareResponse(200, nil, DescribeLoadBalancers)
+	_, err := s.elbv2.DescribeLoadBalancers(nil)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DescribeLoadBalancers")
+	_, ok := values["Names.member.1"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *S) TestCreateTargetGroup(c *C) {
+	testServer.PrepareResponse(200, nil, CreateTargetGroup)
+	resp, err := s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:            "my-targets",
+		Protocol:        "HTTP",
+		Port:            80,
+		VpcId:           "vpc-3ac0fb5f",
+		HealthCheckPath: "/",
+		Matcher:         "200",
+	})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateTargetGroup")
+	c.Assert(values.Get("Name"), Equals, "my-targets")
+	c.Assert(values.Get("Protocol"), Equals, "HTTP")
+	c.Assert(values.Get("Port"), Equals, "80")
+	c.Assert(values.Get("VpcId"), Equals, "vpc-3ac0fb5f")
+	c.Assert(values.Get("HealthCheckPath"), Equals, "/")
+	c.Assert(values.Get("Matcher.HttpCode"), Equals, "200")
+	_, ok := values["HealthCheckIntervalSeconds"]
+	c.Assert(ok, Equals, false)
+	c.Assert(resp.TargetGroups, HasLen, 1)
+	tg := resp.TargetGroups[0]
+	c.Assert(tg.TargetGroupArn, Equals, tgArn)
+	c.Assert(tg.HealthCheckIntervalSeconds, Equals, 30)
+	c.Assert(tg.Matcher.HttpCode, Equals, "200")
+}
+
+func (s *S) TestCreateTargetGroupRejectsTCPHealthCheckSettings(c *C) {
+	_, err := s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:            "my-targets",
+		Protocol:        elbv2.ProtocolTCP,
+		Port:            6379,
+		HealthCheckPath: "/healthz",
+	})
+	c.Assert(errors.Is(err, elbv2.ErrInvalidParameterCombination), Equals, true)
+	_, err = s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:                "my-targets",
+		Protocol:            elbv2.ProtocolTLS,
+		Port:                6379,
+		HealthCheckProtocol: elbv2.ProtocolTCP,
+		Matcher:             "200",
+	})
+	c.Assert(errors.Is(err, elbv2.ErrInvalidParameterCombination), Equals, true)
+	testServer.PrepareResponse(200, nil, CreateTargetGroup)
+	_, err = s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:                "my-targets",
+		Protocol:            elbv2.ProtocolTCP,
+		Port:                6379,
+		HealthCheckProtocol: elbv2.ProtocolHTTP,
+		HealthCheckPath:     "/healthz",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(testServer.WaitRequest().URL.Query().Get("HealthCheckPath"), Equals, "/healthz")
+}
+
+func (s *S) TestRegisterTargets(c *C) {
+	testServer.PrepareResponse(200, nil, RegisterTargets)
+	targets := []elbv2.TargetDescription{{Id: "i-80c8dd94"}, {Id: "i-ceddcd4d", Port: 8080}}
+	_, err := s.elbv2.RegisterTargets(tgArn, targets)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "RegisterTargets")
+	c.Assert(values.Get("TargetGroupArn"), Equals, tgArn)
+	c.Assert(values.Get("Targets.member.1.Id"), Equals, "i-80c8dd94")
+	_, ok := values["Targets.member.1.Port"]
+	c.Assert(ok, Equals, false)
+	c.Assert(values.Get("Targets.member.2.Id"), Equals, "i-ceddcd4d")
+	c.Assert(values.Get("Targets.member.2.Port"), Equals, "8080")
+}
+
+func (s *S) TestDeregisterTargets(c *C) {
+	testServer.PrepareResponse(200, nil, DeregisterTargets)
+	_, err := s.elbv2.DeregisterTargets(tgArn, []elbv2.TargetDescription{{Id: "i-80c8dd94"}})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "DeregisterTargets")
+	c.Assert(values.Get("TargetGroupArn"), Equals, tgArn)
+	c.Assert(values.Get("Targets.member.1.Id"), Equals, "i-80c8dd94")
+}
+
+func (s *S) TestModifyTargetGroupAttributes(c *C) {
+	testServer.PrepareResponse(200, nil, ModifyTargetGroupAttributes)
+	attrs := []elbv2.TargetGroupAttribute{{Key: "deregistration_delay.timeout_seconds", Value: "600"}}
+	resp, err := s.elbv2.ModifyTargetGroupAttributes(tgArn, attrs)
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "ModifyTargetGroupAttributes")
+	c.Assert(values.Get("TargetGroupArn"), Equals, tgArn)
+	c.Assert(values.Get("Attributes.member.1.Key"), Equals, "deregistration_delay.timeout_seconds")
+	c.Assert(values.Get("Attributes.member.1.Value"), Equals, "600")
+	c.Assert(resp.Attributes, HasLen, 3)
+	c.Assert(resp.Attributes[1], Equals, elbv2.TargetGroupAttribute{Key: "deregistration_delay.timeout_seconds", Value: "600"})
+}
+
+func (s *S) TestCreateListener(c *C) {
+	testServer.PrepareResponse(200, nil, CreateListener)
+	resp, err := s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        "HTTPS",
+		Port:            443,
+		SslPolicy:       "ELBSecurityPolicy-2016-08",
+		Certificates:    []elbv2.Certificate{{CertificateArn: "arn:aws:iam::123456789012:server-certificate/my-server-cert"}},
+		DefaultActions:  []elbv2.Action{{Type: "forward", TargetGroupArn: tgArn}},
+	})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateListener")
+	c.Assert(values.Get("LoadBalancerArn"), Equals, lbArn)
+	c.Assert(values.Get("Protocol"), Equals, "HTTPS")
+	c.Assert(values.Get("Port"), Equals, "443")
+	c.Assert(values.Get("SslPolicy"), Equals, "ELBSecurityPolicy-2016-08")
+	c.Assert(values.Get("Certificates.member.1.CertificateArn"), Equals, "arn:aws:iam::123456789012:server-certificate/my-server-cert")
+	c.Assert(values.Get("DefaultActions.member.1.Type"), Equals, "forward")
+	c.Assert(values.Get("DefaultActions.member.1.TargetGroupArn"), Equals, tgArn)
+	c.Assert(resp.Listeners, HasLen, 1)
+	c.Assert(resp.Listeners[0].DefaultActions, DeepEquals, []elbv2.Action{{Type: "forward", TargetGroupArn: tgArn}})
+}
+
+func (s *S) TestCreateListenerRejectsProtocolOfOtherType(c *C) {
+	_, err := s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        elbv2.ProtocolTCP,
+		Port:            6379,
+		DefaultActions:  []elbv2.Action{{Type: "forward", TargetGroupArn: tgArn}},
+	})
+	c.Assert(err, ErrorMatches, "elbv2: invalid parameter combination: TCP listener on an Application Load Balancer")
+	nlbArn := strings.Replace(lbArn, "/app/", "/net/", 1)
+	_, err = s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: nlbArn,
+		Protocol:        elbv2.ProtocolHTTPS,
+		Port:            443,
+		DefaultActions:  []elbv2.Action{{Type: "forward", TargetGroupArn: tgArn}},
+	})
+	c.Assert(errors.Is(err, elbv2.ErrInvalidParameterCombination), Equals, true)
+}
+
+func (s *S) TestCreateRule(c *C) {
+	testServer.PrepareResponse(200, nil, CreateRule)
+	listenerArn := "arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2"
+	resp, err := s.elbv2.CreateRule(&elbv2.CreateRule{
+		ListenerArn: listenerArn,
+		Priority:    10,
+		Conditions:  []elbv2.RuleCondition{{Field: "path-pattern", Values: []string{"/img/*"}}},
+		Actions:     []elbv2.Action{{Type: "forward", TargetGroupArn: tgArn}},
+	})
+	c.Assert(err, IsNil)
+	values := testServer.WaitRequest().URL.Query()
+	c.Assert(values.Get("Action"), Equals, "CreateRule")
+	c.Assert(values.Get("ListenerArn"), Equals, listenerArn)
+	c.Assert(values.Get("Priority"), Equals, "10")
+	c.Assert(values.Get("Conditions.member.1.Field"), Equals, "path-pattern")
+	c.Assert(values.Get("Conditions.member.1.Values.member.1"), Equals, "/img/*")
+	c.Assert(values.Get("Actions.member.1.Type"), Equals, "forward")
+	c.Assert(values.Get("Actions.member.1.TargetGroupArn"), Equals, tgArn)
+	c.Assert(resp.Rules, HasLen, 1)
+	c.Assert(resp.Rules[0].Priority, Equals, "10")
+	c.Assert(resp.Rules[0].Conditions, DeepEquals, []elbv2.RuleCondition{{Field: "path-pattern", Values: []string{"/img/*"}}})
+}
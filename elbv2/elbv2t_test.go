@@ -0,0 +1,263 @@
+package elbv2_test
+
+import (
+	"errors"
+	"github.com/flaviamissi/go-elb/aws"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elbv2"
+	"github.com/flaviamissi/go-elb/elbv2/elbv2test"
+	. "launchpad.net/gocheck"
+)
+
+// LocalServerSuite runs tests against the local elbv2test fake server.
+type LocalServerSuite struct {
+	srv   *elbv2test.Server
+	elbv2 *elbv2.ELBV2
+}
+
+var _ = Suite(&LocalServerSuite{})
+
+func (s *LocalServerSuite) SetUpSuite(c *C) {
+	srv, err := elbv2test.NewServer()
+	c.Assert(err, IsNil)
+	s.srv = srv
+	auth := aws.Auth{AccessKey: "abc", SecretKey: "123"}
+	noRetry := elb.WithRetryPolicy(elb.RetryPolicy{MaxAttempts: 1})
+	s.elbv2 = elbv2.New(auth, aws.Region{Name: "us-east-1", ELBEndpoint: srv.URL()}, noRetry)
+}
+
+func (s *LocalServerSuite) TearDownSuite(c *C) {
+	s.srv.Quit()
+}
+
+func (s *LocalServerSuite) TearDownTest(c *C) {
+	s.srv.ClearErrors()
+	s.srv.ResetHistory()
+}
+
+func (s *LocalServerSuite) TestCreateAndDescribeLoadBalancer(c *C) {
+	resp, err := s.elbv2.CreateLoadBalancer(&elbv2.CreateLoadBalancer{
+		Name:    "testlb",
+		Subnets: []string{"subnet-1", "subnet-2"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.LoadBalancers, HasLen, 1)
+	c.Assert(resp.RequestId, Matches, "req[0-9A-F]+")
+	lb := resp.LoadBalancers[0]
+	defer s.srv.RemoveLoadBalancer(lb.LoadBalancerArn)
+	c.Assert(lb.LoadBalancerName, Equals, "testlb")
+	c.Assert(lb.Type, Equals, "application")
+	c.Assert(lb.Scheme, Equals, "internet-facing")
+	c.Assert(lb.AvailabilityZones, HasLen, 2)
+	c.Assert(lb.AvailabilityZones[1].SubnetId, Equals, "subnet-2")
+	described, err := s.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancers{Names: []string{"testlb"}})
+	c.Assert(err, IsNil)
+	c.Assert(described.LoadBalancers, HasLen, 1)
+	c.Assert(described.LoadBalancers[0].LoadBalancerArn, Equals, lb.LoadBalancerArn)
+	c.Assert(described.LoadBalancers[0].CreatedTime.Equal(lb.CreatedTime), Equals, true)
+}
+
+func (s *LocalServerSuite) TestCreateLoadBalancerDuplicateName(c *C) {
+	arn := s.srv.NewLoadBalancer("testlb")
+	defer s.srv.RemoveLoadBalancer(arn)
+	_, err := s.elbv2.CreateLoadBalancer(&elbv2.CreateLoadBalancer{Name: "testlb", Subnets: []string{"subnet-1"}})
+	c.Assert(elb.IsDuplicateLoadBalancerName(err), Equals, true)
+}
+
+func (s *LocalServerSuite) TestDescribeAbsentLoadBalancer(c *C) {
+	_, err := s.elbv2.DescribeLoadBalancers(&elbv2.DescribeLoadBalancers{Names: []string{"absent"}})
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+}
+
+func (s *LocalServerSuite) TestDeleteLoadBalancer(c *C) {
+	arn := s.srv.NewLoadBalancer("testlb")
+	_, err := s.elbv2.DeleteLoadBalancer(arn)
+	c.Assert(err, IsNil)
+	_, ok := s.srv.LoadBalancer(arn)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *LocalServerSuite) TestSetIpAddressType(c *C) {
+	arn := s.srv.NewLoadBalancer("testlb")
+	defer s.srv.RemoveLoadBalancer(arn)
+	lb, _ := s.srv.LoadBalancer(arn)
+	c.Assert(lb.IpAddressType, Equals, elbv2.IpAddressTypeIPv4)
+	resp, err := s.elbv2.SetIpAddressType(arn, elbv2.IpAddressTypeDualstack)
+	c.Assert(err, IsNil)
+	c.Assert(resp.IpAddressType, Equals, elbv2.IpAddressTypeDualstack)
+	lb, _ = s.srv.LoadBalancer(arn)
+	c.Assert(lb.IpAddressType, Equals, elbv2.IpAddressTypeDualstack)
+	c.Assert(lb.DualStackDNSName(), Equals, "dualstack."+lb.DNSName)
+	_, err = s.elbv2.SetIpAddressType(arn, "ipv6")
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrValidation)
+	_, err = s.elbv2.SetIpAddressType("arn:absent", elbv2.IpAddressTypeIPv4)
+	c.Assert(elb.IsLoadBalancerNotFound(err), Equals, true)
+}
+
+func (s *LocalServerSuite) TestInternalLoadBalancerCannotBeDualstack(c *C) {
+	_, err := s.elbv2.CreateLoadBalancer(&elbv2.CreateLoadBalancer{
+		Name:          "testlb",
+		Subnets:       []string{"subnet-1"},
+		Scheme:        "internal",
+		IpAddressType: elbv2.IpAddressTypeDualstack,
+	})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+}
+
+func (s *LocalServerSuite) TestRegisterAndDeregisterTargets(c *C) {
+	tgArn := s.srv.NewTargetGroup("testtg")
+	defer s.srv.RemoveTargetGroup(tgArn)
+	id := s.srv.NewTarget()
+	_, err := s.elbv2.RegisterTargets(tgArn, []elbv2.TargetDescription{{Id: id}})
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.Targets(tgArn), DeepEquals, []elbv2.TargetDescription{{Id: id, Port: 80}})
+	_, err = s.elbv2.DeregisterTargets(tgArn, []elbv2.TargetDescription{{Id: id}})
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.Targets(tgArn), HasLen, 0)
+}
+
+func (s *LocalServerSuite) TestRegisterUnknownTarget(c *C) {
+	tgArn := s.srv.NewTargetGroup("testtg")
+	defer s.srv.RemoveTargetGroup(tgArn)
+	_, err := s.elbv2.RegisterTargets(tgArn, []elbv2.TargetDescription{{Id: "i-unknown"}})
+	c.Assert(elb.ErrorCode(err), Equals, "InvalidTarget")
+}
+
+func (s *LocalServerSuite) TestCreateListenerAndRule(c *C) {
+	lbArn := s.srv.NewLoadBalancer("testlb")
+	defer s.srv.RemoveLoadBalancer(lbArn)
+	tgResp, err := s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{Name: "testtg", Port: 8080, Matcher: "200-299"})
+	c.Assert(err, IsNil)
+	tg := tgResp.TargetGroups[0]
+	defer s.srv.RemoveTargetGroup(tg.TargetGroupArn)
+	c.Assert(tg.Port, Equals, 8080)
+	c.Assert(tg.Matcher.HttpCode, Equals, "200-299")
+	forward := []elbv2.Action{{Type: "forward", TargetGroupArn: tg.TargetGroupArn}}
+	lResp, err := s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        "HTTP",
+		Port:            80,
+		DefaultActions:  forward,
+	})
+	c.Assert(err, IsNil)
+	listener := lResp.Listeners[0]
+	c.Assert(s.srv.Listeners(lbArn), DeepEquals, []elbv2.Listener{listener})
+	rule := &elbv2.CreateRule{
+		ListenerArn: listener.ListenerArn,
+		Priority:    10,
+		Conditions:  []elbv2.RuleCondition{{Field: "path-pattern", Values: []string{"/api/*"}}},
+		Actions:     forward,
+	}
+	rResp, err := s.elbv2.CreateRule(rule)
+	c.Assert(err, IsNil)
+	c.Assert(rResp.Rules[0].Priority, Equals, "10")
+	c.Assert(s.srv.Rules(listener.ListenerArn), DeepEquals, rResp.Rules)
+	_, err = s.elbv2.CreateRule(rule)
+	c.Assert(elb.ErrorCode(err), Equals, "PriorityInUse")
+	_, err = s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        "HTTP",
+		Port:            80,
+		DefaultActions:  forward,
+	})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrDuplicateListener)
+}
+
+func (s *LocalServerSuite) TestSetError(c *C) {
+	s.srv.SetError("DescribeLoadBalancers", &elb.Error{Code: "AccessDenied", Message: "denied"}, 1)
+	_, err := s.elbv2.DescribeLoadBalancers(nil)
+	c.Assert(elb.ErrorCode(err), Equals, "AccessDenied")
+	_, err = s.elbv2.DescribeLoadBalancers(nil)
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.RequestsFor("DescribeLoadBalancers"), HasLen, 2)
+}
+
+func (s *LocalServerSuite) TestNetworkLoadBalancer(c *C) {
+	resp, err := s.elbv2.CreateLoadBalancer(&elbv2.CreateLoadBalancer{
+		Name:    "testnlb",
+		Subnets: []string{"subnet-1"},
+		Type:    elbv2.TypeNetwork,
+	})
+	c.Assert(err, IsNil)
+	lbArn := resp.LoadBalancers[0].LoadBalancerArn
+	defer s.srv.RemoveLoadBalancer(lbArn)
+	c.Assert(resp.LoadBalancers[0].Type, Equals, elbv2.TypeNetwork)
+	tgResp, err := s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{Name: "testtcp", Protocol: elbv2.ProtocolTCP, Port: 6379})
+	c.Assert(err, IsNil)
+	tg := tgResp.TargetGroups[0]
+	defer s.srv.RemoveTargetGroup(tg.TargetGroupArn)
+	c.Assert(tg.HealthCheckProtocol, Equals, elbv2.ProtocolTCP)
+	c.Assert(tg.HealthCheckPath, Equals, "")
+	forward := []elbv2.Action{{Type: "forward", TargetGroupArn: tg.TargetGroupArn}}
+	_, err = s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        elbv2.ProtocolHTTP,
+		Port:            80,
+		DefaultActions:  forward,
+	})
+	c.Assert(errors.Is(err, elbv2.ErrInvalidParameterCombination), Equals, true)
+	_, err = s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        elbv2.ProtocolTLS,
+		Port:            6379,
+		DefaultActions:  forward,
+	})
+	c.Assert(elb.ErrorCode(err), Equals, "CertificateNotFound")
+	lResp, err := s.elbv2.CreateListener(&elbv2.CreateListener{
+		LoadBalancerArn: lbArn,
+		Protocol:        elbv2.ProtocolTCP,
+		Port:            6379,
+		DefaultActions:  forward,
+	})
+	c.Assert(err, IsNil)
+	_, err = s.elbv2.CreateRule(&elbv2.CreateRule{
+		ListenerArn: lResp.Listeners[0].ListenerArn,
+		Priority:    1,
+		Conditions:  []elbv2.RuleCondition{{Field: "path-pattern", Values: []string{"/*"}}},
+		Actions:     forward,
+	})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrValidation)
+}
+
+func (s *LocalServerSuite) TestTCPTargetGroupRejectsHTTPHealthCheckSettings(c *C) {
+	_, err := s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:            "testtcp",
+		Protocol:        elbv2.ProtocolTCP,
+		Port:            6379,
+		HealthCheckPath: "/healthz",
+	})
+	c.Assert(errors.Is(err, elbv2.ErrInvalidParameterCombination), Equals, true)
+	_, err = s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:                       "testtcp",
+		Protocol:                   elbv2.ProtocolTCP,
+		Port:                       6379,
+		HealthCheckIntervalSeconds: 15,
+	})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrValidation)
+	_, err = s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{
+		Name:                "testhttp",
+		Protocol:            elbv2.ProtocolHTTP,
+		HealthCheckProtocol: elbv2.ProtocolTCP,
+	})
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrValidation)
+}
+
+func (s *LocalServerSuite) TestTCPTargetGroupRejectsStickiness(c *C) {
+	tgResp, err := s.elbv2.CreateTargetGroup(&elbv2.CreateTargetGroup{Name: "testtcp", Protocol: elbv2.ProtocolTCP, Port: 6379})
+	c.Assert(err, IsNil)
+	tgArn := tgResp.TargetGroups[0].TargetGroupArn
+	defer s.srv.RemoveTargetGroup(tgArn)
+	stickiness := []elbv2.TargetGroupAttribute{{Key: "stickiness.enabled", Value: "true"}}
+	_, err = s.elbv2.ModifyTargetGroupAttributes(tgArn, stickiness)
+	c.Assert(elb.ErrorCode(err), Equals, elb.ErrInvalidConfigurationRequest)
+	resp, err := s.elbv2.ModifyTargetGroupAttributes(tgArn, []elbv2.TargetGroupAttribute{{Key: "deregistration_delay.timeout_seconds", Value: "30"}})
+	c.Assert(err, IsNil)
+	c.Assert(resp.Attributes[0], Equals, elbv2.TargetGroupAttribute{Key: "deregistration_delay.timeout_seconds", Value: "30"})
+	httpArn := s.srv.NewTargetGroup("testhttp")
+	defer s.srv.RemoveTargetGroup(httpArn)
+	_, err = s.elbv2.ModifyTargetGroupAttributes(httpArn, stickiness)
+	c.Assert(err, IsNil)
+	attrs, err := s.elbv2.DescribeTargetGroupAttributes(httpArn)
+	c.Assert(err, IsNil)
+	c.Assert(attrs.Attributes, HasLen, 5)
+}
@@ -0,0 +1,1032 @@
+// Package elbv2test implements a fake ELB version 2 provider, simulating
+// Application and Network Load Balancers, their target groups, listeners
+// and rules. Like elbtest, it can induce errors on any given operation and
+// retrospectively determine what operations have been carried out.
+package elbv2test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"github.com/flaviamissi/go-elb/elbv2"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const arnPrefix = "arn:aws:elasticloadbalancing:us-east-1:123456789012:"
+
+// Server implements an ELB version 2 simulator for use in testing.
+type Server struct {
+	url          string
+	listener     net.Listener
+	mutex        sync.Mutex
+	reqId        int
+	idCount      int
+	lbs          map[string]*elbv2.LoadBalancer
+	targetGroups map[string]*elbv2.TargetGroup
+	tgAttributes map[string]map[string]string
+	targets      map[string][]elbv2.TargetDescription
+	knownTargets map[string]bool
+	listeners    map[string]*elbv2.Listener
+	rules        map[string]*rule
+	errors       map[string]*injectedError
+	history      []Request
+}
+
+// Request records an operation received by the server.
+type Request struct {
+	Action    string
+	Params    url.Values
+	RequestId string
+	Time      time.Time
+}
+
+// rule is a listener rule along with the listener it belongs to.
+type rule struct {
+	elbv2.Rule
+	listenerArn string
+}
+
+// injectedError is an error the server returns instead of running an
+// action, as set by SetError.
+type injectedError struct {
+	err   elb.Error
+	times int
+}
+
+// Starts and returns a new server
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on localhost: %v", err)
+	}
+	srv := &Server{
+		listener:     l,
+		url:          "http://" + l.Addr().String(),
+		lbs:          make(map[string]*elbv2.LoadBalancer),
+		targetGroups: make(map[string]*elbv2.TargetGroup),
+		tgAttributes: make(map[string]map[string]string),
+		targets:      make(map[string][]elbv2.TargetDescription),
+		knownTargets: make(map[string]bool),
+		listeners:    make(map[string]*elbv2.Listener),
+		rules:        make(map[string]*rule),
+		errors:       make(map[string]*injectedError),
+	}
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		srv.serveHTTP(w, req)
+	}))
+	return srv, nil
+}
+
+// Quit closes down the server.
+func (srv *Server) Quit() {
+	srv.listener.Close()
+}
+
+// URL returns the URL of the server.
+func (srv *Server) URL() string {
+	return srv.url
+}
+
+// SetError makes the server fail the next times calls of the given action
+// with err, without running it. If times is zero or negative, every call
+// fails until ClearErrors is called. A zero StatusCode in err defaults to
+// 400.
+func (srv *Server) SetError(action string, err *elb.Error, times int) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	e := *err
+	if e.StatusCode == 0 {
+		e.StatusCode = 400
+	}
+	srv.errors[action] = &injectedError{err: e, times: times}
+}
+
+// ClearErrors removes all the errors set by SetError.
+func (srv *Server) ClearErrors() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.errors = make(map[string]*injectedError)
+}
+
+func (srv *Server) injectedError(action string) *elb.Error {
+	injected, ok := srv.errors[action]
+	if !ok {
+		return nil
+	}
+	if injected.times > 0 {
+		injected.times--
+		if injected.times == 0 {
+			delete(srv.errors, action)
+		}
+	}
+	err := injected.err
+	return &err
+}
+
+// Requests returns all the requests received by the server since it was
+// started or its history was last reset, in the order they arrived.
+func (srv *Server) Requests() []Request {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return append([]Request(nil), srv.history...)
+}
+
+// RequestsFor returns the requests for the given action, in the order they
+// arrived.
+func (srv *Server) RequestsFor(action string) []Request {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	var reqs []Request
+	for _, r := range srv.history {
+		if r.Action == action {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// ResetHistory discards the requests recorded so far.
+func (srv *Server) ResetHistory() {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.history = nil
+}
+
+type xmlErrors struct {
+	XMLName   string `xml:"ErrorResponse"`
+	Error     elb.Error
+	RequestId string
+}
+
+func (srv *Server) error(w http.ResponseWriter, err *elb.Error, reqId string) {
+	w.WriteHeader(err.StatusCode)
+	xmlErr := xmlErrors{Error: *err, RequestId: reqId}
+	if e := xml.NewEncoder(w).Encode(xmlErr); e != nil {
+		panic(e)
+	}
+}
+
+func (srv *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	reqId := fmt.Sprintf("req%0X", srv.reqId)
+	srv.reqId++
+	action := req.Form.Get("Action")
+	srv.history = append(srv.history, Request{
+		Action:    action,
+		Params:    req.Form,
+		RequestId: reqId,
+		Time:      time.Now(),
+	})
+	f := actions[action]
+	if f == nil {
+		srv.error(w, &elb.Error{
+			StatusCode: 400,
+			Code:       "InvalidAction",
+			Message:    fmt.Sprintf("Could not find operation %s for version 2015-12-01", action),
+		}, reqId)
+		return
+	}
+	if err := srv.injectedError(action); err != nil {
+		srv.error(w, err, reqId)
+		return
+	}
+	resp, err := f(srv, req, reqId)
+	if err != nil {
+		e, ok := err.(*elb.Error)
+		if !ok {
+			panic(err)
+		}
+		srv.error(w, e, reqId)
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(withRequestId(resp, reqId)); err != nil {
+		panic(err)
+	}
+}
+
+// withRequestId returns a copy of resp, a response struct, with its
+// RequestId field set to reqId, so handlers don't have to fill it in.
+func withRequestId(resp interface{}, reqId string) interface{} {
+	v := reflect.ValueOf(resp)
+	if v.Kind() != reflect.Struct {
+		return resp
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	if f := p.Elem().FieldByName("RequestId"); f.IsValid() && f.Kind() == reflect.String && f.String() == "" {
+		f.SetString(reqId)
+	}
+	return p.Interface()
+}
+
+func validationError(format string, args ...interface{}) error {
+	return &elb.Error{
+		StatusCode: 400,
+		Code:       elb.ErrValidation,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+func apiError(code, message string) error {
+	return &elb.Error{StatusCode: 400, Code: code, Message: message}
+}
+
+func (srv *Server) validate(form url.Values, required ...string) error {
+	for _, field := range required {
+		if form.Get(field) == "" {
+			return validationError("%s is required.", field)
+		}
+	}
+	return nil
+}
+
+// members returns the values of the list parameter name.
+func members(form url.Values, name string) []string {
+	var values []string
+	for i := 1; form.Get(fmt.Sprintf("%s.member.%d", name, i)) != ""; i++ {
+		values = append(values, form.Get(fmt.Sprintf("%s.member.%d", name, i)))
+	}
+	return values
+}
+
+func actionsParam(form url.Values, name string) []elbv2.Action {
+	var actions []elbv2.Action
+	for i := 1; form.Get(fmt.Sprintf("%s.member.%d.Type", name, i)) != ""; i++ {
+		key := fmt.Sprintf("%s.member.%d.", name, i)
+		actions = append(actions, elbv2.Action{
+			Type:           form.Get(key + "Type"),
+			TargetGroupArn: form.Get(key + "TargetGroupArn"),
+		})
+	}
+	return actions
+}
+
+func (srv *Server) validateActions(actions []elbv2.Action) error {
+	if len(actions) == 0 {
+		return validationError("At least one action is required.")
+	}
+	for _, action := range actions {
+		if action.Type != "forward" {
+			return validationError("Unsupported action type '%s'", action.Type)
+		}
+		if err := srv.targetGroupExists(action.TargetGroupArn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (srv *Server) newId() string {
+	srv.idCount++
+	return fmt.Sprintf("%016x", srv.idCount)
+}
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,30}[a-zA-Z0-9])?$`)
+
+func (srv *Server) lbExists(arn string) error {
+	if _, ok := srv.lbs[arn]; !ok {
+		return apiError(elb.ErrLoadBalancerNotFound, fmt.Sprintf("Load balancer '%s' not found", arn))
+	}
+	return nil
+}
+
+func (srv *Server) targetGroupExists(arn string) error {
+	if _, ok := srv.targetGroups[arn]; !ok {
+		return apiError("TargetGroupNotFound", fmt.Sprintf("Target groups '%s' not found", arn))
+	}
+	return nil
+}
+
+func (srv *Server) createLoadBalancer(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "Name", "Subnets.member.1"); err != nil {
+		return nil, err
+	}
+	name := req.Form.Get("Name")
+	if !validName.MatchString(name) {
+		return nil, validationError("Load balancer name '%s' is not valid", name)
+	}
+	for _, lb := range srv.lbs {
+		if lb.LoadBalancerName == name {
+			return nil, &elb.Error{
+				StatusCode: 400,
+				Code:       elb.ErrDuplicateLoadBalancerName,
+				Message:    "A load balancer with the same name exists",
+			}
+		}
+	}
+	lbType := req.Form.Get("Type")
+	if lbType == "" {
+		lbType = elbv2.TypeApplication
+	}
+	if lbType != elbv2.TypeApplication && lbType != elbv2.TypeNetwork {
+		return nil, validationError("Load balancer type '%s' is not valid", lbType)
+	}
+	scheme := req.Form.Get("Scheme")
+	if scheme == "" {
+		scheme = "internet-facing"
+	}
+	ipAddressType := req.Form.Get("IpAddressType")
+	if ipAddressType == "" {
+		ipAddressType = elbv2.IpAddressTypeIPv4
+	}
+	if err := validateIpAddressType(ipAddressType, scheme); err != nil {
+		return nil, err
+	}
+	lb := srv.makeLoadBalancer(name, lbType)
+	lb.Scheme = scheme
+	lb.IpAddressType = ipAddressType
+	lb.SecurityGroups = members(req.Form, "SecurityGroups")
+	for i, subnet := range members(req.Form, "Subnets") {
+		lb.AvailabilityZones = append(lb.AvailabilityZones, elbv2.AvailabilityZone{
+			SubnetId: subnet,
+			ZoneName: fmt.Sprintf("us-east-1%c", 'a'+i%6),
+		})
+	}
+	srv.lbs[lb.LoadBalancerArn] = lb
+	return elbv2.CreateLoadBalancerResp{LoadBalancers: []elbv2.LoadBalancer{*lb}}, nil
+}
+
+func (srv *Server) makeLoadBalancer(name, lbType string) *elbv2.LoadBalancer {
+	kind := "app"
+	if lbType == elbv2.TypeNetwork {
+		kind = "net"
+	}
+	return &elbv2.LoadBalancer{
+		LoadBalancerArn:       fmt.Sprintf("%sloadbalancer/%s/%s/%s", arnPrefix, kind, name, srv.newId()),
+		LoadBalancerName:      name,
+		DNSName:               fmt.Sprintf("%s-%d.us-east-1.elb.amazonaws.com", name, srv.idCount),
+		CanonicalHostedZoneId: "Z35SXDOTRQ7X7K",
+		CreatedTime:           time.Now().UTC().Truncate(time.Millisecond),
+		Scheme:                "internet-facing",
+		Type:                  lbType,
+		IpAddressType:         elbv2.IpAddressTypeIPv4,
+		VpcId:                 "vpc-1a2b3c4d",
+		State:                 elbv2.LoadBalancerState{Code: "active"},
+	}
+}
+
+// validateIpAddressType checks the IP address type of a Load Balancer with
+// the given scheme. Internal Load Balancers only support IPv4.
+func validateIpAddressType(ipAddressType, scheme string) error {
+	if ipAddressType != elbv2.IpAddressTypeIPv4 && ipAddressType != elbv2.IpAddressTypeDualstack {
+		return validationError("IP address type '%s' is not valid", ipAddressType)
+	}
+	if ipAddressType == elbv2.IpAddressTypeDualstack && scheme == "internal" {
+		return apiError(elb.ErrInvalidConfigurationRequest, "Internal load balancers cannot be dualstack")
+	}
+	return nil
+}
+
+func (srv *Server) setIpAddressType(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "LoadBalancerArn", "IpAddressType"); err != nil {
+		return nil, err
+	}
+	arn := req.Form.Get("LoadBalancerArn")
+	if err := srv.lbExists(arn); err != nil {
+		return nil, err
+	}
+	lb := srv.lbs[arn]
+	ipAddressType := req.Form.Get("IpAddressType")
+	if err := validateIpAddressType(ipAddressType, lb.Scheme); err != nil {
+		return nil, err
+	}
+	lb.IpAddressType = ipAddressType
+	return elbv2.SetIpAddressTypeResp{IpAddressType: ipAddressType}, nil
+}
+
+func (srv *Server) deleteLoadBalancer(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "LoadBalancerArn"); err != nil {
+		return nil, err
+	}
+	srv.removeLoadBalancer(req.Form.Get("LoadBalancerArn"))
+	return elbv2.SimpleResp{RequestId: reqId}, nil
+}
+
+// removeLoadBalancer deletes a Load Balancer along with its listeners and
+// their rules. Deleting an absent Load Balancer is a no-op, like in AWS.
+func (srv *Server) removeLoadBalancer(arn string) {
+	delete(srv.lbs, arn)
+	for listenerArn, l := range srv.listeners {
+		if l.LoadBalancerArn == arn {
+			srv.removeListener(listenerArn)
+		}
+	}
+	for _, tg := range srv.targetGroups {
+		tg.LoadBalancerArns = remove(tg.LoadBalancerArns, arn)
+	}
+}
+
+func (srv *Server) removeListener(arn string) {
+	delete(srv.listeners, arn)
+	for ruleArn, r := range srv.rules {
+		if r.listenerArn == arn {
+			delete(srv.rules, ruleArn)
+		}
+	}
+}
+
+func remove(list []string, value string) []string {
+	var result []string
+	for _, v := range list {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (srv *Server) describeLoadBalancers(req *http.Request, reqId string) (interface{}, error) {
+	arns := members(req.Form, "LoadBalancerArns")
+	names := members(req.Form, "Names")
+	if len(arns) > 0 && len(names) > 0 {
+		return nil, validationError("Load balancer names and load balancer ARNs cannot be specified at the same time")
+	}
+	var lbs []elbv2.LoadBalancer
+	for _, arn := range arns {
+		if err := srv.lbExists(arn); err != nil {
+			return nil, err
+		}
+		lbs = append(lbs, *srv.lbs[arn])
+	}
+	for _, name := range names {
+		lb := srv.loadBalancerByName(name)
+		if lb == nil {
+			return nil, apiError(elb.ErrLoadBalancerNotFound, fmt.Sprintf("Load balancers '[%s]' not found", name))
+		}
+		lbs = append(lbs, *lb)
+	}
+	if len(arns) == 0 && len(names) == 0 {
+		for _, lb := range srv.lbs {
+			lbs = append(lbs, *lb)
+		}
+		sort.Slice(lbs, func(i, j int) bool {
+			return lbs[i].LoadBalancerName < lbs[j].LoadBalancerName
+		})
+	}
+	return elbv2.DescribeLoadBalancersResp{LoadBalancers: lbs}, nil
+}
+
+func (srv *Server) loadBalancerByName(name string) *elbv2.LoadBalancer {
+	for _, lb := range srv.lbs {
+		if lb.LoadBalancerName == name {
+			return lb
+		}
+	}
+	return nil
+}
+
+func (srv *Server) createTargetGroup(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "Name"); err != nil {
+		return nil, err
+	}
+	name := req.Form.Get("Name")
+	if !validName.MatchString(name) {
+		return nil, validationError("Target group name '%s' is not valid", name)
+	}
+	for _, tg := range srv.targetGroups {
+		if tg.TargetGroupName == name {
+			return nil, apiError("DuplicateTargetGroupName", "A target group with the same name exists")
+		}
+	}
+	protocol := req.Form.Get("Protocol")
+	if protocol == "" {
+		protocol = elbv2.ProtocolHTTP
+	}
+	if !contains(tgProtocols, protocol) {
+		return nil, validationError("Protocol '%s' is not supported", protocol)
+	}
+	tg := srv.makeTargetGroup(name, protocol)
+	if v := req.Form.Get("Port"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, validationError("Port '%s' is not valid", v)
+		}
+		tg.Port = port
+	}
+	setString := func(field *string, name string) {
+		if v := req.Form.Get(name); v != "" {
+			*field = v
+		}
+	}
+	setString(&tg.VpcId, "VpcId")
+	setString(&tg.TargetType, "TargetType")
+	setString(&tg.HealthCheckProtocol, "HealthCheckProtocol")
+	setString(&tg.HealthCheckPort, "HealthCheckPort")
+	setString(&tg.HealthCheckPath, "HealthCheckPath")
+	setString(&tg.Matcher.HttpCode, "Matcher.HttpCode")
+	for name, field := range map[string]*int{
+		"HealthCheckIntervalSeconds": &tg.HealthCheckIntervalSeconds,
+		"HealthCheckTimeoutSeconds":  &tg.HealthCheckTimeoutSeconds,
+		"HealthyThresholdCount":      &tg.HealthyThresholdCount,
+		"UnhealthyThresholdCount":    &tg.UnhealthyThresholdCount,
+	} {
+		if v := req.Form.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, validationError("%s '%s' is not valid", name, v)
+			}
+			*field = n
+		}
+	}
+	if err := validateHealthCheck(tg, req.Form); err != nil {
+		return nil, err
+	}
+	srv.targetGroups[tg.TargetGroupArn] = tg
+	srv.tgAttributes[tg.TargetGroupArn] = defaultTargetGroupAttributes(tg)
+	return elbv2.CreateTargetGroupResp{TargetGroups: []elbv2.TargetGroup{*tg}}, nil
+}
+
+var (
+	albProtocols = []string{elbv2.ProtocolHTTP, elbv2.ProtocolHTTPS}
+	nlbProtocols = []string{elbv2.ProtocolTCP, elbv2.ProtocolTLS, elbv2.ProtocolUDP, elbv2.ProtocolTCPUDP}
+	tgProtocols  = append(append([]string(nil), albProtocols...), nlbProtocols...)
+)
+
+func isNetworkProtocol(protocol string) bool {
+	return contains(nlbProtocols, protocol)
+}
+
+// makeTargetGroup returns a target group with the defaults AWS uses for the
+// given protocol. Target groups of Network Load Balancers default to TCP
+// health checks.
+func (srv *Server) makeTargetGroup(name, protocol string) *elbv2.TargetGroup {
+	tg := &elbv2.TargetGroup{
+		TargetGroupArn:             fmt.Sprintf("%stargetgroup/%s/%s", arnPrefix, name, srv.newId()),
+		TargetGroupName:            name,
+		Protocol:                   protocol,
+		Port:                       80,
+		VpcId:                      "vpc-1a2b3c4d",
+		TargetType:                 "instance",
+		HealthCheckProtocol:        protocol,
+		HealthCheckPort:            "traffic-port",
+		HealthCheckPath:            "/",
+		HealthCheckIntervalSeconds: 30,
+		HealthCheckTimeoutSeconds:  5,
+		HealthyThresholdCount:      5,
+		UnhealthyThresholdCount:    2,
+		Matcher:                    elbv2.Matcher{HttpCode: "200"},
+	}
+	if isNetworkProtocol(protocol) {
+		tg.HealthCheckProtocol = elbv2.ProtocolTCP
+		tg.HealthCheckPath = ""
+		tg.HealthCheckTimeoutSeconds = 10
+		tg.HealthyThresholdCount = 3
+		tg.UnhealthyThresholdCount = 3
+		tg.Matcher = elbv2.Matcher{}
+	}
+	return tg
+}
+
+// validateHealthCheck checks the health check settings of a new target
+// group against the combinations AWS rejects.
+func validateHealthCheck(tg *elbv2.TargetGroup, form url.Values) error {
+	switch tg.HealthCheckProtocol {
+	case elbv2.ProtocolHTTP, elbv2.ProtocolHTTPS:
+		if !isNetworkProtocol(tg.Protocol) {
+			return nil
+		}
+		if tg.HealthCheckPath == "" {
+			tg.HealthCheckPath = "/"
+		}
+		if tg.Matcher.HttpCode == "" {
+			tg.Matcher.HttpCode = "200-399"
+		}
+	case elbv2.ProtocolTCP:
+		if !isNetworkProtocol(tg.Protocol) {
+			return validationError("Health check protocol '%s' is not supported for target groups with the %s protocol", tg.HealthCheckProtocol, tg.Protocol)
+		}
+		if form.Get("HealthCheckPath") != "" {
+			return validationError("Health check path is not supported for health checks with protocol 'TCP'")
+		}
+		if form.Get("Matcher.HttpCode") != "" {
+			return validationError("Custom health check matchers are not supported for health checks with protocol 'TCP'")
+		}
+	default:
+		return validationError("Health check protocol '%s' is not supported", tg.HealthCheckProtocol)
+	}
+	if i := tg.HealthCheckIntervalSeconds; i != 10 && i != 30 {
+		return validationError("Health check interval must be 10 or 30 seconds for target groups with the %s protocol", tg.Protocol)
+	}
+	if form.Get("HealthCheckTimeoutSeconds") != "" {
+		return validationError("Custom health check timeouts are not supported for target groups with the %s protocol", tg.Protocol)
+	}
+	if tg.HealthyThresholdCount != tg.UnhealthyThresholdCount {
+		return validationError("Healthy and unhealthy threshold counts must be equal for target groups with the %s protocol", tg.Protocol)
+	}
+	return nil
+}
+
+func defaultTargetGroupAttributes(tg *elbv2.TargetGroup) map[string]string {
+	attrs := map[string]string{
+		"deregistration_delay.timeout_seconds": "300",
+		"stickiness.enabled":                   "false",
+	}
+	if isNetworkProtocol(tg.Protocol) {
+		attrs["stickiness.type"] = "source_ip"
+		attrs["proxy_protocol_v2.enabled"] = "false"
+	} else {
+		attrs["stickiness.type"] = "lb_cookie"
+		attrs["stickiness.lb_cookie.duration_seconds"] = "86400"
+		attrs["slow_start.duration_seconds"] = "0"
+	}
+	return attrs
+}
+
+func (srv *Server) targetGroupAttributes(arn string) []elbv2.TargetGroupAttribute {
+	var attrs []elbv2.TargetGroupAttribute
+	for k, v := range srv.tgAttributes[arn] {
+		attrs = append(attrs, elbv2.TargetGroupAttribute{Key: k, Value: v})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+	return attrs
+}
+
+func (srv *Server) describeTargetGroupAttributes(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "TargetGroupArn"); err != nil {
+		return nil, err
+	}
+	arn := req.Form.Get("TargetGroupArn")
+	if err := srv.targetGroupExists(arn); err != nil {
+		return nil, err
+	}
+	return elbv2.DescribeTargetGroupAttributesResp{Attributes: srv.targetGroupAttributes(arn)}, nil
+}
+
+func (srv *Server) modifyTargetGroupAttributes(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "TargetGroupArn", "Attributes.member.1.Key"); err != nil {
+		return nil, err
+	}
+	arn := req.Form.Get("TargetGroupArn")
+	if err := srv.targetGroupExists(arn); err != nil {
+		return nil, err
+	}
+	tg := srv.targetGroups[arn]
+	changed := make(map[string]string)
+	for i := 1; req.Form.Get(fmt.Sprintf("Attributes.member.%d.Key", i)) != ""; i++ {
+		key := fmt.Sprintf("Attributes.member.%d.", i)
+		k, v := req.Form.Get(key+"Key"), req.Form.Get(key+"Value")
+		if _, ok := srv.tgAttributes[arn][k]; !ok {
+			return nil, validationError("Target group attribute key '%s' is not recognized", k)
+		}
+		changed[k] = v
+	}
+	if changed["stickiness.enabled"] == "true" && isNetworkProtocol(tg.Protocol) {
+		return nil, &elb.Error{
+			StatusCode: 400,
+			Code:       elb.ErrInvalidConfigurationRequest,
+			Message:    fmt.Sprintf("Stickiness is not supported for target groups with the %s protocol", tg.Protocol),
+		}
+	}
+	for k, v := range changed {
+		srv.tgAttributes[arn][k] = v
+	}
+	return elbv2.ModifyTargetGroupAttributesResp{Attributes: srv.targetGroupAttributes(arn)}, nil
+}
+
+func targetsParam(form url.Values) []elbv2.TargetDescription {
+	var targets []elbv2.TargetDescription
+	for i := 1; form.Get(fmt.Sprintf("Targets.member.%d.Id", i)) != ""; i++ {
+		key := fmt.Sprintf("Targets.member.%d.", i)
+		port, _ := strconv.Atoi(form.Get(key + "Port"))
+		targets = append(targets, elbv2.TargetDescription{Id: form.Get(key + "Id"), Port: port})
+	}
+	return targets
+}
+
+func (srv *Server) registerTargets(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "TargetGroupArn", "Targets.member.1.Id"); err != nil {
+		return nil, err
+	}
+	tgArn := req.Form.Get("TargetGroupArn")
+	if err := srv.targetGroupExists(tgArn); err != nil {
+		return nil, err
+	}
+	targets := targetsParam(req.Form)
+	for i, target := range targets {
+		if !srv.knownTargets[target.Id] {
+			return nil, apiError("InvalidTarget", fmt.Sprintf("The following targets are not in a running state and cannot be registered: '%s'", target.Id))
+		}
+		if target.Port == 0 {
+			targets[i].Port = srv.targetGroups[tgArn].Port
+		}
+	}
+	for _, target := range targets {
+		if !containsTarget(srv.targets[tgArn], target) {
+			srv.targets[tgArn] = append(srv.targets[tgArn], target)
+		}
+	}
+	return elbv2.SimpleResp{RequestId: reqId}, nil
+}
+
+func (srv *Server) deregisterTargets(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "TargetGroupArn", "Targets.member.1.Id"); err != nil {
+		return nil, err
+	}
+	tgArn := req.Form.Get("TargetGroupArn")
+	if err := srv.targetGroupExists(tgArn); err != nil {
+		return nil, err
+	}
+	for _, target := range targetsParam(req.Form) {
+		if target.Port == 0 {
+			target.Port = srv.targetGroups[tgArn].Port
+		}
+		if !containsTarget(srv.targets[tgArn], target) {
+			return nil, apiError("InvalidTarget", fmt.Sprintf("The following targets are not registered in target group '%s': '%s'", tgArn, target.Id))
+		}
+		var kept []elbv2.TargetDescription
+		for _, t := range srv.targets[tgArn] {
+			if t != target {
+				kept = append(kept, t)
+			}
+		}
+		srv.targets[tgArn] = kept
+	}
+	return elbv2.SimpleResp{RequestId: reqId}, nil
+}
+
+func containsTarget(targets []elbv2.TargetDescription, target elbv2.TargetDescription) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+func (srv *Server) createListener(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "LoadBalancerArn", "Protocol", "Port", "DefaultActions.member.1.Type"); err != nil {
+		return nil, err
+	}
+	lbArn := req.Form.Get("LoadBalancerArn")
+	if err := srv.lbExists(lbArn); err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(req.Form.Get("Port"))
+	if err != nil || port < 1 || port > 65535 {
+		return nil, validationError("Port '%s' is not valid", req.Form.Get("Port"))
+	}
+	protocol := req.Form.Get("Protocol")
+	var certs []elbv2.Certificate
+	for i := 1; req.Form.Get(fmt.Sprintf("Certificates.member.%d.CertificateArn", i)) != ""; i++ {
+		certs = append(certs, elbv2.Certificate{CertificateArn: req.Form.Get(fmt.Sprintf("Certificates.member.%d.CertificateArn", i))})
+	}
+	lb := srv.lbs[lbArn]
+	allowed := albProtocols
+	if lb.Type == elbv2.TypeNetwork {
+		allowed = nlbProtocols
+	}
+	if !contains(allowed, protocol) {
+		return nil, validationError("Listener protocol '%s' must be one of '%s'", protocol, strings.Join(allowed, ", "))
+	}
+	if (protocol == elbv2.ProtocolHTTPS || protocol == elbv2.ProtocolTLS) && len(certs) == 0 {
+		return nil, apiError("CertificateNotFound", fmt.Sprintf("A certificate must be specified for %s listeners", protocol))
+	}
+	defaultActions := actionsParam(req.Form, "DefaultActions")
+	if err := srv.validateActions(defaultActions); err != nil {
+		return nil, err
+	}
+	for _, action := range defaultActions {
+		tg := srv.targetGroups[action.TargetGroupArn]
+		if isNetworkProtocol(tg.Protocol) != isNetworkProtocol(protocol) {
+			return nil, apiError("IncompatibleProtocols", fmt.Sprintf("The %s listener protocol is incompatible with the %s protocol of target group '%s'", protocol, tg.Protocol, tg.TargetGroupArn))
+		}
+	}
+	for _, l := range srv.listeners {
+		if l.LoadBalancerArn == lbArn && l.Port == port {
+			return nil, apiError(elb.ErrDuplicateListener, "A listener already exists on this port for this load balancer")
+		}
+	}
+	l := &elbv2.Listener{
+		ListenerArn:     fmt.Sprintf("%slistener/%s/%s", arnPrefix, lbPath(lb), srv.newId()),
+		LoadBalancerArn: lbArn,
+		Port:            port,
+		Protocol:        protocol,
+		SslPolicy:       req.Form.Get("SslPolicy"),
+		Certificates:    certs,
+		DefaultActions:  defaultActions,
+	}
+	if len(certs) > 0 && l.SslPolicy == "" {
+		l.SslPolicy = "ELBSecurityPolicy-2016-08"
+	}
+	srv.listeners[l.ListenerArn] = l
+	for _, action := range defaultActions {
+		tg := srv.targetGroups[action.TargetGroupArn]
+		if !contains(tg.LoadBalancerArns, lbArn) {
+			tg.LoadBalancerArns = append(tg.LoadBalancerArns, lbArn)
+		}
+	}
+	return elbv2.CreateListenerResp{Listeners: []elbv2.Listener{*l}}, nil
+}
+
+// lbPath returns the "app/name/id" part of the ARN of a Load Balancer.
+func lbPath(lb *elbv2.LoadBalancer) string {
+	return lb.LoadBalancerArn[len(arnPrefix+"loadbalancer/"):]
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (srv *Server) createRule(req *http.Request, reqId string) (interface{}, error) {
+	if err := srv.validate(req.Form, "ListenerArn", "Priority", "Conditions.member.1.Field", "Actions.member.1.Type"); err != nil {
+		return nil, err
+	}
+	listenerArn := req.Form.Get("ListenerArn")
+	listener, ok := srv.listeners[listenerArn]
+	if !ok {
+		return nil, apiError(elb.ErrListenerNotFound, fmt.Sprintf("Listener '%s' not found", listenerArn))
+	}
+	if isNetworkProtocol(listener.Protocol) {
+		return nil, validationError("Rules are not supported for listeners with the %s protocol", listener.Protocol)
+	}
+	priority, err := strconv.Atoi(req.Form.Get("Priority"))
+	if err != nil || priority < 1 || priority > 50000 {
+		return nil, validationError("Priority '%s' must be between 1 and 50000", req.Form.Get("Priority"))
+	}
+	for _, r := range srv.rules {
+		if r.listenerArn == listenerArn && r.Priority == strconv.Itoa(priority) {
+			return nil, apiError("PriorityInUse", fmt.Sprintf("Priority '%d' is currently in use", priority))
+		}
+	}
+	var conditions []elbv2.RuleCondition
+	for i := 1; req.Form.Get(fmt.Sprintf("Conditions.member.%d.Field", i)) != ""; i++ {
+		key := fmt.Sprintf("Conditions.member.%d.", i)
+		field := req.Form.Get(key + "Field")
+		if field != "path-pattern" && field != "host-header" {
+			return nil, validationError("Condition field '%s' is not supported", field)
+		}
+		values := members(req.Form, key+"Values")
+		if len(values) == 0 {
+			return nil, validationError("A condition value must be specified for field '%s'", field)
+		}
+		conditions = append(conditions, elbv2.RuleCondition{Field: field, Values: values})
+	}
+	ruleActions := actionsParam(req.Form, "Actions")
+	if err := srv.validateActions(ruleActions); err != nil {
+		return nil, err
+	}
+	r := &rule{
+		Rule: elbv2.Rule{
+			RuleArn:    fmt.Sprintf("%slistener-rule/%s/%s", arnPrefix, listenerArn[len(arnPrefix+"listener/"):], srv.newId()),
+			Priority:   strconv.Itoa(priority),
+			Conditions: conditions,
+			Actions:    ruleActions,
+		},
+		listenerArn: listenerArn,
+	}
+	srv.rules[r.RuleArn] = r
+	return elbv2.CreateRuleResp{Rules: []elbv2.Rule{r.Rule}}, nil
+}
+
+// NewLoadBalancer creates an Application Load Balancer with the given name
+// and returns its ARN.
+func (srv *Server) NewLoadBalancer(name string) string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	lb := srv.makeLoadBalancer(name, elbv2.TypeApplication)
+	srv.lbs[lb.LoadBalancerArn] = lb
+	return lb.LoadBalancerArn
+}
+
+// NewNetworkLoadBalancer creates a Network Load Balancer with the given
+// name and returns its ARN.
+func (srv *Server) NewNetworkLoadBalancer(name string) string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	lb := srv.makeLoadBalancer(name, elbv2.TypeNetwork)
+	srv.lbs[lb.LoadBalancerArn] = lb
+	return lb.LoadBalancerArn
+}
+
+// RemoveLoadBalancer removes a Load Balancer and its listeners from the
+// server. If no Load Balancer is found it does nothing.
+func (srv *Server) RemoveLoadBalancer(arn string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.removeLoadBalancer(arn)
+}
+
+// LoadBalancer returns the Load Balancer with the given ARN, and whether
+// it exists.
+func (srv *Server) LoadBalancer(arn string) (elbv2.LoadBalancer, bool) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	lb, ok := srv.lbs[arn]
+	if !ok {
+		return elbv2.LoadBalancer{}, false
+	}
+	return *lb, true
+}
+
+// NewTargetGroup creates an HTTP target group on port 80 with the given
+// name and returns its ARN.
+func (srv *Server) NewTargetGroup(name string) string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	tg := srv.makeTargetGroup(name, elbv2.ProtocolHTTP)
+	srv.targetGroups[tg.TargetGroupArn] = tg
+	srv.tgAttributes[tg.TargetGroupArn] = defaultTargetGroupAttributes(tg)
+	return tg.TargetGroupArn
+}
+
+// RemoveTargetGroup removes a target group from the server. If no target
+// group is found it does nothing.
+func (srv *Server) RemoveTargetGroup(arn string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	delete(srv.targetGroups, arn)
+	delete(srv.targets, arn)
+	delete(srv.tgAttributes, arn)
+}
+
+// NewTarget creates a fake instance that can be registered with target
+// groups and returns its ID.
+func (srv *Server) NewTarget() string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	srv.idCount++
+	id := fmt.Sprintf("i-%d", srv.idCount)
+	srv.knownTargets[id] = true
+	return id
+}
+
+// RemoveTarget removes a fake instance. It is not deregistered from the
+// target groups it belongs to.
+func (srv *Server) RemoveTarget(id string) {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	delete(srv.knownTargets, id)
+}
+
+// Targets returns the targets registered with a target group.
+func (srv *Server) Targets(targetGroupArn string) []elbv2.TargetDescription {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	return append([]elbv2.TargetDescription(nil), srv.targets[targetGroupArn]...)
+}
+
+// Listeners returns the listeners of a Load Balancer, sorted by port.
+func (srv *Server) Listeners(lbArn string) []elbv2.Listener {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	var listeners []elbv2.Listener
+	for _, l := range srv.listeners {
+		if l.LoadBalancerArn == lbArn {
+			listeners = append(listeners, *l)
+		}
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].Port < listeners[j].Port
+	})
+	return listeners
+}
+
+// Rules returns the rules of a listener, sorted by priority.
+func (srv *Server) Rules(listenerArn string) []elbv2.Rule {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	var rules []elbv2.Rule
+	for _, r := range srv.rules {
+		if r.listenerArn == listenerArn {
+			rules = append(rules, r.Rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		pi, _ := strconv.Atoi(rules[i].Priority)
+		pj, _ := strconv.Atoi(rules[j].Priority)
+		return pi < pj
+	})
+	return rules
+}
+
+var actions = map[string]func(*Server, *http.Request, string) (interface{}, error){
+	"CreateLoadBalancer":            (*Server).createLoadBalancer,
+	"DeleteLoadBalancer":            (*Server).deleteLoadBalancer,
+	"DescribeLoadBalancers":         (*Server).describeLoadBalancers,
+	"SetIpAddressType":              (*Server).setIpAddressType,
+	"CreateTargetGroup":             (*Server).createTargetGroup,
+	"DescribeTargetGroupAttributes": (*Server).describeTargetGroupAttributes,
+	"ModifyTargetGroupAttributes":   (*Server).modifyTargetGroupAttributes,
+	"RegisterTargets":               (*Server).registerTargets,
+	"DeregisterTargets":             (*Server).deregisterTargets,
+	"CreateListener":                (*Server).createListener,
+	"CreateRule":                    (*Server).createRule,
+}
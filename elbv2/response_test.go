@@ -0,0 +1,209 @@
+package elbv2_test
+
+var CreateLoadBalancer = `
+<CreateLoadBalancerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <CreateLoadBalancerResult>
+    <LoadBalancers>
+      <member>
+        <LoadBalancerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-load-balancer/50dc6c495c0c9188</LoadBalancerArn>
+        <Scheme>internet-facing</Scheme>
+        <LoadBalancerName>my-load-balancer</LoadBalancerName>
+        <VpcId>vpc-3ac0fb5f</VpcId>
+        <CanonicalHostedZoneId>Z2P70J7EXAMPLE</CanonicalHostedZoneId>
+        <CreatedTime>2016-03-25T21:29:48.850Z</CreatedTime>
+        <AvailabilityZones>
+          <member>
+            <SubnetId>subnet-8360a9e7</SubnetId>
+            <ZoneName>us-west-2a</ZoneName>
+          </member>
+          <member>
+            <SubnetId>subnet-b7d581c0</SubnetId>
+            <ZoneName>us-west-2b</ZoneName>
+          </member>
+        </AvailabilityZones>
+        <SecurityGroups>
+          <member>sg-5943793c</member>
+        </SecurityGroups>
+        <DNSName>my-load-balancer-424835706.us-west-2.elb.amazonaws.com</DNSName>
+        <State>
+          <Code>provisioning</Code>
+        </State>
+        <Type>application</Type>
+      </member>
+    </LoadBalancers>
+  </CreateLoadBalancerResult>
+  <ResponseMetadata>
+    <RequestId>32d531b2-f2d0-11e5-9192-3fff33344cfa</RequestId>
+  </ResponseMetadata>
+</CreateLoadBalancerResponse>
+`
+
+var DeleteLoadBalancer = `
+<DeleteLoadBalancerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DeleteLoadBalancerResult/>
+  <ResponseMetadata>
+    <RequestId>1549581b-12b7-11e3-895e-1334aEXAMPLE</RequestId>
+  </ResponseMetadata>
+</DeleteLoadBalancerResponse>
+`
+
+var DescribeLoadBalancers = `
+<DescribeLoadBalancersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DescribeLoadBalancersResult>
+    <LoadBalancers>
+      <member>
+        <LoadBalancerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-load-balancer/50dc6c495c0c9188</LoadBalancerArn>
+        <Scheme>internet-facing</Scheme>
+        <LoadBalancerName>my-load-balancer</LoadBalancerName>
+        <VpcId>vpc-3ac0fb5f</VpcId>
+        <DNSName>my-load-balancer-424835706.us-west-2.elb.amazonaws.com</DNSName>
+        <State>
+          <Code>active</Code>
+        </State>
+        <Type>application</Type>
+      </member>
+    </LoadBalancers>
+    <NextMarker>somemarker</NextMarker>
+  </DescribeLoadBalancersResult>
+  <ResponseMetadata>
+    <RequestId>6581c0ac-f39f-11e5-bb98-57195a6eb84a</RequestId>
+  </ResponseMetadata>
+</DescribeLoadBalancersResponse>
+`
+
+var CreateTargetGroup = `
+<CreateTargetGroupResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <CreateTargetGroupResult>
+    <TargetGroups>
+      <member>
+        <TargetGroupArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067</TargetGroupArn>
+        <TargetGroupName>my-targets</TargetGroupName>
+        <Protocol>HTTP</Protocol>
+        <Port>80</Port>
+        <VpcId>vpc-3ac0fb5f</VpcId>
+        <HealthCheckProtocol>HTTP</HealthCheckProtocol>
+        <HealthCheckPort>traffic-port</HealthCheckPort>
+        <HealthCheckPath>/</HealthCheckPath>
+        <HealthCheckIntervalSeconds>30</HealthCheckIntervalSeconds>
+        <HealthCheckTimeoutSeconds>5</HealthCheckTimeoutSeconds>
+        <HealthyThresholdCount>5</HealthyThresholdCount>
+        <UnhealthyThresholdCount>2</UnhealthyThresholdCount>
+        <Matcher>
+          <HttpCode>200</HttpCode>
+        </Matcher>
+      </member>
+    </TargetGroups>
+  </CreateTargetGroupResult>
+  <ResponseMetadata>
+    <RequestId>b83fe90e-f2d5-11e5-b95d-3b2c1831fc26</RequestId>
+  </ResponseMetadata>
+</CreateTargetGroupResponse>
+`
+
+var RegisterTargets = `
+<RegisterTargetsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <RegisterTargetsResult/>
+  <ResponseMetadata>
+    <RequestId>f9a8fb7b-f2d6-11e5-b95d-3b2c1831fc26</RequestId>
+  </ResponseMetadata>
+</RegisterTargetsResponse>
+`
+
+var DeregisterTargets = `
+<DeregisterTargetsResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <DeregisterTargetsResult/>
+  <ResponseMetadata>
+    <RequestId>c5d8b3b5-f2d7-11e5-b95d-3b2c1831fc26</RequestId>
+  </ResponseMetadata>
+</DeregisterTargetsResponse>
+`
+
+var ModifyTargetGroupAttributes = `
+<ModifyTargetGroupAttributesResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <ModifyTargetGroupAttributesResult>
+    <Attributes>
+      <member>
+        <Value>false</Value>
+        <Key>stickiness.enabled</Key>
+      </member>
+      <member>
+        <Value>600</Value>
+        <Key>deregistration_delay.timeout_seconds</Key>
+      </member>
+      <member>
+        <Value>source_ip</Value>
+        <Key>stickiness.type</Key>
+      </member>
+    </Attributes>
+  </ModifyTargetGroupAttributesResult>
+  <ResponseMetadata>
+    <RequestId>54a0b4b2-f2d9-11e5-b95d-3b2c1831fc26</RequestId>
+  </ResponseMetadata>
+</ModifyTargetGroupAttributesResponse>
+`
+
+var CreateListener = `
+<CreateListenerResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <CreateListenerResult>
+    <Listeners>
+      <member>
+        <LoadBalancerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/my-load-balancer/50dc6c495c0c9188</LoadBalancerArn>
+        <Protocol>HTTP</Protocol>
+        <Port>80</Port>
+        <ListenerArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2</ListenerArn>
+        <DefaultActions>
+          <member>
+            <Type>forward</Type>
+            <TargetGroupArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067</TargetGroupArn>
+          </member>
+        </DefaultActions>
+      </member>
+    </Listeners>
+  </CreateListenerResult>
+  <ResponseMetadata>
+    <RequestId>883e2d33-f2d8-11e5-b95d-3b2c1831fc26</RequestId>
+  </ResponseMetadata>
+</CreateListenerResponse>
+`
+
+var CreateRule = `
+<CreateRuleResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <CreateRuleResult>
+    <Rules>
+      <member>
+        <IsDefault>false</IsDefault>
+        <Conditions>
+          <member>
+            <Field>path-pattern</Field>
+            <Values>
+              <member>/img/*</member>
+            </Values>
+          </member>
+        </Conditions>
+        <Priority>10</Priority>
+        <Actions>
+          <member>
+            <Type>forward</Type>
+            <TargetGroupArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067</TargetGroupArn>
+          </member>
+        </Actions>
+        <RuleArn>arn:aws:elasticloadbalancing:us-west-2:123456789012:listener-rule/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2/9683b2d02a6cabee</RuleArn>
+      </member>
+    </Rules>
+  </CreateRuleResult>
+  <ResponseMetadata>
+    <RequestId>c5478c83-f397-11e5-bb98-57195a6eb84a</RequestId>
+  </ResponseMetadata>
+</CreateRuleResponse>
+`
+
+var LoadBalancerNotFound = `
+<ErrorResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>LoadBalancerNotFound</Code>
+    <Message>One or more load balancers not found</Message>
+  </Error>
+  <RequestId>dc4c5b7e-f3a0-11e5-bb98-57195a6eb84a</RequestId>
+</ErrorResponse>
+`
@@ -0,0 +1,59 @@
+package elbv2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/flaviamissi/go-elb/elb"
+	"strings"
+)
+
+// ErrInvalidParameterCombination is wrapped by the errors returned, before
+// sending any request, for parameter combinations that Network Load
+// Balancers reject.
+var ErrInvalidParameterCombination = errors.New("elbv2: invalid parameter combination")
+
+func isNetworkProtocol(protocol string) bool {
+	switch protocol {
+	case ProtocolTCP, ProtocolTLS, ProtocolUDP, ProtocolTCPUDP:
+		return true
+	}
+	return false
+}
+
+// validate checks the health check of the target group: health checks
+// using TCP, the default for the protocols of Network Load Balancers,
+// have neither a path nor a matcher.
+func (o *CreateTargetGroup) validate() error {
+	protocol := o.HealthCheckProtocol
+	if protocol == "" && isNetworkProtocol(o.Protocol) {
+		protocol = ProtocolTCP
+	}
+	if protocol != ProtocolTCP {
+		return nil
+	}
+	if o.HealthCheckPath != "" {
+		return fmt.Errorf("%w: HealthCheckPath with a TCP health check", ErrInvalidParameterCombination)
+	}
+	if o.Matcher != "" {
+		return fmt.Errorf("%w: Matcher with a TCP health check", ErrInvalidParameterCombination)
+	}
+	return nil
+}
+
+// validate checks that the protocol of the listener is supported by the
+// type of its Load Balancer, as told by the ARN of the Load Balancer:
+// HTTP and HTTPS for Application Load Balancers, TCP, TLS, UDP and
+// TCP_UDP for Network Load Balancers.
+func (o *CreateListener) validate() error {
+	arn, err := elb.ParseARN(o.LoadBalancerArn)
+	if err != nil || o.Protocol == "" {
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(arn.Resource, "loadbalancer/app/") && isNetworkProtocol(o.Protocol):
+		return fmt.Errorf("%w: %s listener on an Application Load Balancer", ErrInvalidParameterCombination, o.Protocol)
+	case strings.HasPrefix(arn.Resource, "loadbalancer/net/") && !isNetworkProtocol(o.Protocol):
+		return fmt.Errorf("%w: %s listener on a Network Load Balancer", ErrInvalidParameterCombination, o.Protocol)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// This package provides a fake server answering the requests of query API
+// clients with canned responses, for their tests.
+package querytest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// Server answers each request with the next response given to
+// PrepareResponse, and hands the request over to WaitRequest.
+type Server struct {
+	*httptest.Server
+	timeout   time.Duration
+	requests  chan *http.Request
+	responses chan response
+}
+
+type response struct {
+	status  int
+	headers map[string]string
+	body    string
+}
+
+// NewServer starts a server. Requests wait up to timeout for their
+// response, and WaitRequest up to timeout for a request.
+func NewServer(timeout time.Duration) *Server {
+	s := &Server{
+		timeout:   timeout,
+		requests:  make(chan *http.Request, 64),
+		responses: make(chan response, 64),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	req.ParseForm()
+	s.requests <- req
+	var resp response
+	select {
+	case resp = <-s.responses:
+	case <-time.After(s.timeout):
+		resp = response{status: 500, body: "timeout waiting for the test to provide a response"}
+	}
+	for k, v := range resp.headers {
+		w.Header().Set(k, v)
+	}
+	if resp.status != 0 {
+		w.WriteHeader(resp.status)
+	}
+	w.Write([]byte(resp.body))
+}
+
+// PrepareResponse queues the response to the next request.
+func (s *Server) PrepareResponse(status int, headers map[string]string, body string) {
+	s.responses <- response{status, headers, body}
+}
+
+// WaitRequest returns the next request received, and panics if none is
+// received in time.
+func (s *Server) WaitRequest() *http.Request {
+	select {
+	case req := <-s.requests:
+		return req
+	case <-time.After(s.timeout):
+		panic("timeout waiting for a request")
+	}
+}
+
+// FlushRequests discards the requests not consumed by WaitRequest.
+func (s *Server) FlushRequests() {
+	for {
+		select {
+		case <-s.requests:
+		default:
+			return
+		}
+	}
+}